@@ -0,0 +1,77 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/library"
+)
+
+// analyzeStructures prints profiles of structures found in vertical
+// files along with a suggested atomStructure
+func analyzeStructures(ctx context.Context, files []string, encoding string, maxLines int, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("missing vertical file")
+	}
+	conf := &cnf.VTEConf{VerticalFiles: files, Encoding: encoding}
+	report, err := library.ProfileStructures(ctx, conf, maxLines)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		data, err := sonic.ConfigDefault.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	sample := "whole vertical"
+	if !report.Complete {
+		sample = "sample"
+	}
+	fmt.Printf("lines: %d, tokens: %d (%s)\n\n", report.NumLines, report.NumTokens, sample)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "structure\tdepth\tcount\tcoverage\tmin\tavg\tmax\tattributes")
+	for _, st := range report.Structures {
+		fmt.Fprintf(
+			tw, "%s%s\t%d\t%d\t%.1f%%\t%d\t%.1f\t%d\t%s\n",
+			strings.Repeat("  ", st.Depth), st.Name, st.Depth, st.Count, st.Coverage*100,
+			st.MinTokens, st.AvgTokens, st.MaxTokens, strings.Join(st.Attrs, ", "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Println()
+	if report.SuggestedAtom != "" {
+		fmt.Printf("suggested atomStructure: %s (%s)\n", report.SuggestedAtom, report.Reason)
+
+	} else {
+		fmt.Printf("no atomStructure suggested (%s)\n", report.Reason)
+	}
+	return nil
+}
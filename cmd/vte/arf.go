@@ -0,0 +1,51 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/library"
+)
+
+// recomputeARF calculates ARF for an existing colcounts
+// table and prints a short summary
+func recomputeARF(ctx context.Context, conf *cnf.VTEConf, format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	result, err := library.RecomputeARF(ctx, conf)
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		data, err := sonic.ConfigDefault.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		fmt.Println(string(data))
+
+	} else {
+		fmt.Printf(
+			"n-grams: %d\nupdated rows: %d\ntokens: %d\n",
+			result.NumNgrams, result.NumUpdatedRows, result.NumTokens)
+	}
+	return nil
+}
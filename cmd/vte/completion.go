@@ -0,0 +1,242 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// commandInfo describes a vte subcommand for the purpose
+// of help, shell completion and man page generation
+type commandInfo struct {
+	name string
+
+	// args is a short synopsis of command arguments
+	args string
+
+	desc string
+
+	// fset contains command options (nil for commands without options)
+	fset *flag.FlagSet
+}
+
+func (c commandInfo) synopsis() string {
+	if c.args == "" {
+		return "vte " + c.name
+	}
+	return "vte " + c.name + " " + c.args
+}
+
+func (c commandInfo) flags() []*flag.Flag {
+	ans := make([]*flag.Flag, 0, 10)
+	if c.fset != nil {
+		c.fset.VisitAll(func(f *flag.Flag) {
+			ans = append(ans, f)
+		})
+	}
+	return ans
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+func commandNames(cmds []commandInfo) []string {
+	ans := make([]string, len(cmds))
+	for i, c := range cmds {
+		ans[i] = c.name
+	}
+	return ans
+}
+
+func writeBashCompletion(w io.Writer, cmds []commandInfo) {
+	fmt.Fprintln(w, "# bash completion for vte")
+	fmt.Fprintln(w, "_vte() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    local opts=""`)
+	fmt.Fprintln(w, `    if [ "$COMP_CWORD" -eq 1 ]; then`)
+	fmt.Fprintf(w, "        COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(commandNames(cmds), " "))
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    case "${COMP_WORDS[1]}" in`)
+	for _, c := range cmds {
+		flags := c.flags()
+		if len(flags) == 0 {
+			continue
+		}
+		names := make([]string, len(flags))
+		for i, f := range flags {
+			names[i] = "-" + f.Name
+		}
+		fmt.Fprintf(w, "        %s) opts=\"%s\" ;;\n", c.name, strings.Join(names, " "))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, `    if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=( $(compgen -W "$opts" -- "$cur") )`)
+	fmt.Fprintln(w, "    else")
+	fmt.Fprintln(w, `        COMPREPLY=( $(compgen -f -- "$cur") )`)
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o filenames -F _vte vte")
+}
+
+func zshEscape(s string) string {
+	r := strings.NewReplacer("'", "'\\''", "[", "\\[", "]", "\\]", ":", "\\:")
+	return r.Replace(s)
+}
+
+func writeZshCompletion(w io.Writer, cmds []commandInfo) {
+	fmt.Fprintln(w, "#compdef vte")
+	fmt.Fprintln(w, "_vte() {")
+	fmt.Fprintln(w, "    local -a commands")
+	fmt.Fprintln(w, "    commands=(")
+	for _, c := range cmds {
+		fmt.Fprintf(w, "        '%s:%s'\n", c.name, zshEscape(c.desc))
+	}
+	fmt.Fprintln(w, "    )")
+	fmt.Fprintln(w, "    if (( CURRENT == 2 )); then")
+	fmt.Fprintln(w, "        _describe 'command' commands")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    case $words[2] in")
+	for _, c := range cmds {
+		flags := c.flags()
+		if len(flags) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s)\n", c.name)
+		fmt.Fprintln(w, "            _arguments \\")
+		for _, f := range flags {
+			if isBoolFlag(f) {
+				fmt.Fprintf(w, "                '-%s[%s]' \\\n", f.Name, zshEscape(f.Usage))
+
+			} else {
+				fmt.Fprintf(w, "                '-%s[%s]:value:' \\\n", f.Name, zshEscape(f.Usage))
+			}
+		}
+		fmt.Fprintln(w, "                '*:file:_files'")
+		fmt.Fprintln(w, "            ;;")
+	}
+	fmt.Fprintln(w, "        *)")
+	fmt.Fprintln(w, "            _files")
+	fmt.Fprintln(w, "            ;;")
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, `_vte "$@"`)
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", `\'`)
+}
+
+func writeFishCompletion(w io.Writer, cmds []commandInfo) {
+	fmt.Fprintln(w, "# fish completion for vte")
+	for _, c := range cmds {
+		fmt.Fprintf(
+			w, "complete -c vte -f -n '__fish_use_subcommand' -a %s -d '%s'\n", c.name, fishEscape(c.desc))
+	}
+	for _, c := range cmds {
+		for _, f := range c.flags() {
+			var reqArg string
+			if !isBoolFlag(f) {
+				reqArg = " -r"
+			}
+			fmt.Fprintf(
+				w, "complete -c vte -n '__fish_seen_subcommand_from %s' -o %s%s -d '%s'\n",
+				c.name, f.Name, reqArg, fishEscape(f.Usage))
+		}
+	}
+}
+
+// writeCompletion writes a shell completion script
+// for a specified shell (bash, zsh, fish)
+func writeCompletion(w io.Writer, shell string, cmds []commandInfo) error {
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, cmds)
+	case "zsh":
+		writeZshCompletion(w, cmds)
+	case "fish":
+		writeFishCompletion(w, cmds)
+	default:
+		return fmt.Errorf("unsupported shell '%s' (supported: bash, zsh, fish)", shell)
+	}
+	return nil
+}
+
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// writeManPage writes a man page (in the roff format)
+// describing all the commands and their options
+func writeManPage(w io.Writer, cmds []commandInfo, version string) {
+	fmt.Fprintf(
+		w, ".TH VTE 1 \"%s\" \"vert-tagextract %s\" \"User Commands\"\n",
+		time.Now().Format("2006-01-02"), roffEscape(version))
+	fmt.Fprintln(w, ".SH NAME")
+	fmt.Fprintln(w, `vte \- extract text types and positional attributes from a corpus vertical file`)
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, `.B vte`)
+	fmt.Fprintln(w, `.I command`)
+	fmt.Fprintln(w, `[options] [arguments]`)
+	fmt.Fprintln(w, ".SH DESCRIPTION")
+	fmt.Fprintln(w, "Vert-tagextract extracts structural attribute metadata and n-gram frequency")
+	fmt.Fprintln(w, "information from a corpus vertical file to an SQL database (SQLite or MySQL).")
+	fmt.Fprintln(w, "Options can be written both as \\fB\\-option\\fR and \\fB\\-\\-option\\fR.")
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, c := range cmds {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, ".B %s\n", roffEscape(c.synopsis()))
+		fmt.Fprintln(w, roffEscape(c.desc))
+		flags := c.flags()
+		if len(flags) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, ".RS")
+		for _, f := range flags {
+			fmt.Fprintln(w, ".TP")
+			if isBoolFlag(f) {
+				fmt.Fprintf(w, "\\fB\\-%s\\fR\n", roffEscape(f.Name))
+
+			} else {
+				fmt.Fprintf(w, "\\fB\\-%s\\fR \\fIvalue\\fR\n", roffEscape(f.Name))
+			}
+			usage := f.Usage
+			if f.DefValue != "" && !isBoolFlag(f) {
+				usage += fmt.Sprintf(" (default: %s)", f.DefValue)
+			}
+			fmt.Fprintln(w, roffEscape(usage))
+		}
+		fmt.Fprintln(w, ".RE")
+	}
+	fmt.Fprintln(w, ".SH ENVIRONMENT")
+	fmt.Fprintln(w, "With \\fB\\-config\\-env\\fR, the configuration is read from \\fBVTE_CONFIG\\fR")
+	fmt.Fprintln(w, "(a complete JSON configuration) and/or individual \\fBVTE_*\\fR variables")
+	fmt.Fprintln(w, "(e.g. \\fBVTE_CORPUS\\fR, \\fBVTE_DB_PASSWORD\\fR).")
+	fmt.Fprintln(w, ".SH SEE ALSO")
+	fmt.Fprintln(w, "https://github.com/czcorpus/vert\\-tagextract")
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/colcounts"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/rs/zerolog/log"
+)
+
+func writeFreqListFile(path, label string, items []colcounts.FreqItem) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write frequency list: %w", err)
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if err := colcounts.WriteFreqListTSV(w, label, items); err != nil {
+		return fmt.Errorf("failed to write frequency list: %w", err)
+	}
+	return w.Flush()
+}
+
+// exportFreqLists creates frequency lists of counted columns. With
+// outDir specified, each column is written to [corpus].[column].tsv
+// there. Otherwise a single column (selected by column) is written
+// to stdout.
+func exportFreqLists(conf *cnf.VTEConf, column, outDir string, minCount, limit int) error {
+	columns, err := selectCountedColumns(conf, column)
+	if err != nil {
+		return err
+	}
+	if outDir == "" && len(columns) > 1 {
+		return fmt.Errorf("more counted columns configured, please specify -column or -output-dir")
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, col := range columns {
+		label := countedColumnLabel(col)
+		items, err := colcounts.FreqList(
+			reader,
+			colcounts.FreqListQuery{
+				Corpus:   conf.CorpusID(),
+				Column:   col,
+				MinCount: minCount,
+				Limit:    limit,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if outDir == "" {
+			w := bufio.NewWriter(os.Stdout)
+			if err := colcounts.WriteFreqListTSV(w, label, items); err != nil {
+				return err
+			}
+			return w.Flush()
+		}
+		path := filepath.Join(outDir, fmt.Sprintf("%s.%s.tsv", conf.Corpus, label))
+		if err := writeFreqListFile(path, label, items); err != nil {
+			return err
+		}
+		log.Info().Str("file", path).Int("items", len(items)).Msg("written frequency list")
+	}
+	return nil
+}
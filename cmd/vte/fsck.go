@@ -0,0 +1,59 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/fsck"
+)
+
+// checkDatabase runs integrity checks of a generated database and
+// prints a report. The returned bool is true in case the report
+// contains at least one error.
+func checkDatabase(conf *cnf.VTEConf, format string) (bool, error) {
+	if format != "text" && format != "json" {
+		return false, fmt.Errorf("unsupported output format '%s'", format)
+	}
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return false, err
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	report, err := fsck.Check(reader, conf)
+	if err != nil {
+		return false, err
+	}
+	if format == "json" {
+		data, err := sonic.ConfigDefault.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return false, fmt.Errorf("failed to encode report: %w", err)
+		}
+		fmt.Println(string(data))
+
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	return report.HasErrors(), nil
+}
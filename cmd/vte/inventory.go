@@ -0,0 +1,62 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/library"
+)
+
+// extractInventory writes all the distinct values of a positional
+// attribute along with their frequencies to stdout (as TSV).
+// The column can be specified either by its index in the vertical
+// or by a name of a configured counted column.
+func extractInventory(ctx context.Context, conf *cnf.VTEConf, column, modFn string, minCount int) error {
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return err
+	}
+	col, ok := conf.Ngrams.VertColumns.FindByName(column)
+	if !ok {
+		idx, err := strconv.Atoi(column)
+		if err != nil || idx < 0 {
+			return fmt.Errorf("invalid column '%s' (expected an index or a counted column name)", column)
+		}
+		col = db.VertColumn{Idx: idx}
+	}
+	if modFn != "" {
+		col.ModFn = modFn
+	}
+	items, err := library.ExtractInventory(ctx, conf, col)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	for _, item := range items {
+		if item.Count < minCount {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%d\n", item.Value, item.Count)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/colcounts"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+)
+
+func countedColumnLabel(vc db.VertColumn) string {
+	if vc.Role != "" {
+		return vc.Role
+	}
+	return fmt.Sprintf("col%d", vc.Idx)
+}
+
+func columnLabel(conf *cnf.VTEConf, i int) string {
+	return countedColumnLabel(conf.Ngrams.VertColumns[i])
+}
+
+// selectCountedColumns returns either a single counted column
+// specified by its name or all the counted columns in case
+// the name is empty
+func selectCountedColumns(conf *cnf.VTEConf, name string) (db.VertColumns, error) {
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return nil, err
+	}
+	if len(conf.Ngrams.VertColumns) == 0 {
+		return nil, fmt.Errorf("no counted columns configured for corpus %s", conf.Corpus)
+	}
+	if name == "" {
+		return conf.Ngrams.VertColumns, nil
+	}
+	col, ok := conf.Ngrams.VertColumns.FindByName(name)
+	if !ok {
+		return nil, fmt.Errorf("column '%s' is not among counted columns", name)
+	}
+	return db.VertColumns{col}, nil
+}
+
+// queryNgrams searches the colcounts table of a corpus
+// and prints matching n-grams to stdout
+func queryNgrams(conf *cnf.VTEConf, matches []string, minCount, limit int) error {
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return err
+	}
+	if len(conf.Ngrams.VertColumns) == 0 {
+		return fmt.Errorf("no counted columns configured for corpus %s", conf.Corpus)
+	}
+	query := colcounts.Query{
+		Corpus:   conf.CorpusID(),
+		MinCount: minCount,
+		Limit:    limit,
+	}
+	for _, expr := range matches {
+		m, err := colcounts.ParseMatch(expr, conf.Ngrams.VertColumns)
+		if err != nil {
+			return err
+		}
+		query.Matches = append(query.Matches, m)
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	records, err := colcounts.Search(reader, conf.Ngrams.VertColumns, query)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	hdr := make([]string, len(conf.Ngrams.VertColumns))
+	for i := range conf.Ngrams.VertColumns {
+		hdr[i] = columnLabel(conf, i)
+	}
+	fmt.Fprintf(tw, "%s\tcount\tarf\n", strings.Join(hdr, "\t"))
+	for _, rec := range records {
+		fmt.Fprintf(tw, "%s\t%d\t%01.2f\n", strings.Join(rec.Values, "\t"), rec.Count, rec.ARF)
+	}
+	return tw.Flush()
+}
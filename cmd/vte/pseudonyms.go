@@ -0,0 +1,74 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+)
+
+// printPseudonyms prints the pseudonymization mapping
+// (see cnf.AnonymizeConf.MappingFile), optionally limited
+// to a single attribute
+func printPseudonyms(conf *cnf.VTEConf, attr, format string) error {
+	if conf.Anonymize.MappingFile == "" {
+		return fmt.Errorf("no anonymize mappingFile configured")
+	}
+	key, err := conf.Anonymize.MappingKey()
+	if err != nil {
+		return err
+	}
+	dict, err := proc.LoadPseudonymDict(conf.Anonymize.MappingFile, key)
+	if err != nil {
+		return err
+	}
+	mapping := dict.Mapping()
+	if attr != "" {
+		mapping = map[string]map[string]string{attr: mapping[attr]}
+	}
+	switch format {
+	case "tsv":
+		attrs := make([]string, 0, len(mapping))
+		for k := range mapping {
+			attrs = append(attrs, k)
+		}
+		sort.Strings(attrs)
+		for _, a := range attrs {
+			pseudonyms := make([]string, 0, len(mapping[a]))
+			for p := range mapping[a] {
+				pseudonyms = append(pseudonyms, p)
+			}
+			sort.Strings(pseudonyms)
+			for _, p := range pseudonyms {
+				fmt.Printf("%s\t%s\t%s\n", a, p, mapping[a][p])
+			}
+		}
+	case "json":
+		data, err := sonic.ConfigStd.MarshalIndent(mapping, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode pseudonyms: %w", err)
+		}
+		fmt.Println(string(data))
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/registry"
+)
+
+// writeRegistryDraft prints a draft Manatee registry based
+// on the configuration and data stored in the database
+func writeRegistryDraft(conf *cnf.VTEConf, maxSubcorpValues int) error {
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return err
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	stats, err := registry.CollectAttrStats(reader, conf)
+	if err != nil {
+		return err
+	}
+	return registry.Write(
+		os.Stdout, conf, stats, registry.Options{MaxSubcorpValues: maxSubcorpValues})
+}
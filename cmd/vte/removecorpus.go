@@ -0,0 +1,48 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/library"
+)
+
+// removeCorpus deletes rows of a single corpus (by default the configured
+// one) from a grouped database and prints numbers of deleted rows
+func removeCorpus(conf *cnf.VTEConf, corpus string) error {
+	if corpus != "" {
+		conf.Corpus = corpus
+	}
+	if conf.Corpus == "" {
+		return fmt.Errorf("no corpus specified")
+	}
+	removed, err := library.RemoveCorpus(conf)
+	for _, item := range removed {
+		tables := make([]string, 0, len(item.Tables))
+		for table := range item.Tables {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			fmt.Printf("%s\t%s\t%d\n", item.Database, table, item.Tables[table])
+		}
+	}
+	return err
+}
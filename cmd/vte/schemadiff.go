@@ -0,0 +1,67 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+)
+
+// printSchemaDiff prints schema changes between two major versions.
+// In case of the 'sql' format, statements migrating a database
+// described by conf are printed instead (conf is not needed otherwise).
+func printSchemaDiff(from, to, format string, conf *cnf.VTEConf) error {
+	changes, err := db.SchemaChanges(from, to)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "text":
+		for _, ch := range changes {
+			fmt.Println(ch.String())
+		}
+	case "json":
+		out := struct {
+			From    string            `json:"from"`
+			To      string            `json:"to"`
+			Changes []db.SchemaChange `json:"changes"`
+		}{From: from, To: to, Changes: changes}
+		data, err := sonic.ConfigDefault.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode schema changes: %w", err)
+		}
+		fmt.Println(string(data))
+	case "sql":
+		if conf == nil {
+			return fmt.Errorf("the sql format requires a config file")
+		}
+		stmts, err := factory.MigrationSQL(conf, to, changes)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range stmts {
+			fmt.Println(stmt)
+		}
+	default:
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	return nil
+}
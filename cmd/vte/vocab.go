@@ -0,0 +1,64 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/colcounts"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+)
+
+// reportVocabStats calculates vocabulary statistics (vocabulary
+// growth, hapax ratio, coverage) of counted columns and writes them
+// to stdout in a specified format (json, csv)
+func reportVocabStats(conf *cnf.VTEConf, column, format string, numPoints int) error {
+	if format != "json" && format != "csv" {
+		return fmt.Errorf("unsupported output format '%s'", format)
+	}
+	columns, err := selectCountedColumns(conf, column)
+	if err != nil {
+		return err
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	ans := make([]colcounts.VocabStats, len(columns))
+	for i, col := range columns {
+		freqs, err := colcounts.ColumnFrequencies(reader, conf.CorpusID(), col)
+		if err != nil {
+			return err
+		}
+		ans[i] = colcounts.ComputeVocabStats(freqs, numPoints)
+		ans[i].Corpus = conf.Corpus
+		ans[i].Column = countedColumnLabel(col)
+	}
+	if format == "csv" {
+		return colcounts.WriteVocabStatsCSV(os.Stdout, ans)
+	}
+	data, err := sonic.ConfigDefault.MarshalIndent(ans, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode vocabulary statistics: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -32,6 +33,11 @@ import (
 	"github.com/czcorpus/vert-tagextract/v3/cnf"
 	"github.com/czcorpus/vert-tagextract/v3/db/colgen"
 	"github.com/czcorpus/vert-tagextract/v3/library"
+	"github.com/czcorpus/vert-tagextract/v3/monitor"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/czcorpus/vert-tagextract/v3/registry"
+	"github.com/czcorpus/vert-tagextract/v3/server"
+	"github.com/czcorpus/vert-tagextract/v3/udex"
 
 	"github.com/tomachalek/vertigo/v6"
 
@@ -70,29 +76,147 @@ func dumpNewConf(corpusName string) {
 	fmt.Println()
 }
 
-func exportData(ctx context.Context, confPath string, appendData bool) error {
-	conf, err := cnf.LoadConf(confPath)
+type confSourceArgs struct {
+	fromEnv     bool
+	useKeyring  bool
+	askPassword bool
+	dbConfFrom  string
+	dbConfPath  string
+}
+
+func (args *confSourceArgs) register(fset *flag.FlagSet) {
+	fset.BoolVar(
+		&args.fromEnv, "config-env", false, "read configuration from environment (VTE_CONFIG and/or VTE_* variables)")
+	fset.BoolVar(
+		&args.useKeyring, "keyring", false, "read database password from OS keyring")
+	fset.BoolVar(
+		&args.askPassword, "ask-password", false, "ask for database password interactively")
+	fset.StringVar(
+		&args.dbConfFrom, "db-conf-from", "", "read database connection settings from another JSON config file")
+	fset.StringVar(
+		&args.dbConfPath, "db-conf-jsonpath", "",
+		"dot-separated path to database settings within -db-conf-from file (e.g. liveAttrs.db)")
+}
+
+func loadConf(confPath string, args confSourceArgs) (*cnf.VTEConf, error) {
+	var conf *cnf.VTEConf
+	var err error
+	if args.fromEnv {
+		conf, err = cnf.LoadConfFromEnv()
+
+	} else if confPath == "" {
+		err = fmt.Errorf("missing config file")
+
+	} else {
+		conf, err = cnf.LoadConf(confPath)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to export data: %w", err)
+		return nil, err
+	}
+	if args.dbConfFrom != "" {
+		if err := cnf.LoadDBConfFrom(&conf.DB, args.dbConfFrom, args.dbConfPath); err != nil {
+			return nil, err
+		}
+
+	} else if args.dbConfPath != "" {
+		return nil, fmt.Errorf("-db-conf-jsonpath requires -db-conf-from")
+	}
+	if args.useKeyring {
+		conf.DB.Password, err = cnf.LookupKeyringPassword(&conf.DB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.askPassword {
+		conf.DB.Password, err = cnf.AskPassword(
+			fmt.Sprintf("database password for %s: ", cnf.KeyringAccount(&conf.DB)))
+		if err != nil {
+			return nil, err
+		}
 	}
+	return conf, nil
+}
+
+func exportData(ctx context.Context, conf *cnf.VTEConf, appendData bool, httpAddr string) error {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt)
 	signal.Notify(signalChan, syscall.SIGTERM)
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var tracker *monitor.Tracker
+	if httpAddr != "" {
+		tracker = monitor.NewTracker(conf.Corpus, cancel)
+		srv := monitor.NewServer(httpAddr, tracker)
+		go func() {
+			log.Info().Str("address", srv.Addr).Msg("starting HTTP dashboard")
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error().Err(err).Msg("failed to run HTTP dashboard")
+			}
+		}()
+		defer srv.Close()
+	}
+
 	t0 := time.Now()
-	statusChan, err := library.ExtractData(ctx, conf, appendData)
+	_, err := library.Extract(
+		ctx,
+		conf,
+		appendData,
+		library.WithProgressFunc(func(status proc.Status) {
+			if tracker != nil {
+				tracker.Update(status)
+			}
+			if status.Error != nil {
+				log.Error().Err(status.Error).Msg("error during data extraction (not exiting)")
+			}
+		}),
+	)
+	if tracker != nil {
+		tracker.SetFinished()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to export data: %w", err)
 	}
-	for status := range statusChan {
-		if status.Error != nil {
-			log.Error().Err(status.Error).Msg("error during data extraction (not exiting)")
-		}
-	}
 	log.Info().Dur("procTime", time.Since(t0)).Msg("Finished")
 	return nil
 }
 
+func runServer(listenAddr string, maxJobs int, queueFile, tokenFile, dataDir string) error {
+	if tokenFile == "" {
+		return fmt.Errorf("failed to run server: -auth-token-file must be specified")
+	}
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read auth. token: %w", err)
+	}
+	authToken := strings.TrimSpace(string(data))
+	if authToken == "" {
+		return fmt.Errorf("failed to read auth. token: file %s is empty", tokenFile)
+	}
+	srv, err := server.NewServer(maxJobs, queueFile, authToken, dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to run server: %w", err)
+	}
+	httpServer := &http.Server{
+		Addr:    listenAddr,
+		Handler: srv.Handler(),
+	}
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt)
+	signal.Notify(signalChan, syscall.SIGTERM)
+	go func() {
+		<-signalChan
+		log.Warn().Msg("shutdown requested, cancelling all the jobs")
+		srv.Shutdown()
+		httpServer.Close()
+	}()
+	log.Info().Str("address", listenAddr).Msg("starting vte job server")
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("failed to run server: %w", err)
+	}
+	return nil
+}
+
 func setupLog(jsonLog bool) {
 	if !jsonLog {
 		log.Logger = log.Output(
@@ -105,6 +229,7 @@ func setupLog(jsonLog bool) {
 }
 
 func main() {
+	var commands []commandInfo
 	flag.Usage = func() {
 		var verStr strings.Builder
 		baseHdrRow := "+-------------------------------------------------------------+"
@@ -119,28 +244,84 @@ func main() {
 		fmt.Printf("\nSupported encodings:\n%s\n", strings.Join(vertigo.SupportedCharsets(), ", "))
 		fmt.Printf("\nSupported selfJoin column generator functions:\n%s\n", strings.Join(colgen.GetFuncList(), ", "))
 		fmt.Println("\nUsage:")
-		fmt.Println("vte create config.json\n\t(run an export configured in config.json, add data to a new database)")
-		fmt.Println("vte append config.json\n\t(run an export configured in config.json, add data to an existing database)")
-		fmt.Println("vte template\n\t(create a half empty sample config and write it to stdout)")
+		for _, c := range commands {
+			fmt.Printf("%s\n\t(%s)\n", c.synopsis(), c.desc)
+		}
 		fmt.Println("\n(config file should be named after a respective corpus name, e.g. syn_v4.json)")
-		fmt.Println("vte version\n\tshow detailed version information")
+		fmt.Println("\nUse 'vte help command' to show options of a command.")
 	}
-	flag.Parse()
 	var jsonLog bool
+	var httpAddr string
+	var protectTables bool
+	var protectTablesPattern string
+	var sampleAtoms int
+	var sampleRatio float64
+	var maxAtoms int
+	var maxLines int
+	var anonymize bool
+	var replaceCorpus bool
+	var addToCorpus bool
+	var confSrc confSourceArgs
 
 	createCommand := flag.NewFlagSet("create", flag.ExitOnError)
 	createCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	createCommand.StringVar(
+		&httpAddr, "http", "", "run an HTTP dashboard with live progress on a specified address (e.g. :8080 = localhost only, 0.0.0.0:8080)")
+	createCommand.BoolVar(
+		&protectTables, "protect-tables", false,
+		"refuse to create or drop MySQL tables not prefixed by the (grouped) corpus name")
+	createCommand.StringVar(
+		&protectTablesPattern, "protect-tables-pattern", "",
+		"refuse to create or drop MySQL tables not matching the pattern ('%' matches any string)")
+	createCommand.IntVar(
+		&sampleAtoms, "sample-atoms", 0, "process at most N atoms (e.g. documents) and store them")
+	createCommand.Float64Var(
+		&sampleRatio, "sample-ratio", 0, "process only a portion of atoms evenly spread over the vertical (e.g. 0.01)")
+	createCommand.IntVar(
+		&maxAtoms, "max-atoms", 0, "stop after N read atoms (sampled or not), store the data processed so far and exit successfully")
+	createCommand.IntVar(
+		&maxLines, "max-lines", 0,
+		"stop after N vertical lines (the current atom is finished), store the data processed so far and exit successfully")
+	createCommand.BoolVar(
+		&anonymize, "anonymize", false, "apply the redaction rules of the anonymize configuration")
+	confSrc.register(createCommand)
 	createCommand.Usage = func() {
-		fmt.Println("Usage: vte create conf.json")
+		fmt.Println("Usage: vte create [options] conf.json")
 		fmt.Println("\nOptions:")
 		createCommand.PrintDefaults()
 	}
 	appendCommand := flag.NewFlagSet("append", flag.ExitOnError)
 	appendCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	appendCommand.StringVar(
+		&httpAddr, "http", "", "run an HTTP dashboard with live progress on a specified address (e.g. :8080 = localhost only, 0.0.0.0:8080)")
+	appendCommand.BoolVar(
+		&protectTables, "protect-tables", false,
+		"refuse to create or drop MySQL tables not prefixed by the (grouped) corpus name")
+	appendCommand.StringVar(
+		&protectTablesPattern, "protect-tables-pattern", "",
+		"refuse to create or drop MySQL tables not matching the pattern ('%' matches any string)")
+	appendCommand.IntVar(
+		&sampleAtoms, "sample-atoms", 0, "process at most N atoms (e.g. documents) and store them")
+	appendCommand.Float64Var(
+		&sampleRatio, "sample-ratio", 0, "process only a portion of atoms evenly spread over the vertical (e.g. 0.01)")
+	appendCommand.IntVar(
+		&maxAtoms, "max-atoms", 0, "stop after N read atoms (sampled or not), store the data processed so far and exit successfully")
+	appendCommand.IntVar(
+		&maxLines, "max-lines", 0,
+		"stop after N vertical lines (the current atom is finished), store the data processed so far and exit successfully")
+	appendCommand.BoolVar(
+		&anonymize, "anonymize", false, "apply the redaction rules of the anonymize configuration")
+	appendCommand.BoolVar(
+		&replaceCorpus, "replace-corpus", false,
+		"delete rows of the corpus (corpus_id) already stored in the database before appending the data")
+	appendCommand.BoolVar(
+		&addToCorpus, "add-to-corpus", false,
+		"add the data to rows of the corpus (corpus_id) already stored in the database")
+	confSrc.register(appendCommand)
 	appendCommand.Usage = func() {
-		fmt.Println("Usage: vte append conf.json")
+		fmt.Println("Usage: vte append [options] conf.json")
 		fmt.Println("\nOptions:")
-		createCommand.PrintDefaults()
+		appendCommand.PrintDefaults()
 	}
 	templateCommand := flag.NewFlagSet("template", flag.ExitOnError)
 	templateCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
@@ -150,6 +331,289 @@ func main() {
 		createCommand.PrintDefaults()
 	}
 
+	serveCommand := flag.NewFlagSet("serve", flag.ExitOnError)
+	serveCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	listenAddr := serveCommand.String("listen", "localhost:8085", "address to listen on")
+	maxJobs := serveCommand.Int("max-jobs", 1, "max. number of concurrently running jobs")
+	queueFile := serveCommand.String(
+		"queue-file", "", "a file for storing unfinished jobs so they survive server restarts")
+	authTokenFile := serveCommand.String(
+		"auth-token-file", "", "a file with a token API clients must send as 'Authorization: Bearer token' (required)")
+	dataDir := serveCommand.String(
+		"data-dir", "", "a directory submitted jobs can write files (SQLite databases, manifests etc.) to")
+	serveCommand.Usage = func() {
+		fmt.Println("Usage: vte serve [options]")
+		fmt.Println("\nOptions:")
+		serveCommand.PrintDefaults()
+	}
+
+	ngramsCommand := flag.NewFlagSet("ngrams", flag.ExitOnError)
+	ngramsCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	var ngramsMatch stringList
+	ngramsCommand.Var(
+		&ngramsMatch, "match",
+		"match a counted column (specified by role, colN or index) against a value; "+
+			"'*' matches any string (e.g. lemma=pes*); can be repeated")
+	ngramsMinCount := ngramsCommand.Int("min-count", 0, "min. absolute frequency")
+	ngramsLimit := ngramsCommand.Int("limit", 50, "max. number of n-grams to print (0 = no limit)")
+	confSrc.register(ngramsCommand)
+	ngramsCommand.Usage = func() {
+		fmt.Println("Usage: vte ngrams conf.json [options]")
+		fmt.Println("\nOptions:")
+		ngramsCommand.PrintDefaults()
+	}
+
+	freqlistCommand := flag.NewFlagSet("freqlist", flag.ExitOnError)
+	freqlistCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	freqlistColumn := freqlistCommand.String(
+		"column", "", "counted column (specified by role, colN or index) to export")
+	freqlistOutDir := freqlistCommand.String(
+		"output-dir", "", "write a TSV file for each counted column to the directory (default: stdout)")
+	freqlistMinCount := freqlistCommand.Int("min-count", 0, "min. absolute frequency")
+	freqlistLimit := freqlistCommand.Int("limit", 0, "max. number of items (0 = no limit)")
+	confSrc.register(freqlistCommand)
+	freqlistCommand.Usage = func() {
+		fmt.Println("Usage: vte freqlist conf.json [options]")
+		fmt.Println("\nOptions:")
+		freqlistCommand.PrintDefaults()
+	}
+
+	wordlistCommand := flag.NewFlagSet("wordlist", flag.ExitOnError)
+	wordlistCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	var wordlistColumns stringList
+	wordlistCommand.Var(
+		&wordlistColumns, "column",
+		"counted column (specified by role, colN or index) to export; can be repeated (default: all)")
+	wordlistOutput := wordlistCommand.String("output", "", "output file (default: stdout)")
+	wordlistMinCount := wordlistCommand.Int("min-count", 0, "min. absolute frequency")
+	confSrc.register(wordlistCommand)
+	wordlistCommand.Usage = func() {
+		fmt.Println("Usage: vte wordlist conf.json [options]")
+		fmt.Println("\nOptions:")
+		wordlistCommand.PrintDefaults()
+	}
+
+	registryCommand := flag.NewFlagSet("registry", flag.ExitOnError)
+	registryCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	registryMaxValues := registryCommand.Int(
+		"max-values", registry.DfltMaxSubcorpValues,
+		"max. number of distinct values of an attribute listed in SUBCORPATTRS")
+	confSrc.register(registryCommand)
+	registryCommand.Usage = func() {
+		fmt.Println("Usage: vte registry conf.json [options]")
+		fmt.Println("\nOptions:")
+		registryCommand.PrintDefaults()
+	}
+
+	removeCorpusCommand := flag.NewFlagSet("remove-corpus", flag.ExitOnError)
+	removeCorpusCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	removeCorpusName := removeCorpusCommand.String(
+		"corpus", "", "corpus to remove from the (grouped) database (default: the configured one)")
+	confSrc.register(removeCorpusCommand)
+	removeCorpusCommand.Usage = func() {
+		fmt.Println("Usage: vte remove-corpus conf.json [options]")
+		fmt.Println("\nOptions:")
+		removeCorpusCommand.PrintDefaults()
+	}
+
+	vocabCommand := flag.NewFlagSet("vocab", flag.ExitOnError)
+	vocabCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	vocabColumn := vocabCommand.String(
+		"column", "", "counted column (specified by role, colN or index) to analyze (default: all)")
+	vocabFormat := vocabCommand.String("format", "json", "output format (json, csv)")
+	vocabPoints := vocabCommand.Int("points", 20, "number of points of the vocabulary growth curve")
+	confSrc.register(vocabCommand)
+	vocabCommand.Usage = func() {
+		fmt.Println("Usage: vte vocab conf.json [options]")
+		fmt.Println("\nOptions:")
+		vocabCommand.PrintDefaults()
+	}
+
+	fsckCommand := flag.NewFlagSet("fsck", flag.ExitOnError)
+	fsckCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	fsckFormat := fsckCommand.String("format", "text", "output format (text, json)")
+	confSrc.register(fsckCommand)
+	fsckCommand.Usage = func() {
+		fmt.Println("Usage: vte fsck conf.json [options]")
+		fmt.Println("\nOptions:")
+		fsckCommand.PrintDefaults()
+	}
+
+	verifyCommand := flag.NewFlagSet("verify", flag.ExitOnError)
+	verifyCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	verifyFormat := verifyCommand.String("format", "text", "output format (text, json)")
+	verifySample := verifyCommand.Int(
+		"sample", 0, "compare only the first N atoms of the vertical (0 = all, totals compared)")
+	confSrc.register(verifyCommand)
+	verifyCommand.Usage = func() {
+		fmt.Println("Usage: vte verify conf.json [options]")
+		fmt.Println("\nOptions:")
+		verifyCommand.PrintDefaults()
+	}
+
+	arfCommand := flag.NewFlagSet("arf", flag.ExitOnError)
+	arfCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	arfFormat := arfCommand.String("format", "text", "output format (text, json)")
+	confSrc.register(arfCommand)
+	arfCommand.Usage = func() {
+		fmt.Println("Usage: vte arf conf.json [options]")
+		fmt.Println("\nOptions:")
+		arfCommand.PrintDefaults()
+	}
+
+	inventoryCommand := flag.NewFlagSet("inventory", flag.ExitOnError)
+	inventoryCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	inventoryColumn := inventoryCommand.String(
+		"column", "", "positional attribute index (or a name of a counted column)")
+	inventoryModFn := inventoryCommand.String(
+		"mod-fn", "", "value transformation (e.g. toLower, firstChar); overrides modFn of a counted column")
+	inventoryMinCount := inventoryCommand.Int("min-count", 0, "min. absolute frequency")
+	confSrc.register(inventoryCommand)
+	inventoryCommand.Usage = func() {
+		fmt.Println("Usage: vte inventory conf.json -column N [options]")
+		fmt.Println("\nOptions:")
+		inventoryCommand.PrintDefaults()
+	}
+
+	schemaDiffCommand := flag.NewFlagSet("schema-diff", flag.ExitOnError)
+	schemaDiffCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	schemaDiffFrom := schemaDiffCommand.String("from", "", "source version (v2, v3)")
+	schemaDiffTo := schemaDiffCommand.String("to", "", "target version (v2, v3)")
+	schemaDiffFormat := schemaDiffCommand.String(
+		"format", "text", "output format (text, json, sql); sql requires a config file")
+	confSrc.register(schemaDiffCommand)
+	schemaDiffCommand.Usage = func() {
+		fmt.Println("Usage: vte schema-diff -from v2 -to v3 [options] [conf.json]")
+		fmt.Println("\nOptions:")
+		schemaDiffCommand.PrintDefaults()
+	}
+
+	analyzeCommand := flag.NewFlagSet("analyze", flag.ExitOnError)
+	analyzeCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	analyzeMaxLines := analyzeCommand.Int(
+		"max-lines", 1000000, "read at most N vertical lines (0 = the whole vertical)")
+	analyzeEncoding := analyzeCommand.String("encoding", "utf-8", "vertical file encoding")
+	analyzeFormat := analyzeCommand.String("format", "text", "output format (text, json)")
+	analyzeCommand.Usage = func() {
+		fmt.Println("Usage: vte analyze [options] vertical [vertical...]")
+		fmt.Println("\nOptions:")
+		analyzeCommand.PrintDefaults()
+	}
+
+	pseudonymsCommand := flag.NewFlagSet("pseudonyms", flag.ExitOnError)
+	pseudonymsCommand.BoolVar(&jsonLog, "json-log", false, "set JSON logging format")
+	pseudonymsAttr := pseudonymsCommand.String(
+		"attr", "", "print only pseudonyms of an attribute (in the struct_attr form)")
+	pseudonymsFormat := pseudonymsCommand.String("format", "tsv", "output format (tsv, json)")
+	confSrc.register(pseudonymsCommand)
+	pseudonymsCommand.Usage = func() {
+		fmt.Println("Usage: vte pseudonyms conf.json [options]")
+		fmt.Println("\nOptions:")
+		pseudonymsCommand.PrintDefaults()
+	}
+
+	udCommand := flag.NewFlagSet("ud", flag.ExitOnError)
+	udCommand.Usage = func() {
+		fmt.Println("Usage: vte ud [options] [pos attr idx] [feat attr idx] [vertical path]")
+		fmt.Println("\nOptions:")
+		udCommand.PrintDefaults()
+	}
+
+	commands = []commandInfo{
+		{
+			name: "create", args: "[options] config.json", fset: createCommand,
+			desc: "run an export configured in config.json, add data to a new database",
+		},
+		{
+			name: "append", args: "[options] config.json", fset: appendCommand,
+			desc: "run an export configured in config.json, add data to an existing database",
+		},
+		{
+			name: "template", args: "corpus_name", fset: templateCommand,
+			desc: "create a half empty sample config and write it to stdout",
+		},
+		{
+			name: "serve", args: "[options]", fset: serveCommand,
+			desc: "run a server accepting extraction jobs via a JSON API",
+		},
+		{
+			name: "ngrams", args: "config.json [-match attr=value] [-min-count N] [-limit N]", fset: ngramsCommand,
+			desc: "search in extracted n-grams",
+		},
+		{
+			name: "freqlist", args: "config.json [-column attr] [-output-dir dir] [-min-count N] [-limit N]",
+			fset: freqlistCommand,
+			desc: "export frequency lists of counted columns as TSV",
+		},
+		{
+			name: "wordlist", args: "config.json [-column attr]... [-output file] [-min-count N]",
+			fset: wordlistCommand,
+			desc: "export counted columns as a NoSketchEngine/Manatee compatible wordlist",
+		},
+		{
+			name: "vocab", args: "config.json [-column attr] [-format json|csv] [-points N]", fset: vocabCommand,
+			desc: "report vocabulary growth, hapax ratio and coverage of counted columns",
+		},
+		{
+			name: "inventory", args: "config.json -column N [-mod-fn fn] [-min-count N]", fset: inventoryCommand,
+			desc: "list all distinct values of a positional attribute with frequencies",
+		},
+		{
+			name: "registry", args: "config.json [-max-values N]", fset: registryCommand,
+			desc: "print a draft Manatee registry based on the configuration and generated data",
+		},
+		{
+			name: "remove-corpus", args: "config.json [-corpus name]", fset: removeCorpusCommand,
+			desc: "delete rows of a single corpus from a (grouped) database",
+		},
+		{
+			name: "fsck", args: "config.json [-format text|json]", fset: fsckCommand,
+			desc: "verify consistency of a generated database",
+		},
+		{
+			name: "verify", args: "config.json [-sample N] [-format text|json]", fset: verifyCommand,
+			desc: "compare a generated database with its vertical file(s) without modifying it",
+		},
+		{
+			name: "arf", args: "config.json [-format text|json]", fset: arfCommand,
+			desc: "recompute ARF of n-grams stored in an existing database (liveattrs are not modified)",
+		},
+		{
+			name: "schema-diff", args: "-from v2 -to v3 [-format text|json|sql] [config.json]",
+			fset: schemaDiffCommand,
+			desc: "describe schema changes between major versions, print upgrade SQL for a database",
+		},
+		{
+			name: "analyze", args: "[-max-lines N] [-format text|json] vertical...", fset: analyzeCommand,
+			desc: "describe structures of a vertical and suggest an atom structure",
+		},
+		{
+			name: "pseudonyms", args: "config.json [-attr struct_attr] [-format tsv|json]", fset: pseudonymsCommand,
+			desc: "print the mapping between pseudonyms and original values of anonymized attributes",
+		},
+		{
+			name: "ud", args: "[-no-checks] [-max-num-err N] posIdx featIdx vertical", fset: udCommand,
+			desc: "extract UD tag variants as JSON, same as the standalone udex",
+		},
+		{
+			name: "completion", args: "bash|zsh|fish",
+			desc: "write a shell completion script to stdout",
+		},
+		{
+			name: "man",
+			desc: "write a man page to stdout",
+		},
+		{
+			name: "help", args: "[command]",
+			desc: "show help for a command",
+		},
+		{
+			name: "version",
+			desc: "show detailed version information",
+		},
+	}
+	flag.Parse()
+
 	if len(os.Args) < 2 {
 		fmt.Println("Action not specified")
 		os.Exit(2)
@@ -163,7 +627,33 @@ func main() {
 		createCommand.Parse(os.Args[2:])
 		setupLog(jsonLog)
 		ctx := context.TODO() // TODO
-		if err := exportData(ctx, createCommand.Arg(0), false); err != nil {
+		conf, err := loadConf(createCommand.Arg(0), confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if protectTables {
+			conf.DB.ProtectTables = true
+		}
+		if protectTablesPattern != "" {
+			conf.DB.ProtectTablesPattern = protectTablesPattern
+		}
+		if sampleAtoms > 0 {
+			conf.Sample.Atoms = sampleAtoms
+		}
+		if sampleRatio > 0 {
+			conf.Sample.Ratio = sampleRatio
+		}
+		if maxAtoms > 0 {
+			conf.Sample.MaxAtoms = maxAtoms
+		}
+		if maxLines > 0 {
+			conf.Sample.Lines = maxLines
+		}
+		if anonymize {
+			conf.Anonymize.Enabled = true
+		}
+		if err := exportData(ctx, conf, false, httpAddr); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -176,7 +666,43 @@ func main() {
 		appendCommand.Parse(os.Args[2:])
 		setupLog(jsonLog)
 		ctx := context.TODO()
-		if err := exportData(ctx, appendCommand.Arg(0), true); err != nil {
+		conf, err := loadConf(appendCommand.Arg(0), confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if protectTables {
+			conf.DB.ProtectTables = true
+		}
+		if protectTablesPattern != "" {
+			conf.DB.ProtectTablesPattern = protectTablesPattern
+		}
+		if sampleAtoms > 0 {
+			conf.Sample.Atoms = sampleAtoms
+		}
+		if sampleRatio > 0 {
+			conf.Sample.Ratio = sampleRatio
+		}
+		if maxAtoms > 0 {
+			conf.Sample.MaxAtoms = maxAtoms
+		}
+		if maxLines > 0 {
+			conf.Sample.Lines = maxLines
+		}
+		if anonymize {
+			conf.Anonymize.Enabled = true
+		}
+		if replaceCorpus && addToCorpus {
+			fmt.Println("-replace-corpus and -add-to-corpus cannot be combined")
+			os.Exit(3)
+		}
+		if replaceCorpus {
+			conf.ExistingCorpus = cnf.ExistingCorpusReplace
+		}
+		if addToCorpus {
+			conf.ExistingCorpus = cnf.ExistingCorpusAdd
+		}
+		if err := exportData(ctx, conf, true, httpAddr); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
@@ -188,6 +714,268 @@ func main() {
 		templateCommand.Parse(os.Args[2:])
 		setupLog(jsonLog)
 		dumpNewConf(templateCommand.Arg(0))
+	case "serve":
+		serveCommand.Parse(os.Args[2:])
+		setupLog(jsonLog)
+		if err := runServer(*listenAddr, *maxJobs, *queueFile, *authTokenFile, *dataDir); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "ngrams":
+		args := parseInterleaved(ngramsCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := queryNgrams(conf, ngramsMatch, *ngramsMinCount, *ngramsLimit); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "freqlist":
+		args := parseInterleaved(freqlistCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		err = exportFreqLists(conf, *freqlistColumn, *freqlistOutDir, *freqlistMinCount, *freqlistLimit)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "wordlist":
+		args := parseInterleaved(wordlistCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		err = exportManateeWordlist(conf, wordlistColumns, *wordlistOutput, *wordlistMinCount)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "vocab":
+		args := parseInterleaved(vocabCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := reportVocabStats(conf, *vocabColumn, *vocabFormat, *vocabPoints); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "inventory":
+		args := parseInterleaved(inventoryCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err = extractInventory(ctx, conf, *inventoryColumn, *inventoryModFn, *inventoryMinCount)
+		stop()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "registry":
+		args := parseInterleaved(registryCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := writeRegistryDraft(conf, *registryMaxValues); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "remove-corpus":
+		args := parseInterleaved(removeCorpusCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := removeCorpus(conf, *removeCorpusName); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "fsck":
+		args := parseInterleaved(fsckCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		hasErrors, err := checkDatabase(conf, *fsckFormat)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if hasErrors {
+			os.Exit(2)
+		}
+	case "verify":
+		args := parseInterleaved(verifyCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		hasDrift, err := verifyDatabase(ctx, conf, *verifyFormat, *verifySample)
+		stop()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if hasDrift {
+			os.Exit(2)
+		}
+	case "arf":
+		args := parseInterleaved(arfCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err = recomputeARF(ctx, conf, *arfFormat)
+		stop()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "schema-diff":
+		args := parseInterleaved(schemaDiffCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var conf *cnf.VTEConf
+		if len(args) > 0 || confSrc.fromEnv {
+			var confPath string
+			if len(args) > 0 {
+				confPath = args[0]
+			}
+			var err error
+			conf, err = loadConf(confPath, confSrc)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		if err := printSchemaDiff(*schemaDiffFrom, *schemaDiffTo, *schemaDiffFormat, conf); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "analyze":
+		args := parseInterleaved(analyzeCommand, os.Args[2:])
+		setupLog(jsonLog)
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := analyzeStructures(ctx, args, *analyzeEncoding, *analyzeMaxLines, *analyzeFormat)
+		stop()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "pseudonyms":
+		args := parseInterleaved(pseudonymsCommand, os.Args[2:])
+		setupLog(jsonLog)
+		var confPath string
+		if len(args) > 0 {
+			confPath = args[0]
+		}
+		conf, err := loadConf(confPath, confSrc)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := printPseudonyms(conf, *pseudonymsAttr, *pseudonymsFormat); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "ud":
+		os.Exit(udex.Run(udCommand, os.Args[2:]))
+	case "completion":
+		if len(os.Args) < 3 {
+			fmt.Println("Missing argument")
+			os.Exit(3)
+		}
+		if err := writeCompletion(os.Stdout, os.Args[2], commands); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "man":
+		writeManPage(os.Stdout, commands, version)
+	case "help":
+		if len(os.Args) < 3 {
+			flag.Usage()
+			return
+		}
+		for _, c := range commands {
+			if c.name == os.Args[2] {
+				if c.fset != nil {
+					c.fset.Usage()
+
+				} else {
+					fmt.Printf("Usage: %s\n\n%s\n", c.synopsis(), c.desc)
+				}
+				return
+			}
+		}
+		fmt.Printf("Unknown command: %s\n", os.Args[2])
+		os.Exit(3)
 	case "version":
 		fmt.Printf("vert-tagextract %s\nbuild date: %s\nlast commit: %s\n", version, build, gitCommit)
 	default:
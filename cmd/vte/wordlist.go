@@ -0,0 +1,92 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/colcounts"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/rs/zerolog/log"
+)
+
+// exportManateeWordlist writes a NoSketchEngine/Manatee compatible
+// wordlist of the selected counted columns (all of them if none is
+// specified) either to outPath or to stdout.
+func exportManateeWordlist(conf *cnf.VTEConf, columns []string, outPath string, minCount int) error {
+	var cols db.VertColumns
+	if len(columns) == 0 {
+		selected, err := selectCountedColumns(conf, "")
+		if err != nil {
+			return err
+		}
+		cols = selected
+	}
+	for _, name := range columns {
+		selected, err := selectCountedColumns(conf, name)
+		if err != nil {
+			return err
+		}
+		cols = append(cols, selected...)
+	}
+	sep, err := conf.Ngrams.NgramSeparator()
+	if err != nil {
+		return err
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var out io.Writer = os.Stdout
+	if outPath != "" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("failed to write wordlist: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	numItems, err := colcounts.WriteManateeWordlist(
+		reader,
+		colcounts.WordlistQuery{
+			Corpus:    conf.CorpusID(),
+			Columns:   cols,
+			NgramSize: conf.Ngrams.NgramSize,
+			Separator: sep,
+			MinCount:  minCount,
+		},
+		w,
+	)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write wordlist: %w", err)
+	}
+	if outPath != "" {
+		log.Info().Str("file", outPath).Int("items", numItems).Msg("written Manatee wordlist")
+	}
+	return nil
+}
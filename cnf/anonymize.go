@@ -0,0 +1,171 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+const (
+	// RedactDrop replaces an attribute value by an empty value
+	RedactDrop = "drop"
+
+	// RedactHash replaces an attribute value by its (salted) hash
+	// so equal values stay equal after redaction
+	RedactHash = "hash"
+
+	// RedactPrefix keeps only first RedactionRule.PrefixLength
+	// characters of an attribute value
+	RedactPrefix = "prefix"
+
+	// mappingKeyLength is a required length (in bytes) of a key
+	// encrypting the pseudonymization mapping (AES-256)
+	mappingKeyLength = 32
+
+	// redactedHashLength is a number of hex characters
+	// of a hash replacing a value
+	redactedHashLength = 32
+)
+
+// RedactionRule specifies how values of a structural
+// attribute are redacted
+type RedactionRule struct {
+
+	// Attr is an attribute in the [struct]_[attr] form
+	Attr string `json:"attr"`
+
+	// Action is one of RedactDrop, RedactHash, RedactPrefix
+	Action string `json:"action"`
+
+	// PrefixLength is a number of kept characters for RedactPrefix
+	PrefixLength int `json:"prefixLength,omitempty"`
+}
+
+// AnonymizeConf configures redaction of privacy-sensitive structural
+// attributes (e.g. speaker names). The rules are applied only if
+// enabled so both internal and published databases can be generated
+// from the same configuration.
+type AnonymizeConf struct {
+
+	// Enabled activates the rules (see also the -anonymize
+	// option of create and append commands)
+	Enabled bool `json:"enabled,omitempty"`
+
+	Rules []RedactionRule `json:"rules,omitempty"`
+
+	// HashSalt is a secret key used to hash values (RedactHash). Without
+	// the salt, hashes of short values can be reversed by trying all
+	// the possible values.
+	HashSalt string `json:"hashSalt,omitempty"`
+
+	// MappingFile is an optional path of a file where a mapping between
+	// hashes (RedactHash) and original values is stored so internal
+	// users can de-pseudonymize data of a published database. The file
+	// is readable only by its owner.
+	MappingFile string `json:"mappingFile,omitempty"`
+
+	// MappingKeyEnv is an optional name of an environment variable
+	// containing a hex-encoded 256-bit key used to encrypt MappingFile
+	MappingKeyEnv string `json:"mappingKeyEnv,omitempty"`
+}
+
+// MappingKey returns a key encrypting the mapping file. In case
+// MappingKeyEnv is not configured, nil is returned (i.e. the file
+// is not encrypted).
+func (ac AnonymizeConf) MappingKey() ([]byte, error) {
+	if ac.MappingKeyEnv == "" {
+		return nil, nil
+	}
+	v := os.Getenv(ac.MappingKeyEnv)
+	if v == "" {
+		return nil, fmt.Errorf("anonymize mapping key variable %s is not set", ac.MappingKeyEnv)
+	}
+	key, err := hex.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid anonymize mapping key in %s: %w", ac.MappingKeyEnv, err)
+	}
+	if len(key) != mappingKeyLength {
+		return nil, fmt.Errorf(
+			"invalid anonymize mapping key in %s: expected %d bytes, found %d",
+			ac.MappingKeyEnv, mappingKeyLength, len(key))
+	}
+	return key, nil
+}
+
+// IsActive tests whether there are rules to be applied
+func (ac AnonymizeConf) IsActive() bool {
+	return ac.Enabled && len(ac.Rules) > 0
+}
+
+// Validate tests whether rules refer to configured
+// attributes and have valid actions
+func (ac AnonymizeConf) Validate(structures map[string][]string) error {
+	if ac.MappingKeyEnv != "" && ac.MappingFile == "" {
+		return fmt.Errorf("anonymize mappingKeyEnv requires mappingFile")
+	}
+	available := structAttrNames(structures)
+	seen := make(map[string]bool)
+	for _, rule := range ac.Rules {
+		if !containsSorted(available, rule.Attr) {
+			return fmt.Errorf("anonymize attribute %s is not configured in structures", rule.Attr)
+		}
+		if seen[rule.Attr] {
+			return fmt.Errorf("anonymize attribute %s configured more than once", rule.Attr)
+		}
+		seen[rule.Attr] = true
+		switch rule.Action {
+		case RedactDrop:
+		case RedactHash:
+			if ac.HashSalt == "" {
+				return fmt.Errorf("anonymize attribute %s requires hashSalt to be set", rule.Attr)
+			}
+		case RedactPrefix:
+			if rule.PrefixLength <= 0 {
+				return fmt.Errorf("anonymize attribute %s requires a positive prefixLength", rule.Attr)
+			}
+		default:
+			return fmt.Errorf("invalid anonymize action '%s' for attribute %s", rule.Action, rule.Attr)
+		}
+	}
+	return nil
+}
+
+// Redact applies a rule to a value. Empty values
+// are kept as they are.
+func (ac AnonymizeConf) Redact(rule RedactionRule, value string) string {
+	if value == "" {
+		return value
+	}
+	switch rule.Action {
+	case RedactDrop:
+		return ""
+	case RedactHash:
+		mac := hmac.New(sha256.New, []byte(ac.HashSalt))
+		mac.Write([]byte(value))
+		return hex.EncodeToString(mac.Sum(nil))[:redactedHashLength]
+	case RedactPrefix:
+		runes := []rune(value)
+		if len(runes) > rule.PrefixLength {
+			return string(runes[:rule.PrefixLength])
+		}
+	}
+	return value
+}
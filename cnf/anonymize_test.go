@@ -0,0 +1,51 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeValidate(t *testing.T) {
+	structures := map[string][]string{"sp": {"name", "birth"}}
+	ac := AnonymizeConf{Rules: []RedactionRule{{Attr: "sp_name", Action: RedactHash}}}
+	assert.ErrorContains(t, ac.Validate(structures), "hashSalt")
+	ac.HashSalt = "salt"
+	assert.NoError(t, ac.Validate(structures))
+	ac.Rules = append(ac.Rules, RedactionRule{Attr: "sp_birth", Action: RedactPrefix})
+	assert.ErrorContains(t, ac.Validate(structures), "prefixLength")
+	ac.Rules[1].PrefixLength = 4
+	assert.NoError(t, ac.Validate(structures))
+	ac.Rules = append(ac.Rules, RedactionRule{Attr: "sp_age", Action: RedactDrop})
+	assert.ErrorContains(t, ac.Validate(structures), "not configured")
+	ac.Rules = []RedactionRule{{Attr: "sp_name", Action: "mask"}}
+	assert.ErrorContains(t, ac.Validate(structures), "invalid anonymize action")
+}
+
+func TestAnonymizeRedact(t *testing.T) {
+	ac := AnonymizeConf{HashSalt: "salt"}
+	assert.Equal(t, "", ac.Redact(RedactionRule{Action: RedactDrop}, "Jan Novák"))
+	assert.Equal(t, "Jan N", ac.Redact(RedactionRule{Action: RedactPrefix, PrefixLength: 5}, "Jan Novák"))
+	assert.Equal(t, "Jan", ac.Redact(RedactionRule{Action: RedactPrefix, PrefixLength: 5}, "Jan"))
+	h1 := ac.Redact(RedactionRule{Action: RedactHash}, "Jan Novák")
+	assert.Len(t, h1, 32)
+	assert.Equal(t, h1, ac.Redact(RedactionRule{Action: RedactHash}, "Jan Novák"))
+	assert.NotEqual(t, h1, AnonymizeConf{HashSalt: "other"}.Redact(RedactionRule{Action: RedactHash}, "Jan Novák"))
+	assert.Equal(t, "", ac.Redact(RedactionRule{Action: RedactHash}, ""))
+}
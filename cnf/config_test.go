@@ -60,3 +60,48 @@ func TestNgramMaxRequiredColumnNoColumns(t *testing.T) {
 	var cnf NgramConf
 	assert.Equal(t, 0, cnf.MaxRequiredColumn())
 }
+
+func TestNgramMissingColumnPolicy(t *testing.T) {
+	var cnf NgramConf
+	p, err := cnf.MissingColumnPolicy()
+	assert.NoError(t, err)
+	assert.Equal(t, MissingColumnEmpty, p)
+	assert.Equal(t, DfltMissingColumnSentinel, cnf.GetMissingColumnSentinel())
+
+	cnf.MissingColumn = "foo"
+	_, err = cnf.MissingColumnPolicy()
+	assert.Error(t, err)
+}
+
+func TestDataConfigHashIgnoresConnection(t *testing.T) {
+	conf := &VTEConf{Corpus: "susanne", Structures: map[string][]string{"doc": {"id", "title"}}}
+	h1, err := conf.DataConfigHash()
+	assert.NoError(t, err)
+	conf.DB.Password = "secret"
+	conf.DB.Host = "db.example.com"
+	conf.DB.Mirrors = []db.Conf{{Type: "sqlite", Name: "/tmp/susanne.db"}}
+	h2, err := conf.DataConfigHash()
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+	conf.Structures["doc"] = append(conf.Structures["doc"], "author")
+	h3, err := conf.DataConfigHash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, h1, h3)
+}
+
+func TestWithoutPasswordsMirrors(t *testing.T) {
+	conf := &VTEConf{}
+	conf.DB.Mirrors = []db.Conf{{Type: "mysql", Password: "secret"}}
+	masked := conf.WithoutPasswords()
+	assert.Equal(t, passwordReplacement, masked.DB.Mirrors[0].Password)
+	assert.Equal(t, "secret", conf.DB.Mirrors[0].Password)
+}
+
+func TestValidateErrorBudgets(t *testing.T) {
+	conf := VTEConf{ErrorBudgets: map[string]int{ErrCategoryParse: 0, ErrCategoryInsert: 10}}
+	assert.NoError(t, conf.ValidateErrorBudgets())
+	conf.ErrorBudgets["filter"] = 1
+	assert.ErrorContains(t, conf.ValidateErrorBudgets(), "invalid error category")
+	conf.ErrorBudgets = map[string]int{ErrCategoryColumn: -1}
+	assert.Error(t, conf.ValidateErrorBudgets())
+}
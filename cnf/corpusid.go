@@ -0,0 +1,121 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxCorpusIDLength is the max. length of a corpus_id value
+	// (given by the MySQL schema)
+	MaxCorpusIDLength = 63
+
+	corpusIDVarCorpus         = "corpus"
+	corpusIDVarParallelCorpus = "parallelCorpus"
+	corpusIDVarLang           = "lang"
+)
+
+var (
+	corpusIDPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+	corpusIDValue       = regexp.MustCompile(`^[a-zA-Z0-9_\-.]+$`)
+	corpusIDVarName     = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+)
+
+// corpusIDVars returns values of all the placeholders
+// available in CorpusIDTemplate. The 'lang' value is
+// available only for corpora named [parallelCorpus]_[lang].
+func (c *VTEConf) corpusIDVars() map[string]string {
+	ans := make(map[string]string, len(c.CorpusIDVars)+3)
+	for k, v := range c.CorpusIDVars {
+		ans[k] = v
+	}
+	ans[corpusIDVarCorpus] = c.Corpus
+	if c.ParallelCorpus != "" {
+		ans[corpusIDVarParallelCorpus] = c.ParallelCorpus
+		if lang, ok := strings.CutPrefix(c.Corpus, c.ParallelCorpus+"_"); ok && lang != "" {
+			ans[corpusIDVarLang] = lang
+		}
+	}
+	return ans
+}
+
+// resolveCorpusID replaces placeholders in CorpusIDTemplate
+func (c *VTEConf) resolveCorpusID() (string, error) {
+	if c.CorpusIDTemplate == "" {
+		return c.Corpus, nil
+	}
+	if strings.Count(c.CorpusIDTemplate, "{") != strings.Count(c.CorpusIDTemplate, "}") {
+		return "", fmt.Errorf("invalid corpusIdTemplate %s: unbalanced braces", c.CorpusIDTemplate)
+	}
+	vars := c.corpusIDVars()
+	var errs []string
+	ans := corpusIDPlaceholder.ReplaceAllStringFunc(c.CorpusIDTemplate, func(p string) string {
+		name := p[1 : len(p)-1]
+		v, ok := vars[name]
+		if !ok {
+			errs = append(errs, name)
+		}
+		return v
+	})
+	if len(errs) > 0 {
+		return "", fmt.Errorf(
+			"invalid corpusIdTemplate %s: unavailable value(s) %s",
+			c.CorpusIDTemplate, strings.Join(errs, ", "))
+	}
+	if !corpusIDValue.MatchString(ans) {
+		return "", fmt.Errorf(
+			"invalid corpusIdTemplate %s: resolved value '%s' contains unsupported characters",
+			c.CorpusIDTemplate, ans)
+	}
+	if len(ans) > MaxCorpusIDLength {
+		return "", fmt.Errorf(
+			"invalid corpusIdTemplate %s: resolved value %s is longer than %d characters",
+			c.CorpusIDTemplate, ans, MaxCorpusIDLength)
+	}
+	return ans, nil
+}
+
+// ValidateCorpusID tests whether CorpusIDTemplate and CorpusIDVars
+// can be used to produce a valid corpus_id value
+func (c *VTEConf) ValidateCorpusID() error {
+	for k := range c.CorpusIDVars {
+		if !corpusIDVarName.MatchString(k) {
+			return fmt.Errorf("invalid corpusIdVars key '%s'", k)
+		}
+		if k == corpusIDVarCorpus || k == corpusIDVarParallelCorpus || k == corpusIDVarLang {
+			return fmt.Errorf("corpusIdVars cannot redefine the built-in value %s", k)
+		}
+	}
+	_, err := c.resolveCorpusID()
+	return err
+}
+
+// CorpusID returns a value stored in the corpus_id columns
+// of all the tables. By default, this is the Corpus but it
+// can be customized via CorpusIDTemplate. The configuration
+// is expected to be validated via ValidateCorpusID. In case
+// the template cannot be resolved, Corpus is returned.
+func (c *VTEConf) CorpusID() string {
+	ans, err := c.resolveCorpusID()
+	if err != nil {
+		return c.Corpus
+	}
+	return ans
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorpusIDDefault(t *testing.T) {
+	conf := VTEConf{Corpus: "intercorp_v13_en", ParallelCorpus: "intercorp_v13"}
+	assert.NoError(t, conf.ValidateCorpusID())
+	assert.Equal(t, "intercorp_v13_en", conf.CorpusID())
+}
+
+func TestCorpusIDTemplate(t *testing.T) {
+	conf := VTEConf{
+		Corpus:           "intercorp_v13_en",
+		ParallelCorpus:   "intercorp_v13",
+		CorpusIDTemplate: "{lang}.{version}",
+		CorpusIDVars:     map[string]string{"version": "13.1"},
+	}
+	assert.NoError(t, conf.ValidateCorpusID())
+	assert.Equal(t, "en.13.1", conf.CorpusID())
+}
+
+func TestCorpusIDTemplateInvalid(t *testing.T) {
+	conf := VTEConf{Corpus: "syn2020", CorpusIDTemplate: "{parallelCorpus}_{lang}"}
+	assert.Error(t, conf.ValidateCorpusID())
+	assert.Equal(t, "syn2020", conf.CorpusID())
+
+	conf = VTEConf{Corpus: "syn2020", CorpusIDTemplate: "{corpus"}
+	assert.Error(t, conf.ValidateCorpusID())
+
+	conf = VTEConf{Corpus: "syn2020", CorpusIDTemplate: "{corpus} x"}
+	assert.Error(t, conf.ValidateCorpusID())
+
+	conf = VTEConf{Corpus: "syn2020", CorpusIDTemplate: "{corpus}", CorpusIDVars: map[string]string{"lang": "cs"}}
+	assert.Error(t, conf.ValidateCorpusID())
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// LoadDBConfFrom reads database connection settings from a JSON
+// file of another application (e.g. a service sharing the same
+// database). The jsonPath is a dot-separated path to an object
+// compatible with db.Conf (e.g. "liveAttrs.db"); an empty path
+// means the whole document. Loaded items overwrite the respective
+// items of dst, the other ones are kept.
+func LoadDBConfFrom(dst *db.Conf, path, jsonPath string) error {
+	rawData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load database configuration: %w", err)
+	}
+	var keys []any
+	if jsonPath != "" {
+		for _, k := range strings.Split(jsonPath, ".") {
+			keys = append(keys, k)
+		}
+	}
+	node, err := sonic.Get(rawData, keys...)
+	if err != nil {
+		return fmt.Errorf("failed to find '%s' in %s: %w", jsonPath, path, err)
+	}
+	raw, err := node.Raw()
+	if err != nil {
+		return fmt.Errorf("failed to find '%s' in %s: %w", jsonPath, path, err)
+	}
+	if err := sonic.UnmarshalString(raw, dst); err != nil {
+		return fmt.Errorf("invalid database configuration '%s' in %s: %w", jsonPath, path, err)
+	}
+	return ResolveDBPassword(dst)
+}
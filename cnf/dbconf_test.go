@@ -0,0 +1,44 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadDBConfFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.json")
+	data := `{"port": 8080, "liveAttrs": {"db": {"type": "mysql", "host": "db:3306",
+		"user": "kontext", "password": "secret"}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dbConf := db.Conf{Name: "liveattrs", ProtectTables: true}
+	assert.NoError(t, LoadDBConfFrom(&dbConf, path, "liveAttrs.db"))
+	assert.Equal(t, "mysql", dbConf.Type)
+	assert.Equal(t, "db:3306", dbConf.Host)
+	assert.Equal(t, "secret", dbConf.Password)
+	assert.Equal(t, "liveattrs", dbConf.Name)
+	assert.True(t, dbConf.ProtectTables)
+
+	assert.Error(t, LoadDBConfFrom(&dbConf, path, "liveAttrs.missing"))
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"fmt"
+)
+
+const (
+	// DegradeOnTooManyErrors triggers a retry in case the run
+	// exceeded the configured maxNumErrors
+	DegradeOnTooManyErrors = "tooManyErrors"
+
+	// DegradeOnOutOfMemory triggers a retry in case the run failed
+	// with an out-of-memory error reported by the OS or by the database
+	// (see factory.IsOutOfMemoryErr). Please note that a Go runtime out-of-memory
+	// condition terminates the whole process and cannot be handled.
+	DegradeOnOutOfMemory = "outOfMemory"
+)
+
+// DegradationStep contains settings overriding the respective
+// values of the original configuration. Items left empty are
+// kept unchanged. NgramFilter replaces ngrams.filter so it limits
+// only the counted n-grams while Filter replaces the main filter.
+type DegradationStep struct {
+	NgramSize    *int        `json:"ngramSize,omitempty"`
+	CalcARF      *bool       `json:"calcARF,omitempty"`
+	MaxNumErrors *int        `json:"maxNumErrors,omitempty"`
+	Filter       *FilterConf `json:"filter,omitempty"`
+	NgramFilter  *FilterConf `json:"ngramFilter,omitempty"`
+}
+
+// DegradationConf specifies how a failed run is retried
+// with safer settings. Steps are applied in their order, each one
+// on top of the original configuration (i.e. they do not accumulate).
+type DegradationConf struct {
+	On    []string          `json:"on"`
+	Steps []DegradationStep `json:"steps"`
+}
+
+func (dc *DegradationConf) IsConfigured() bool {
+	return len(dc.Steps) > 0
+}
+
+// Triggers tells whether a failure of a provided kind
+// (DegradeOnTooManyErrors, DegradeOnOutOfMemory) should lead to a retry.
+// If no trigger is configured, all the kinds are considered.
+func (dc *DegradationConf) Triggers(kind string) bool {
+	if len(dc.On) == 0 {
+		return true
+	}
+	for _, v := range dc.On {
+		if v == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func (dc *DegradationConf) Validate() error {
+	for _, v := range dc.On {
+		if v != DegradeOnTooManyErrors && v != DegradeOnOutOfMemory {
+			return fmt.Errorf("invalid degradation trigger '%s'", v)
+		}
+	}
+	for i, step := range dc.Steps {
+		if step.NgramSize != nil && *step.NgramSize < 1 {
+			return fmt.Errorf("invalid ngramSize in degradation step %d", i)
+		}
+		if step.MaxNumErrors != nil && *step.MaxNumErrors < 0 {
+			return fmt.Errorf("invalid maxNumErrors in degradation step %d", i)
+		}
+	}
+	return nil
+}
+
+// WithDegradation creates a copy of the configuration with settings
+// overridden by the provided degradation step
+func (c *VTEConf) WithDegradation(step DegradationStep) *VTEConf {
+	ans := *c
+	if step.NgramSize != nil {
+		ans.Ngrams.NgramSize = *step.NgramSize
+	}
+	if step.CalcARF != nil {
+		ans.Ngrams.CalcARF = *step.CalcARF
+	}
+	if step.MaxNumErrors != nil {
+		ans.MaxNumErrors = *step.MaxNumErrors
+	}
+	if step.Filter != nil {
+		ans.Filter = *step.Filter
+	}
+	if step.NgramFilter != nil {
+		ans.Ngrams.Filter = step.NgramFilter
+	}
+	return &ans
+}
@@ -0,0 +1,39 @@
+// Copyright 2024 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2024 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDegradation(t *testing.T) {
+	conf := &VTEConf{
+		Filter: FilterConf{Lib: "main.so", Fn: "Filter"},
+		Ngrams: NgramConf{NgramSize: 3, CalcARF: true},
+	}
+	size := 1
+	ngramFilter := &FilterConf{Lib: "ngrams.so", Fn: "Stricter"}
+	degraded := conf.WithDegradation(DegradationStep{NgramSize: &size, NgramFilter: ngramFilter})
+	assert.Equal(t, 1, degraded.Ngrams.NgramSize)
+	assert.True(t, degraded.Ngrams.CalcARF)
+	assert.Equal(t, ngramFilter, degraded.Ngrams.Filter)
+	assert.Equal(t, conf.Filter, degraded.Filter)
+	assert.Equal(t, 3, conf.Ngrams.NgramSize)
+	assert.Nil(t, conf.Ngrams.Filter)
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// EnvConfigBlob is an environment variable containing
+	// a complete JSON-encoded configuration
+	EnvConfigBlob = "VTE_CONFIG"
+)
+
+type envSetter func(conf *VTEConf, value string) error
+
+func setEnvJSON(target func(conf *VTEConf) any) envSetter {
+	return func(conf *VTEConf, value string) error {
+		return sonic.UnmarshalString(value, target(conf))
+	}
+}
+
+func setEnvList(target func(conf *VTEConf) *[]string) envSetter {
+	return func(conf *VTEConf, value string) error {
+		items := strings.Split(value, ",")
+		ans := make([]string, 0, len(items))
+		for _, item := range items {
+			if v := strings.TrimSpace(item); v != "" {
+				ans = append(ans, v)
+			}
+		}
+		*target(conf) = ans
+		return nil
+	}
+}
+
+// envVariables maps individual environment variables to
+// respective configuration items. Simple values are passed
+// as they are, lists are comma-separated and complex values
+// are JSON-encoded.
+var envVariables = map[string]envSetter{
+	"VTE_CORPUS":                func(c *VTEConf, v string) error { c.Corpus = v; return nil },
+	"VTE_PARALLEL_CORPUS":       func(c *VTEConf, v string) error { c.ParallelCorpus = v; return nil },
+	"VTE_CORPUS_ID_TEMPLATE":    func(c *VTEConf, v string) error { c.CorpusIDTemplate = v; return nil },
+	"VTE_ATOM_STRUCTURE":        func(c *VTEConf, v string) error { c.AtomStructure = v; return nil },
+	"VTE_ATOM_PARENT_STRUCTURE": func(c *VTEConf, v string) error { c.AtomParentStructure = v; return nil },
+	"VTE_VERTICAL_FILE":         func(c *VTEConf, v string) error { c.VerticalFile = v; return nil },
+	"VTE_ENCODING":              func(c *VTEConf, v string) error { c.Encoding = v; return nil },
+	"VTE_OUTPUT_COMPAT":         func(c *VTEConf, v string) error { c.OutputCompat = v; return nil },
+	"VTE_DB_TYPE":               func(c *VTEConf, v string) error { c.DB.Type = v; return nil },
+	"VTE_DB_NAME":               func(c *VTEConf, v string) error { c.DB.Name = v; return nil },
+	"VTE_DB_HOST":               func(c *VTEConf, v string) error { c.DB.Host = v; return nil },
+	"VTE_DB_READ_HOST":          func(c *VTEConf, v string) error { c.DB.ReadHost = v; return nil },
+	"VTE_DB_USER":               func(c *VTEConf, v string) error { c.DB.User = v; return nil },
+	"VTE_DB_PASSWORD":           func(c *VTEConf, v string) error { c.DB.Password = v; return nil },
+	"VTE_DB_PASSWORD_ENV":       func(c *VTEConf, v string) error { c.DB.PasswordEnv = v; return nil },
+	"VTE_DB_PASSWORD_FILE":      func(c *VTEConf, v string) error { c.DB.PasswordFile = v; return nil },
+	"VTE_DB_PRIMARY_KEY":        func(c *VTEConf, v string) error { c.DB.PrimaryKey = v; return nil },
+	"VTE_DB_CHARSET":            func(c *VTEConf, v string) error { c.DB.Charset = v; return nil },
+	"VTE_EXISTING_CORPUS":       func(c *VTEConf, v string) error { c.ExistingCorpus = v; return nil },
+	"VTE_VERTICAL_FILES":        setEnvList(func(c *VTEConf) *[]string { return &c.VerticalFiles }),
+	"VTE_INDEXED_COLS":          setEnvList(func(c *VTEConf) *[]string { return &c.IndexedCols }),
+	"VTE_HELPER_VIEWS":          setEnvList(func(c *VTEConf) *[]string { return &c.HelperViews }),
+	"VTE_DB_PRECONF_SETTINGS":   setEnvJSON(func(c *VTEConf) any { return &c.DB.PreconfQueries }),
+	"VTE_STRUCTURES":            setEnvJSON(func(c *VTEConf) any { return &c.Structures }),
+	"VTE_NGRAMS":                setEnvJSON(func(c *VTEConf) any { return &c.Ngrams }),
+	"VTE_SELF_JOIN":             setEnvJSON(func(c *VTEConf) any { return &c.SelfJoin }),
+	"VTE_BIB_VIEW":              setEnvJSON(func(c *VTEConf) any { return &c.BibView }),
+	"VTE_FILTER":                setEnvJSON(func(c *VTEConf) any { return &c.Filter }),
+	"VTE_NOTIFICATIONS":         setEnvJSON(func(c *VTEConf) any { return &c.Notifications }),
+	"VTE_COLUMN_COUNT_CHECK":    setEnvJSON(func(c *VTEConf) any { return &c.ColumnCountCheck }),
+	"VTE_DEGRADATION":           setEnvJSON(func(c *VTEConf) any { return &c.Degradation }),
+	"VTE_DB_BACKUP":             setEnvJSON(func(c *VTEConf) any { return &c.DB.Backup }),
+	"VTE_DB_DIALECT_HINTS":      setEnvJSON(func(c *VTEConf) any { return &c.DB.DialectHints }),
+	"VTE_DB_OPTIMIZE":           setEnvJSON(func(c *VTEConf) any { return &c.DB.Optimize }),
+	"VTE_DB_PARTITIONING":       setEnvJSON(func(c *VTEConf) any { return &c.DB.ColcountsPartitioning }),
+	"VTE_DB_COLLATIONS":         setEnvJSON(func(c *VTEConf) any { return &c.DB.Collations }),
+	"VTE_DB_SQLITE":             setEnvJSON(func(c *VTEConf) any { return &c.DB.SQLite }),
+	"VTE_DB_COLCOUNTS":          setEnvJSON(func(c *VTEConf) any { return &c.DB.Colcounts }),
+	"VTE_DB_HISTORY":            setEnvJSON(func(c *VTEConf) any { return &c.DB.History }),
+	"VTE_DB_MIRRORS":            setEnvJSON(func(c *VTEConf) any { return &c.DB.Mirrors }),
+	"VTE_DB_RETRY":              setEnvJSON(func(c *VTEConf) any { return &c.DB.Retry }),
+	"VTE_DB_TLS":                setEnvJSON(func(c *VTEConf) any { return &c.DB.TLS }),
+	"VTE_DB_DSN_PARAMS":         setEnvJSON(func(c *VTEConf) any { return &c.DB.DSNParams }),
+	"VTE_MANIFEST":              setEnvJSON(func(c *VTEConf) any { return &c.Manifest }),
+	"VTE_SAMPLE":                setEnvJSON(func(c *VTEConf) any { return &c.Sample }),
+	"VTE_ATTR_VALUES":           setEnvJSON(func(c *VTEConf) any { return &c.AttrValues }),
+	"VTE_ATTR_PAIRS":            setEnvJSON(func(c *VTEConf) any { return &c.AttrPairs }),
+	"VTE_EVENTS":                setEnvJSON(func(c *VTEConf) any { return &c.Events }),
+	"VTE_POST_COMMIT_HOOKS":     setEnvJSON(func(c *VTEConf) any { return &c.PostCommitHooks }),
+	"VTE_ERROR_BUDGETS":         setEnvJSON(func(c *VTEConf) any { return &c.ErrorBudgets }),
+	"VTE_ANONYMIZE":             setEnvJSON(func(c *VTEConf) any { return &c.Anonymize }),
+	"VTE_SYNTHETIC_ATOMS":       setEnvJSON(func(c *VTEConf) any { return &c.SyntheticAtoms }),
+	"VTE_KONTEXT":               setEnvJSON(func(c *VTEConf) any { return &c.KonText }),
+	"VTE_CORPUS_ID_VARS":        setEnvJSON(func(c *VTEConf) any { return &c.CorpusIDVars }),
+	"VTE_STACK_STRUCT_EVAL": func(c *VTEConf, v string) error {
+		var err error
+		c.StackStructEval, err = strconv.ParseBool(v)
+		return err
+	},
+	"VTE_DB_PROTECT_TABLES": func(c *VTEConf, v string) error {
+		var err error
+		c.DB.ProtectTables, err = strconv.ParseBool(v)
+		return err
+	},
+	"VTE_DB_PROTECT_TABLES_PATTERN": func(c *VTEConf, v string) error {
+		c.DB.ProtectTablesPattern = v
+		return nil
+	},
+	"VTE_DB_PORT": func(c *VTEConf, v string) error {
+		var err error
+		c.DB.Port, err = strconv.Atoi(v)
+		return err
+	},
+	"VTE_DB_ATOMIC_WRITE": func(c *VTEConf, v string) error {
+		var err error
+		c.DB.AtomicWrite, err = strconv.ParseBool(v)
+		return err
+	},
+	"VTE_MAX_NUM_ERRORS": func(c *VTEConf, v string) error {
+		var err error
+		c.MaxNumErrors, err = strconv.Atoi(v)
+		return err
+	},
+	"VTE_ON_FILE_ERROR": func(c *VTEConf, v string) error { c.OnFileError = v; return nil },
+	"VTE_STRUCT_COUNTS": func(c *VTEConf, v string) error { c.StructCounts = v; return nil },
+	"VTE_INVALID_UTF8":  func(c *VTEConf, v string) error { c.InvalidUTF8 = v; return nil },
+	"VTE_HTML_ENTITIES": func(c *VTEConf, v string) error { c.HTMLEntities = v; return nil },
+	"VTE_WHITESPACE":    func(c *VTEConf, v string) error { c.Whitespace = v; return nil },
+	"VTE_FILE_RETRIES": func(c *VTEConf, v string) error {
+		var err error
+		c.FileRetries, err = strconv.Atoi(v)
+		return err
+	},
+}
+
+// LoadConfFromEnv creates a configuration based solely on environment
+// variables. First, a possible complete JSON configuration stored in
+// VTE_CONFIG is loaded. Then, individual VTE_* variables (e.g. VTE_CORPUS,
+// VTE_DB_PASSWORD) are applied, overwriting respective values.
+func LoadConfFromEnv() (*VTEConf, error) {
+	var conf VTEConf
+	if blob := os.Getenv(EnvConfigBlob); blob != "" {
+		if err := sonic.UnmarshalString(blob, &conf); err != nil {
+			return nil, fmt.Errorf("failed to load config from %s: %w", EnvConfigBlob, err)
+		}
+	}
+	var numApplied int
+	for name, setter := range envVariables {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setter(&conf, value); err != nil {
+			return nil, fmt.Errorf("failed to load config from %s: %w", name, err)
+		}
+		numApplied++
+	}
+	if os.Getenv(EnvConfigBlob) == "" && numApplied == 0 {
+		return nil, fmt.Errorf("no configuration found in environment variables")
+	}
+	if err := ResolveDBPassword(&conf.DB); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfFromEnvBlob(t *testing.T) {
+	t.Setenv(EnvConfigBlob, `{"corpus": "syn2020", "atomStructure": "doc", "db": {"type": "sqlite", "name": "/tmp/x.db"}}`)
+	t.Setenv("VTE_DB_NAME", "/tmp/y.db")
+	conf, err := LoadConfFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "syn2020", conf.Corpus)
+	assert.Equal(t, "doc", conf.AtomStructure)
+	assert.Equal(t, "sqlite", conf.DB.Type)
+	assert.Equal(t, "/tmp/y.db", conf.DB.Name)
+}
+
+func TestLoadConfFromEnvVariables(t *testing.T) {
+	t.Setenv("VTE_CORPUS", "syn2020")
+	t.Setenv("VTE_VERTICAL_FILES", "/a.vert, /b.vert")
+	t.Setenv("VTE_STRUCTURES", `{"doc": ["id", "title"]}`)
+	t.Setenv("VTE_STACK_STRUCT_EVAL", "true")
+	t.Setenv("VTE_MAX_NUM_ERRORS", "100")
+	t.Setenv("VTE_DB_PROTECT_TABLES_PATTERN", "syn2020_%")
+	t.Setenv("VTE_DB_MIRRORS", `[{"type": "mysql", "name": "syn2020", "host": "mirror1:3306"}]`)
+	t.Setenv("VTE_CORPUS_ID_TEMPLATE", "{lang}.{version}")
+	t.Setenv("VTE_CORPUS_ID_VARS", `{"version": "13.1"}`)
+	t.Setenv("VTE_EXISTING_CORPUS", "replace")
+	t.Setenv("VTE_DB_PORT", "3307")
+	t.Setenv("VTE_DB_CHARSET", "utf8mb4")
+	t.Setenv("VTE_DB_TLS", `{"enabled": true, "caCert": "/etc/vte/ca.pem"}`)
+	t.Setenv("VTE_DB_DSN_PARAMS", `{"timeout": "10s"}`)
+	t.Setenv("VTE_DB_RETRY", `{"maxAttempts": 5, "delayMs": 200}`)
+	t.Setenv("VTE_KONTEXT", `{"path": "/opt/kontext/conf/corpora/syn2020.json"}`)
+	conf, err := LoadConfFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "syn2020", conf.Corpus)
+	assert.Equal(t, []string{"/a.vert", "/b.vert"}, conf.VerticalFiles)
+	assert.Equal(t, []string{"id", "title"}, conf.Structures["doc"])
+	assert.True(t, conf.StackStructEval)
+	assert.Equal(t, 100, conf.MaxNumErrors)
+	assert.Equal(t, "syn2020_%", conf.DB.ProtectTablesPattern)
+	if assert.Len(t, conf.DB.Mirrors, 1) {
+		assert.Equal(t, "mirror1:3306", conf.DB.Mirrors[0].Host)
+	}
+	assert.Equal(t, "/opt/kontext/conf/corpora/syn2020.json", conf.KonText.Path)
+	assert.Equal(t, "{lang}.{version}", conf.CorpusIDTemplate)
+	assert.Equal(t, map[string]string{"version": "13.1"}, conf.CorpusIDVars)
+	assert.Equal(t, ExistingCorpusReplace, conf.ExistingCorpus)
+	assert.Equal(t, 3307, conf.DB.Port)
+	assert.Equal(t, "utf8mb4", conf.DB.Charset)
+	assert.True(t, conf.DB.TLS.Enabled)
+	assert.Equal(t, "/etc/vte/ca.pem", conf.DB.TLS.CACert)
+	assert.Equal(t, map[string]string{"timeout": "10s"}, conf.DB.DSNParams)
+	assert.Equal(t, 5, conf.DB.Retry.MaxAttempts)
+	assert.Equal(t, 200, conf.DB.Retry.DelayMs)
+}
+
+func TestLoadConfFromEnvInvalidValue(t *testing.T) {
+	t.Setenv("VTE_MAX_NUM_ERRORS", "lots")
+	_, err := LoadConfFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("VTE_MAX_NUM_ERRORS", "100")
+	t.Setenv("VTE_DB_PORT", "mysql")
+	_, err = LoadConfFromEnv()
+	assert.Error(t, err)
+}
+
+func TestLoadConfFromEnvPasswordFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwd")
+	assert.NoError(t, os.WriteFile(path, []byte("secret\n"), 0600))
+	t.Setenv("VTE_DB_PASSWORD_FILE", path)
+	conf, err := LoadConfFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, path, conf.DB.PasswordFile)
+	assert.Equal(t, "secret", conf.DB.Password)
+
+	t.Setenv("VTE_DB_PASSWORD_ENV", "VTE_TEST_DB_PASSWORD")
+	t.Setenv("VTE_TEST_DB_PASSWORD", "from-env")
+	conf, err = LoadConfFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "VTE_TEST_DB_PASSWORD", conf.DB.PasswordEnv)
+	assert.Equal(t, "from-env", conf.DB.Password)
+}
@@ -0,0 +1,31 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+// KonTextConf configures writing of a KonText liveattrs
+// configuration fragment (see library.KonTextLiveAttrs)
+type KonTextConf struct {
+
+	// Path specifies where the fragment is written once
+	// a run is successfully finished (disabled if empty)
+	Path string `json:"path,omitempty"`
+}
+
+// IsConfigured tells whether the fragment should be written
+func (c KonTextConf) IsConfigured() bool {
+	return c.Path != ""
+}
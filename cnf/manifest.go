@@ -0,0 +1,85 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// ManifestConf configures a JSON manifest describing
+// a generated database (see library.Manifest)
+type ManifestConf struct {
+	Enabled bool `json:"enabled"`
+
+	// Path specifies where the manifest is written. For SQLite, the
+	// default is the database path with the '.manifest.json' suffix.
+	// For MySQL, the path must be always specified.
+	Path string `json:"path,omitempty"`
+}
+
+// ManifestPath returns a path of the manifest file
+func (c *VTEConf) ManifestPath() (string, error) {
+	if c.Manifest.Path != "" {
+		return c.Manifest.Path, nil
+	}
+	if c.DB.Type == "sqlite" {
+		return c.DB.Name + ".manifest.json", nil
+	}
+	return "", fmt.Errorf("manifest.path must be specified for database type %s", c.DB.Type)
+}
+
+// DataConfigHash returns a SHA-256 hash of all the configuration
+// items affecting generated data. Database connection settings,
+// notifications and similar items are ignored so e.g. a changed
+// password does not change the hash.
+func (c *VTEConf) DataConfigHash() (string, error) {
+	tmp := *c
+	tmp.DB.Host = ""
+	tmp.DB.ReadHost = ""
+	tmp.DB.User = ""
+	tmp.DB.Password = ""
+	tmp.DB.PasswordEnv = ""
+	tmp.DB.PasswordFile = ""
+	tmp.DB.PreconfQueries = nil
+	tmp.DB.Port = 0
+	tmp.DB.Charset = ""
+	tmp.DB.TLS = db.TLSConf{}
+	tmp.DB.DSNParams = nil
+	tmp.DB.ProtectTables = false
+	tmp.DB.ProtectTablesPattern = ""
+	tmp.DB.AtomicWrite = false
+	tmp.DB.Backup.Enabled = false
+	tmp.DB.Backup.Keep = 0
+	tmp.DB.Mirrors = nil
+	tmp.ExistingCorpus = ""
+	tmp.DB.Retry = db.RetryConf{}
+	tmp.Notifications = NotificationConf{}
+	tmp.Manifest = ManifestConf{}
+	tmp.KonText = KonTextConf{}
+	tmp.Verbosity = 0
+	data, err := sonic.ConfigStd.Marshal(tmp)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate config hash: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// structAttrModifiers lists transformations applied to values
+// of a structural attribute (in the order they are applied)
+func (c *VTEConf) structAttrModifiers(attr string) []string {
+	ans := make([]string, 0, 4)
+	if v, err := c.InvalidUTF8Policy(); err == nil && v != InvalidUTF8Keep {
+		ans = append(ans, "invalidUtf8:"+v)
+	}
+	if v, err := c.HTMLEntitiesPolicy(); err == nil && v != HTMLEntitiesKeep {
+		ans = append(ans, "htmlEntities:"+v)
+	}
+	if v, err := c.WhitespacePolicy(); err == nil && v != WhitespaceKeep {
+		ans = append(ans, "whitespace:"+v)
+	}
+	if c.Anonymize.IsActive() {
+		for _, rule := range c.Anonymize.Rules {
+			if rule.Attr != attr {
+				continue
+			}
+			if rule.Action == RedactPrefix {
+				ans = append(ans, fmt.Sprintf("anonymize:%s:%d", rule.Action, rule.PrefixLength))
+
+			} else {
+				ans = append(ans, "anonymize:"+rule.Action)
+			}
+		}
+	}
+	return ans
+}
+
+// posAttrModifiers lists transformations and constraints
+// applied to values of a counted column
+func posAttrModifiers(col db.VertColumn) []string {
+	ans := make([]string, 0, 4)
+	if col.UDFeats != "" {
+		ans = append(ans, "udFeats:"+col.UDFeats)
+	}
+	if col.ModFn != "" {
+		ans = append(ans, "modFn:"+col.ModFn)
+	}
+	if col.MinLength > 0 {
+		ans = append(ans, "minLength:"+strconv.Itoa(col.MinLength))
+	}
+	if col.Match != "" {
+		ans = append(ans, "match:"+col.Match)
+	}
+	if col.NotMatch != "" {
+		ans = append(ans, "notMatch:"+col.NotMatch)
+	}
+	return ans
+}
+
+// ColumnProvenance describes origin of all the columns written
+// to the liveattrs and colcounts tables (see db.ColumnInfoTable)
+func (c *VTEConf) ColumnProvenance() []db.ColumnInfo {
+	ans := make([]db.ColumnInfo, 0, 30)
+	laTable := db.TableName(db.LiveAttrsTable, c.OutputCompat)
+	structs := make([]string, 0, len(c.Structures))
+	for st := range c.Structures {
+		structs = append(structs, st)
+	}
+	sort.Strings(structs)
+	for _, st := range structs {
+		for _, attr := range c.Structures[st] {
+			ans = append(ans, db.ColumnInfo{
+				Table:     laTable,
+				Column:    st + "_" + attr,
+				Source:    db.ColumnSourceStructAttr,
+				Structure: st,
+				Attr:      attr,
+				Modifiers: c.structAttrModifiers(st + "_" + attr),
+			})
+		}
+	}
+	computed := [][2]string{
+		{"corpus_id", "corpus identifier"},
+		{"poscount", "number of tokens of the atom structure"},
+		{"wordcount", "number of tokens of the atom structure accepted by the filter"},
+	}
+	if c.SelfJoin.IsConfigured() {
+		computed = append(computed, [2]string{"item_id", "identifier of aligned atoms"})
+	}
+	for _, item := range computed {
+		ans = append(ans, db.ColumnInfo{
+			Table:       laTable,
+			Column:      item[0],
+			Source:      db.ColumnSourceComputed,
+			Description: item[1],
+		})
+	}
+	if len(c.Ngrams.VertColumns) == 0 {
+		return ans
+	}
+	names := db.GenerateColCountNames(c.Ngrams.VertColumns)
+	for i, col := range c.Ngrams.VertColumns {
+		ans = append(ans, db.ColumnInfo{
+			Table:       db.ColCountsTable,
+			Column:      names[i],
+			Source:      db.ColumnSourcePosAttr,
+			Attr:        strconv.Itoa(col.Idx),
+			Modifiers:   posAttrModifiers(col),
+			Description: col.Role,
+		})
+	}
+	for _, item := range [][2]string{
+		{"corpus_id", "corpus identifier"},
+		{"hash_id", "hash of the n-gram values"},
+		{"count", "absolute frequency"},
+		{"arf", "average reduced frequency"},
+	} {
+		ans = append(ans, db.ColumnInfo{
+			Table:       db.ColCountsTable,
+			Column:      item[0],
+			Source:      db.ColumnSourceComputed,
+			Description: item[1],
+		})
+	}
+	return ans
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnProvenance(t *testing.T) {
+	conf := &VTEConf{
+		Structures:   map[string][]string{"sp": {"name"}, "doc": {"title"}},
+		HTMLEntities: HTMLEntitiesDecode,
+		Anonymize: AnonymizeConf{
+			Enabled: true,
+			Rules:   []RedactionRule{{Attr: "sp_name", Action: RedactPrefix, PrefixLength: 3}},
+		},
+		Ngrams: NgramConf{
+			VertColumns: db.VertColumns{{Idx: 0}, {Idx: 2, ModFn: "toLower", Role: "lemma"}},
+		},
+	}
+	info := conf.ColumnProvenance()
+	assert.Len(t, info, 2+3+2+4)
+	assert.Equal(t, db.ColumnInfo{
+		Table:     db.LiveAttrsTable,
+		Column:    "doc_title",
+		Source:    db.ColumnSourceStructAttr,
+		Structure: "doc",
+		Attr:      "title",
+		Modifiers: []string{"htmlEntities:decode"},
+	}, info[0])
+	assert.Equal(t, []string{"htmlEntities:decode", "anonymize:prefix:3"}, info[1].Modifiers)
+	assert.Equal(t, db.ColumnInfo{
+		Table:       db.ColCountsTable,
+		Column:      "col2",
+		Source:      db.ColumnSourcePosAttr,
+		Attr:        "2",
+		Modifiers:   []string{"modFn:toLower"},
+		Description: "lemma",
+	}, info[6])
+
+	conf.OutputCompat = db.OutputCompatV2
+	assert.Equal(t, db.LegacyLiveAttrsTable, conf.ColumnProvenance()[0].Table)
+}
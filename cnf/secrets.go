@@ -0,0 +1,120 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"golang.org/x/term"
+)
+
+const (
+	// KeyringService is a service name under which
+	// database passwords are searched in OS keyring
+	KeyringService = "vert-tagextract"
+)
+
+// AskPassword reads a password from the terminal
+// (without echoing typed characters).
+func AskPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("cannot ask for password - stdin is not a terminal")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passwd, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(passwd), nil
+}
+
+// KeyringAccount returns an account name used to search
+// for a database password in OS keyring ([user]@[host])
+func KeyringAccount(conf *db.Conf) string {
+	return fmt.Sprintf("%s@%s", conf.User, conf.Host)
+}
+
+// LookupKeyringPassword searches for a database password in OS
+// keyring. On Linux, Secret Service is used via the 'secret-tool'
+// utility, on macOS, Keychain is used via the 'security' utility.
+// The password is expected to be stored under the service
+// KeyringService and account KeyringAccount(), e.g.:
+//
+//	secret-tool store --label=vte service vert-tagextract account user@host
+func LookupKeyringPassword(conf *db.Conf) (string, error) {
+	var cmd *exec.Cmd
+	account := KeyringAccount(conf)
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", KeyringService, "account", account)
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", KeyringService, "-a", account, "-w")
+	default:
+		return "", fmt.Errorf("keyring lookup not supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find password for %s in keyring: %w", account, err)
+	}
+	passwd := strings.TrimRight(string(out), "\r\n")
+	if passwd == "" {
+		return "", fmt.Errorf("no password for %s found in keyring", account)
+	}
+	return passwd, nil
+}
+
+// ResolveDBPassword sets conf.Password (and passwords of mirrors)
+// based on PasswordEnv or PasswordFile in case they are configured.
+// The environment variable takes precedence over the file and the file
+// takes precedence over the literal password. An undefined variable
+// is skipped in case there is another source of the password.
+func ResolveDBPassword(conf *db.Conf) error {
+	if conf.PasswordEnv != "" {
+		if passwd, ok := os.LookupEnv(conf.PasswordEnv); ok {
+			conf.Password = passwd
+			return resolveMirrorPasswords(conf)
+		}
+		if conf.PasswordFile == "" && conf.Password == "" {
+			return fmt.Errorf(
+				"failed to resolve database password: variable %s not set", conf.PasswordEnv)
+		}
+	}
+	if conf.PasswordFile != "" {
+		data, err := os.ReadFile(conf.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to resolve database password: %w", err)
+		}
+		conf.Password = strings.TrimRight(string(data), "\r\n")
+	}
+	return resolveMirrorPasswords(conf)
+}
+
+func resolveMirrorPasswords(conf *db.Conf) error {
+	for i := range conf.Mirrors {
+		if err := ResolveDBPassword(&conf.Mirrors[i]); err != nil {
+			return fmt.Errorf("mirror %d: %w", i, err)
+		}
+	}
+	return nil
+}
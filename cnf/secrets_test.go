@@ -0,0 +1,55 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDBPasswordPriority(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwd")
+	assert.NoError(t, os.WriteFile(path, []byte("from-file\n"), 0600))
+	t.Setenv("VTE_TEST_DB_PASSWORD", "from-env")
+
+	conf := db.Conf{Password: "literal", PasswordFile: path, PasswordEnv: "VTE_TEST_DB_PASSWORD"}
+	assert.NoError(t, ResolveDBPassword(&conf))
+	assert.Equal(t, "from-env", conf.Password)
+
+	conf = db.Conf{Password: "literal", PasswordFile: path, PasswordEnv: "VTE_TEST_UNDEFINED"}
+	assert.NoError(t, ResolveDBPassword(&conf))
+	assert.Equal(t, "from-file", conf.Password)
+
+	conf = db.Conf{Password: "literal"}
+	assert.NoError(t, ResolveDBPassword(&conf))
+	assert.Equal(t, "literal", conf.Password)
+}
+
+func TestResolveDBPasswordErrors(t *testing.T) {
+	conf := db.Conf{PasswordEnv: "VTE_TEST_UNDEFINED"}
+	assert.Error(t, ResolveDBPassword(&conf))
+
+	conf = db.Conf{Password: "literal", PasswordFile: filepath.Join(t.TempDir(), "missing")}
+	assert.Error(t, ResolveDBPassword(&conf))
+
+	conf = db.Conf{Mirrors: []db.Conf{{PasswordEnv: "VTE_TEST_UNDEFINED"}}}
+	assert.Error(t, ResolveDBPassword(&conf))
+}
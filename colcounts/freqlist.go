@@ -0,0 +1,142 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// FreqItem is a single value of a frequency list.
+// ARF is valid only if HasARF is true.
+type FreqItem struct {
+	Value  string
+	Count  int
+	IPM    float64
+	ARF    float64
+	HasARF bool
+}
+
+// FreqListQuery specifies a frequency list of a single counted column
+type FreqListQuery struct {
+	Corpus   string
+	Column   db.VertColumn
+	MinCount int
+	Limit    int
+}
+
+// ipm calculates "instances per million" relative frequency
+func ipm(count, numTokens int) float64 {
+	if numTokens == 0 {
+		return 0
+	}
+	return float64(count) / float64(numTokens) * 1e6
+}
+
+// FreqList creates a frequency list of values of a counted column
+// ordered by absolute frequency (most frequent first).
+// In case more counted columns are configured, the same column value
+// may be spread among more rows of the colcounts table. Their counts are
+// summed and so are their ARF values which is only an approximation
+// of ARF of the aggregated value. Rows without calculated ARF are stored
+// with a negative value - in case any of the rows is such, the item has
+// no ARF.
+func FreqList(reader *db.Reader, query FreqListQuery) ([]FreqItem, error) {
+	numTokens, err := reader.NumTokens(query.Corpus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create frequency list: %w", err)
+	}
+	args := []any{query.Corpus}
+	sqlq := fmt.Sprintf(
+		"SELECT col%d, SUM(count) AS cnt, CASE WHEN MIN(arf) >= 0 THEN SUM(arf) END "+
+			"FROM %s WHERE corpus_id = ? GROUP BY col%d",
+		query.Column.Idx, reader.Table("colcounts"), query.Column.Idx)
+	if query.MinCount > 0 {
+		sqlq += " HAVING SUM(count) >= ?"
+		args = append(args, query.MinCount)
+	}
+	sqlq += fmt.Sprintf(" ORDER BY cnt DESC, col%d", query.Column.Idx)
+	if query.Limit > 0 {
+		sqlq += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+	rows, err := reader.DB.Query(sqlq, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create frequency list: %w", err)
+	}
+	defer rows.Close()
+	ans := make([]FreqItem, 0, 1000)
+	for rows.Next() {
+		var value sql.NullString
+		var arf sql.NullFloat64
+		var item FreqItem
+		if err := rows.Scan(&value, &item.Count, &arf); err != nil {
+			return nil, fmt.Errorf("failed to create frequency list: %w", err)
+		}
+		item.Value = value.String
+		item.ARF = arf.Float64
+		item.HasARF = arf.Valid
+		item.IPM = ipm(item.Count, numTokens)
+		ans = append(ans, item)
+	}
+	return ans, rows.Err()
+}
+
+// WriteFreqListTSV writes a frequency list as TSV with a header line.
+// The arf column of items without ARF is empty.
+func WriteFreqListTSV(w io.Writer, label string, items []FreqItem) error {
+	if _, err := fmt.Fprintf(w, "%s\tcount\tipm\tarf\n", label); err != nil {
+		return err
+	}
+	for _, item := range items {
+		var arf string
+		if item.HasARF {
+			arf = fmt.Sprintf("%01.2f", item.ARF)
+		}
+		_, err := fmt.Fprintf(w, "%s\t%d\t%01.4f\t%s\n", item.Value, item.Count, item.IPM, arf)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ColumnFrequencies returns absolute frequencies of all
+// the values of a counted column (in no particular order)
+func ColumnFrequencies(reader *db.Reader, corpus string, column db.VertColumn) ([]int, error) {
+	rows, err := reader.DB.Query(
+		fmt.Sprintf(
+			"SELECT SUM(count) FROM %s WHERE corpus_id = ? GROUP BY col%d",
+			reader.Table("colcounts"), column.Idx),
+		corpus,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get column frequencies: %w", err)
+	}
+	defer rows.Close()
+	ans := make([]int, 0, 1000)
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("failed to get column frequencies: %w", err)
+		}
+		ans = append(ans, v)
+	}
+	return ans, rows.Err()
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReader(t *testing.T) *db.Reader {
+	database, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.SetMaxOpenConns(1)
+	queries := []string{
+		"CREATE TABLE colcounts (hash_id TEXT, col0 TEXT, col1 TEXT, corpus_id TEXT, count INTEGER, arf INTEGER)",
+		"CREATE TABLE stats (corpus_id TEXT, name TEXT, value INTEGER)",
+		"INSERT INTO colcounts VALUES ('h1', 'x', 'N', 'c1', 4, 2.5), ('h2', 'x', 'V', 'c1', 2, 1.5), " +
+			"('h3', 'y', 'N', 'c1', 3, -1), ('h4', 'x', 'N', 'c2', 5, -1), ('h5', 'y', 'N', 'c2', 1, -1)",
+		"INSERT INTO stats VALUES ('c1', 'words', 100), ('c2', 'words', 100)",
+	}
+	for _, q := range queries {
+		if _, err := database.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &db.Reader{DB: database}
+}
+
+func TestFreqList(t *testing.T) {
+	reader := createTestReader(t)
+	defer reader.Close()
+	items, err := FreqList(reader, FreqListQuery{Corpus: "c1", Column: db.VertColumn{Idx: 0}})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]FreqItem{
+			{Value: "x", Count: 6, IPM: 60000, ARF: 4, HasARF: true},
+			{Value: "y", Count: 3, IPM: 30000},
+		},
+		items,
+	)
+}
+
+func TestFreqListWithoutARF(t *testing.T) {
+	reader := createTestReader(t)
+	defer reader.Close()
+	items, err := FreqList(reader, FreqListQuery{Corpus: "c2", Column: db.VertColumn{Idx: 0}})
+	assert.NoError(t, err)
+	var buff strings.Builder
+	assert.NoError(t, WriteFreqListTSV(&buff, "word", items))
+	assert.Equal(t, "word\tcount\tipm\tarf\nx\t5\t50000.0000\t\ny\t1\t10000.0000\t\n", buff.String())
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount"
+)
+
+const (
+	// manateeNgramSeparator is used by Manatee/NoSketchEngine
+	// to join tokens of n-grams
+	manateeNgramSeparator = " "
+)
+
+var manateeValueReplacer = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+// WordlistQuery specifies a Manatee compatible wordlist/n-gram list
+type WordlistQuery struct {
+	Corpus  string
+	Columns db.VertColumns
+
+	// NgramSize and Separator describe how values of the colcounts
+	// table are stored so they can be converted to Manatee n-grams
+	NgramSize int
+	Separator string
+
+	MinCount int
+}
+
+// ManateeValue converts a stored colcounts value to the form used
+// by Manatee, i.e. n-gram tokens are joined by a space and characters
+// conflicting with the line format (tabs, line breaks) are replaced
+// by a space.
+func ManateeValue(value string, ngramSize int, sep string) string {
+	if ngramSize > 1 {
+		value = strings.Join(ptcount.SplitNgram(value, sep), manateeNgramSeparator)
+	}
+	return manateeValueReplacer.Replace(value)
+}
+
+// WriteManateeWordlist writes a wordlist (or an n-gram list for
+// n-gram size > 1) of the selected counted columns in the format
+// produced and consumed by NoSketchEngine/Manatee utilities
+// (e.g. the 'wordlist' export or lsclex -f): each line contains
+// tab separated values of the columns followed by their absolute
+// frequency. There is no header and items are ordered by frequency
+// (most frequent first). In case not all the counted columns are
+// selected, the counts are aggregated over the remaining ones.
+func WriteManateeWordlist(reader *db.Reader, query WordlistQuery, w io.Writer) (int, error) {
+	if len(query.Columns) == 0 {
+		return 0, fmt.Errorf("failed to write wordlist: no columns specified")
+	}
+	colNames := db.GenerateColCountNames(query.Columns)
+	args := []any{query.Corpus}
+	sqlq := fmt.Sprintf(
+		"SELECT %s, SUM(count) AS cnt FROM %s WHERE corpus_id = ? GROUP BY %s",
+		strings.Join(colNames, ", "), reader.Table("colcounts"), strings.Join(colNames, ", "))
+	if query.MinCount > 0 {
+		sqlq += " HAVING SUM(count) >= ?"
+		args = append(args, query.MinCount)
+	}
+	sqlq += fmt.Sprintf(" ORDER BY cnt DESC, %s", strings.Join(colNames, ", "))
+	rows, err := reader.DB.Query(sqlq, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write wordlist: %w", err)
+	}
+	defer rows.Close()
+	values := make([]sql.NullString, len(colNames))
+	var count int
+	dest := make([]any, len(colNames)+1)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	dest[len(colNames)] = &count
+	line := make([]string, len(colNames))
+	var numItems int
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return numItems, fmt.Errorf("failed to write wordlist: %w", err)
+		}
+		for i, v := range values {
+			line[i] = ManateeValue(v.String, query.NgramSize, query.Separator)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", strings.Join(line, "\t"), count); err != nil {
+			return numItems, fmt.Errorf("failed to write wordlist: %w", err)
+		}
+		numItems++
+	}
+	return numItems, rows.Err()
+}
@@ -0,0 +1,32 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManateeValue(t *testing.T) {
+	assert.Equal(t, "pes", ManateeValue("pes", 1, " "))
+	assert.Equal(t, "a\\ b", ManateeValue("a\\ b", 1, " "))
+	assert.Equal(t, "velký pes", ManateeValue("velký|pes", 2, "|"))
+	assert.Equal(t, "a|b c", ManateeValue("a\\|b|c", 2, "|"))
+	assert.Equal(t, "New York city", ManateeValue("New York city", 2, " "))
+	assert.Equal(t, "x y", ManateeValue("x\ty", 1, " "))
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+const (
+	likeEscapeChar = "!"
+)
+
+// Match is a condition on a counted column value.
+// The pattern may contain '*' matching any substring.
+type Match struct {
+	Column  db.VertColumn
+	Pattern string
+}
+
+// likePattern converts the pattern to an SQL LIKE
+// expression (to be used with ESCAPE likeEscapeChar)
+func (m Match) likePattern() string {
+	ans := strings.NewReplacer(
+		likeEscapeChar, likeEscapeChar+likeEscapeChar,
+		"%", likeEscapeChar+"%",
+		"_", likeEscapeChar+"_",
+	).Replace(m.Pattern)
+	return strings.ReplaceAll(ans, "*", "%")
+}
+
+// ParseMatch parses an expression like 'lemma=pes*' where the left
+// side identifies a counted column (by its role, name or index)
+func ParseMatch(expr string, cols db.VertColumns) (Match, error) {
+	tmp := strings.SplitN(expr, "=", 2)
+	if len(tmp) != 2 {
+		return Match{}, fmt.Errorf("invalid match expression '%s' (expected attr=value)", expr)
+	}
+	col, ok := cols.FindByName(strings.TrimSpace(tmp[0]))
+	if !ok {
+		return Match{}, fmt.Errorf("column '%s' is not among counted columns", tmp[0])
+	}
+	return Match{Column: col, Pattern: tmp[1]}, nil
+}
+
+// Query specifies how to search in the colcounts table
+type Query struct {
+	Corpus   string
+	Matches  []Match
+	MinCount int
+	Limit    int
+}
+
+// Record is a single n-gram with its frequency information.
+// Values are ordered the same way as configured counted columns.
+type Record struct {
+	Values []string
+	Count  int
+	ARF    float64
+}
+
+// Search finds n-grams matching the query, ordered
+// by their frequency (most frequent first).
+func Search(reader *db.Reader, cols db.VertColumns, query Query) ([]Record, error) {
+	colNames := db.GenerateColCountNames(cols)
+	where := []string{"corpus_id = ?"}
+	args := []any{query.Corpus}
+	for _, m := range query.Matches {
+		where = append(where, fmt.Sprintf("col%d LIKE ? ESCAPE '%s'", m.Column.Idx, likeEscapeChar))
+		args = append(args, m.likePattern())
+	}
+	if query.MinCount > 0 {
+		where = append(where, "count >= ?")
+		args = append(args, query.MinCount)
+	}
+	sqlq := fmt.Sprintf(
+		"SELECT %s, count, arf FROM %s WHERE %s ORDER BY count DESC",
+		strings.Join(colNames, ", "), reader.Table("colcounts"), strings.Join(where, " AND "))
+	if query.Limit > 0 {
+		sqlq += fmt.Sprintf(" LIMIT %d", query.Limit)
+	}
+	rows, err := reader.DB.Query(sqlq, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query colcounts: %w", err)
+	}
+	defer rows.Close()
+	ans := make([]Record, 0, 100)
+	for rows.Next() {
+		values := make([]sql.NullString, len(colNames))
+		var count int
+		var arf sql.NullFloat64
+		dest := make([]any, len(colNames)+2)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		dest[len(colNames)] = &count
+		dest[len(colNames)+1] = &arf
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to query colcounts: %w", err)
+		}
+		rec := Record{Values: make([]string, len(values)), Count: count, ARF: arf.Float64}
+		for i, v := range values {
+			rec.Values[i] = v.String
+		}
+		ans = append(ans, rec)
+	}
+	return ans, rows.Err()
+}
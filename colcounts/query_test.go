@@ -0,0 +1,52 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMatch(t *testing.T) {
+	cols := db.VertColumns{{Idx: 0, Role: "word"}, {Idx: 2, Role: "lemma"}}
+	m, err := ParseMatch("lemma=pes*", cols)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, m.Column.Idx)
+	assert.Equal(t, "pes*", m.Pattern)
+
+	m, err = ParseMatch("col0=a=b", cols)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, m.Column.Idx)
+	assert.Equal(t, "a=b", m.Pattern)
+
+	_, err = ParseMatch("tag=N*", cols)
+	assert.Error(t, err)
+	_, err = ParseMatch("lemma", cols)
+	assert.Error(t, err)
+}
+
+func TestLikePattern(t *testing.T) {
+	m := Match{Pattern: "50%_off!*"}
+	assert.Equal(t, "50!%!_off!!%", m.likePattern())
+}
+
+func TestIPM(t *testing.T) {
+	assert.InDelta(t, 2500.0, ipm(5, 2000), 1e-9)
+	assert.Equal(t, 0.0, ipm(5, 0))
+}
@@ -0,0 +1,192 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// CurvePoint is a single point of a vocabulary growth
+// or a coverage curve
+type CurvePoint struct {
+	X int     `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// VocabStats contains basic lexical statistics of a counted column.
+//
+// VocabularyGrowth contains expected numbers of types for increasing
+// numbers of tokens. As the colcounts table does not contain positions
+// of values, the curve is interpolated from the frequency distribution
+// (i.e. it corresponds to randomly ordered text).
+//
+// Coverage contains ratios of tokens covered by N most frequent types.
+type VocabStats struct {
+	Corpus           string       `json:"corpus"`
+	Column           string       `json:"column"`
+	Tokens           int          `json:"tokens"`
+	Types            int          `json:"types"`
+	HapaxLegomena    int          `json:"hapaxLegomena"`
+	DisLegomena      int          `json:"disLegomena"`
+	HapaxRatio       float64      `json:"hapaxRatio"`
+	TypeTokenRatio   float64      `json:"typeTokenRatio"`
+	ZipfExponent     float64      `json:"zipfExponent"`
+	VocabularyGrowth []CurvePoint `json:"vocabularyGrowth"`
+	Coverage         []CurvePoint `json:"coverage"`
+}
+
+// expectedTypes calculates expected vocabulary size of a random
+// sample of n tokens out of the whole text with numTokens tokens
+func expectedTypes(freqs []int, numTokens, n int) float64 {
+	if n >= numTokens {
+		return float64(len(freqs))
+	}
+	p := 1 - float64(n)/float64(numTokens)
+	var missing float64
+	for _, f := range freqs {
+		missing += math.Pow(p, float64(f))
+	}
+	return float64(len(freqs)) - missing
+}
+
+// zipfExponent estimates the exponent of Zipf's law using
+// least squares fit of log(frequency) against log(rank).
+// The frequencies must be sorted in descending order.
+func zipfExponent(freqs []int) float64 {
+	if len(freqs) < 2 {
+		return 0
+	}
+	var sx, sy, sxx, sxy float64
+	for i, f := range freqs {
+		x := math.Log(float64(i + 1))
+		y := math.Log(float64(f))
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+	}
+	n := float64(len(freqs))
+	denom := n*sxx - sx*sx
+	if denom == 0 {
+		return 0
+	}
+	return -(n*sxy - sx*sy) / denom
+}
+
+// coverageRanks generates ranks 1, 2, 5, 10, 20, 50,...
+// up to (and including) numTypes
+func coverageRanks(numTypes int) []int {
+	ans := make([]int, 0, 20)
+	for base := 1; base < numTypes; base *= 10 {
+		for _, m := range []int{1, 2, 5} {
+			if base*m < numTypes {
+				ans = append(ans, base*m)
+			}
+		}
+	}
+	if numTypes > 0 {
+		ans = append(ans, numTypes)
+	}
+	return ans
+}
+
+// ComputeVocabStats calculates vocabulary statistics out of provided
+// absolute frequencies of individual types. The numPoints argument
+// specifies number of points of the vocabulary growth curve.
+func ComputeVocabStats(freqs []int, numPoints int) VocabStats {
+	sorted := make([]int, len(freqs))
+	copy(sorted, freqs)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	var ans VocabStats
+	ans.Types = len(sorted)
+	for _, f := range sorted {
+		ans.Tokens += f
+		switch f {
+		case 1:
+			ans.HapaxLegomena++
+		case 2:
+			ans.DisLegomena++
+		}
+	}
+	if ans.Types == 0 || ans.Tokens == 0 {
+		return ans
+	}
+	ans.HapaxRatio = float64(ans.HapaxLegomena) / float64(ans.Types)
+	ans.TypeTokenRatio = float64(ans.Types) / float64(ans.Tokens)
+	ans.ZipfExponent = zipfExponent(sorted)
+
+	if numPoints < 1 {
+		numPoints = 1
+	}
+	ans.VocabularyGrowth = make([]CurvePoint, 0, numPoints)
+	for i := 1; i <= numPoints; i++ {
+		n := int(math.Round(float64(ans.Tokens) * float64(i) / float64(numPoints)))
+		if n == 0 || len(ans.VocabularyGrowth) > 0 && ans.VocabularyGrowth[len(ans.VocabularyGrowth)-1].X == n {
+			continue
+		}
+		ans.VocabularyGrowth = append(
+			ans.VocabularyGrowth,
+			CurvePoint{X: n, Y: expectedTypes(sorted, ans.Tokens, n)},
+		)
+	}
+
+	ranks := coverageRanks(ans.Types)
+	ans.Coverage = make([]CurvePoint, 0, len(ranks))
+	var cumul, r int
+	for _, rank := range ranks {
+		for ; r < rank; r++ {
+			cumul += sorted[r]
+		}
+		ans.Coverage = append(
+			ans.Coverage,
+			CurvePoint{X: rank, Y: float64(cumul) / float64(ans.Tokens)},
+		)
+	}
+	return ans
+}
+
+// WriteVocabStatsCSV writes statistics of one or more columns
+// as CSV with columns: corpus, column, measure, x, value.
+// Single value measures have the 'x' column empty.
+func WriteVocabStatsCSV(w io.Writer, stats []VocabStats) error {
+	cw := csv.NewWriter(w)
+	fmtFloat := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	cw.Write([]string{"corpus", "column", "measure", "x", "value"})
+	for _, st := range stats {
+		cw.Write([]string{st.Corpus, st.Column, "tokens", "", strconv.Itoa(st.Tokens)})
+		cw.Write([]string{st.Corpus, st.Column, "types", "", strconv.Itoa(st.Types)})
+		cw.Write([]string{st.Corpus, st.Column, "hapaxLegomena", "", strconv.Itoa(st.HapaxLegomena)})
+		cw.Write([]string{st.Corpus, st.Column, "disLegomena", "", strconv.Itoa(st.DisLegomena)})
+		cw.Write([]string{st.Corpus, st.Column, "hapaxRatio", "", fmtFloat(st.HapaxRatio)})
+		cw.Write([]string{st.Corpus, st.Column, "typeTokenRatio", "", fmtFloat(st.TypeTokenRatio)})
+		cw.Write([]string{st.Corpus, st.Column, "zipfExponent", "", fmtFloat(st.ZipfExponent)})
+		for _, p := range st.VocabularyGrowth {
+			cw.Write([]string{st.Corpus, st.Column, "vocabularyGrowth", strconv.Itoa(p.X), fmtFloat(p.Y)})
+		}
+		for _, p := range st.Coverage {
+			cw.Write([]string{st.Corpus, st.Column, "coverage", strconv.Itoa(p.X), fmtFloat(p.Y)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
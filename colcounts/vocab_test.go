@@ -0,0 +1,48 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colcounts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeVocabStats(t *testing.T) {
+	st := ComputeVocabStats([]int{1, 8, 2, 1, 4, 4}, 4)
+	assert.Equal(t, 20, st.Tokens)
+	assert.Equal(t, 6, st.Types)
+	assert.Equal(t, 2, st.HapaxLegomena)
+	assert.Equal(t, 1, st.DisLegomena)
+	assert.InDelta(t, 2.0/6.0, st.HapaxRatio, 1e-9)
+	assert.Greater(t, st.ZipfExponent, 0.0)
+
+	assert.Len(t, st.VocabularyGrowth, 4)
+	assert.Equal(t, 20, st.VocabularyGrowth[3].X)
+	assert.InDelta(t, 6.0, st.VocabularyGrowth[3].Y, 1e-9)
+	for i := 1; i < len(st.VocabularyGrowth); i++ {
+		assert.Greater(t, st.VocabularyGrowth[i].Y, st.VocabularyGrowth[i-1].Y)
+	}
+
+	assert.Equal(t, []CurvePoint{{X: 1, Y: 0.4}, {X: 2, Y: 0.6}, {X: 5, Y: 0.95}, {X: 6, Y: 1}}, st.Coverage)
+}
+
+func TestComputeVocabStatsEmpty(t *testing.T) {
+	st := ComputeVocabStats([]int{}, 10)
+	assert.Equal(t, 0, st.Types)
+	assert.Empty(t, st.Coverage)
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BackupInfix separates an original name and a timestamp
+	// in names of backups (e.g. colcounts_bak_20260101120000)
+	BackupInfix = "_bak_"
+
+	// DfltKeepBackups is a default number of backups
+	// kept per table (or database file)
+	DfltKeepBackups = 1
+
+	backupTimestampLayout = "20060102150405"
+)
+
+// BackupConf configures backing up of existing data
+// before they are replaced by a non-append run
+type BackupConf struct {
+	Enabled bool `json:"enabled"`
+
+	// Keep specifies how many most recent backups of each table
+	// (or SQLite database file) are kept. Older ones are removed
+	// once a new backup is created. (DfltKeepBackups if not set)
+	Keep int `json:"keep,omitempty"`
+}
+
+// GetKeep returns the configured number of kept backups
+// or DfltKeepBackups if not configured
+func (c BackupConf) GetKeep() int {
+	if c.Keep <= 0 {
+		return DfltKeepBackups
+	}
+	return c.Keep
+}
+
+// BackupName creates a name of a backup for a table (or a file)
+func BackupName(name string, t time.Time) string {
+	return name + BackupInfix + t.Format(backupTimestampLayout)
+}
+
+// parseBackupName splits a backup name into the original name
+// and the timestamp part. For names not produced by BackupName,
+// false is returned.
+func parseBackupName(name string) (string, string, bool) {
+	idx := strings.LastIndex(name, BackupInfix)
+	if idx <= 0 {
+		return "", "", false
+	}
+	ts := name[idx+len(BackupInfix):]
+	if _, err := time.Parse(backupTimestampLayout, ts); err != nil {
+		return "", "", false
+	}
+	return name[:idx], ts, true
+}
+
+// ExpiredBackups selects backups which exceed the number of backups
+// to keep. Backups are grouped by their original names so each
+// table keeps its own most recent backups. Names not produced
+// by BackupName are ignored.
+func ExpiredBackups(names []string, keep int) []string {
+	groups := make(map[string][]string)
+	for _, name := range names {
+		orig, _, ok := parseBackupName(name)
+		if !ok {
+			continue
+		}
+		groups[orig] = append(groups[orig], name)
+	}
+	ans := make([]string, 0, len(names))
+	for _, items := range groups {
+		// the timestamp format is sortable as a string
+		sort.Slice(items, func(i, j int) bool {
+			_, ti, _ := parseBackupName(items[i])
+			_, tj, _ := parseBackupName(items[j])
+			return ti > tj
+		})
+		if len(items) > keep {
+			ans = append(ans, items[keep:]...)
+		}
+	}
+	sort.Strings(ans)
+	return ans
+}
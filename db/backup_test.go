@@ -0,0 +1,47 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredBackups(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "colcounts_bak_20260301120000", BackupName("colcounts", ts))
+	names := []string{
+		BackupName("colcounts", ts),
+		BackupName("colcounts", ts.Add(time.Hour)),
+		BackupName("colcounts", ts.Add(-time.Hour)),
+		BackupName("stats", ts),
+		"colcounts_bak_notatime",
+		"colcounts",
+	}
+	assert.Equal(
+		t,
+		[]string{"colcounts_bak_20260301110000"},
+		ExpiredBackups(names, 2),
+	)
+	assert.Equal(
+		t,
+		[]string{"colcounts_bak_20260301110000", "colcounts_bak_20260301120000"},
+		ExpiredBackups(names, 1),
+	)
+}
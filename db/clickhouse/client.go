@@ -0,0 +1,112 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+const (
+	requestTimeout = 10 * time.Minute
+
+	// maxErrorBodySize limits the size of an error message
+	// read from a response
+	maxErrorBodySize = 4096
+
+	// errCodeMemoryLimitExceeded is ClickHouse MEMORY_LIMIT_EXCEEDED
+	// error code as provided by the X-ClickHouse-Exception-Code header
+	errCodeMemoryLimitExceeded = "241"
+)
+
+// client sends queries to the ClickHouse HTTP interface
+type client struct {
+	url      string
+	database string
+	user     string
+	password string
+	http     *http.Client
+}
+
+// exec sends a query. In case data is not nil, the query is passed
+// as a URL parameter and data are sent as the request body (used by
+// INSERT ... FORMAT ... queries). Params are passed as query parameters
+// (param_[name]) or settings.
+func (c *client) exec(query string, params url.Values, data io.Reader) error {
+	return c.execContext(context.Background(), query, params, data)
+}
+
+// execContext is the same as exec but the request is aborted
+// once ctx is cancelled
+func (c *client) execContext(ctx context.Context, query string, params url.Values, data io.Reader) error {
+	args := url.Values{}
+	for k, v := range params {
+		args[k] = v
+	}
+	if c.database != "" {
+		args.Set("database", c.database)
+	}
+	body := data
+	if body == nil {
+		body = strings.NewReader(query)
+
+	} else {
+		args.Set("query", query)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/?"+args.Encode(), body)
+	if err != nil {
+		return fmt.Errorf("failed to prepare ClickHouse request: %w", err)
+	}
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send ClickHouse query: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		if resp.Header.Get("X-ClickHouse-Exception-Code") == errCodeMemoryLimitExceeded {
+			return fmt.Errorf(
+				"ClickHouse query failed (status %d): %s: %w",
+				resp.StatusCode, strings.TrimSpace(string(msg)), db.ErrOutOfMemory)
+		}
+		return fmt.Errorf(
+			"ClickHouse query failed (status %d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+func newClient(rawURL, database, user, password string) *client {
+	return &client{
+		url:      strings.TrimRight(rawURL, "/"),
+		database: database,
+		user:     user,
+		password: password,
+		http:     &http.Client{Timeout: requestTimeout},
+	}
+}
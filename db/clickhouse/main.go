@@ -0,0 +1,358 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clickhouse provides a db.Writer storing the colcounts
+// table in ClickHouse while all the other tables are passed to
+// another writer. ClickHouse is accessed via its HTTP interface.
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	colcountsTable = "colcounts"
+
+	// segmentColumn identifies rows inserted after a savepoint
+	// in the staging table
+	segmentColumn = "vte_segment"
+)
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// Writer stores colcounts rows to ClickHouse and passes all
+// the other data to the embedded db.Writer.
+//
+// As ClickHouse has no transactions, rows are inserted into
+// a staging table first and moved to the target table once
+// the data are committed. In the create mode, the previous rows
+// of the corpus are replaced by the new ones.
+type Writer struct {
+	db.Writer
+
+	client       *client
+	table        string
+	stagingTable string
+	corpusID     string
+	countColumns db.VertColumns
+	batchSize    int
+	appendMode   bool
+
+	// ReplaceCorpus, if true, makes the writer replace the previous
+	// rows of the corpus also in the append mode (see DeleteCorpus)
+	ReplaceCorpus bool
+
+	// staging is true if the staging table exists
+	staging bool
+
+	// segment is incremented by each savepoint so rows
+	// inserted after a savepoint can be removed
+	segment    int
+	savepoints map[string]int
+
+	buff     bytes.Buffer
+	buffCols []string
+	buffRows int
+}
+
+// columnDefs creates definitions of the target table columns
+func (w *Writer) columnDefs() []string {
+	ans := make([]string, 0, len(w.countColumns)+4)
+	for _, c := range db.GenerateColCountNames(w.countColumns) {
+		ans = append(ans, c+" String")
+	}
+	return append(
+		ans, "corpus_id LowCardinality(String)", "count Int64", "arf Float64", "hash_id String")
+}
+
+func (w *Writer) Initialize(appendMode bool) error {
+	if err := w.Writer.Initialize(appendMode); err != nil {
+		return err
+	}
+	w.appendMode = appendMode
+	err := w.client.exec(
+		fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = MergeTree ORDER BY (corpus_id, hash_id)",
+			w.table, strings.Join(w.columnDefs(), ", ")),
+		nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create ClickHouse table %s: %w", w.table, err)
+	}
+	if err := w.client.exec("DROP TABLE IF EXISTS "+w.stagingTable, nil, nil); err != nil {
+		return fmt.Errorf("failed to drop ClickHouse table %s: %w", w.stagingTable, err)
+	}
+	err = w.client.exec(
+		fmt.Sprintf(
+			"CREATE TABLE %s (%s, %s UInt32) ENGINE = MergeTree ORDER BY tuple()",
+			w.stagingTable, strings.Join(w.columnDefs(), ", "), segmentColumn),
+		nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create ClickHouse table %s: %w", w.stagingTable, err)
+	}
+	w.staging = true
+	log.Info().
+		Str("table", w.table).
+		Str("stagingTable", w.stagingTable).
+		Msg("Initialized ClickHouse colcounts storage")
+	return nil
+}
+
+func (w *Writer) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	if table != colcountsTable {
+		return w.Writer.PrepareInsert(table, attrs)
+	}
+	if !w.staging {
+		return nil, fmt.Errorf("cannot prepare insert - ClickHouse storage not initialized")
+	}
+	if err := w.flush(); err != nil {
+		return nil, err
+	}
+	w.buffCols = append(append([]string{}, attrs...), segmentColumn)
+	return &insert{writer: w, numCols: len(attrs)}, nil
+}
+
+// flush sends all the buffered rows to the staging table
+func (w *Writer) flush() error {
+	return w.flushContext(context.Background())
+}
+
+func (w *Writer) flushContext(ctx context.Context) error {
+	if w.buffRows == 0 {
+		return nil
+	}
+	err := w.client.execContext(
+		ctx,
+		fmt.Sprintf(
+			"INSERT INTO %s (%s) FORMAT TabSeparated", w.stagingTable, strings.Join(w.buffCols, ", ")),
+		nil, &w.buff)
+	w.buff.Reset()
+	w.buffRows = 0
+	if err != nil {
+		return fmt.Errorf("failed to insert colcounts to ClickHouse: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) addRow(ctx context.Context, values []any) error {
+	for _, v := range values {
+		switch tv := v.(type) {
+		case nil:
+			w.buff.WriteString(`\N`)
+		case string:
+			w.buff.WriteString(tsvEscaper.Replace(tv))
+		default:
+			fmt.Fprint(&w.buff, tv)
+		}
+		w.buff.WriteByte('\t')
+	}
+	fmt.Fprintf(&w.buff, "%d\n", w.segment)
+	w.buffRows++
+	if w.buffRows >= w.batchSize {
+		return w.flushContext(ctx)
+	}
+	return nil
+}
+
+func (w *Writer) Savepoint(name string) error {
+	if err := w.Writer.Savepoint(name); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.segment++
+	w.savepoints[name] = w.segment
+	return nil
+}
+
+func (w *Writer) RollbackToSavepoint(name string) error {
+	if err := w.Writer.RollbackToSavepoint(name); err != nil {
+		return err
+	}
+	segment, ok := w.savepoints[name]
+	if !ok {
+		return fmt.Errorf("unknown savepoint %s", name)
+	}
+	w.buff.Reset()
+	w.buffRows = 0
+	err := w.client.exec(
+		fmt.Sprintf("ALTER TABLE %s DELETE WHERE %s >= {segment:UInt32}", w.stagingTable, segmentColumn),
+		url.Values{"param_segment": {fmt.Sprint(segment)}, "mutations_sync": {"2"}},
+		nil)
+	if err != nil {
+		return fmt.Errorf("failed to roll back ClickHouse colcounts: %w", err)
+	}
+	return nil
+}
+
+// dropStaging removes the staging table (if it exists)
+func (w *Writer) dropStaging() error {
+	if !w.staging {
+		return nil
+	}
+	w.staging = false
+	w.buff.Reset()
+	w.buffRows = 0
+	if err := w.client.exec("DROP TABLE IF EXISTS "+w.stagingTable, nil, nil); err != nil {
+		return fmt.Errorf("failed to drop ClickHouse table %s: %w", w.stagingTable, err)
+	}
+	return nil
+}
+
+// publish moves rows from the staging table to the target table
+func (w *Writer) publish() error {
+	if !w.staging {
+		return db.ErrNoActiveTransaction
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if !w.appendMode || w.ReplaceCorpus {
+		err := w.client.exec(
+			fmt.Sprintf("ALTER TABLE %s DELETE WHERE corpus_id = {corpus:String}", w.table),
+			url.Values{"param_corpus": {w.corpusID}, "mutations_sync": {"2"}},
+			nil)
+		if err != nil {
+			return fmt.Errorf("failed to remove previous colcounts of %s: %w", w.corpusID, err)
+		}
+	}
+	if len(w.buffCols) > 0 {
+		cols := strings.Join(w.buffCols[:len(w.buffCols)-1], ", ")
+		err := w.client.exec(
+			fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", w.table, cols, cols, w.stagingTable),
+			nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to move colcounts to %s: %w", w.table, err)
+		}
+	}
+	return w.dropStaging()
+}
+
+// DeleteCorpus deletes rows of the corpus via the embedded writer.
+// As ClickHouse does not support transactions, the previous colcounts
+// rows are removed only once the data are committed (see ReplaceCorpus).
+func (w *Writer) DeleteCorpus(corpusID string) (map[string]int, error) {
+	if corpusID != w.corpusID {
+		return nil, fmt.Errorf(
+			"cannot delete corpus %s - the ClickHouse writer is bound to %s", corpusID, w.corpusID)
+	}
+	ans, err := w.Writer.DeleteCorpus(corpusID)
+	if err != nil {
+		return nil, err
+	}
+	w.ReplaceCorpus = true
+	return ans, nil
+}
+
+// Commit moves the colcounts rows to the target table and then
+// commits the embedded writer. In case the rows cannot be moved,
+// the embedded writer is rolled back.
+func (w *Writer) Commit() error {
+	if err := w.publish(); err != nil {
+		w.dropStaging()
+		w.Writer.Rollback()
+		return err
+	}
+	return w.Writer.Commit()
+}
+
+func (w *Writer) Rollback() error {
+	err := w.dropStaging()
+	if err2 := w.Writer.Rollback(); err2 != nil {
+		return err2
+	}
+	return err
+}
+
+func (w *Writer) Close() {
+	if err := w.dropStaging(); err != nil {
+		log.Warn().Err(err).Msg("failed to clean up ClickHouse staging table")
+	}
+	w.Writer.Close()
+}
+
+// insert buffers colcounts rows
+type insert struct {
+	writer  *Writer
+	numCols int
+}
+
+func (ins *insert) Exec(values ...any) error {
+	return ins.ExecContext(context.Background(), values...)
+}
+
+// ExecContext buffers a row. Once the buffer is full, it is sent
+// to ClickHouse and the request is aborted in case ctx is cancelled.
+func (ins *insert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(values) != ins.numCols {
+		return fmt.Errorf("invalid number of values (expected %d, got %d)", ins.numCols, len(values))
+	}
+	return ins.writer.addRow(ctx, values)
+}
+
+// DeleteCorpus removes all the colcounts rows of a corpus
+// from the ClickHouse table configured by conf
+func DeleteCorpus(conf db.ColcountsStorageConf, corpusID string) error {
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+	c := newClient(conf.URL, conf.Database, conf.User, conf.Password)
+	err := c.exec(
+		fmt.Sprintf("ALTER TABLE %s DELETE WHERE corpus_id = {corpus:String}", conf.GetTable()),
+		url.Values{"param_corpus": {corpusID}, "mutations_sync": {"2"}},
+		nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove colcounts of %s: %w", corpusID, err)
+	}
+	return nil
+}
+
+// NewWriter creates a writer storing colcounts to ClickHouse
+// (as configured by conf) and passing the other tables to w
+func NewWriter(
+	w db.Writer,
+	conf db.ColcountsStorageConf,
+	corpusID string,
+	countColumns db.VertColumns,
+) (*Writer, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	table := conf.GetTable()
+	return &Writer{
+		Writer:       w,
+		client:       newClient(conf.URL, conf.Database, conf.User, conf.Password),
+		table:        table,
+		stagingTable: fmt.Sprintf("%s_staging_%s", table, unsafeNameChars.ReplaceAllString(corpusID, "_")),
+		corpusID:     corpusID,
+		countColumns: countColumns,
+		batchSize:    conf.GetBatchSize(),
+		savepoints:   make(map[string]int),
+	}, nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clickhouse
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	query  string
+	data   string
+	params map[string]string
+}
+
+type fakeServer struct {
+	sync.Mutex
+	requests []request
+}
+
+func (fs *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fs.Lock()
+	defer fs.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	req := request{params: make(map[string]string)}
+	for k, v := range r.URL.Query() {
+		req.params[k] = v[0]
+	}
+	if q := r.URL.Query().Get("query"); q != "" {
+		req.query = q
+		req.data = string(body)
+
+	} else {
+		req.query = string(body)
+	}
+	fs.requests = append(fs.requests, req)
+}
+
+func (fs *fakeServer) queries() []string {
+	ans := make([]string, len(fs.requests))
+	for i, r := range fs.requests {
+		ans[i] = r.query
+	}
+	return ans
+}
+
+type stubWriter struct {
+	db.Writer
+	committed bool
+	deleted   []string
+}
+
+func (sw *stubWriter) Initialize(appendMode bool) error      { return nil }
+func (sw *stubWriter) Savepoint(name string) error           { return nil }
+func (sw *stubWriter) RollbackToSavepoint(name string) error { return nil }
+func (sw *stubWriter) Commit() error                         { sw.committed = true; return nil }
+func (sw *stubWriter) Rollback() error                       { return nil }
+func (sw *stubWriter) Close()                                {}
+
+func (sw *stubWriter) DeleteCorpus(corpusID string) (map[string]int, error) {
+	sw.deleted = append(sw.deleted, corpusID)
+	return map[string]int{db.LiveAttrsTable: 1}, nil
+}
+
+func newTestWriter(t *testing.T, srv *fakeServer) (*Writer, *stubWriter) {
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	main := &stubWriter{}
+	w, err := NewWriter(
+		main,
+		db.ColcountsStorageConf{Type: db.ColcountsStorageClickHouse, URL: ts.URL, Database: "vte", BatchSize: 2},
+		"syn/v1",
+		db.VertColumns{{Idx: 0}, {Idx: 2}},
+	)
+	assert.NoError(t, err)
+	return w, main
+}
+
+func TestWriterCommit(t *testing.T) {
+	srv := &fakeServer{}
+	w, main := newTestWriter(t, srv)
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.Savepoint("f0"))
+	ins, err := w.PrepareInsert("colcounts", []string{"col0", "col2", "corpus_id", "count", "arf", "hash_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("a\tb", "NN", "syn/v1", 3, 1.5, "h1"))
+	assert.NoError(t, ins.Exec("c", "VB", "syn/v1", 1, -1, "h2"))
+	assert.NoError(t, ins.Exec("d", "VB", "syn/v1", 1, -1, "h3"))
+	assert.NoError(t, w.Commit())
+	assert.True(t, main.committed)
+
+	assert.Equal(
+		t,
+		[]string{
+			"CREATE TABLE IF NOT EXISTS colcounts (col0 String, col2 String, corpus_id LowCardinality(String), " +
+				"count Int64, arf Float64, hash_id String) ENGINE = MergeTree ORDER BY (corpus_id, hash_id)",
+			"DROP TABLE IF EXISTS colcounts_staging_syn_v1",
+			"CREATE TABLE colcounts_staging_syn_v1 (col0 String, col2 String, corpus_id LowCardinality(String), " +
+				"count Int64, arf Float64, hash_id String, vte_segment UInt32) ENGINE = MergeTree ORDER BY tuple()",
+			"INSERT INTO colcounts_staging_syn_v1 (col0, col2, corpus_id, count, arf, hash_id, vte_segment) FORMAT TabSeparated",
+			"INSERT INTO colcounts_staging_syn_v1 (col0, col2, corpus_id, count, arf, hash_id, vte_segment) FORMAT TabSeparated",
+			"ALTER TABLE colcounts DELETE WHERE corpus_id = {corpus:String}",
+			"INSERT INTO colcounts (col0, col2, corpus_id, count, arf, hash_id) " +
+				"SELECT col0, col2, corpus_id, count, arf, hash_id FROM colcounts_staging_syn_v1",
+			"DROP TABLE IF EXISTS colcounts_staging_syn_v1",
+		},
+		srv.queries(),
+	)
+	assert.Equal(t, "a\\tb\tNN\tsyn/v1\t3\t1.5\th1\t1\nc\tVB\tsyn/v1\t1\t-1\th2\t1\n", srv.requests[3].data)
+	assert.Equal(t, "syn/v1", srv.requests[5].params["param_corpus"])
+	assert.Equal(t, "vte", srv.requests[5].params["database"])
+}
+
+func TestWriterRollbackToSavepoint(t *testing.T) {
+	srv := &fakeServer{}
+	w, _ := newTestWriter(t, srv)
+	assert.NoError(t, w.Initialize(true))
+	assert.NoError(t, w.Savepoint("f0"))
+	ins, err := w.PrepareInsert("colcounts", []string{"col0", "col2", "corpus_id", "count", "arf", "hash_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("a", "NN", "syn/v1", 3, 1.5, "h1"))
+	assert.NoError(t, w.RollbackToSavepoint("f0"))
+	assert.NoError(t, w.Rollback())
+	assert.NoError(t, w.Rollback())
+
+	queries := srv.queries()
+	assert.Equal(t, "ALTER TABLE colcounts_staging_syn_v1 DELETE WHERE vte_segment >= {segment:UInt32}", queries[3])
+	assert.Equal(t, "1", srv.requests[3].params["param_segment"])
+	assert.Equal(t, "DROP TABLE IF EXISTS colcounts_staging_syn_v1", queries[4])
+	assert.Len(t, queries, 5)
+	for _, q := range queries {
+		assert.False(t, strings.HasPrefix(q, "INSERT"))
+	}
+}
+
+func TestWriterDeleteCorpus(t *testing.T) {
+	srv := &fakeServer{}
+	w, main := newTestWriter(t, srv)
+	assert.NoError(t, w.Initialize(true))
+	_, err := w.DeleteCorpus("syn/v2")
+	assert.Error(t, err)
+	deleted, err := w.DeleteCorpus("syn/v1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{db.LiveAttrsTable: 1}, deleted)
+	assert.Equal(t, []string{"syn/v1"}, main.deleted)
+	assert.NoError(t, w.Commit())
+
+	queries := srv.queries()
+	assert.Contains(t, queries, "ALTER TABLE colcounts DELETE WHERE corpus_id = {corpus:String}")
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// ColcountsStorageClickHouse stores the colcounts
+	// table in a ClickHouse database
+	ColcountsStorageClickHouse = "clickhouse"
+
+	// DfltClickHouseBatchSize is the default number of rows
+	// sent to ClickHouse in a single INSERT
+	DfltClickHouseBatchSize = 100000
+
+	// DfltClickHouseTable is the default name of the ClickHouse colcounts table
+	DfltClickHouseTable = "colcounts"
+)
+
+// identifierRegexp matches safe names of database objects
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// ColcountsStorageConf configures an alternative storage of
+// the colcounts table (i.e. the n-gram counts are not written
+// to the database configured by Conf)
+type ColcountsStorageConf struct {
+
+	// Type is either empty (colcounts are stored along with other
+	// tables) or ColcountsStorageClickHouse
+	Type string `json:"type,omitempty"`
+
+	// URL is a URL of the ClickHouse HTTP interface
+	// (e.g. http://localhost:8123)
+	URL string `json:"url,omitempty"`
+
+	Database string `json:"database,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// Table is a name of the colcounts table (default: colcounts)
+	Table string `json:"table,omitempty"`
+
+	// BatchSize is a number of rows sent in a single request
+	// (default: DfltClickHouseBatchSize)
+	BatchSize int `json:"batchSize,omitempty"`
+}
+
+func (c ColcountsStorageConf) IsConfigured() bool {
+	return c.Type != ""
+}
+
+func (c ColcountsStorageConf) GetTable() string {
+	if c.Table == "" {
+		return DfltClickHouseTable
+	}
+	return c.Table
+}
+
+func (c ColcountsStorageConf) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return DfltClickHouseBatchSize
+	}
+	return c.BatchSize
+}
+
+func (c ColcountsStorageConf) Validate() error {
+	switch c.Type {
+	case "":
+		return nil
+	case ColcountsStorageClickHouse:
+	default:
+		return fmt.Errorf("invalid colcounts.type value '%s' (supported: %s)", c.Type, ColcountsStorageClickHouse)
+	}
+	if c.URL == "" {
+		return fmt.Errorf("colcounts.url must be specified for the %s storage", c.Type)
+	}
+	if c.Database != "" && !identifierRegexp.MatchString(c.Database) {
+		return fmt.Errorf("invalid colcounts.database value '%s'", c.Database)
+	}
+	if !identifierRegexp.MatchString(c.GetTable()) {
+		return fmt.Errorf("invalid colcounts.table value '%s'", c.Table)
+	}
+	return nil
+}
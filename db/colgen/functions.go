@@ -17,6 +17,8 @@
 package colgen
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"strings"
 )
@@ -26,6 +28,7 @@ var (
 		"intercorp": intercorp,
 		"identity":  identity,
 		"empty":     empty,
+		"hash":      hash,
 	}
 )
 
@@ -71,6 +74,17 @@ func identity(attrs map[string]interface{}, useAttrs []string) (string, error) {
 	return strings.Join(vals, "_"), nil
 }
 
+// hash creates a SHA1 hex digest of the argument values. Combined
+// with token arguments, this allows content-based item identifiers.
+func hash(attrs map[string]interface{}, useAttrs []string) (string, error) {
+	vals, err := fetchStringVals(attrs, useAttrs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(strings.Join(vals, "\x00")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func GetFuncByName(fnName string) (AlignedUnboundColGenFn, error) {
 	fn, ok := FuncList[fnName]
 	if ok {
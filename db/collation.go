@@ -0,0 +1,96 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SQLiteConf configures SQLite-specific features
+type SQLiteConf struct {
+
+	// Extensions lists paths of SQLite extensions (e.g. ICU)
+	// loaded into each connection
+	Extensions []string `json:"extensions,omitempty"`
+
+	// ICUCollations maps collation names (as used in Conf.Collations)
+	// to ICU locales (e.g. "czech": "cs_CZ"). The collations are created
+	// in each connection via icu_load_collation which requires the ICU
+	// extension to be listed in Extensions.
+	ICUCollations map[string]string `json:"icuCollations,omitempty"`
+}
+
+// IsConfigured tests whether any extension or collation is configured
+func (sc SQLiteConf) IsConfigured() bool {
+	return len(sc.Extensions) > 0 || len(sc.ICUCollations) > 0
+}
+
+// Key returns a string uniquely identifying the configuration
+func (sc SQLiteConf) Key() string {
+	icu := make([]string, 0, len(sc.ICUCollations))
+	for k, v := range sc.ICUCollations {
+		icu = append(icu, k+"="+v)
+	}
+	sort.Strings(icu)
+	return strings.Join(sc.Extensions, ";") + "|" + strings.Join(icu, ";")
+}
+
+// Validate tests whether collation names are valid
+func (sc SQLiteConf) Validate() error {
+	for name := range sc.ICUCollations {
+		if !identifierRegexp.MatchString(name) {
+			return fmt.Errorf("invalid ICU collation name '%s'", name)
+		}
+	}
+	if len(sc.ICUCollations) > 0 && len(sc.Extensions) == 0 {
+		return fmt.Errorf("sqlite.icuCollations require the ICU extension in sqlite.extensions")
+	}
+	return nil
+}
+
+// ValidateCollations tests whether collations refer to existing
+// liveattrs ([struct]_[attr]) or colcounts (colN) columns and
+// whether collation names are valid
+func ValidateCollations(collations map[string]string, structures map[string][]string, countColumns VertColumns) error {
+	available := make(map[string]bool)
+	for st, attrs := range structures {
+		for _, a := range attrs {
+			available[st+"_"+a] = true
+		}
+	}
+	for _, c := range GenerateColCountNames(countColumns) {
+		available[c] = true
+	}
+	for col, collation := range collations {
+		if !available[col] {
+			return fmt.Errorf("collation configured for an unknown column %s", col)
+		}
+		if !identifierRegexp.MatchString(collation) {
+			return fmt.Errorf("invalid collation name '%s' for column %s", collation, col)
+		}
+	}
+	return nil
+}
+
+// CollationCharset derives a MySQL character set from a collation
+// name (e.g. utf8mb4_czech_ci -> utf8mb4)
+func CollationCharset(collation string) string {
+	charset, _, _ := strings.Cut(collation, "_")
+	return charset
+}
@@ -0,0 +1,42 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCollations(t *testing.T) {
+	structs := map[string][]string{"doc": {"author", "title"}}
+	cols := VertColumns{{Idx: 0}, {Idx: 2}}
+	assert.NoError(t, ValidateCollations(
+		map[string]string{"doc_author": "utf8mb4_czech_ci", "col2": "czech"}, structs, cols))
+	assert.Error(t, ValidateCollations(map[string]string{"doc_year": "czech"}, structs, cols))
+	assert.Error(t, ValidateCollations(map[string]string{"doc_author": "czech; DROP"}, structs, cols))
+	assert.Equal(t, "utf8mb4", CollationCharset("utf8mb4_czech_ci"))
+}
+
+func TestSQLiteConfValidate(t *testing.T) {
+	assert.NoError(t, SQLiteConf{}.Validate())
+	assert.Error(t, SQLiteConf{ICUCollations: map[string]string{"czech": "cs_CZ"}}.Validate())
+	assert.NoError(t, SQLiteConf{
+		Extensions:    []string{"/usr/lib/libSqliteIcu.so"},
+		ICUCollations: map[string]string{"czech": "cs_CZ"},
+	}.Validate())
+}
@@ -17,7 +17,9 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -27,19 +29,173 @@ const (
 	// for VARCHARs used for "colcounts" (which is a base
 	// for n-grams)
 	DfltColcountVarcharSize = 255
+
+	// DfltSelfJoinTokenLimit is a default max. number of atom's tokens
+	// provided to a column generator function (see SelfJoinConf.TokenColumns)
+	DfltSelfJoinTokenLimit = 20
+
+	// CacheTable is a key-value table used by the KonText liveattrs
+	// plug-in to cache query results. It is created (scoped the same way
+	// as other tables of a corpus) and emptied by vte whenever data
+	// are changed.
+	CacheTable = "cache"
+
+	// RunMetadataTable is a table storing information
+	// about extraction runs in a key-value manner per corpus_id
+	RunMetadataTable = "run_metadata"
+
+	// StatsTable is a table storing aggregate totals (see StatsTokens etc.)
+	// in a key-value manner per corpus_id
+	StatsTable = "stats"
+
+	// StatsTokens is a stats key for total number of tokens
+	StatsTokens = "tokens"
+
+	// StatsWords is a stats key for number of tokens accepted
+	// by a configured filter (i.e. tokens actually counted
+	// in colcounts)
+	StatsWords = "words"
+
+	// StatsARFTokens is a stats key for the token total used
+	// to calculate ARF (average distances)
+	StatsARFTokens = "arf_tokens"
+
+	// StatsAtoms is a stats key for number of atom structures
+	StatsAtoms = "atoms"
+
+	// StatsStructPrefix is a prefix of stats keys containing
+	// numbers of individual structures (e.g. "struct:doc")
+	StatsStructPrefix = "struct:"
+
+	// UDFeatsNormalize normalizes UD FEATS values so different
+	// orderings of the same features are counted as one value
+	UDFeatsNormalize = "normalize"
+
+	// UDFeatsExplode normalizes UD FEATS values and also counts
+	// individual features into UDFeatsTable
+	UDFeatsExplode = "explode"
+
+	// UDFeatsTable stores counts of individual UD features
+	UDFeatsTable = "udfeats"
+
+	// AttrValuesTable stores distinct values of structural attributes
+	// along with numbers of atoms and tokens (see AttrValueCount)
+	AttrValuesTable = "attr_values"
+
+	// AttrPairsTable stores co-occurrences of values of configured
+	// structural attribute pairs (see AttrPairCount)
+	AttrPairsTable = "attr_pairs"
+
+	// ColumnInfoTable describes origin of columns of other
+	// tables (see ColumnInfo)
+	ColumnInfoTable = "column_info"
+
+	// ColumnSourceStructAttr - a column contains values
+	// of a structural attribute
+	ColumnSourceStructAttr = "structAttr"
+
+	// ColumnSourcePosAttr - a column contains values
+	// of a positional attribute (a vertical column)
+	ColumnSourcePosAttr = "posAttr"
+
+	// ColumnSourceComputed - a column is computed by vte
+	// (e.g. poscount)
+	ColumnSourceComputed = "computed"
+
+	// RunMetadataCreated is a run metadata key for the
+	// datetime the data were written
+	RunMetadataCreated = "created"
+
+	// RunMetadataHashAlgorithm is a run metadata key for the algorithm
+	// used to create colcounts hash_id values
+	RunMetadataHashAlgorithm = "hash_algorithm"
+
+	// RunMetadataConfigHash is a run metadata key for a hash of
+	// the configuration used to write the data (see cnf.VTEConf.DataConfigHash)
+	RunMetadataConfigHash = "config_hash"
+
+	// RunMetadataVersion is a run metadata key for the version
+	// of vert-tagextract used to write the data
+	RunMetadataVersion = "vte_version"
 )
 
+// AttrValueCount contains numbers of atoms and tokens
+// of a structural attribute value (e.g. doc_genre = "fiction")
+type AttrValueCount struct {
+	Attr      string
+	Value     string
+	NumAtoms  int
+	NumTokens int
+}
+
+// AttrPairCount contains numbers of atoms and tokens with
+// a combination of two structural attribute values
+// (e.g. doc_txtype = "fiction" and doc_period = "1990s")
+type AttrPairCount struct {
+	Attr1     string
+	Value1    string
+	Attr2     string
+	Value2    string
+	NumAtoms  int
+	NumTokens int
+}
+
+// ColumnInfo describes which part of a vertical produced a database
+// column and how the values were modified so consumers can
+// explain column semantics (see ColumnInfoTable)
+type ColumnInfo struct {
+
+	// Table is a table name without any backend-specific
+	// prefix (see TableName)
+	Table string
+
+	Column string
+
+	// Source is one of ColumnSourceStructAttr, ColumnSourcePosAttr,
+	// ColumnSourceComputed
+	Source string
+
+	// Structure is a name of a source structure (structAttr only)
+	Structure string
+
+	// Attr is a name of a structural attribute or an index
+	// of a positional one
+	Attr string
+
+	// Modifiers lists applied value transformations in the order
+	// they are applied (e.g. "whitespace:collapse", "modFn:toLower")
+	Modifiers []string
+
+	// Description is a short description of computed columns
+	// or a role of a positional attribute
+	Description string
+}
+
+// ErrNoActiveTransaction is returned by Writer.Commit
+// in case there is no transaction to be committed (e.g.
+// it has been already committed or rolled back)
+var ErrNoActiveTransaction = errors.New("no active transaction")
+
+// ErrOutOfMemory marks errors caused by a database which ran out
+// of memory in case a backend cannot provide a more specific error
+// type (see factory.IsOutOfMemoryErr)
+var ErrOutOfMemory = errors.New("database out of memory")
+
 type Insert struct {
 	Stmt *sql.Stmt
 }
 
 func (ins *Insert) Exec(values ...any) error {
+	return ins.ExecContext(context.Background(), values...)
+}
+
+func (ins *Insert) ExecContext(ctx context.Context, values ...any) error {
 	for i, v := range values {
 		if _, ok := v.(string); ok && v == "" {
 			values[i] = sql.NullString{String: "", Valid: false}
 		}
 	}
-	_, err := ins.Stmt.Exec(values...)
+	_, err := ins.Stmt.ExecContext(ctx, values...)
 	return err
 }
 
@@ -49,6 +205,38 @@ func (ins *Insert) Exec(values ...any) error {
 type SelfJoinConf struct {
 	ArgColumns  []string `json:"argColumns"`
 	GeneratorFn string   `json:"generatorFn"`
+
+	// TokenColumns specifies positional columns of atom's tokens
+	// made available to the generator function as arguments
+	// 'tokens_col[N]' (values of the first TokenLimit tokens joined
+	// by a space). With TokenColumns configured, the item_id value
+	// is generated once the atom is closed.
+	TokenColumns []int `json:"tokenColumns,omitempty"`
+
+	// TokenLimit is a max. number of tokens used for token
+	// arguments (DfltSelfJoinTokenLimit if not set)
+	TokenLimit int `json:"tokenLimit,omitempty"`
+}
+
+// UsesTokens tells whether the generator function
+// needs positional data of atom's tokens
+func (c *SelfJoinConf) UsesTokens() bool {
+	return len(c.TokenColumns) > 0
+}
+
+// GetTokenLimit returns configured token limit
+// or DfltSelfJoinTokenLimit if not configured
+func (c *SelfJoinConf) GetTokenLimit() int {
+	if c.TokenLimit <= 0 {
+		return DfltSelfJoinTokenLimit
+	}
+	return c.TokenLimit
+}
+
+// TokenArgName returns a name of a generator function
+// argument containing values of a positional column
+func TokenArgName(col int) string {
+	return fmt.Sprintf("tokens_col%d", col)
 }
 
 func (c *SelfJoinConf) IsConfigured() bool {
@@ -93,6 +281,99 @@ type Conf struct {
 	User           string   `json:"user"`
 	Password       string   `json:"password"`
 	PreconfQueries []string `json:"preconfSettings"`
+
+	// PasswordEnv is a name of an environment variable containing
+	// the password. It takes precedence over PasswordFile and Password.
+	PasswordEnv string `json:"passwordEnv,omitempty"`
+
+	// PasswordFile is a path to a file containing the password
+	// (trailing line breaks are ignored). It takes precedence
+	// over Password.
+	PasswordFile string `json:"passwordFile,omitempty"`
+
+	// Port is a port of the database server (MySQL only). It can be
+	// also specified as a part of Host.
+	Port int `json:"port,omitempty"`
+
+	// Charset is a connection character set (MySQL only, e.g. utf8mb4)
+	Charset string `json:"charset,omitempty"`
+
+	// TLS configures encrypted connections (MySQL only, see TLSConf)
+	TLS TLSConf `json:"tls"`
+
+	// DSNParams are additional parameters of the connection
+	// (MySQL only), e.g. timeout, readTimeout or server system
+	// variables (see the go-sql-driver/mysql documentation)
+	DSNParams map[string]string `json:"dsnParams,omitempty"`
+
+	// ReadHost is an optional host (typically a read replica) used
+	// by read-only operations (queries, fsck etc.). Data are always
+	// written to Host.
+	ReadHost string `json:"readHost,omitempty"`
+
+	// ProtectTables, if true, makes MySQL writer refuse to create
+	// or drop any table, view or index not prefixed by the (grouped)
+	// corpus name
+	ProtectTables bool `json:"protectTables,omitempty"`
+
+	// ProtectTablesPattern is an explicit pattern ('%' matches any string)
+	// all the created or dropped tables, views and indices must match.
+	// Unlike ProtectTables, the pattern does not depend on corpus
+	// configuration so a misconfigured corpus cannot widen it.
+	// Setting the pattern implies ProtectTables.
+	ProtectTablesPattern string `json:"protectTablesPattern,omitempty"`
+
+	// PrimaryKey specifies how ids of LiveAttrsTable rows
+	// are obtained (see PrimaryKeyAutoIncrement etc.)
+	PrimaryKey string `json:"primaryKey,omitempty"`
+
+	// AtomicWrite, if true, makes SQLite writer build the database
+	// in a temporary file and move it to Name once the data are
+	// committed
+	AtomicWrite bool `json:"atomicWrite,omitempty"`
+
+	// Backup configures backing up of existing data before
+	// a non-append run replaces them (see BackupConf)
+	Backup BackupConf `json:"backup"`
+
+	// DialectHints allows adapting the MySQL writer
+	// to a specific server (see DialectHintsConf)
+	DialectHints DialectHintsConf `json:"dialectHints"`
+
+	// Retry configures retrying of operations failed due
+	// to transient errors (MySQL only, see RetryConf)
+	Retry RetryConf `json:"retry"`
+
+	// Optimize configures a post-commit optimization
+	// of tables (see OptimizeConf)
+	Optimize OptimizeConf `json:"optimize"`
+
+	// ColcountsPartitioning configures partitioning
+	// of the colcounts table (MySQL only, see PartitioningConf)
+	ColcountsPartitioning PartitioningConf `json:"colcountsPartitioning"`
+
+	// Collations specifies collations of structural attribute
+	// ([struct]_[attr]) and colcounts (colN) columns so values
+	// sort correctly in the target language (column -> collation)
+	Collations map[string]string `json:"collations,omitempty"`
+
+	// SQLite configures SQLite extensions and ICU
+	// collations (see SQLiteConf)
+	SQLite SQLiteConf `json:"sqlite"`
+
+	// Colcounts configures an alternative storage
+	// of the colcounts table (see ColcountsStorageConf)
+	Colcounts ColcountsStorageConf `json:"colcounts"`
+
+	// History configures recording of liveattrs
+	// changes (see HistoryConf)
+	History HistoryConf `json:"history"`
+
+	// Mirrors are additional databases all the data are written to
+	// along with this one (e.g. SQLite for local inspection along
+	// with a production MySQL). Read-only operations use only this
+	// (primary) database.
+	Mirrors []Conf `json:"mirrors,omitempty"`
 }
 
 type VertColumn struct {
@@ -105,6 +386,23 @@ type VertColumn struct {
 	// specify whether the column belongs to one of
 	// {word, lemma, sublemma, tag}
 	Role string `json:"role,omitempty"`
+
+	// UDFeats declares the column as Universal Dependencies FEATS
+	// (see UDFeatsNormalize, UDFeatsExplode)
+	UDFeats string `json:"udFeats,omitempty"`
+
+	// MinLength, Match and NotMatch are optional constraints
+	// on (unmodified) column values. Tokens not meeting them
+	// are excluded from counting (see ptcount.ColumnConstraints).
+	MinLength int    `json:"minLength,omitempty"`
+	Match     string `json:"match,omitempty"`
+	NotMatch  string `json:"notMatch,omitempty"`
+}
+
+// HasConstraints tests whether the column has at least
+// one of MinLength, Match, NotMatch configured
+func (vc VertColumn) HasConstraints() bool {
+	return vc.MinLength > 0 || vc.Match != "" || vc.NotMatch != ""
 }
 
 func (vc VertColumn) IsUndefined() bool {
@@ -122,6 +420,31 @@ func (vc VertColumns) GetByIdx(idx int) VertColumn {
 	return VertColumn{Idx: -1}
 }
 
+// FindByName searches for a column either by its role
+// (e.g. 'lemma'), by its database name (e.g. 'col1')
+// or by its index (e.g. '1').
+func (vc VertColumns) FindByName(name string) (VertColumn, bool) {
+	for _, v := range vc {
+		if v.Role != "" && v.Role == name ||
+			fmt.Sprintf("col%d", v.Idx) == name || fmt.Sprint(v.Idx) == name {
+			return v, true
+		}
+	}
+	return VertColumn{Idx: -1}, false
+}
+
+// ExplodedUDFeats returns columns configured to count
+// individual UD features (see UDFeatsExplode)
+func (vc VertColumns) ExplodedUDFeats() VertColumns {
+	ans := make(VertColumns, 0, len(vc))
+	for _, v := range vc {
+		if v.UDFeats == UDFeatsExplode {
+			ans = append(ans, v)
+		}
+	}
+	return ans
+}
+
 // MaxColumn returns max index of a column
 // in VertColumns. E.g. if one defines
 // columns {3, 10, 7}, then 10 will be returned.
@@ -143,17 +466,360 @@ func (vc VertColumns) MaxColumn() int {
 	return maxc
 }
 
+// Validate checks that column indices are non-negative
+// and that no column is configured more than once. There is no
+// upper limit for the indices - verticals with any number of columns
+// are supported.
+func (vc VertColumns) Validate() error {
+	seen := make(map[int]bool)
+	for _, v := range vc {
+		if v.Idx < 0 {
+			return fmt.Errorf("invalid column index %d", v.Idx)
+		}
+		if seen[v.Idx] {
+			return fmt.Errorf("column %d configured more than once", v.Idx)
+		}
+		seen[v.Idx] = true
+	}
+	return nil
+}
+
 type Writer interface {
 	DatabaseExists() bool
 	Initialize(appendMode bool) error
 	PrepareInsert(table string, attrs []string) (InsertOperation, error)
+
+	// SetRunMetadata stores (or replaces) run metadata
+	// for a corpus (see RunMetadataTable)
+	SetRunMetadata(corpusID string, values map[string]string) error
+
+	// SetStats stores (or replaces) aggregate totals
+	// for a corpus (see StatsTable)
+	SetStats(corpusID string, values map[string]int) error
+
+	// AddAttrValues adds numbers of atoms and tokens of structural
+	// attribute values to the already stored ones (see AttrValuesTable)
+	AddAttrValues(corpusID string, values []AttrValueCount) error
+
+	// AddAttrPairs adds numbers of atoms and tokens of structural
+	// attribute value pairs to the already stored ones (see AttrPairsTable)
+	AddAttrPairs(corpusID string, values []AttrPairCount) error
+
+	// SetColumnInfo stores (or replaces) descriptions
+	// of columns for a corpus (see ColumnInfoTable)
+	SetColumnInfo(corpusID string, values []ColumnInfo) error
+
+	// DeleteCorpus removes all the rows of a corpus from the tables
+	// listed in CorpusTables and clears the cache table. The rows are
+	// deleted within the current transaction so a rollback keeps them.
+	// The returned map contains numbers of deleted rows of individual tables.
+	DeleteCorpus(corpusID string) (map[string]int, error)
+
+	// Commit commits the current transaction. Once called (no matter
+	// whether successfully or not), the transaction is finished.
 	Commit() error
+
+	// Savepoint creates a named savepoint within the current transaction
+	Savepoint(name string) error
+
+	// RollbackToSavepoint discards all the changes made after
+	// the savepoint. The transaction stays active.
+	RollbackToSavepoint(name string) error
+
+	// Rollback rolls back the current transaction. It is a no-op
+	// in case there is no active transaction so it is safe to call
+	// it e.g. after a failed Commit.
 	Rollback() error
+
+	// Close closes the database (any unfinished transaction
+	// is rolled back). Calling Close more than once is safe.
 	Close()
 }
 
 type InsertOperation interface {
 	Exec(values ...any) error
+
+	// ExecContext is the same as Exec but the operation
+	// is stopped once the context is cancelled
+	ExecContext(ctx context.Context, values ...any) error
+}
+
+// CorpusTables lists tables containing rows of individual
+// corpora (identified by corpus_id), see Reader.DeleteCorpus
+// and Writer.DeleteCorpus
+var CorpusTables = []string{
+	LiveAttrsTable,
+	ColCountsTable,
+	UDFeatsTable,
+	AttrValuesTable,
+	AttrPairsTable,
+	RunMetadataTable,
+	StatsTable,
+	ColumnInfoTable,
+}
+
+// Reader provides read access to data generated by a Writer.
+// As different backends name tables differently (e.g. MySQL prefixes
+// table names with a (grouped) corpus name), queries should always
+// obtain table names via the Table method.
+type Reader struct {
+	DB          *sql.DB
+	TablePrefix string
+
+	// OutputCompat is an output compatibility level the database
+	// has been created with (see OutputCompatV2 etc.)
+	OutputCompat string
+}
+
+// Table returns a quoted backend-specific name of a table
+// (e.g. `colcounts` or `liveattrs_entry`)
+func (r *Reader) Table(name string) string {
+	return fmt.Sprintf("`%s%s`", r.TablePrefix, TableName(name, r.OutputCompat))
+}
+
+// RunMetadata returns all the run metadata stored for a corpus
+func (r *Reader) RunMetadata(corpusID string) (map[string]string, error) {
+	rows, err := r.DB.Query(
+		fmt.Sprintf("SELECT name, value FROM %s WHERE corpus_id = ?", r.Table(RunMetadataTable)),
+		corpusID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run metadata: %w", err)
+	}
+	defer rows.Close()
+	ans := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, fmt.Errorf("failed to read run metadata: %w", err)
+		}
+		ans[k] = v
+	}
+	return ans, rows.Err()
+}
+
+// Stats returns all the aggregate totals stored for a corpus
+func (r *Reader) Stats(corpusID string) (map[string]int, error) {
+	rows, err := r.DB.Query(
+		fmt.Sprintf("SELECT name, value FROM %s WHERE corpus_id = ?", r.Table(StatsTable)),
+		corpusID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	defer rows.Close()
+	ans := make(map[string]int)
+	for rows.Next() {
+		var k string
+		var v int
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, fmt.Errorf("failed to read stats: %w", err)
+		}
+		ans[k] = v
+	}
+	return ans, rows.Err()
+}
+
+// NumTokens returns number of counted tokens (see StatsWords)
+// of a corpus as stored in the stats table
+func (r *Reader) NumTokens(corpusID string) (int, error) {
+	stats, err := r.Stats(corpusID)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := stats[StatsWords]
+	if !ok {
+		return 0, fmt.Errorf("number of tokens for %s not found in stats", corpusID)
+	}
+	return v, nil
+}
+
+// RowCount returns number of rows of a corpus in a table
+// (referred by its logical name)
+func (r *Reader) RowCount(table, corpusID string) (int, error) {
+	var ans int
+	err := r.DB.QueryRow(
+		fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE corpus_id = ?", r.Table(table)),
+		corpusID,
+	).Scan(&ans)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows of %s: %w", table, err)
+	}
+	return ans, nil
+}
+
+// TableExists tests whether a table (referred by
+// its logical name) exists in the database
+func (r *Reader) TableExists(table string) bool {
+	rows, err := r.DB.Query(fmt.Sprintf("SELECT 1 FROM %s LIMIT 0", r.Table(table)))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// DeleteCorpus removes all the rows of a corpus from the tables
+// (referred by their logical names). Tables not present in the database
+// are skipped. Like UpdateARF, DeleteCorpus modifies the database and all
+// the changes are made within a single transaction. The returned map
+// contains numbers of deleted rows of individual tables.
+func (r *Reader) DeleteCorpus(corpusID string, tables []string) (map[string]int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete corpus %s: %w", corpusID, err)
+	}
+	ans, err := DeleteCorpusRows(tx, r.Table, corpusID, tables)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to delete corpus %s: %w", corpusID, err)
+	}
+	return ans, nil
+}
+
+// RemoveCorpus removes all the rows of a corpus from CorpusTables and
+// clears the cache table. In case runID is not empty, the removal is
+// recorded to LiveAttrsHistoryTable under the run ID (see WithHistory).
+// All the changes are made within a single transaction.
+func (r *Reader) RemoveCorpus(corpusID, runID string) (map[string]int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete corpus %s: %w", corpusID, err)
+	}
+	ans, err := DeleteCorpusRows(tx, r.Table, corpusID, CorpusTables)
+	if err == nil {
+		err = ClearCacheRows(tx, r.Table)
+	}
+	if err == nil && runID != "" {
+		err = recordHistoryTx(tx, r.Table, runID, corpusID, HistoryOpDelete, "")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to delete corpus %s: %w", corpusID, err)
+	}
+	return ans, nil
+}
+
+// DeleteCorpusRows removes all the rows of a corpus from the tables
+// (referred by their logical names) within an existing transaction.
+// Tables not present in the database are skipped. The table function
+// translates logical names to backend-specific ones (see Reader.Table).
+// It is up to the caller to commit or roll back the transaction.
+func DeleteCorpusRows(
+	tx *sql.Tx, table func(string) string, corpusID string, tables []string,
+) (map[string]int, error) {
+	ans := make(map[string]int, len(tables))
+	for _, t := range tables {
+		if !tableExistsTx(tx, table(t)) {
+			continue
+		}
+		res, err := tx.Exec(
+			fmt.Sprintf("DELETE FROM %s WHERE corpus_id = ?", table(t)), corpusID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete corpus %s from %s: %w", corpusID, t, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete corpus %s from %s: %w", corpusID, t, err)
+		}
+		ans[t] = int(n)
+	}
+	return ans, nil
+}
+
+// ClearCacheRows is a variant of Reader.ClearCache
+// working within an existing transaction
+func ClearCacheRows(tx *sql.Tx, table func(string) string) error {
+	if !tableExistsTx(tx, table(CacheTable)) {
+		return nil
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table(CacheTable))); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// tableExistsTx tests whether a table (referred by its
+// backend-specific name) exists within a transaction
+func tableExistsTx(tx *sql.Tx, table string) bool {
+	rows, err := tx.Query(fmt.Sprintf("SELECT 1 FROM %s LIMIT 0", table))
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+// ClearCache removes all the items of the cache table
+// (if it exists) so KonText does not serve outdated results
+func (r *Reader) ClearCache() error {
+	if !r.TableExists(CacheTable) {
+		return nil
+	}
+	if _, err := r.DB.Exec(fmt.Sprintf("DELETE FROM %s", r.Table(CacheTable))); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// UpdateARF replaces ARF values of colcounts rows of a corpus
+// (identified by their hash_id) and stores the token total used
+// to calculate them (see StatsARFTokens). Rows without a provided
+// value are kept untouched. Unlike the other methods, UpdateARF
+// modifies the database. All the changes are made within a single
+// transaction. The returned value is a number of updated rows.
+func (r *Reader) UpdateARF(corpusID string, values map[string]float64, numTokens int) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to update ARF: %w", err)
+	}
+	stmt, err := tx.Prepare(
+		fmt.Sprintf("UPDATE %s SET arf = ? WHERE corpus_id = ? AND hash_id = ?", r.Table(ColCountsTable)))
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("failed to update ARF: %w", err)
+	}
+	defer stmt.Close()
+	var ans int
+	for hashID, arf := range values {
+		res, err := stmt.Exec(arf, corpusID, hashID)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("failed to update ARF: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("failed to update ARF: %w", err)
+		}
+		ans += int(n)
+	}
+	_, err = tx.Exec(
+		fmt.Sprintf("DELETE FROM %s WHERE corpus_id = ? AND name = ?", r.Table(StatsTable)),
+		corpusID, StatsARFTokens)
+	if err == nil {
+		_, err = tx.Exec(
+			fmt.Sprintf("INSERT INTO %s (corpus_id, name, value) VALUES (?, ?, ?)", r.Table(StatsTable)),
+			corpusID, StatsARFTokens, numTokens)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("failed to update ARF: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to update ARF: %w", err)
+	}
+	return ans, nil
+}
+
+func (r *Reader) Close() error {
+	return r.DB.Close()
 }
 
 // GenerateColCountNames creates a list of general column names
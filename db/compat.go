@@ -0,0 +1,53 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import "fmt"
+
+const (
+	// OutputCompatV2 produces tables named the way vert-tagextract v2
+	// did (as expected by older KonText liveattrs deployments)
+	OutputCompatV2 = "v2"
+
+	// OutputCompatV3 is the current naming (default)
+	OutputCompatV3 = "v3"
+
+	// LegacyLiveAttrsTable is the name of LiveAttrsTable
+	// in case OutputCompatV2 is used
+	LegacyLiveAttrsTable = "item"
+)
+
+// ValidateOutputCompat tests whether the provided value is
+// a supported output compatibility level (empty means default)
+func ValidateOutputCompat(compat string) error {
+	switch compat {
+	case "", OutputCompatV2, OutputCompatV3:
+		return nil
+	}
+	return fmt.Errorf("invalid outputCompat value '%s' (supported: v2, v3)", compat)
+}
+
+// TableName translates a logical table name (e.g. LiveAttrsTable)
+// to the one actually used in the database for a provided output
+// compatibility level. The returned name does not contain any
+// backend-specific prefix.
+func TableName(logical, compat string) string {
+	if compat == OutputCompatV2 && logical == LiveAttrsTable {
+		return LegacyLiveAttrsTable
+	}
+	return logical
+}
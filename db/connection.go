@@ -0,0 +1,105 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+)
+
+var charsetRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// reservedDSNParams are DSN parameters set by vert-tagextract itself
+// or by dedicated configuration fields (port, charset, tls)
+var reservedDSNParams = map[string]bool{
+	"tls":              true,
+	"charset":          true,
+	"parseTime":        true,
+	"loc":              true,
+	"maxAllowedPacket": true,
+}
+
+// TLSConf configures TLS of MySQL connections
+type TLSConf struct {
+
+	// Enabled, if true, makes the connection use TLS verified
+	// against system CA certificates. It is implied by CACert
+	// and SkipVerify.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// CACert is a path to a PEM encoded CA certificate
+	// used to verify the server certificate
+	CACert string `json:"caCert,omitempty"`
+
+	// SkipVerify, if true, disables verification of the server
+	// certificate (use only for testing)
+	SkipVerify bool `json:"skipVerify,omitempty"`
+}
+
+// IsConfigured tells whether TLS should be used
+func (c TLSConf) IsConfigured() bool {
+	return c.Enabled || c.CACert != "" || c.SkipVerify
+}
+
+// Validate tests whether the configuration contains
+// supported values
+func (c TLSConf) Validate() error {
+	if c.CACert != "" && c.SkipVerify {
+		return fmt.Errorf("tls.caCert and tls.skipVerify cannot be combined")
+	}
+	return nil
+}
+
+// ValidateConnection tests connection related settings
+// (port, charset, tls, dsnParams) of a MySQL database
+func (c Conf) ValidateConnection() error {
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port value %d", c.Port)
+	}
+	if c.Port > 0 {
+		for _, host := range []string{c.Host, c.ReadHost} {
+			if _, _, err := net.SplitHostPort(host); err == nil {
+				return fmt.Errorf("port cannot be specified both in host %s and in port", host)
+			}
+		}
+	}
+	if c.Charset != "" && !charsetRegexp.MatchString(c.Charset) {
+		return fmt.Errorf("invalid charset value %s", c.Charset)
+	}
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
+	for k := range c.DSNParams {
+		if k == "" {
+			return fmt.Errorf("empty dsnParams key")
+		}
+		if reservedDSNParams[k] {
+			return fmt.Errorf("dsnParams cannot contain %s (use a dedicated setting)", k)
+		}
+	}
+	return nil
+}
+
+// Address returns the host with the configured port (if any)
+func (c Conf) Address(host string) string {
+	if c.Port > 0 {
+		return net.JoinHostPort(host, strconv.Itoa(c.Port))
+	}
+	return host
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConnection(t *testing.T) {
+	assert.NoError(t, Conf{Host: "db.example.org", Port: 3307, Charset: "utf8mb4"}.ValidateConnection())
+	assert.Error(t, Conf{Host: "db.example.org:3306", Port: 3307}.ValidateConnection())
+	assert.Error(t, Conf{Port: 70000}.ValidateConnection())
+	assert.Error(t, Conf{Charset: "utf8;drop"}.ValidateConnection())
+	assert.Error(t, Conf{TLS: TLSConf{CACert: "ca.pem", SkipVerify: true}}.ValidateConnection())
+	assert.Error(t, Conf{DSNParams: map[string]string{"tls": "true"}}.ValidateConnection())
+	assert.NoError(t, Conf{DSNParams: map[string]string{"timeout": "5s"}}.ValidateConnection())
+}
+
+func TestConfAddress(t *testing.T) {
+	assert.Equal(t, "db.example.org", Conf{}.Address("db.example.org"))
+	assert.Equal(t, "db.example.org:3307", Conf{Port: 3307}.Address("db.example.org"))
+	assert.Equal(t, "[::1]:3307", Conf{Port: 3307}.Address("::1"))
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import "fmt"
+
+const (
+	// DialectAuto detects the server dialect from its version (default)
+	DialectAuto = "auto"
+
+	// DialectMySQL - Oracle MySQL server
+	DialectMySQL = "mysql"
+
+	// DialectMariaDB - MariaDB server
+	DialectMariaDB = "mariadb"
+)
+
+// DialectHintsConf helps the MySQL writer to adapt to differences
+// between Oracle MySQL and MariaDB servers
+type DialectHintsConf struct {
+
+	// Dialect is one of DialectAuto, DialectMySQL, DialectMariaDB
+	Dialect string `json:"dialect,omitempty"`
+
+	// MaxAllowedPacket is a max. size (in bytes) of a packet sent
+	// to the server (zero means the database driver default)
+	MaxAllowedPacket int `json:"maxAllowedPacket,omitempty"`
+
+	// AnalyzeTables, if true, makes the writer update statistics
+	// of the data tables once the data are committed (for MariaDB,
+	// engine-independent statistics are collected too)
+	AnalyzeTables bool `json:"analyzeTables,omitempty"`
+}
+
+// Validate tests whether the configuration contains
+// supported values
+func (c DialectHintsConf) Validate() error {
+	switch c.Dialect {
+	case "", DialectAuto, DialectMySQL, DialectMariaDB:
+	default:
+		return fmt.Errorf("invalid dialectHints.dialect value '%s' (supported: auto, mysql, mariadb)", c.Dialect)
+	}
+	if c.MaxAllowedPacket < 0 {
+		return fmt.Errorf("invalid dialectHints.maxAllowedPacket value %d", c.MaxAllowedPacket)
+	}
+	return nil
+}
@@ -17,11 +17,17 @@
 package factory
 
 import (
+	"errors"
 	"fmt"
+	"syscall"
+	"time"
 
 	"github.com/czcorpus/vert-tagextract/v3/cnf"
 	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/clickhouse"
+	"github.com/czcorpus/vert-tagextract/v3/db/jsonl"
 	"github.com/czcorpus/vert-tagextract/v3/db/mysql"
+	"github.com/czcorpus/vert-tagextract/v3/db/parquet"
 	"github.com/czcorpus/vert-tagextract/v3/db/sqlite"
 )
 
@@ -53,19 +59,136 @@ func (nw *NullWriter) PrepareInsert(table string, attrs []string) (db.InsertOper
 	return nil, fmt.Errorf("no valid database writer installed")
 }
 
+func (nw *NullWriter) SetRunMetadata(corpusID string, values map[string]string) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
+func (nw *NullWriter) SetStats(corpusID string, values map[string]int) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
+func (nw *NullWriter) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
+func (nw *NullWriter) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
+func (nw *NullWriter) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
+func (nw *NullWriter) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return nil, fmt.Errorf("no valid database writer installed")
+}
+
 func (nw *NullWriter) Commit() error {
 	return fmt.Errorf("no valid database writer installed")
 }
 
+func (nw *NullWriter) Savepoint(name string) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
+func (nw *NullWriter) RollbackToSavepoint(name string) error {
+	return fmt.Errorf("no valid database writer installed")
+}
+
 func (nw *NullWriter) Rollback() error {
 	return fmt.Errorf("no valid database writer installed")
 }
 
 func (nw *NullWriter) Close() {}
 
+// NewDatabaseWriter creates a writer for the configured database.
+// In case mirrors are configured, the returned writer passes
+// all the data to all of them (see db.Tee).
 func NewDatabaseWriter(conf *cnf.VTEConf) (db.Writer, error) {
+	w, err := newDatabaseWriter(conf)
+	if err != nil || len(conf.DB.Mirrors) == 0 {
+		return w, err
+	}
+	targets := []db.TeeTarget{{Name: targetName(conf.DB), Writer: w}}
+	for i, mirror := range conf.DB.Mirrors {
+		if len(mirror.Mirrors) > 0 {
+			w.Close()
+			return nil, fmt.Errorf("db.mirrors[%d]: nested mirrors are not supported", i)
+		}
+		mconf := *conf
+		mconf.DB = mirror
+		mw, err := newDatabaseWriter(&mconf)
+		if err == nil {
+			if _, ok := mw.(*NullWriter); ok {
+				err = fmt.Errorf("unsupported database type: %s", mirror.Type)
+			}
+		}
+		if err != nil {
+			for _, t := range targets {
+				t.Writer.Close()
+			}
+			return nil, fmt.Errorf("db.mirrors[%d]: %w", i, err)
+		}
+		targets = append(targets, db.TeeTarget{Name: targetName(mirror), Writer: mw})
+	}
+	return db.Tee(targets...), nil
+}
+
+// targetName identifies a database in error messages
+func targetName(conf db.Conf) string {
+	if conf.Host != "" {
+		return fmt.Sprintf("%s://%s/%s", conf.Type, conf.Host, conf.Name)
+	}
+	return fmt.Sprintf("%s:%s", conf.Type, conf.Name)
+}
+
+func newDatabaseWriter(conf *cnf.VTEConf) (db.Writer, error) {
+	if err := conf.DB.Optimize.Validate(); err != nil {
+		return nil, err
+	}
+	if err := db.ValidatePrimaryKey(conf.DB.PrimaryKey, conf.SelfJoin.IsConfigured()); err != nil {
+		return nil, err
+	}
+	if err := db.ValidateHelperViews(conf.HelperViews, len(conf.Ngrams.VertColumns) > 0); err != nil {
+		return nil, err
+	}
+	if err := db.ValidateCollations(conf.DB.Collations, conf.Structures, conf.Ngrams.VertColumns); err != nil {
+		return nil, err
+	}
+	if err := conf.DB.Colcounts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := conf.DB.History.Validate(conf.DB.Type); err != nil {
+		return nil, err
+	}
+	w, err := newBaseWriter(conf)
+	if err != nil {
+		return nil, err
+	}
+	if conf.DB.History.Enabled {
+		w = db.WithHistory(w, conf.CorpusID(), db.NewRunID(time.Now()))
+	}
+	if !conf.DB.Colcounts.IsConfigured() {
+		return w, nil
+	}
+	if _, ok := w.(*NullWriter); ok {
+		return w, nil
+	}
+	cw, err := clickhouse.NewWriter(w, conf.DB.Colcounts, conf.CorpusID(), conf.Ngrams.VertColumns)
+	if err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// newBaseWriter creates a writer for the configured database type
+// (either a built-in one or one registered via RegisterWriter)
+func newBaseWriter(conf *cnf.VTEConf) (db.Writer, error) {
 	switch conf.DB.Type {
 	case "sqlite":
+		if err := conf.DB.SQLite.Validate(); err != nil {
+			return nil, err
+		}
 		db := &sqlite.Writer{
 			Path:           conf.DB.Name,
 			PreconfQueries: conf.DB.PreconfQueries,
@@ -74,11 +197,69 @@ func NewDatabaseWriter(conf *cnf.VTEConf) (db.Writer, error) {
 			SelfJoinConf:   conf.SelfJoin,
 			BibViewConf:    conf.BibView,
 			VertColumns:    conf.Ngrams.VertColumns,
+			OutputCompat:   conf.OutputCompat,
+			Atomic:         conf.DB.AtomicWrite,
+			Backup:         conf.DB.Backup,
+			AttrValues:     conf.AttrValues.Enabled,
+			AttrPairs:      conf.AttrPairs.IsConfigured(),
+			Optimize:       conf.DB.Optimize,
+			Collations:     conf.DB.Collations,
+			HelperViews:    conf.HelperViews,
+			PrimaryKey:     conf.DB.PrimaryKey,
+			History:        conf.DB.History.Enabled,
+			SQLite:         conf.DB.SQLite,
 		}
 		return db, nil
 	case "mysql":
 		return mysql.NewWriter(conf)
+	case "parquet":
+		return &parquet.Writer{Dir: conf.DB.Name, OutputCompat: conf.OutputCompat}, nil
+	case "jsonl":
+		return &jsonl.Writer{Dir: conf.DB.Name, OutputCompat: conf.OutputCompat}, nil
 	default:
+		if ctor, ok := registeredWriter(conf.DB.Type); ok {
+			return ctor(conf)
+		}
 		return &NullWriter{}, nil
 	}
 }
+
+// NewDatabaseReader opens a database generated by a writer
+// created by NewDatabaseWriter for the same configuration.
+func NewDatabaseReader(conf *cnf.VTEConf) (*db.Reader, error) {
+	switch conf.DB.Type {
+	case "sqlite":
+		return sqlite.OpenReader(conf.DB.Name, conf.OutputCompat, conf.DB.SQLite)
+	case "mysql":
+		return mysql.OpenReader(conf)
+	default:
+		return nil, fmt.Errorf("unsupported database type: %s", conf.DB.Type)
+	}
+}
+
+// IsOutOfMemoryErr tells whether an error has been caused by a lack
+// of memory reported by the OS or by any of the supported databases
+func IsOutOfMemoryErr(err error) bool {
+	return errors.Is(err, syscall.ENOMEM) ||
+		errors.Is(err, db.ErrOutOfMemory) ||
+		sqlite.IsOutOfMemoryErr(err) ||
+		mysql.IsOutOfMemoryErr(err)
+}
+
+// MigrationSQL generates statements upgrading (or downgrading) a database
+// created for the provided configuration to the target outputCompat
+// version. The changes are expected to be obtained via db.SchemaChanges.
+func MigrationSQL(conf *cnf.VTEConf, to string, changes []db.SchemaChange) ([]string, error) {
+	ans := make([]string, 0, len(changes)+1)
+	for _, ch := range changes {
+		switch conf.DB.Type {
+		case "sqlite":
+			ans = append(ans, sqlite.MigrationSQL(ch, db.TableName(db.LiveAttrsTable, to), conf.BibView)...)
+		case "mysql":
+			ans = append(ans, mysql.MigrationSQL(ch, mysql.GroupedCorpusName(conf), to, conf.BibView)...)
+		default:
+			return nil, fmt.Errorf("unsupported database type: %s", conf.DB.Type)
+		}
+	}
+	return ans, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factory
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOutOfMemoryErr(t *testing.T) {
+	assert.True(t, IsOutOfMemoryErr(fmt.Errorf("failed to insert: %w", syscall.ENOMEM)))
+	assert.True(t, IsOutOfMemoryErr(fmt.Errorf("failed to insert: %w", db.ErrOutOfMemory)))
+	assert.True(t, IsOutOfMemoryErr(fmt.Errorf("failed to insert: %w", sqlite3.Error{Code: sqlite3.ErrNomem})))
+	assert.True(t, IsOutOfMemoryErr(&gomysql.MySQLError{Number: 1041}))
+	assert.False(t, IsOutOfMemoryErr(&gomysql.MySQLError{Number: 1062}))
+	assert.False(t, IsOutOfMemoryErr(sqlite3.Error{Code: sqlite3.ErrIoErr}))
+	assert.False(t, IsOutOfMemoryErr(fmt.Errorf("failed to insert: out of memory")))
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factory
+
+import (
+	"sync"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// WriterCtor creates a writer for a configuration
+// with a matching database type (conf.DB.Type)
+type WriterCtor func(conf *cnf.VTEConf) (db.Writer, error)
+
+var (
+	builtinWriters = map[string]bool{
+		"sqlite":  true,
+		"mysql":   true,
+		"parquet": true,
+		"jsonl":   true,
+	}
+
+	registeredWriters   = make(map[string]WriterCtor)
+	registeredWritersMu sync.RWMutex
+)
+
+// RegisterWriter makes a custom storage backend available under
+// the database type name (i.e. for configurations with db.type
+// set to name) so applications embedding vert-tagextract can use
+// their own backends. Like with database/sql drivers, it is
+// expected to be called from an init function. It panics in case
+// ctor is nil or the name is empty, built-in or already registered.
+func RegisterWriter(name string, ctor WriterCtor) {
+	if ctor == nil {
+		panic("factory: RegisterWriter ctor is nil")
+	}
+	if name == "" {
+		panic("factory: RegisterWriter name is empty")
+	}
+	if builtinWriters[name] {
+		panic("factory: cannot register a built-in writer " + name)
+	}
+	registeredWritersMu.Lock()
+	defer registeredWritersMu.Unlock()
+	if _, ok := registeredWriters[name]; ok {
+		panic("factory: RegisterWriter called twice for writer " + name)
+	}
+	registeredWriters[name] = ctor
+}
+
+// registeredWriter returns a constructor of a writer
+// registered via RegisterWriter
+func registeredWriter(name string) (WriterCtor, bool) {
+	registeredWritersMu.RLock()
+	defer registeredWritersMu.RUnlock()
+	ctor, ok := registeredWriters[name]
+	return ctor, ok
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type customWriter struct {
+	NullWriter
+	name string
+}
+
+func TestRegisterWriter(t *testing.T) {
+	RegisterWriter("test-custom", func(conf *cnf.VTEConf) (db.Writer, error) {
+		return &customWriter{name: conf.DB.Name}, nil
+	})
+	conf := &cnf.VTEConf{}
+	conf.DB.Type = "test-custom"
+	conf.DB.Name = "foo"
+	w, err := NewDatabaseWriter(conf)
+	assert.NoError(t, err)
+	if assert.IsType(t, &customWriter{}, w) {
+		assert.Equal(t, "foo", w.(*customWriter).name)
+	}
+
+	assert.Panics(t, func() {
+		RegisterWriter("test-custom", func(conf *cnf.VTEConf) (db.Writer, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		RegisterWriter("sqlite", func(conf *cnf.VTEConf) (db.Writer, error) { return nil, nil })
+	})
+}
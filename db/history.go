@@ -0,0 +1,193 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// LiveAttrsHistoryTable stores changes of LiveAttrsTable
+	// rows (see HistoryConf)
+	LiveAttrsHistoryTable = "liveattrs_history"
+
+	// HistoryOpInsert - a row has been inserted (the data column
+	// contains a JSON object with inserted values)
+	HistoryOpInsert = "insert"
+
+	// HistoryOpDelete - rows of the corpus have been removed (in case
+	// the data column is empty, all the rows of the corpus have been removed)
+	HistoryOpDelete = "delete"
+
+	// RunMetadataRunID is a run metadata key for an identifier
+	// of the run used in LiveAttrsHistoryTable
+	RunMetadataRunID = "run_id"
+
+	// historyTimeFormat is a format of the changed_at column
+	historyTimeFormat = "2006-01-02 15:04:05"
+)
+
+var historyColumns = []string{"run_id", "corpus_id", "op", "changed_at", "data"}
+
+// HistoryConf configures recording of changes of LiveAttrsTable
+// rows so the state of the table at any past date can be reconstructed
+type HistoryConf struct {
+	Enabled bool `json:"enabled"`
+}
+
+// Validate tests whether the history is supported by the database type
+func (hc HistoryConf) Validate(dbType string) error {
+	if hc.Enabled && dbType != "sqlite" && dbType != "mysql" {
+		return fmt.Errorf("history is not supported for database type %s", dbType)
+	}
+	return nil
+}
+
+// NewRunID generates a unique identifier of an extraction run
+func NewRunID(t time.Time) string {
+	rnd := make([]byte, 4)
+	rand.Read(rnd)
+	return t.UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(rnd)
+}
+
+// historyWriter is a Writer decorator recording inserted
+// LiveAttrsTable rows to LiveAttrsHistoryTable within
+// the same transaction
+type historyWriter struct {
+	Writer
+	corpusID string
+	runID    string
+}
+
+func (hw *historyWriter) record(corpusID, op, data string) error {
+	ins, err := hw.Writer.PrepareInsert(LiveAttrsHistoryTable, historyColumns)
+	if err != nil {
+		return err
+	}
+	return ins.Exec(hw.runID, corpusID, op, time.Now().UTC().Format(historyTimeFormat), data)
+}
+
+// recordHistoryTx is a variant of historyWriter.record
+// working within an existing transaction
+func recordHistoryTx(tx *sql.Tx, table func(string) string, runID, corpusID, op, data string) error {
+	_, err := tx.Exec(
+		fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?)",
+			table(LiveAttrsHistoryTable), strings.Join(historyColumns, ", ")),
+		runID, corpusID, op, time.Now().UTC().Format(historyTimeFormat), data)
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// Initialize initializes the wrapped writer. In the create mode,
+// removal of all the previous rows of the corpus is recorded.
+func (hw *historyWriter) Initialize(appendMode bool) error {
+	if err := hw.Writer.Initialize(appendMode); err != nil {
+		return err
+	}
+	if appendMode {
+		return nil
+	}
+	if err := hw.record(hw.corpusID, HistoryOpDelete, ""); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// DeleteCorpus deletes rows of the corpus via the wrapped writer
+// and records the removal within the same transaction
+func (hw *historyWriter) DeleteCorpus(corpusID string) (map[string]int, error) {
+	ans, err := hw.Writer.DeleteCorpus(corpusID)
+	if err != nil {
+		return nil, err
+	}
+	if err := hw.record(corpusID, HistoryOpDelete, ""); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+	return ans, nil
+}
+
+func (hw *historyWriter) PrepareInsert(table string, attrs []string) (InsertOperation, error) {
+	ins, err := hw.Writer.PrepareInsert(table, attrs)
+	if err != nil || table != LiveAttrsTable {
+		return ins, err
+	}
+	hist, err := hw.Writer.PrepareInsert(LiveAttrsHistoryTable, historyColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &historyInsert{ins: ins, hist: hist, attrs: attrs, writer: hw}, nil
+}
+
+// SetRunMetadata stores run metadata along with the run ID
+// used in the history table
+func (hw *historyWriter) SetRunMetadata(corpusID string, values map[string]string) error {
+	ext := make(map[string]string, len(values)+1)
+	for k, v := range values {
+		ext[k] = v
+	}
+	ext[RunMetadataRunID] = hw.runID
+	return hw.Writer.SetRunMetadata(corpusID, ext)
+}
+
+type historyInsert struct {
+	ins    InsertOperation
+	hist   InsertOperation
+	attrs  []string
+	writer *historyWriter
+}
+
+func (hi *historyInsert) Exec(values ...any) error {
+	return hi.ExecContext(context.Background(), values...)
+}
+
+func (hi *historyInsert) ExecContext(ctx context.Context, values ...any) error {
+	data := make(map[string]any, len(hi.attrs))
+	for i, attr := range hi.attrs {
+		data[attr] = values[i]
+	}
+	// the underlying insert may modify values (keys are sorted
+	// so records of the same data are identical)
+	encData, err := sonic.ConfigStd.MarshalToString(data)
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	if err := hi.ins.ExecContext(ctx, values...); err != nil {
+		return err
+	}
+	return hi.hist.ExecContext(
+		ctx,
+		hi.writer.runID, hi.writer.corpusID, HistoryOpInsert,
+		time.Now().UTC().Format(historyTimeFormat), encData)
+}
+
+// WithHistory returns a Writer recording changes of LiveAttrsTable
+// rows to LiveAttrsHistoryTable and passing all the data to w.
+// The history table must be created by w.
+func WithHistory(w Writer, corpusID, runID string) Writer {
+	return &historyWriter{Writer: w, corpusID: corpusID, runID: runID}
+}
@@ -0,0 +1,33 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryDeleteCorpus(t *testing.T) {
+	w := &stubWriter{}
+	deleted, err := WithHistory(w, "syn/v1", "run1").DeleteCorpus("syn/v1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{LiveAttrsTable: 2}, deleted)
+	assert.Len(t, w.ins.rows, 1)
+	assert.Equal(t, []any{"run1", "syn/v1", HistoryOpDelete}, w.ins.rows[0][:3])
+	assert.Equal(t, "", w.ins.rows[0][4])
+}
@@ -0,0 +1,507 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/fs"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// FileSuffix is a suffix of files written by Writer
+	FileSuffix = ".jsonl"
+)
+
+const (
+	// mergeReplace keeps only the last row for each key
+	mergeReplace = iota
+
+	// mergeSum sums non-key (integer) columns of rows with the same key
+	mergeSum
+)
+
+// encodeRow appends a JSON object (with keys ordered the same
+// way as columns) followed by a newline to buff
+func encodeRow(buff []byte, keys [][]byte, values []any) ([]byte, error) {
+	buff = append(buff, '{')
+	for i, v := range values {
+		if i > 0 {
+			buff = append(buff, ',')
+		}
+		buff = append(buff, keys[i]...)
+		// the same way SQL writers do, empty strings are stored as nulls
+		if sv, ok := v.(string); ok && sv == "" {
+			v = nil
+		}
+		data, err := sonic.Marshal(v)
+		if err != nil {
+			return buff, fmt.Errorf("failed to encode value of %s: %w", keys[i], err)
+		}
+		buff = append(buff, data...)
+	}
+	return append(buff, '}', '\n'), nil
+}
+
+// encodeKeys prepares encoded object keys for columns
+func encodeKeys(cols []string) ([][]byte, error) {
+	ans := make([][]byte, len(cols))
+	for i, col := range cols {
+		data, err := sonic.Marshal(col)
+		if err != nil {
+			return nil, err
+		}
+		ans[i] = append(data, ':')
+	}
+	return ans, nil
+}
+
+// stream is a table written row by row to a temporary file
+// which replaces the table's file once data are committed
+type stream struct {
+	cols    []string
+	keys    [][]byte
+	path    string
+	file    *os.File
+	writer  *bufio.Writer
+	size    int64
+	rowBuff []byte
+}
+
+// ExecContext checks ctx and writes the row (the operation
+// itself does not block so it cannot be interrupted)
+func (s *stream) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Exec(values...)
+}
+
+func (s *stream) Exec(values ...any) error {
+	if len(values) != len(s.cols) {
+		return fmt.Errorf(
+			"invalid number of values (expected %d, got %d)", len(s.cols), len(values))
+	}
+	var err error
+	s.rowBuff, err = encodeRow(s.rowBuff[:0], s.keys, values)
+	if err != nil {
+		return err
+	}
+	n, err := s.writer.Write(s.rowBuff)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write row: %w", err)
+	}
+	return nil
+}
+
+// truncate discards all the data written after size bytes
+func (s *stream) truncate(size int64) error {
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if err := s.file.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := s.file.Seek(size, 0); err != nil {
+		return err
+	}
+	s.size = size
+	return nil
+}
+
+// close closes the temporary file. With keep set to false,
+// the file is also removed.
+func (s *stream) close(keep bool) error {
+	err := s.writer.Flush()
+	if cErr := s.file.Close(); err == nil {
+		err = cErr
+	}
+	if !keep || err != nil {
+		os.Remove(s.file.Name())
+	}
+	return err
+}
+
+// keyedTable buffers rows of small tables (run metadata, stats etc.)
+// which must be merged by a key before they are written
+type keyedTable struct {
+	cols []string
+	rows [][]any
+
+	// keyLen is a number of leading columns forming a key
+	keyLen int
+	merge  int
+}
+
+// mergedRows returns rows with applied merging strategy
+func (t *keyedTable) mergedRows() [][]any {
+	ans := make([][]any, 0, len(t.rows))
+	index := make(map[string]int)
+	for _, row := range t.rows {
+		key := fmt.Sprint(row[:t.keyLen]...)
+		idx, ok := index[key]
+		if !ok {
+			index[key] = len(ans)
+			ans = append(ans, append([]any{}, row...))
+			continue
+		}
+		if t.merge == mergeReplace {
+			ans[idx] = append([]any{}, row...)
+			continue
+		}
+		for i := t.keyLen; i < len(row); i++ {
+			prev, _ := ans[idx][i].(int)
+			curr, _ := row[i].(int)
+			ans[idx][i] = prev + curr
+		}
+	}
+	return ans
+}
+
+// savepoint stores sizes of all the tables
+type savepoint struct {
+	streams map[string]int64
+	keyed   map[string]int
+}
+
+// Writer writes each table to a JSON Lines file (<Dir>/<table>.jsonl)
+// with one JSON object per row (e.g. an atom or an n-gram). Rows of
+// liveattrs, colcounts and udfeats are written continuously to temporary
+// files so they are not kept in memory. Files of the previous run are
+// replaced once the data are committed. The append mode is not supported.
+type Writer struct {
+	Dir string
+
+	// OutputCompat specifies naming of tables (see db.OutputCompatV2 etc.)
+	OutputCompat string
+
+	streams    map[string]*stream
+	keyed      map[string]*keyedTable
+	savepoints map[string]savepoint
+}
+
+func (w *Writer) tablePath(name string) string {
+	return filepath.Join(w.Dir, name+FileSuffix)
+}
+
+func (w *Writer) tmpPath(name string) string {
+	return filepath.Join(w.Dir, "."+name+FileSuffix+".tmp")
+}
+
+// knownTables lists all the tables a writer may produce
+func (w *Writer) knownTables() []string {
+	return []string{
+		db.TableName(db.LiveAttrsTable, w.OutputCompat),
+		db.ColCountsTable,
+		db.UDFeatsTable,
+		db.RunMetadataTable,
+		db.StatsTable,
+		db.ColumnInfoTable,
+		db.AttrValuesTable,
+		db.AttrPairsTable,
+	}
+}
+
+func (w *Writer) DatabaseExists() bool {
+	return fs.IsFile(w.tablePath(db.TableName(db.LiveAttrsTable, w.OutputCompat)))
+}
+
+func (w *Writer) Initialize(appendMode bool) error {
+	if appendMode {
+		return fmt.Errorf("jsonl output does not support the append mode")
+	}
+	if w.Dir == "" {
+		return fmt.Errorf("jsonl output directory not specified")
+	}
+	if err := os.MkdirAll(w.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create jsonl output directory: %w", err)
+	}
+	w.streams = make(map[string]*stream)
+	w.keyed = make(map[string]*keyedTable)
+	w.savepoints = make(map[string]savepoint)
+	log.Info().Str("directory", w.Dir).Msg("Writing data to jsonl files")
+	return nil
+}
+
+func (w *Writer) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	if w.streams == nil {
+		return nil, fmt.Errorf("cannot write table %s - no transaction active", table)
+	}
+	name := db.TableName(table, w.OutputCompat)
+	if s, ok := w.streams[name]; ok {
+		if strings.Join(s.cols, ",") != strings.Join(attrs, ",") {
+			return nil, fmt.Errorf("inconsistent columns of table %s", name)
+		}
+		return s, nil
+	}
+	keys, err := encodeKeys(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare table %s: %w", name, err)
+	}
+	file, err := os.Create(w.tmpPath(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare table %s: %w", name, err)
+	}
+	s := &stream{
+		cols:   attrs,
+		keys:   keys,
+		path:   w.tablePath(name),
+		file:   file,
+		writer: bufio.NewWriterSize(file, 1024*1024),
+	}
+	w.streams[name] = s
+	return s, nil
+}
+
+func (w *Writer) getKeyedTable(name string, cols []string, keyLen, merge int) (*keyedTable, error) {
+	if w.keyed == nil {
+		return nil, fmt.Errorf("cannot write table %s - no transaction active", name)
+	}
+	t, ok := w.keyed[name]
+	if !ok {
+		t = &keyedTable{cols: cols, keyLen: keyLen, merge: merge}
+		w.keyed[name] = t
+	}
+	return t, nil
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	ans := make([]string, 0, len(values))
+	for k := range values {
+		ans = append(ans, k)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+func (w *Writer) SetRunMetadata(corpusID string, values map[string]string) error {
+	t, err := w.getKeyedTable(db.RunMetadataTable, []string{"corpus_id", "name", "value"}, 2, mergeReplace)
+	if err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(values) {
+		t.rows = append(t.rows, []any{corpusID, k, values[k]})
+	}
+	return nil
+}
+
+// DeleteCorpus is not supported as the output is always
+// written from scratch (see Initialize)
+func (w *Writer) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return nil, fmt.Errorf("deleting corpus rows is not supported by the JSONL writer")
+}
+
+func (w *Writer) SetStats(corpusID string, values map[string]int) error {
+	t, err := w.getKeyedTable(db.StatsTable, []string{"corpus_id", "name", "value"}, 2, mergeReplace)
+	if err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(values) {
+		t.rows = append(t.rows, []any{corpusID, k, values[k]})
+	}
+	return nil
+}
+
+func (w *Writer) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	t, err := w.getKeyedTable(
+		db.ColumnInfoTable,
+		[]string{
+			"corpus_id", "table_name", "column_name", "source", "structure", "attr", "modifiers", "description"},
+		3,
+		mergeReplace,
+	)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		t.rows = append(
+			t.rows,
+			[]any{
+				corpusID, v.Table, v.Column, v.Source, v.Structure, v.Attr,
+				strings.Join(v.Modifiers, ","), v.Description,
+			},
+		)
+	}
+	return nil
+}
+
+func (w *Writer) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	t, err := w.getKeyedTable(
+		db.AttrValuesTable,
+		[]string{"corpus_id", "attr", "value", "num_atoms", "num_tokens"},
+		3,
+		mergeSum,
+	)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		t.rows = append(t.rows, []any{corpusID, v.Attr, v.Value, v.NumAtoms, v.NumTokens})
+	}
+	return nil
+}
+
+func (w *Writer) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	t, err := w.getKeyedTable(
+		db.AttrPairsTable,
+		[]string{"corpus_id", "attr1", "value1", "attr2", "value2", "num_atoms", "num_tokens"},
+		5,
+		mergeSum,
+	)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		t.rows = append(
+			t.rows, []any{corpusID, v.Attr1, v.Value1, v.Attr2, v.Value2, v.NumAtoms, v.NumTokens})
+	}
+	return nil
+}
+
+// writeKeyedTable writes merged rows of a table via
+// a temporary file
+func (w *Writer) writeKeyedTable(name string, t *keyedTable) (int, error) {
+	keys, err := encodeKeys(t.cols)
+	if err != nil {
+		return 0, err
+	}
+	rows := t.mergedRows()
+	var data []byte
+	for _, row := range rows {
+		data, err = encodeRow(data, keys, row)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := os.WriteFile(w.tmpPath(name), data, 0644); err != nil {
+		return 0, err
+	}
+	return len(rows), os.Rename(w.tmpPath(name), w.tablePath(name))
+}
+
+func (w *Writer) Commit() error {
+	if w.streams == nil {
+		return db.ErrNoActiveTransaction
+	}
+	streams, keyed := w.streams, w.keyed
+	w.streams = nil
+	w.keyed = nil
+	w.savepoints = nil
+	var err error
+	for _, name := range sortedKeys(streams) {
+		s := streams[name]
+		if err != nil {
+			s.close(false)
+			continue
+		}
+		if err = s.close(true); err == nil {
+			err = os.Rename(s.file.Name(), s.path)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to write table %s: %w", name, err)
+			continue
+		}
+		log.Info().Str("table", name).Int64("size", s.size).Msg("Written jsonl file")
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(keyed) {
+		numRows, err := w.writeKeyedTable(name, keyed[name])
+		if err != nil {
+			return fmt.Errorf("failed to write table %s: %w", name, err)
+		}
+		log.Info().Str("table", name).Int("rows", numRows).Msg("Written jsonl file")
+	}
+	// files of tables not produced by this run would mix old and new data
+	for _, name := range w.knownTables() {
+		if _, ok := streams[name]; ok {
+			continue
+		}
+		if _, ok := keyed[name]; ok {
+			continue
+		}
+		if fs.IsFile(w.tablePath(name)) {
+			if err := os.Remove(w.tablePath(name)); err != nil {
+				return fmt.Errorf("failed to remove stale file: %w", err)
+			}
+			log.Info().Str("table", name).Msg("Removed stale jsonl file")
+		}
+	}
+	return nil
+}
+
+func (w *Writer) Savepoint(name string) error {
+	if w.streams == nil {
+		return db.ErrNoActiveTransaction
+	}
+	sp := savepoint{
+		streams: make(map[string]int64, len(w.streams)),
+		keyed:   make(map[string]int, len(w.keyed)),
+	}
+	for k, s := range w.streams {
+		sp.streams[k] = s.size
+	}
+	for k, t := range w.keyed {
+		sp.keyed[k] = len(t.rows)
+	}
+	w.savepoints[name] = sp
+	return nil
+}
+
+func (w *Writer) RollbackToSavepoint(name string) error {
+	if w.streams == nil {
+		return db.ErrNoActiveTransaction
+	}
+	sp, ok := w.savepoints[name]
+	if !ok {
+		return fmt.Errorf("failed to roll back to savepoint %s: no such savepoint", name)
+	}
+	for k, s := range w.streams {
+		if err := s.truncate(sp.streams[k]); err != nil {
+			return fmt.Errorf("failed to roll back to savepoint %s: %w", name, err)
+		}
+	}
+	for k, t := range w.keyed {
+		t.rows = t.rows[:sp.keyed[k]]
+	}
+	return nil
+}
+
+func (w *Writer) Rollback() error {
+	for _, s := range w.streams {
+		s.close(false)
+	}
+	w.streams = nil
+	w.keyed = nil
+	w.savepoints = nil
+	return nil
+}
+
+func (w *Writer) Close() {
+	w.Rollback()
+}
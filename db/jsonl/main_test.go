@@ -0,0 +1,91 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestWriterCommit(t *testing.T) {
+	w := &Writer{Dir: filepath.Join(t.TempDir(), "out")}
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "poscount", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1", 100, "susanne"))
+	assert.NoError(t, w.Savepoint("sp1"))
+	assert.NoError(t, ins.Exec("d2", 200, "susanne"))
+	cc, err := w.PrepareInsert(db.ColCountsTable, []string{"col0", "count", "arf"})
+	assert.NoError(t, err)
+	assert.NoError(t, cc.Exec("x", 1, 0.5))
+	assert.NoError(t, w.AddAttrValues("susanne", []db.AttrValueCount{{Attr: "doc_id", Value: "d2", NumAtoms: 1}}))
+	assert.NoError(t, w.RollbackToSavepoint("sp1"))
+	assert.NoError(t, ins.Exec("", 300, "susanne"))
+	assert.NoError(t, cc.Exec("a \"b\"", 2, 1.25))
+	assert.NoError(t, w.AddAttrValues("susanne", []db.AttrValueCount{{Attr: "doc_id", Value: "d1", NumAtoms: 1}}))
+	assert.NoError(t, w.AddAttrValues("susanne", []db.AttrValueCount{{Attr: "doc_id", Value: "d1", NumAtoms: 2}}))
+	assert.NoError(t, w.SetStats("susanne", map[string]int{db.StatsTokens: 10}))
+	assert.NoError(t, w.SetStats("susanne", map[string]int{db.StatsTokens: 20}))
+	assert.NoError(t, w.Commit())
+	assert.ErrorIs(t, w.Commit(), db.ErrNoActiveTransaction)
+	assert.True(t, w.DatabaseExists())
+
+	assert.Equal(
+		t,
+		"{\"doc_id\":\"d1\",\"poscount\":100,\"corpus_id\":\"susanne\"}\n"+
+			"{\"doc_id\":null,\"poscount\":300,\"corpus_id\":\"susanne\"}\n",
+		readFile(t, filepath.Join(w.Dir, db.LiveAttrsTable+FileSuffix)),
+	)
+	assert.Equal(
+		t,
+		"{\"col0\":\"a \\\"b\\\"\",\"count\":2,\"arf\":1.25}\n",
+		readFile(t, filepath.Join(w.Dir, db.ColCountsTable+FileSuffix)),
+	)
+	assert.Equal(
+		t,
+		"{\"corpus_id\":\"susanne\",\"attr\":\"doc_id\",\"value\":\"d1\",\"num_atoms\":3,\"num_tokens\":0}\n",
+		readFile(t, filepath.Join(w.Dir, db.AttrValuesTable+FileSuffix)),
+	)
+	assert.Equal(
+		t,
+		"{\"corpus_id\":\"susanne\",\"name\":\"tokens\",\"value\":20}\n",
+		readFile(t, filepath.Join(w.Dir, db.StatsTable+FileSuffix)),
+	)
+	entries, err := os.ReadDir(w.Dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 4) // no temporary files left
+
+	assert.NoError(t, w.Initialize(false))
+	ins, err = w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d3"))
+	assert.NoError(t, w.Commit())
+	assert.Equal(t, "{\"doc_id\":\"d3\"}\n", readFile(t, filepath.Join(w.Dir, db.LiveAttrsTable+FileSuffix)))
+	assert.NoFileExists(t, filepath.Join(w.Dir, db.StatsTable+FileSuffix))
+
+	assert.Error(t, w.Initialize(true))
+}
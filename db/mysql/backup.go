@@ -0,0 +1,124 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/rs/zerolog/log"
+)
+
+// maxTableNameLength is a MySQL limit for identifiers
+const maxTableNameLength = 64
+
+// backedUpTables returns names of tables containing data
+// which are backed up before a non-append run
+func backedUpTables(groupedCorpusName, laTable string) []string {
+	return []string{
+		laTable,
+		groupedCorpusName + "_colcounts",
+		groupedCorpusName + "_" + db.RunMetadataTable,
+		groupedCorpusName + "_" + db.StatsTable,
+		groupedCorpusName + "_" + db.ColumnInfoTable,
+		groupedCorpusName + "_" + db.UDFeatsTable,
+		groupedCorpusName + "_" + db.AttrValuesTable,
+		groupedCorpusName + "_" + db.AttrPairsTable,
+	}
+}
+
+// listCorpusTables returns names of all the tables
+// prefixed by the grouped corpus name
+func listCorpusTables(database *sql.DB, dbName, groupedCorpusName string) ([]string, error) {
+	rows, err := database.Query(
+		"SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME LIKE ?",
+		dbName, strings.ReplaceAll(groupedCorpusName, "_", "\\_")+"\\_%",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+	ans := make([]string, 0, 20)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		ans = append(ans, name)
+	}
+	return ans, rows.Err()
+}
+
+// backupTables renames existing data tables to timestamped names
+// (using a single, atomic RENAME TABLE statement) and drops backups
+// exceeding the number of backups to keep. The existing argument
+// contains names of all the tables of the (grouped) corpus.
+func backupTables(
+	database execer,
+	existing []string,
+	groupedCorpusName, laTable string,
+	keep int,
+	now time.Time,
+) error {
+	isExisting := make(map[string]bool)
+	for _, t := range existing {
+		isExisting[t] = true
+	}
+	tables := backedUpTables(groupedCorpusName, laTable)
+	renames := make([]string, 0, len(tables))
+	for _, t := range tables {
+		if !isExisting[t] {
+			continue
+		}
+		backup := db.BackupName(t, now)
+		if len(backup) > maxTableNameLength {
+			return fmt.Errorf("cannot back up table %s - name %s is too long", t, backup)
+		}
+		if isExisting[backup] {
+			return fmt.Errorf("cannot back up table %s - backup %s already exists", t, backup)
+		}
+		renames = append(renames, fmt.Sprintf("`%s` TO `%s`", t, backup))
+		existing = append(existing, backup)
+	}
+	if len(renames) > 0 {
+		if _, err := database.Exec("RENAME TABLE " + strings.Join(renames, ", ")); err != nil {
+			return fmt.Errorf("failed to back up tables: %w", err)
+		}
+		log.Info().Strs("tables", renames).Msg("Backed up existing tables")
+	}
+	isOwnTable := make(map[string]bool)
+	for _, t := range tables {
+		isOwnTable[t] = true
+	}
+	// corpora sharing the prefix (e.g. syn_v2 for syn) must not be affected
+	ownBackups := make([]string, 0, len(existing))
+	for _, t := range existing {
+		if idx := strings.LastIndex(t, db.BackupInfix); idx > 0 && isOwnTable[t[:idx]] {
+			ownBackups = append(ownBackups, t)
+		}
+	}
+	for _, expired := range db.ExpiredBackups(ownBackups, keep) {
+		if _, err := database.Exec(fmt.Sprintf("DROP TABLE IF EXISTS `%s`", expired)); err != nil {
+			return fmt.Errorf("failed to drop expired backup %s: %w", expired, err)
+		}
+		log.Info().Str("table", expired).Msg("Dropped expired table backup")
+	}
+	return nil
+}
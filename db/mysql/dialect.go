@@ -0,0 +1,99 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/rs/zerolog/log"
+)
+
+// detectDialect determines whether the server is MariaDB
+// or Oracle MySQL based on its version string
+func detectDialect(database *sql.DB) (string, error) {
+	var version string
+	if err := database.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to detect server dialect: %w", err)
+	}
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return db.DialectMariaDB, nil
+	}
+	return db.DialectMySQL, nil
+}
+
+const (
+	// maintenanceAnalyze updates statistics of tables
+	maintenanceAnalyze = "ANALYZE"
+
+	// maintenanceOptimize compacts tables (and updates their statistics)
+	maintenanceOptimize = "OPTIMIZE"
+)
+
+// maintenanceStatement creates an ANALYZE TABLE or OPTIMIZE TABLE
+// statement for a specified dialect
+func maintenanceStatement(op, dialect string, tables []string) string {
+	quoted := make([]string, len(tables))
+	for i, t := range tables {
+		quoted[i] = "`" + t + "`"
+	}
+	ans := op + " TABLE " + joinArgs(quoted)
+	if op == maintenanceAnalyze && dialect == db.DialectMariaDB {
+		ans += " PERSISTENT FOR ALL"
+	}
+	return ans
+}
+
+// maintainTables runs a maintenance operation (maintenanceAnalyze,
+// maintenanceOptimize) on the tables which exist. Problems reported
+// by the server are only logged as the data are already committed.
+func maintainTables(database *sql.DB, op, dialect string, existing, tables []string) error {
+	isExisting := make(map[string]bool)
+	for _, t := range existing {
+		isExisting[t] = true
+	}
+	selected := make([]string, 0, len(tables))
+	for _, t := range tables {
+		if isExisting[t] {
+			selected = append(selected, t)
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	rows, err := database.Query(maintenanceStatement(op, dialect, selected))
+	if err != nil {
+		return fmt.Errorf("failed to %s tables: %w", strings.ToLower(op), err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, msgOp, msgType, msgText string
+		if err := rows.Scan(&table, &msgOp, &msgType, &msgText); err != nil {
+			return fmt.Errorf("failed to %s tables: %w", strings.ToLower(op), err)
+		}
+		if msgType == "error" || msgType == "warning" {
+			log.Warn().Str("table", table).Str("message", msgText).Msgf("%s TABLE reported a problem", op)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to %s tables: %w", strings.ToLower(op), err)
+	}
+	log.Info().Strs("tables", selected).Str("dialect", dialect).Msgf("Finished %s TABLE", op)
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceStatement(t *testing.T) {
+	tables := []string{"susanne_liveattrs_entry", "susanne_colcounts"}
+	assert.Equal(
+		t,
+		"ANALYZE TABLE `susanne_liveattrs_entry`, `susanne_colcounts`",
+		maintenanceStatement(maintenanceAnalyze, db.DialectMySQL, tables),
+	)
+	assert.Equal(
+		t,
+		"ANALYZE TABLE `susanne_liveattrs_entry`, `susanne_colcounts` PERSISTENT FOR ALL",
+		maintenanceStatement(maintenanceAnalyze, db.DialectMariaDB, tables),
+	)
+	assert.Equal(
+		t,
+		"OPTIMIZE TABLE `susanne_liveattrs_entry`, `susanne_colcounts`",
+		maintenanceStatement(maintenanceOptimize, db.DialectMariaDB, tables),
+	)
+}
@@ -17,8 +17,13 @@
 package mysql
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -43,6 +48,63 @@ type Writer struct {
 	// (aligned) corpora together (e.g. intercorp_v13_en, intercorp_v13_cs => intercorp_v13)
 	groupedCorpusName string
 
+	// protectPattern, if non-empty, makes all the schema
+	// modifications to be checked against the pattern
+	protectPattern string
+
+	// outputCompat specifies naming of tables (see db.OutputCompatV2 etc.)
+	outputCompat string
+
+	// backup configures renaming of existing tables
+	// before they are replaced by a non-append run
+	backup db.BackupConf
+
+	// dialectHints - see db.DialectHintsConf
+	dialectHints db.DialectHintsConf
+
+	// optimize configures post-commit optimizations
+	optimize db.OptimizeConf
+
+	// attrValues, if true, makes the writer create
+	// a table of structural attribute values (see db.AttrValuesTable)
+	attrValues bool
+
+	// attrPairs, if true, makes the writer create a table
+	// of structural attribute value pairs (see db.AttrPairsTable)
+	attrPairs bool
+
+	// corpusID is the corpus the data are written for
+	// (used to create colcounts partitions)
+	corpusID string
+
+	// partitioning configures partitioning of the colcounts
+	// table (see db.PartitioningConf)
+	partitioning db.PartitioningConf
+
+	// primaryKey specifies how ids of liveattrs
+	// rows are obtained (see db.PrimaryKeyAutoIncrement etc.)
+	primaryKey string
+
+	// history, if true, makes the writer create a table
+	// of liveattrs changes (see db.LiveAttrsHistoryTable)
+	history bool
+
+	// helperViews lists helper views created along
+	// with the schema (see db.HelperViews)
+	helperViews []string
+
+	// collations maps liveattrs and colcounts columns
+	// to their collations (see db.Conf.Collations)
+	collations map[string]string
+
+	// dialect is the server dialect (db.DialectMySQL, db.DialectMariaDB)
+	// resolved during initialization
+	dialect string
+
+	// retry configures retrying of operations
+	// failed due to transient errors
+	retry db.RetryConf
+
 	Structures   map[string][]string
 	IndexedCols  []string
 	SelfJoinConf db.SelfJoinConf
@@ -50,10 +112,14 @@ type Writer struct {
 	CountColumns db.VertColumns
 }
 
+func (w *Writer) laTable() string {
+	return laTableName(w.groupedCorpusName, w.outputCompat)
+}
+
 func (w *Writer) DatabaseExists() bool {
 	row := w.database.QueryRow(
 		`SELECT COUNT(*) > 0 FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`,
-		w.dbName, w.groupedCorpusName+"_liveattrs_entry",
+		w.dbName, w.laTable(),
 	)
 	var ans bool
 	err := row.Scan(&ans)
@@ -67,42 +133,168 @@ func (w *Writer) DatabaseExists() bool {
 	return ans
 }
 
+// ddlExecer returns an object used to modify the database schema
+func (w *Writer) ddlExecer() execer {
+	var ans execer = &retryingExecer{database: w.database, retry: w.retry}
+	if w.protectPattern != "" {
+		ans = &protectedExecer{database: ans, pattern: w.protectPattern}
+	}
+	return ans
+}
+
+// resolveDialect determines the server dialect
+// based on configuration (or detects it)
+func (w *Writer) resolveDialect() error {
+	switch w.dialectHints.Dialect {
+	case "", db.DialectAuto:
+		var err error
+		w.dialect, err = detectDialect(w.database)
+		if err != nil {
+			return err
+		}
+		log.Info().Str("dialect", w.dialect).Msg("Detected database server dialect")
+	default:
+		w.dialect = w.dialectHints.Dialect
+	}
+	return nil
+}
+
 func (w *Writer) Initialize(appendMode bool) error {
+	if err := w.resolveDialect(); err != nil {
+		return err
+	}
 	var err error
 	dbExisted := w.DatabaseExists()
+	ddl := w.ddlExecer()
 	if !appendMode {
 		if dbExisted {
 			log.
 				Warn().
-				Str("storageName", w.dbName+"/"+w.groupedCorpusName+"_liveattrs_entry").
+				Str("storageName", w.dbName+"/"+w.laTable()).
 				Msg("The data storage already exists. Existing data will be deleted.")
-			err := dropExisting(w.database, w.groupedCorpusName)
+			if w.backup.Enabled {
+				existing, err := listCorpusTables(w.database, w.dbName, w.groupedCorpusName)
+				if err != nil {
+					return err
+				}
+				err = backupTables(
+					ddl, existing, w.groupedCorpusName, w.laTable(), w.backup.GetKeep(), time.Now())
+				if err != nil {
+					return err
+				}
+			}
+			err := dropExisting(ddl, w.groupedCorpusName, w.laTable())
 			if err != nil {
 				return err
 			}
 		}
 		err := createSchema(
-			w.database,
+			ddl,
 			w.groupedCorpusName,
+			w.laTable(),
 			w.Structures,
 			w.IndexedCols,
 			w.SelfJoinConf.IsConfigured(),
 			w.CountColumns,
+			w.partitioning,
+			w.corpusID,
+			w.collations,
+			w.primaryKey,
 		)
 		if err != nil {
 			return err
 		}
 		if w.BibViewConf.IsConfigured() {
 			err := createBibView(
-				w.database, w.groupedCorpusName, w.BibViewConf.Cols, w.BibViewConf.IDAttr)
+				ddl, w.groupedCorpusName, w.laTable(), w.BibViewConf.Cols, w.BibViewConf.IDAttr)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	w.tx, err = w.database.Begin()
-	return err
+	if err := createCacheTable(ddl, w.groupedCorpusName); err != nil {
+		return err
+	}
+	if err := createRunMetadataTable(ddl, w.groupedCorpusName); err != nil {
+		return err
+	}
+	if err := createStatsTable(ddl, w.groupedCorpusName); err != nil {
+		return err
+	}
+	if err := createColumnInfoTable(ddl, w.groupedCorpusName); err != nil {
+		return err
+	}
+	if w.history {
+		if err := createHistoryTable(ddl, w.groupedCorpusName); err != nil {
+			return err
+		}
+	}
+	if !appendMode {
+		if err := createHelperViews(ddl, w.groupedCorpusName, w.laTable(), w.helperViews); err != nil {
+			return err
+		}
+	}
+	if w.attrValues {
+		if err := createAttrValuesTable(ddl, w.groupedCorpusName); err != nil {
+			return err
+		}
+	}
+	if w.attrPairs {
+		if err := createAttrPairsTable(ddl, w.groupedCorpusName); err != nil {
+			return err
+		}
+	}
+	if appendMode {
+		if err := w.checkSchema(); err != nil {
+			return err
+		}
+		if err := w.ensureColcountsPartition(ddl); err != nil {
+			return err
+		}
+	}
+
+	err = withRetry(w.retry, isTransientErr, func() error {
+		var err error
+		w.tx, err = w.database.Begin()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if appendMode {
+		return clearCache(w.tx, w.groupedCorpusName)
+	}
+	return nil
+}
+
+// ensureColcountsPartition adds a colcounts partition for the
+// written corpus in case the db.PartitionByCorpus partitioning
+// is configured and the partition does not exist yet
+func (w *Writer) ensureColcountsPartition(ddl execer) error {
+	if w.partitioning.By != db.PartitionByCorpus || len(w.CountColumns) == 0 {
+		return nil
+	}
+	table := w.groupedCorpusName + "_" + db.ColCountsTable
+	existing, err := tablePartitions(w.database, w.dbName, table)
+	if err != nil {
+		return err
+	}
+	return ensureCorpusPartition(ddl, table, w.corpusID, existing)
+}
+
+// checkSchema verifies that existing tables contain all
+// the columns required by the configuration
+func (w *Writer) checkSchema() error {
+	expected := db.ExpectedSchema(w.Structures, w.SelfJoinConf.IsConfigured(), w.CountColumns)
+	actual, err := readSchema(w.database, w.dbName, w.groupedCorpusName, w.outputCompat, expected)
+	if err != nil {
+		return err
+	}
+	if diff := db.CompareSchema(expected, actual); !diff.IsEmpty() {
+		return fmt.Errorf("database schema does not match the configuration:\n%s", diff)
+	}
+	return nil
 }
 
 func (w *Writer) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
@@ -113,58 +305,308 @@ func (w *Writer) PrepareInsert(table string, attrs []string) (db.InsertOperation
 	for i := range attrs {
 		valReplac[i] = "?"
 	}
+	var onDuplicate string
+	if table == db.ColCountsTable {
+		// n-grams already stored for the corpus (see cnf.ExistingCorpusAdd);
+		// ARF values of different runs cannot be combined
+		onDuplicate = " ON DUPLICATE KEY UPDATE count = count + VALUES(count), arf = -1"
+	}
 	stmt, err := w.tx.Prepare(
 		fmt.Sprintf(
-			"INSERT INTO `%s_%s` (%s) VALUES (%s)",
+			"INSERT INTO `%s_%s` (%s) VALUES (%s)%s",
 			w.groupedCorpusName,
 			table,
 			joinArgs(attrs),
 			joinArgs(valReplac),
+			onDuplicate,
 		),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare INSERT into %s: %s", table, err)
 	}
-	return &db.Insert{Stmt: stmt}, nil
+	return &retryingInsert{ins: &db.Insert{Stmt: stmt}, retry: w.retry}, nil
+}
+
+func (w *Writer) SetRunMetadata(corpusID string, values map[string]string) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot set run metadata - no transaction active")
+	}
+	return setRunMetadata(w.tx, w.groupedCorpusName, corpusID, values)
+}
+
+func (w *Writer) SetStats(corpusID string, values map[string]int) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot set stats - no transaction active")
+	}
+	return setStats(w.tx, w.groupedCorpusName, corpusID, values)
+}
+
+func (w *Writer) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot set column info - no transaction active")
+	}
+	return setColumnInfo(w.tx, w.groupedCorpusName, corpusID, values)
+}
+
+func (w *Writer) DeleteCorpus(corpusID string) (map[string]int, error) {
+	if w.tx == nil {
+		return nil, fmt.Errorf("cannot delete corpus - no transaction active")
+	}
+	names := &db.Reader{TablePrefix: w.groupedCorpusName + "_", OutputCompat: w.outputCompat}
+	ans, err := db.DeleteCorpusRows(w.tx, names.Table, corpusID, db.CorpusTables)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ClearCacheRows(w.tx, names.Table); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (w *Writer) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot add attribute values - no transaction active")
+	}
+	return addAttrValues(w.tx, w.groupedCorpusName, corpusID, values)
+}
+
+func (w *Writer) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot add attribute pairs - no transaction active")
+	}
+	return addAttrPairs(w.tx, w.groupedCorpusName, corpusID, values)
 }
 
 func (w *Writer) Commit() error {
-	return w.tx.Commit()
+	if w.tx == nil {
+		return db.ErrNoActiveTransaction
+	}
+	err := w.tx.Commit()
+	w.tx = nil
+	if err != nil {
+		return err
+	}
+	if err := w.optimizeTables(); err != nil {
+		log.Warn().Err(err).Msg("failed to optimize tables")
+	}
+	return nil
+}
+
+// physicalTables translates logical table names
+// to the ones used in the database
+func (w *Writer) physicalTables(logical []string) []string {
+	ans := make([]string, len(logical))
+	for i, t := range logical {
+		if t == db.LiveAttrsTable {
+			ans[i] = w.laTable()
+
+		} else {
+			ans[i] = w.groupedCorpusName + "_" + t
+		}
+	}
+	return ans
+}
+
+// optimizeTables runs the configured post-commit optimizations
+// (see db.OptimizeConf, db.DialectHintsConf.AnalyzeTables)
+func (w *Writer) optimizeTables() error {
+	analyzed := w.optimize.Analyze
+	if w.dialectHints.AnalyzeTables {
+		analyzed = []string{db.OptimizeAll}
+	}
+	if len(analyzed) == 0 && len(w.optimize.Compact) == 0 {
+		return nil
+	}
+	existing, err := listCorpusTables(w.database, w.dbName, w.groupedCorpusName)
+	if err != nil {
+		return err
+	}
+	err = maintainTables(
+		w.database, maintenanceOptimize, w.dialect, existing,
+		w.physicalTables(db.OptimizedTables(w.optimize.Compact)))
+	if err != nil {
+		return err
+	}
+	return maintainTables(
+		w.database, maintenanceAnalyze, w.dialect, existing,
+		w.physicalTables(db.OptimizedTables(analyzed)))
+}
+
+func (w *Writer) Savepoint(name string) error {
+	if w.tx == nil {
+		return db.ErrNoActiveTransaction
+	}
+	if _, err := w.tx.Exec("SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *Writer) RollbackToSavepoint(name string) error {
+	if w.tx == nil {
+		return db.ErrNoActiveTransaction
+	}
+	if _, err := w.tx.Exec("ROLLBACK TO SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint %s: %w", name, err)
+	}
+	return nil
 }
 
 func (w *Writer) Rollback() error {
-	return w.tx.Rollback()
+	if w.tx == nil {
+		return nil
+	}
+	err := w.tx.Rollback()
+	w.tx = nil
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
 }
 
 func (w *Writer) Close() {
+	if w.database == nil {
+		return
+	}
+	if err := w.Rollback(); err != nil {
+		log.Warn().Err(err).Msg("failed to roll back unfinished transaction")
+	}
 	err := w.database.Close()
 	if err != nil {
 		log.Warn().Err(err).Msg("error closing database")
 	}
+	w.database = nil
 }
 
-func NewWriter(conf *cnf.VTEConf) (*Writer, error) {
-
+func openDatabase(conf *cnf.VTEConf, host string) (*sql.DB, error) {
+	if err := conf.DB.ValidateConnection(); err != nil {
+		return nil, err
+	}
 	mconf := mysql.NewConfig()
 	mconf.Net = "tcp"
-	mconf.Addr = conf.DB.Host
+	mconf.Addr = conf.DB.Address(host)
 	mconf.User = conf.DB.User
 	mconf.Passwd = conf.DB.Password
 	mconf.DBName = conf.DB.Name
 	mconf.ParseTime = true
 	mconf.Loc = time.Local
-	db, err := sql.Open("mysql", mconf.FormatDSN())
+	if conf.DB.DialectHints.MaxAllowedPacket > 0 {
+		mconf.MaxAllowedPacket = conf.DB.DialectHints.MaxAllowedPacket
+	}
+	if len(conf.DB.DSNParams) > 0 || conf.DB.Charset != "" {
+		// the driver distinguishes its own parameters from server
+		// system variables only when parsing a DSN
+		params := url.Values{}
+		for k, v := range conf.DB.DSNParams {
+			params.Set(k, v)
+		}
+		if conf.DB.Charset != "" {
+			params.Set("charset", conf.DB.Charset)
+		}
+		// (parseTime is always set so the DSN already contains parameters)
+		var err error
+		mconf, err = mysql.ParseDSN(mconf.FormatDSN() + "&" + params.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("invalid dsnParams: %w", err)
+		}
+	}
+	if conf.DB.TLS.IsConfigured() {
+		tlsConf, err := tlsConfig(conf.DB.TLS)
+		if err != nil {
+			return nil, err
+		}
+		mconf.TLS = tlsConf
+	}
+	connector, err := mysql.NewConnector(mconf)
 	if err != nil {
 		return nil, err
 	}
-	groupedCorpusName := conf.Corpus
+	return sql.OpenDB(connector), nil
+}
+
+// tlsConfig creates a TLS configuration of a connection
+func tlsConfig(conf db.TLSConf) (*tls.Config, error) {
+	ans := &tls.Config{InsecureSkipVerify: conf.SkipVerify}
+	if conf.CACert != "" {
+		pem, err := os.ReadFile(conf.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to read CA certificate: no certificate found in %s", conf.CACert)
+		}
+		ans.RootCAs = pool
+	}
+	return ans, nil
+}
+
+// GroupedCorpusName returns a name used as a prefix for all
+// the tables of the corpus. For parallel corpora, this is
+// the name of the whole group (e.g. intercorp_v13).
+func GroupedCorpusName(conf *cnf.VTEConf) string {
 	if conf.ParallelCorpus != "" {
-		groupedCorpusName = conf.ParallelCorpus
+		return conf.ParallelCorpus
+	}
+	return conf.Corpus
+}
+
+// OpenReader opens a database for reading tables
+// of the configured (grouped) corpus
+// (using db.readHost in case it is configured)
+func OpenReader(conf *cnf.VTEConf) (*db.Reader, error) {
+	host := conf.DB.Host
+	if conf.DB.ReadHost != "" {
+		host = conf.DB.ReadHost
+	}
+	database, err := openDatabase(conf, host)
+	if err != nil {
+		return nil, err
+	}
+	return &db.Reader{
+		DB:           database,
+		TablePrefix:  GroupedCorpusName(conf) + "_",
+		OutputCompat: conf.OutputCompat,
+	}, nil
+}
+
+func NewWriter(conf *cnf.VTEConf) (*Writer, error) {
+	if err := conf.DB.DialectHints.Validate(); err != nil {
+		return nil, err
+	}
+	if err := conf.DB.ColcountsPartitioning.Validate(); err != nil {
+		return nil, err
+	}
+	if err := conf.DB.Retry.Validate(); err != nil {
+		return nil, err
+	}
+	protectPattern, err := protectTablesPattern(conf.DB, GroupedCorpusName(conf))
+	if err != nil {
+		return nil, err
+	}
+	db, err := openDatabase(conf, conf.DB.Host)
+	if err != nil {
+		return nil, err
 	}
+	groupedCorpusName := GroupedCorpusName(conf)
 	return &Writer{
 		database:          db,
 		dbName:            conf.DB.Name,
 		groupedCorpusName: groupedCorpusName,
+		protectPattern:    protectPattern,
+		outputCompat:      conf.OutputCompat,
+		backup:            conf.DB.Backup,
+		dialectHints:      conf.DB.DialectHints,
+		retry:             conf.DB.Retry,
+		optimize:          conf.DB.Optimize,
+		attrValues:        conf.AttrValues.Enabled,
+		attrPairs:         conf.AttrPairs.IsConfigured(),
+		corpusID:          conf.CorpusID(),
+		partitioning:      conf.DB.ColcountsPartitioning,
+		collations:        conf.DB.Collations,
+		helperViews:       conf.HelperViews,
+		primaryKey:        conf.DB.PrimaryKey,
+		history:           conf.DB.History.Enabled,
 		Structures:        conf.Structures,
 		IndexedCols:       conf.IndexedCols,
 		SelfJoinConf:      conf.SelfJoin,
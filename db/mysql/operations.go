@@ -26,9 +26,11 @@ import (
 	"github.com/czcorpus/vert-tagextract/v3/db"
 )
 
-const (
-	laTableSuffix = "_liveattrs_entry"
-)
+// laTableName returns a full name of the liveattrs table
+// for a provided output compatibility level (see db.OutputCompatV2)
+func laTableName(groupedCorpusName, outputCompat string) string {
+	return groupedCorpusName + "_" + db.TableName(db.LiveAttrsTable, outputCompat)
+}
 
 // dropExisting drops existing tables/views.
 // It is safe to call this even if one or more of these does not exist.
@@ -36,26 +38,65 @@ const (
 // which is able to group multipe (aligned) corpora together.E.g. 'intercorp_v13_cs'
 // and 'intercorp_v13_en' will likely groupedName 'intercorp_v13'. For single corpora,
 // the groupedCorpusName is the same as the original one.
-func dropExisting(database *sql.DB, groupedCorpusName string) error {
+func dropExisting(database execer, groupedCorpusName, laTable string) error {
 	log.Info().Msg("Attempting to drop possible existing tables and views...")
 	var err error
-	_, err = database.Exec("DROP TABLE IF EXISTS cache")
+	// note: older versions dropped a global 'cache' table here which
+	// may have belonged to another corpus
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.CacheTable))
 	if err != nil {
-		return fmt.Errorf("failed to drop table 'cache': %s", err)
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.CacheTable, err)
 	}
 	_, err = database.Exec(fmt.Sprintf("DROP VIEW IF EXISTS `%s_bibliography`", groupedCorpusName))
 	if err != nil {
 		return fmt.Errorf("failed to drop view `%s_bibliography`: %s", groupedCorpusName, err)
 	}
+	for _, v := range db.HelperViews() {
+		_, err = database.Exec(fmt.Sprintf("DROP VIEW IF EXISTS `%s_%s`", groupedCorpusName, v))
+		if err != nil {
+			return fmt.Errorf("failed to drop view `%s_%s`: %s", groupedCorpusName, v, err)
+		}
+	}
 	_, err = database.Exec(
-		fmt.Sprintf("DROP TABLE IF EXISTS `%s%s`", groupedCorpusName, laTableSuffix))
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s`", laTable))
 	if err != nil {
-		return fmt.Errorf("failed to drop table '%s%s': %s", groupedCorpusName, laTableSuffix, err)
+		return fmt.Errorf("failed to drop table '%s': %s", laTable, err)
 	}
 	_, err = database.Exec(fmt.Sprintf("DROP TABLE IF EXISTS `%s_colcounts`", groupedCorpusName))
 	if err != nil {
 		return fmt.Errorf("failed to drop table `%s_colcounts`: %s", groupedCorpusName, err)
 	}
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.RunMetadataTable))
+	if err != nil {
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.RunMetadataTable, err)
+	}
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.StatsTable))
+	if err != nil {
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.StatsTable, err)
+	}
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.ColumnInfoTable))
+	if err != nil {
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.ColumnInfoTable, err)
+	}
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.UDFeatsTable))
+	if err != nil {
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.UDFeatsTable, err)
+	}
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.AttrValuesTable))
+	if err != nil {
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.AttrValuesTable, err)
+	}
+	_, err = database.Exec(
+		fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", groupedCorpusName, db.AttrPairsTable))
+	if err != nil {
+		return fmt.Errorf("failed to drop table `%s_%s`: %s", groupedCorpusName, db.AttrPairsTable, err)
+	}
 	log.Info().Msg("...DONE")
 	return nil
 }
@@ -97,18 +138,18 @@ func generateAuxColDefs(hasSelfJoin bool) []string {
 	return ans
 }
 
-func createAuxIndices(database *sql.DB, groupedCorpusName string, cols []string) error {
+func createAuxIndices(database execer, groupedCorpusName, laTable string, cols []string) error {
 	var err error
 	for _, c := range cols {
 		_, err = database.Exec(
-			fmt.Sprintf("CREATE INDEX `%s_%s_idx` ON `%s%s`(%s)",
-				groupedCorpusName, c, groupedCorpusName, laTableSuffix, c))
+			fmt.Sprintf("CREATE INDEX `%s_%s_idx` ON `%s`(%s)",
+				groupedCorpusName, c, laTable, c))
 		if err != nil {
 			return err
 		}
 		log.Info().
 			Str("index", fmt.Sprintf(`%s_%s_idx`, groupedCorpusName, c)).
-			Str("table", groupedCorpusName+laTableSuffix).
+			Str("table", laTable).
 			Str("column", c).
 			Msg("Created custom database index")
 	}
@@ -132,59 +173,107 @@ func generateViewColDefs(cols []string, idAttr string) []string {
 
 // createBibView creates a database view needed
 // by liveattrs to fetch bibliography information.
-func createBibView(database *sql.DB, groupedCorpusName string, cols []string, idAttr string) error {
+func createBibView(database execer, groupedCorpusName, laTable string, cols []string, idAttr string) error {
 	colDefs := generateViewColDefs(cols, idAttr)
 	_, err := database.Exec(fmt.Sprintf(
-		"CREATE VIEW %s_bibliography AS SELECT %s FROM `%s%s`",
-		groupedCorpusName, joinArgs(colDefs), groupedCorpusName, laTableSuffix))
+		"CREATE VIEW %s_bibliography AS SELECT %s FROM `%s`",
+		groupedCorpusName, joinArgs(colDefs), laTable))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// createHelperViews creates helper views (see db.HelperViews)
+// joining data tables with corpus totals
+func createHelperViews(database execer, groupedCorpusName, laTable string, views []string) error {
+	for _, v := range views {
+		var query string
+		switch v {
+		case db.LiveAttrsStatsView:
+			query = fmt.Sprintf(
+				"CREATE VIEW `%s_%s` AS SELECT la.*, s.value AS corpus_tokens, "+
+					"la.poscount / s.value AS token_share "+
+					"FROM `%s` AS la LEFT JOIN `%s_%s` AS s ON s.corpus_id = la.corpus_id AND s.name = '%s'",
+				groupedCorpusName, v, laTable, groupedCorpusName, db.StatsTable, db.StatsTokens)
+		case db.ColcountsIPMView:
+			query = fmt.Sprintf(
+				"CREATE VIEW `%s_%s` AS SELECT c.*, c.count * 1000000.0 / s.value AS ipm "+
+					"FROM `%s_colcounts` AS c LEFT JOIN `%s_%s` AS s "+
+					"ON s.corpus_id = c.corpus_id AND s.name = '%s'",
+				groupedCorpusName, v, groupedCorpusName, groupedCorpusName, db.StatsTable, db.StatsTokens)
+		default:
+			return fmt.Errorf("unsupported helper view %s", v)
+		}
+		if _, err := database.Exec(query); err != nil {
+			return fmt.Errorf("failed to create view `%s_%s`: %s", groupedCorpusName, v, err)
+		}
+		log.Info().Str("view", groupedCorpusName+"_"+v).Msg("Created helper view")
+	}
+	return nil
+}
+
+// collationClause returns a CHARACTER SET ... COLLATE ... clause
+// for a column with a configured collation (or an empty string)
+func collationClause(col string, collations map[string]string) string {
+	coll, ok := collations[col]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" CHARACTER SET %s COLLATE %s", db.CollationCharset(coll), coll)
+}
+
 // createSchema creates all the required tables, views and indices
 func createSchema(
-	database *sql.DB,
+	database execer,
 	groupedCorpusName string,
+	laTable string,
 	structures map[string][]string,
 	indexedCols []string,
 	useSelfJoin bool,
 	countColumns db.VertColumns,
+	partitioning db.PartitioningConf,
+	corpusID string,
+	collations map[string]string,
+	primaryKey string,
 ) error {
 	log.Info().Msg("Attempting to create tables and views")
 
 	cols := generateColNames(structures)
 	colsDefs := make([]string, len(cols))
 	for i, col := range cols {
-		colsDefs[i] = fmt.Sprintf("%s TEXT", col)
+		colsDefs[i] = fmt.Sprintf("%s TEXT%s", col, collationClause(col, collations))
 	}
 	auxColDefs := generateAuxColDefs(useSelfJoin)
 	allCollsDefs := append(colsDefs, auxColDefs...)
+	idDef := "id INTEGER PRIMARY KEY auto_increment"
+	if db.IsDeterministicPrimaryKey(primaryKey) {
+		idDef = "id BIGINT PRIMARY KEY"
+	}
 	_, dbErr := database.Exec(
 		fmt.Sprintf(
-			"CREATE TABLE `%s%s` (id INTEGER PRIMARY KEY auto_increment, %s) ENGINE=InnoDB ROW_FORMAT=DYNAMIC",
-			groupedCorpusName,
-			laTableSuffix,
+			"CREATE TABLE `%s` (%s, %s) ENGINE=InnoDB ROW_FORMAT=DYNAMIC",
+			laTable,
+			idDef,
 			joinArgs(allCollsDefs),
 		),
 	)
 	if dbErr != nil {
 		return fmt.Errorf(
-			"failed to create table '%s%s': %s", groupedCorpusName, laTableSuffix, dbErr)
+			"failed to create table '%s': %s", laTable, dbErr)
 	}
 
 	if useSelfJoin {
 		_, dbErr = database.Exec(fmt.Sprintf(
-			"CREATE UNIQUE INDEX `%s%s_item_id_corpus_id_idx` ON `%s%s`(item_id, corpus_id)",
-			groupedCorpusName, laTableSuffix, groupedCorpusName, laTableSuffix))
+			"CREATE UNIQUE INDEX `%s_item_id_corpus_id_idx` ON `%s`(item_id, corpus_id)",
+			laTable, laTable))
 		if dbErr != nil {
 			return fmt.Errorf(
-				"failed to create index `%s%s_item_id_corpus_id_idx` on `%s%s`(item_id, corpus_id): %s",
-				groupedCorpusName, laTableSuffix, groupedCorpusName, laTableSuffix, dbErr)
+				"failed to create index `%s_item_id_corpus_id_idx` on `%s`(item_id, corpus_id): %s",
+				laTable, laTable, dbErr)
 		}
 	}
-	dbErr = createAuxIndices(database, groupedCorpusName, indexedCols)
+	dbErr = createAuxIndices(database, groupedCorpusName, laTable, indexedCols)
 	if dbErr != nil {
 		return fmt.Errorf("failed to create a custom index: %s", dbErr)
 	}
@@ -192,11 +281,18 @@ func createSchema(
 	if len(countColumns) > 0 {
 		colDefs := db.GenerateColCountNames(countColumns)
 		for i, c := range colDefs {
-			colDefs[i] = c + fmt.Sprintf(" VARCHAR(%d) COLLATE utf8_bin", db.DfltColcountVarcharSize)
+			coll := collationClause(c, collations)
+			if coll == "" {
+				coll = " COLLATE utf8_bin"
+			}
+			colDefs[i] = c + fmt.Sprintf(" VARCHAR(%d)%s", db.DfltColcountVarcharSize, coll)
 		}
+		// corpora of a grouped database may share n-grams (and thus hash_id);
+		// the partitioning column must be part of the primary key too
 		_, dbErr = database.Exec(fmt.Sprintf(
-			"CREATE TABLE %s_colcounts (%s, hash_id VARCHAR(40), corpus_id VARCHAR(%d), count INTEGER, arf INTEGER, PRIMARY KEY(hash_id))",
-			groupedCorpusName, strings.Join(colDefs, ", "), db.DfltColcountVarcharSize))
+			"CREATE TABLE %s_colcounts (%s, hash_id VARCHAR(40), corpus_id VARCHAR(%d), count INTEGER, arf INTEGER, PRIMARY KEY(hash_id, corpus_id))%s",
+			groupedCorpusName, strings.Join(colDefs, ", "), db.DfltColcountVarcharSize,
+			partitionClause(partitioning, corpusID)))
 		if dbErr != nil {
 			return fmt.Errorf("failed to create table '%s_colcounts': %s", groupedCorpusName, dbErr)
 		}
@@ -209,6 +305,304 @@ func createSchema(
 				groupedCorpusName, dbErr)
 		}
 	}
+	if len(countColumns.ExplodedUDFeats()) > 0 {
+		_, dbErr = database.Exec(fmt.Sprintf(
+			"CREATE TABLE `%s_%s` (corpus_id VARCHAR(%d), col INTEGER, name VARCHAR(63), "+
+				"value VARCHAR(%d) COLLATE utf8_bin, count INTEGER)",
+			groupedCorpusName, db.UDFeatsTable, db.DfltColcountVarcharSize, db.DfltColcountVarcharSize))
+		if dbErr != nil {
+			return fmt.Errorf("failed to create table '%s_%s': %s", groupedCorpusName, db.UDFeatsTable, dbErr)
+		}
+		_, dbErr = database.Exec(fmt.Sprintf(
+			"CREATE INDEX %s_%s_corpus_id_idx ON `%s_%s`(corpus_id)",
+			groupedCorpusName, db.UDFeatsTable, groupedCorpusName, db.UDFeatsTable))
+		if dbErr != nil {
+			return fmt.Errorf(
+				"failed to create index on %s_%s(corpus_id): %s", groupedCorpusName, db.UDFeatsTable, dbErr)
+		}
+	}
 	log.Info().Msg("DONE")
 	return nil
 }
+
+// createCacheTable creates the liveattrs cache table
+// in case it does not exist yet
+func createCacheTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (`key` VARCHAR(255) PRIMARY KEY, value LONGTEXT)",
+		groupedCorpusName, db.CacheTable))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.CacheTable, err)
+	}
+	return nil
+}
+
+// clearCache removes all the cached liveattrs data
+// as they are not valid once new data are added
+func clearCache(tx *sql.Tx, groupedCorpusName string) error {
+	_, err := tx.Exec(fmt.Sprintf("DELETE FROM `%s_%s`", groupedCorpusName, db.CacheTable))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to clear table '%s_%s': %s", groupedCorpusName, db.CacheTable, err)
+	}
+	return nil
+}
+
+// createRunMetadataTable creates a table for run metadata
+// in case it does not exist yet (which may be the case
+// for databases created by older versions)
+func createRunMetadataTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (corpus_id VARCHAR(63), name VARCHAR(63), value TEXT, "+
+			"PRIMARY KEY(corpus_id, name))",
+		groupedCorpusName, db.RunMetadataTable))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.RunMetadataTable, err)
+	}
+	return nil
+}
+
+// setRunMetadata inserts or replaces run metadata for a corpus
+func setRunMetadata(tx *sql.Tx, groupedCorpusName, corpusID string, values map[string]string) error {
+	for k, v := range values {
+		_, err := tx.Exec(
+			fmt.Sprintf(
+				"REPLACE INTO `%s_%s` (corpus_id, name, value) VALUES (?, ?, ?)",
+				groupedCorpusName, db.RunMetadataTable),
+			corpusID, k, v)
+		if err != nil {
+			return fmt.Errorf("failed to set run metadata: %s", err)
+		}
+	}
+	return nil
+}
+
+// createStatsTable creates a table for aggregate totals
+// in case it does not exist yet
+func createStatsTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (corpus_id VARCHAR(63), name VARCHAR(127), value BIGINT, "+
+			"PRIMARY KEY(corpus_id, name))",
+		groupedCorpusName, db.StatsTable))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.StatsTable, err)
+	}
+	return nil
+}
+
+// setStats inserts or replaces aggregate totals for a corpus
+func setStats(tx *sql.Tx, groupedCorpusName, corpusID string, values map[string]int) error {
+	for k, v := range values {
+		_, err := tx.Exec(
+			fmt.Sprintf(
+				"REPLACE INTO `%s_%s` (corpus_id, name, value) VALUES (?, ?, ?)",
+				groupedCorpusName, db.StatsTable),
+			corpusID, k, v)
+		if err != nil {
+			return fmt.Errorf("failed to set stats: %s", err)
+		}
+	}
+	return nil
+}
+
+// createColumnInfoTable creates a table describing columns
+// in case it does not exist yet
+func createColumnInfoTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (corpus_id VARCHAR(63), table_name VARCHAR(63), "+
+			"column_name VARCHAR(127), source VARCHAR(63), structure VARCHAR(63), attr VARCHAR(127), "+
+			"modifiers TEXT, description TEXT, PRIMARY KEY(corpus_id, table_name, column_name))",
+		groupedCorpusName, db.ColumnInfoTable))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.ColumnInfoTable, err)
+	}
+	return nil
+}
+
+// createHistoryTable creates a table of liveattrs changes (see db.HistoryConf).
+// The table is neither dropped by dropExisting nor backed up as it spans more runs.
+func createHistoryTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (id BIGINT PRIMARY KEY auto_increment, run_id VARCHAR(63), "+
+			"corpus_id VARCHAR(63), op VARCHAR(16), changed_at DATETIME, data LONGTEXT, "+
+			"INDEX `%s_%s_corpus_id_changed_at_idx` (corpus_id, changed_at))",
+		groupedCorpusName, db.LiveAttrsHistoryTable, groupedCorpusName, db.LiveAttrsHistoryTable))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.LiveAttrsHistoryTable, err)
+	}
+	return nil
+}
+
+// setColumnInfo replaces descriptions of columns for a corpus
+func setColumnInfo(tx *sql.Tx, groupedCorpusName, corpusID string, values []db.ColumnInfo) error {
+	_, err := tx.Exec(
+		fmt.Sprintf("DELETE FROM `%s_%s` WHERE corpus_id = ?", groupedCorpusName, db.ColumnInfoTable),
+		corpusID)
+	if err != nil {
+		return fmt.Errorf("failed to set column info: %s", err)
+	}
+	for _, v := range values {
+		_, err := tx.Exec(
+			fmt.Sprintf(
+				"INSERT INTO `%s_%s` (corpus_id, table_name, column_name, source, structure, attr, "+
+					"modifiers, description) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+				groupedCorpusName, db.ColumnInfoTable),
+			corpusID, v.Table, v.Column, v.Source, v.Structure, v.Attr,
+			strings.Join(v.Modifiers, ","), v.Description)
+		if err != nil {
+			return fmt.Errorf("failed to set column info: %s", err)
+		}
+	}
+	return nil
+}
+
+// createAttrValuesTable creates a table of structural attribute
+// values in case it does not exist yet
+func createAttrValuesTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (corpus_id VARCHAR(63), attr VARCHAR(127), "+
+			"value VARCHAR(%d) COLLATE utf8_bin, num_atoms BIGINT, num_tokens BIGINT, "+
+			"PRIMARY KEY(corpus_id, attr, value))",
+		groupedCorpusName, db.AttrValuesTable, db.DfltColcountVarcharSize))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.AttrValuesTable, err)
+	}
+	return nil
+}
+
+// addAttrValues adds numbers of atoms and tokens
+// of attribute values to the stored ones
+func addAttrValues(tx *sql.Tx, groupedCorpusName, corpusID string, values []db.AttrValueCount) error {
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT INTO `%s_%s` (corpus_id, attr, value, num_atoms, num_tokens) VALUES (?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE "+
+			"num_atoms = num_atoms + VALUES(num_atoms), num_tokens = num_tokens + VALUES(num_tokens)",
+		groupedCorpusName, db.AttrValuesTable))
+	if err != nil {
+		return fmt.Errorf("failed to add attribute values: %s", err)
+	}
+	defer stmt.Close()
+	for _, v := range values {
+		if _, err := stmt.Exec(corpusID, v.Attr, v.Value, v.NumAtoms, v.NumTokens); err != nil {
+			return fmt.Errorf("failed to add attribute values: %s", err)
+		}
+	}
+	return nil
+}
+
+// createAttrPairsTable creates a table of structural attribute
+// value pairs in case it does not exist yet
+func createAttrPairsTable(database execer, groupedCorpusName string) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s_%s` (corpus_id VARCHAR(63), "+
+			"attr1 VARCHAR(127), value1 VARCHAR(%d) COLLATE utf8_bin, "+
+			"attr2 VARCHAR(127), value2 VARCHAR(%d) COLLATE utf8_bin, "+
+			"num_atoms BIGINT, num_tokens BIGINT, "+
+			"PRIMARY KEY(corpus_id, attr1, value1, attr2, value2))",
+		groupedCorpusName, db.AttrPairsTable, db.DfltColcountVarcharSize, db.DfltColcountVarcharSize))
+	if err != nil {
+		return fmt.Errorf(
+			"failed to create table '%s_%s': %s", groupedCorpusName, db.AttrPairsTable, err)
+	}
+	return nil
+}
+
+// addAttrPairs adds numbers of atoms and tokens
+// of attribute value pairs to the stored ones
+func addAttrPairs(tx *sql.Tx, groupedCorpusName, corpusID string, values []db.AttrPairCount) error {
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT INTO `%s_%s` (corpus_id, attr1, value1, attr2, value2, num_atoms, num_tokens) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE "+
+			"num_atoms = num_atoms + VALUES(num_atoms), num_tokens = num_tokens + VALUES(num_tokens)",
+		groupedCorpusName, db.AttrPairsTable))
+	if err != nil {
+		return fmt.Errorf("failed to add attribute pairs: %s", err)
+	}
+	defer stmt.Close()
+	for _, v := range values {
+		_, err := stmt.Exec(corpusID, v.Attr1, v.Value1, v.Attr2, v.Value2, v.NumAtoms, v.NumTokens)
+		if err != nil {
+			return fmt.Errorf("failed to add attribute pairs: %s", err)
+		}
+	}
+	return nil
+}
+
+// readSchema reads declared column types of provided tables (referred by
+// their logical names, i.e. without the groupedCorpusName prefix).
+// Tables which do not exist are not present in the result.
+func readSchema(
+	database *sql.DB,
+	dbName string,
+	groupedCorpusName string,
+	outputCompat string,
+	tables []db.TableSchema,
+) (map[string]map[string]string, error) {
+	ans := make(map[string]map[string]string)
+	for _, table := range tables {
+		rows, err := database.Query(
+			"SELECT COLUMN_NAME, COLUMN_TYPE FROM information_schema.COLUMNS "+
+				"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+			dbName, groupedCorpusName+"_"+db.TableName(table.Name, outputCompat),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema of %s: %w", table.Name, err)
+		}
+		cols := make(map[string]string)
+		for rows.Next() {
+			var name, colType string
+			if err := rows.Scan(&name, &colType); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to read schema of %s: %w", table.Name, err)
+			}
+			cols[name] = colType
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema of %s: %w", table.Name, err)
+		}
+		if len(cols) > 0 {
+			ans[table.Name] = cols
+		}
+	}
+	return ans, nil
+}
+
+// MigrationSQL returns statements applying a schema change (see db.SchemaChanges).
+// The outputCompat argument specifies the target version. Changes which cannot
+// (or need not) be applied by SQL are returned as comments.
+func MigrationSQL(
+	change db.SchemaChange,
+	groupedCorpusName string,
+	outputCompat string,
+	bibView db.BibViewConf,
+) []string {
+	switch change.Kind {
+	case db.SchemaChangeRenameTable:
+		return []string{
+			fmt.Sprintf(
+				"RENAME TABLE `%s_%s` TO `%s_%s`;",
+				groupedCorpusName, change.Table, groupedCorpusName, change.NewName),
+		}
+	case db.SchemaChangeRecreateView:
+		if !bibView.IsConfigured() {
+			return []string{}
+		}
+		return []string{
+			fmt.Sprintf("DROP VIEW IF EXISTS `%s_%s`;", groupedCorpusName, change.Table),
+			fmt.Sprintf(
+				"CREATE VIEW `%s_%s` AS SELECT %s FROM `%s`;",
+				groupedCorpusName, change.Table,
+				joinArgs(generateViewColDefs(bibView.Cols, bibView.IDAttr)),
+				laTableName(groupedCorpusName, outputCompat)),
+		}
+	}
+	return []string{"-- " + change.String()}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/rs/zerolog/log"
+)
+
+// corpusPartitionName returns a name of a colcounts partition
+// containing data of a corpus (see db.PartitionByCorpus)
+func corpusPartitionName(corpusID string) string {
+	return "p_" + corpusID
+}
+
+// corpusPartitionDef creates a definition of a partition
+// containing data of a corpus (see db.PartitionByCorpus)
+func corpusPartitionDef(corpusID string) string {
+	return fmt.Sprintf(
+		"PARTITION `%s` VALUES IN ('%s')",
+		corpusPartitionName(corpusID), strings.ReplaceAll(corpusID, "'", "''"))
+}
+
+// partitionClause creates a PARTITION BY clause of CREATE TABLE
+// (an empty string in case no partitioning is configured)
+func partitionClause(conf db.PartitioningConf, corpusID string) string {
+	switch conf.By {
+	case db.PartitionByCorpus:
+		return fmt.Sprintf(" PARTITION BY LIST COLUMNS(corpus_id) (%s)", corpusPartitionDef(corpusID))
+	case db.PartitionByCorpusHash:
+		return fmt.Sprintf(" PARTITION BY KEY(corpus_id) PARTITIONS %d", conf.GetPartitions())
+	}
+	return ""
+}
+
+// tablePartitions returns names of existing partitions
+// of a table (empty for non-partitioned tables)
+func tablePartitions(database *sql.DB, dbName, table string) ([]string, error) {
+	rows, err := database.Query(
+		"SELECT PARTITION_NAME FROM information_schema.PARTITIONS "+
+			"WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL",
+		dbName, table,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list partitions of %s: %w", table, err)
+	}
+	defer rows.Close()
+	ans := make([]string, 0, 10)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list partitions of %s: %w", table, err)
+		}
+		ans = append(ans, name)
+	}
+	return ans, rows.Err()
+}
+
+// ensureCorpusPartition adds a colcounts partition for a corpus
+// in case it does not exist yet (see db.PartitionByCorpus).
+// The existing argument contains names of existing partitions.
+func ensureCorpusPartition(database execer, table, corpusID string, existing []string) error {
+	if len(existing) == 0 {
+		return fmt.Errorf(
+			"table %s is not partitioned (partitioning can be changed only by a 'create' run)", table)
+	}
+	name := corpusPartitionName(corpusID)
+	for _, p := range existing {
+		if p == name {
+			return nil
+		}
+	}
+	_, err := database.Exec(
+		fmt.Sprintf("ALTER TABLE `%s` ADD PARTITION (%s)", table, corpusPartitionDef(corpusID)))
+	if err != nil {
+		return fmt.Errorf("failed to add partition %s to %s: %w", name, table, err)
+	}
+	log.Info().Str("table", table).Str("partition", name).Msg("Added colcounts partition")
+	return nil
+}
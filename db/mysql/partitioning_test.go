@@ -0,0 +1,57 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionClause(t *testing.T) {
+	assert.Equal(t, "", partitionClause(db.PartitioningConf{}, "intercorp_v13_cs"))
+	assert.Equal(
+		t,
+		" PARTITION BY LIST COLUMNS(corpus_id) (PARTITION `p_intercorp_v13_cs` VALUES IN ('intercorp_v13_cs'))",
+		partitionClause(db.PartitioningConf{By: db.PartitionByCorpus}, "intercorp_v13_cs"),
+	)
+	assert.Equal(
+		t,
+		" PARTITION BY KEY(corpus_id) PARTITIONS 8",
+		partitionClause(db.PartitioningConf{By: db.PartitionByCorpusHash}, "intercorp_v13_cs"),
+	)
+}
+
+func TestEnsureCorpusPartition(t *testing.T) {
+	rec := &recordingExecer{}
+	ex := &protectedExecer{database: rec, pattern: "intercorp_v13_%"}
+	existing := []string{"p_intercorp_v13_cs"}
+	assert.NoError(t, ensureCorpusPartition(ex, "intercorp_v13_colcounts", "intercorp_v13_cs", existing))
+	assert.Empty(t, rec.queries)
+	assert.NoError(t, ensureCorpusPartition(ex, "intercorp_v13_colcounts", "intercorp_v13_en", existing))
+	assert.Equal(
+		t,
+		[]string{
+			"ALTER TABLE `intercorp_v13_colcounts` ADD PARTITION " +
+				"(PARTITION `p_intercorp_v13_en` VALUES IN ('intercorp_v13_en'))",
+		},
+		rec.queries,
+	)
+	assert.ErrorContains(
+		t, ensureCorpusPartition(ex, "intercorp_v13_colcounts", "intercorp_v13_en", nil), "not partitioned")
+}
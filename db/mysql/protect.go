@@ -0,0 +1,167 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+var (
+	// ErrProtectedTable is returned in case a DDL statement
+	// refers to a table not matching the protection pattern
+	ErrProtectedTable = errors.New("statement refers to a protected table")
+
+	ddlStatementRegexp = regexp.MustCompile(
+		"(?is)^\\s*(CREATE|DROP)\\s+(?:UNIQUE\\s+)?(TABLE|VIEW|INDEX)\\s+" +
+			"(?:IF\\s+(?:NOT\\s+)?EXISTS\\s+)?(`[^`]+`|[\\w$]+)(?:\\s+ON\\s+(`[^`]+`|[\\w$]+))?")
+
+	renameStatementRegexp = regexp.MustCompile(`(?is)^\s*RENAME\s+TABLE\s+(.+)$`)
+
+	addPartitionRegexp = regexp.MustCompile(
+		"(?is)^\\s*ALTER\\s+TABLE\\s+(`[^`]+`|[\\w$]+)\\s+ADD\\s+PARTITION\\s*\\(")
+
+	renamePairRegexp = regexp.MustCompile("(?is)^\\s*(`[^`]+`|[\\w$]+)\\s+TO\\s+(`[^`]+`|[\\w$]+)\\s*$")
+
+	ddlKeywordRegexp = regexp.MustCompile(`(?is)^\s*(CREATE|DROP|ALTER|RENAME|TRUNCATE)\b`)
+)
+
+// execer is the part of *sql.DB used to modify
+// the database schema
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// protectedExecer checks all the DDL statements against
+// a table name pattern before passing them to the database.
+type protectedExecer struct {
+	database execer
+	pattern  string
+}
+
+func (p *protectedExecer) Exec(query string, args ...any) (sql.Result, error) {
+	if err := checkDDLStatement(query, p.pattern); err != nil {
+		return nil, err
+	}
+	return p.database.Exec(query, args...)
+}
+
+// matchTablePattern tests a name against a pattern where
+// '%' matches any (possibly empty) string. All the other
+// characters (including '_') are matched literally.
+func matchTablePattern(name, pattern string) bool {
+	parts := strings.Split(pattern, "%")
+	if len(parts) == 1 {
+		return name == pattern
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		idx := strings.Index(name, p)
+		if idx < 0 {
+			return false
+		}
+		name = name[idx+len(p):]
+	}
+	return strings.HasSuffix(name, last)
+}
+
+// protectTablesPattern returns a pattern schema modifications are checked
+// against (an empty string if the protection is disabled). An explicitly
+// configured pattern is preferred over the one derived from the corpus name.
+func protectTablesPattern(conf db.Conf, groupedCorpusName string) (string, error) {
+	if conf.ProtectTablesPattern != "" {
+		if strings.HasPrefix(conf.ProtectTablesPattern, "%") {
+			return "", fmt.Errorf(
+				"invalid protectTablesPattern '%s' - the pattern must start with a table name prefix",
+				conf.ProtectTablesPattern)
+		}
+		return conf.ProtectTablesPattern, nil
+	}
+	if conf.ProtectTables {
+		return groupedCorpusName + "_%", nil
+	}
+	return "", nil
+}
+
+// checkRenameStatement verifies that all the source and target
+// tables of a RENAME TABLE statement match the pattern
+func checkRenameStatement(pairs, pattern string) error {
+	for _, pair := range strings.Split(pairs, ",") {
+		srch := renamePairRegexp.FindStringSubmatch(pair)
+		if srch == nil {
+			return fmt.Errorf("%w: unsupported RENAME TABLE clause %s", ErrProtectedTable, pair)
+		}
+		for _, name := range srch[1:] {
+			name = strings.Trim(name, "`")
+			if !matchTablePattern(name, pattern) {
+				return fmt.Errorf(
+					"%w: RENAME TABLE `%s` does not match pattern '%s'", ErrProtectedTable, name, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// checkDDLStatement verifies that a CREATE/DROP/RENAME TABLE
+// (or ALTER TABLE ... ADD PARTITION) statement refers only to tables,
+// views and indices matching the pattern. Other DDL statements (including
+// multi-table DROP) are refused as they cannot be verified. Non-DDL statements
+// are always accepted.
+func checkDDLStatement(query, pattern string) error {
+	if srch := renameStatementRegexp.FindStringSubmatch(query); srch != nil {
+		return checkRenameStatement(srch[1], pattern)
+	}
+	if srch := addPartitionRegexp.FindStringSubmatch(query); srch != nil {
+		name := strings.Trim(srch[1], "`")
+		if !matchTablePattern(name, pattern) {
+			return fmt.Errorf(
+				"%w: ALTER TABLE `%s` does not match pattern '%s'", ErrProtectedTable, name, pattern)
+		}
+		return nil
+	}
+	srch := ddlStatementRegexp.FindStringSubmatch(query)
+	if srch == nil {
+		if ddlKeywordRegexp.MatchString(query) {
+			return fmt.Errorf("%w: unsupported statement %s", ErrProtectedTable, query)
+		}
+		return nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(query[len(srch[0]):]), ",") {
+		return fmt.Errorf("%w: multi-table statement %s", ErrProtectedTable, query)
+	}
+	for _, name := range srch[3:] {
+		if name == "" {
+			continue
+		}
+		name = strings.Trim(name, "`")
+		if !matchTablePattern(name, pattern) {
+			return fmt.Errorf(
+				"%w: %s %s `%s` does not match pattern '%s'",
+				ErrProtectedTable, strings.ToUpper(srch[1]), strings.ToUpper(srch[2]), name, pattern)
+		}
+	}
+	return nil
+}
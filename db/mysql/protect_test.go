@@ -0,0 +1,130 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingExecer struct {
+	queries []string
+}
+
+func (r *recordingExecer) Exec(query string, args ...any) (sql.Result, error) {
+	r.queries = append(r.queries, query)
+	return nil, nil
+}
+
+func TestMatchTablePattern(t *testing.T) {
+	assert.True(t, matchTablePattern("intercorp_v13_colcounts", "intercorp_v13_%"))
+	assert.True(t, matchTablePattern("intercorp_v13_", "intercorp_v13_%"))
+	assert.False(t, matchTablePattern("intercorp_v13", "intercorp_v13_%"))
+	assert.False(t, matchTablePattern("intercorp_v13xcolcounts", "intercorp_v13_%"))
+	assert.False(t, matchTablePattern("syn2020_colcounts", "intercorp_v13_%"))
+	assert.True(t, matchTablePattern("a_x_b", "a_%_b"))
+	assert.False(t, matchTablePattern("a_x_c", "a_%_b"))
+}
+
+func TestCheckDDLStatement(t *testing.T) {
+	p := "susanne_%"
+	assert.NoError(t, checkDDLStatement("DROP TABLE IF EXISTS `susanne_colcounts`", p))
+	assert.NoError(t, checkDDLStatement("CREATE TABLE IF NOT EXISTS `susanne_cache` (x TEXT)", p))
+	assert.NoError(t, checkDDLStatement("CREATE VIEW susanne_bibliography AS SELECT 1", p))
+	assert.NoError(t, checkDDLStatement(
+		"CREATE INDEX susanne_colcounts_corpus_id_idx ON susanne_colcounts(corpus_id)", p))
+	assert.NoError(t, checkDDLStatement("SELECT * FROM cache", p))
+	assert.ErrorIs(t, checkDDLStatement("DROP TABLE IF EXISTS `cache`", p), ErrProtectedTable)
+	assert.ErrorIs(t, checkDDLStatement("drop view syn_bibliography", p), ErrProtectedTable)
+	assert.ErrorIs(t, checkDDLStatement(
+		"CREATE UNIQUE INDEX `susanne_idx` ON `syn_liveattrs_entry`(item_id)", p), ErrProtectedTable)
+	assert.ErrorIs(t, checkDDLStatement("TRUNCATE TABLE susanne_cache", p), ErrProtectedTable)
+	assert.NoError(t, checkDDLStatement(
+		"ALTER TABLE `susanne_colcounts` ADD PARTITION (PARTITION `p_x` VALUES IN ('x'))", p))
+	assert.ErrorIs(t, checkDDLStatement(
+		"ALTER TABLE `syn_colcounts` ADD PARTITION (PARTITION `p_x` VALUES IN ('x'))", p), ErrProtectedTable)
+	assert.ErrorIs(t, checkDDLStatement("ALTER TABLE susanne_colcounts DROP COLUMN arf", p), ErrProtectedTable)
+	assert.ErrorIs(t, checkDDLStatement("DROP TABLE susanne_cache, syn_cache", p), ErrProtectedTable)
+	assert.ErrorIs(t, checkDDLStatement("DROP TABLE IF EXISTS `susanne_cache` , `syn_cache`", p), ErrProtectedTable)
+	assert.NoError(t, checkDDLStatement("DROP VIEW IF EXISTS `susanne_bibliography`;", p))
+}
+
+func TestProtectTablesPattern(t *testing.T) {
+	pattern, err := protectTablesPattern(db.Conf{}, "intercorp_v13")
+	assert.NoError(t, err)
+	assert.Equal(t, "", pattern)
+	pattern, err = protectTablesPattern(db.Conf{ProtectTables: true}, "intercorp_v13")
+	assert.NoError(t, err)
+	assert.Equal(t, "intercorp_v13_%", pattern)
+	pattern, err = protectTablesPattern(db.Conf{ProtectTablesPattern: "intercorp_%"}, "intercorp_v13")
+	assert.NoError(t, err)
+	assert.Equal(t, "intercorp_%", pattern)
+	pattern, err = protectTablesPattern(
+		db.Conf{ProtectTables: true, ProtectTablesPattern: "intercorp_v13_%"}, "intercorp")
+	assert.NoError(t, err)
+	assert.Equal(t, "intercorp_v13_%", pattern)
+	_, err = protectTablesPattern(db.Conf{ProtectTablesPattern: "%_colcounts"}, "intercorp_v13")
+	assert.Error(t, err)
+}
+
+func TestProtectedSchemaOperations(t *testing.T) {
+	rec := &recordingExecer{}
+	ex := &protectedExecer{database: rec, pattern: "susanne_%"}
+	countCols := db.VertColumns{{Idx: 0}, {Idx: 1, UDFeats: db.UDFeatsExplode}}
+	assert.NoError(t, dropExisting(ex, "susanne", "susanne_liveattrs_entry"))
+	assert.NoError(t, createSchema(
+		ex, "susanne", "susanne_liveattrs_entry", map[string][]string{"doc": {"id"}}, []string{"doc_id"}, true, countCols,
+		db.PartitioningConf{By: db.PartitionByCorpus}, "susanne", nil, ""))
+	assert.NoError(t, createBibView(ex, "susanne", "susanne_liveattrs_entry", []string{"doc_id"}, "doc_id"))
+	assert.NoError(t, createCacheTable(ex, "susanne"))
+	assert.NoError(t, createRunMetadataTable(ex, "susanne"))
+	assert.NoError(t, createStatsTable(ex, "susanne"))
+	assert.Greater(t, len(rec.queries), 10)
+
+	ex.pattern = "syn_%"
+	assert.ErrorContains(t, dropExisting(ex, "susanne", "susanne_liveattrs_entry"), "does not match pattern")
+}
+
+func TestBackupTables(t *testing.T) {
+	rec := &recordingExecer{}
+	ex := &protectedExecer{database: rec, pattern: "susanne_%"}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing := []string{
+		"susanne_liveattrs_entry",
+		"susanne_colcounts",
+		"susanne_stats",
+		"susanne_colcounts_bak_20260201120000",
+		"susanne_v2_colcounts_bak_20250101120000",
+	}
+	assert.NoError(t, backupTables(ex, existing, "susanne", "susanne_liveattrs_entry", 1, now))
+	assert.Equal(
+		t,
+		[]string{
+			"RENAME TABLE `susanne_liveattrs_entry` TO `susanne_liveattrs_entry_bak_20260301120000`, " +
+				"`susanne_colcounts` TO `susanne_colcounts_bak_20260301120000`, " +
+				"`susanne_stats` TO `susanne_stats_bak_20260301120000`",
+			"DROP TABLE IF EXISTS `susanne_colcounts_bak_20260201120000`",
+		},
+		rec.queries,
+	)
+	assert.ErrorIs(
+		t, checkDDLStatement("RENAME TABLE susanne_stats TO syn_stats", "susanne_%"), ErrProtectedTable)
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/rs/zerolog/log"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+const (
+	errOutOfMemory     = 1037
+	errOutOfSortMemory = 1038
+	errOutOfResources  = 1041
+	errLockWaitTimeout = 1205
+	errDeadlock        = 1213
+)
+
+// isTransientErr tells whether an error may disappear once
+// the operation is repeated (a lost connection, a deadlock,
+// a lock wait timeout). This applies only to operations outside
+// of a transaction as MySQL rolls back the whole transaction in
+// such cases.
+func isTransientErr(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == errLockWaitTimeout || myErr.Number == errDeadlock
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.As(err, &netErr)
+}
+
+// isTransientStmtErr tells whether a statement executed within
+// a transaction can be repeated. This is true only for a lock
+// wait timeout which rolls back just the statement (with the
+// default innodb_rollback_on_timeout=OFF).
+func isTransientStmtErr(err error) bool {
+	var myErr *mysql.MySQLError
+	return errors.As(err, &myErr) && myErr.Number == errLockWaitTimeout
+}
+
+// IsOutOfMemoryErr tells whether an error has been caused
+// by the MySQL server running out of memory
+func IsOutOfMemoryErr(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	switch myErr.Number {
+	case errOutOfMemory, errOutOfSortMemory, errOutOfResources:
+		return true
+	}
+	return false
+}
+
+// withRetry calls fn until it succeeds, fails with a non-transient
+// error (as decided by transient) or the max. number of attempts
+// is reached
+func withRetry(conf db.RetryConf, transient func(error) bool, fn func() error) error {
+	return withRetryContext(context.Background(), conf, transient, fn)
+}
+
+// withRetryContext is the same as withRetry but it stops waiting
+// for a next attempt once ctx is cancelled
+func withRetryContext(
+	ctx context.Context,
+	conf db.RetryConf,
+	transient func(error) bool,
+	fn func() error,
+) error {
+	var err error
+	for attempt := 1; attempt <= conf.GetMaxAttempts(); attempt++ {
+		err = fn()
+		if err == nil || !transient(err) || attempt == conf.GetMaxAttempts() {
+			break
+		}
+		delay := conf.Delay(attempt)
+		log.Warn().
+			Err(err).
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Msg("transient database error, going to retry")
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// retryingExecer repeats statements executed
+// outside a transaction on transient errors
+type retryingExecer struct {
+	database execer
+	retry    db.RetryConf
+}
+
+func (r *retryingExecer) Exec(query string, args ...any) (sql.Result, error) {
+	var ans sql.Result
+	err := withRetry(r.retry, isTransientErr, func() error {
+		var err error
+		ans, err = r.database.Exec(query, args...)
+		return err
+	})
+	return ans, err
+}
+
+// retryingInsert repeats inserts failed
+// due to a lock wait timeout
+type retryingInsert struct {
+	ins   db.InsertOperation
+	retry db.RetryConf
+}
+
+func (r *retryingInsert) Exec(values ...any) error {
+	return r.ExecContext(context.Background(), values...)
+}
+
+func (r *retryingInsert) ExecContext(ctx context.Context, values ...any) error {
+	return withRetryContext(ctx, r.retry, isTransientStmtErr, func() error {
+		return r.ins.ExecContext(ctx, values...)
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransientErr(t *testing.T) {
+	assert.True(t, isTransientErr(&mysql.MySQLError{Number: errDeadlock}))
+	assert.True(t, isTransientErr(fmt.Errorf("failed: %w", driver.ErrBadConn)))
+	assert.False(t, isTransientErr(&mysql.MySQLError{Number: 1062}))
+	assert.True(t, isTransientStmtErr(&mysql.MySQLError{Number: errLockWaitTimeout}))
+	assert.False(t, isTransientStmtErr(&mysql.MySQLError{Number: errDeadlock}))
+}
+
+func TestWithRetry(t *testing.T) {
+	conf := db.RetryConf{MaxAttempts: 3, DelayMs: 1}
+	var calls int
+	err := withRetry(conf, isTransientErr, func() error {
+		calls++
+		if calls < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = withRetry(conf, isTransientErr, func() error {
+		calls++
+		return &mysql.MySQLError{Number: 1062}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = withRetry(conf, isTransientErr, func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWithRetryContextCancelled(t *testing.T) {
+	conf := db.RetryConf{MaxAttempts: 3, DelayMs: 60000}
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err := withRetryContext(ctx, conf, isTransientErr, func() error {
+		calls++
+		cancel()
+		return driver.ErrBadConn
+	})
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
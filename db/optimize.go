@@ -0,0 +1,100 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import "fmt"
+
+// OptimizeAll can be used instead of a table name
+// to refer to all the optimizable tables
+const OptimizeAll = "*"
+
+// optimizableTables contains logical names of tables
+// which can be optimized after data are loaded
+var optimizableTables = []string{
+	LiveAttrsTable,
+	ColCountsTable,
+	UDFeatsTable,
+	AttrValuesTable,
+	AttrPairsTable,
+	RunMetadataTable,
+	StatsTable,
+	ColumnInfoTable,
+	CacheTable,
+}
+
+// OptimizeConf configures a step run once data are committed
+// so delivered databases have fresh statistics and compact files.
+// Tables are specified by their logical names (e.g. "colcounts")
+// or by OptimizeAll.
+type OptimizeConf struct {
+
+	// Analyze lists tables with statistics to be updated
+	// (ANALYZE in SQLite, ANALYZE TABLE in MySQL)
+	Analyze []string `json:"analyze,omitempty"`
+
+	// Compact lists tables to be compacted (OPTIMIZE TABLE in MySQL).
+	// SQLite can compact only the whole database file (VACUUM)
+	// so any table listed here causes the whole database to be compacted.
+	Compact []string `json:"compact,omitempty"`
+}
+
+// IsConfigured tests whether there is anything to optimize
+func (c OptimizeConf) IsConfigured() bool {
+	return len(c.Analyze) > 0 || len(c.Compact) > 0
+}
+
+// Validate tests whether all the listed tables can be optimized
+func (c OptimizeConf) Validate() error {
+	for _, names := range [][]string{c.Analyze, c.Compact} {
+		for _, name := range names {
+			if name == OptimizeAll {
+				continue
+			}
+			var found bool
+			for _, t := range optimizableTables {
+				if t == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("table '%s' cannot be optimized", name)
+			}
+		}
+	}
+	return nil
+}
+
+// OptimizedTables expands a list of configured table names
+// (possibly containing OptimizeAll) to logical table names
+// without duplicates
+func OptimizedTables(names []string) []string {
+	selected := make(map[string]bool)
+	for _, name := range names {
+		if name == OptimizeAll {
+			return append([]string{}, optimizableTables...)
+		}
+		selected[name] = true
+	}
+	ans := make([]string, 0, len(selected))
+	for _, t := range optimizableTables {
+		if selected[t] {
+			ans = append(ans, t)
+		}
+	}
+	return ans
+}
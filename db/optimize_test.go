@@ -0,0 +1,30 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizedTables(t *testing.T) {
+	assert.Equal(t, []string{LiveAttrsTable, StatsTable}, OptimizedTables([]string{StatsTable, LiveAttrsTable}))
+	assert.Len(t, OptimizedTables([]string{StatsTable, OptimizeAll}), len(optimizableTables))
+	assert.NoError(t, OptimizeConf{Analyze: []string{OptimizeAll}, Compact: []string{ColCountsTable}}.Validate())
+	assert.Error(t, OptimizeConf{Analyze: []string{"bibliography"}}.Validate())
+}
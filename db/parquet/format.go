@@ -0,0 +1,506 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// The file layout follows the Apache Parquet format specification.
+// Only the subset needed by vte is supported: flat schemas with
+// optional columns, PLAIN encoding and no compression. Metadata
+// are serialized using the Thrift compact protocol.
+
+const (
+	magic = "PAR1"
+
+	// dfltRowGroupSize is a max. number of rows in a row group
+	// (each column chunk of a row group is written as a single page)
+	dfltRowGroupSize = 100000
+
+	createdBy = "vert-tagextract"
+
+	// physical types
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	repetitionOptional = 1
+	convertedTypeUTF8  = 0
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecUncompressed = 0
+	pageTypeData      = 0
+
+	// thrift compact protocol types
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+// column describes a single (optional) column of a file
+type column struct {
+	name     string
+	physType int
+}
+
+// thriftWriter serializes structures using
+// the Thrift compact protocol
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int
+	stack  []int
+}
+
+func (tw *thriftWriter) varint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	tw.buf.Write(tmp[:n])
+}
+
+func (tw *thriftWriter) zigzag(v int64) {
+	tw.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+func (tw *thriftWriter) fieldHeader(id, typ int) {
+	delta := id - tw.lastID
+	if delta > 0 && delta <= 15 {
+		tw.buf.WriteByte(byte(delta<<4 | typ))
+
+	} else {
+		tw.buf.WriteByte(byte(typ))
+		tw.zigzag(int64(id))
+	}
+	tw.lastID = id
+}
+
+func (tw *thriftWriter) i32Field(id int, v int32) {
+	tw.fieldHeader(id, ctI32)
+	tw.zigzag(int64(v))
+}
+
+func (tw *thriftWriter) i64Field(id int, v int64) {
+	tw.fieldHeader(id, ctI64)
+	tw.zigzag(v)
+}
+
+func (tw *thriftWriter) binary(v string) {
+	tw.varint(uint64(len(v)))
+	tw.buf.WriteString(v)
+}
+
+func (tw *thriftWriter) stringField(id int, v string) {
+	tw.fieldHeader(id, ctBinary)
+	tw.binary(v)
+}
+
+func (tw *thriftWriter) listField(id, elemType, size int) {
+	tw.fieldHeader(id, ctList)
+	if size < 15 {
+		tw.buf.WriteByte(byte(size<<4 | elemType))
+
+	} else {
+		tw.buf.WriteByte(byte(0xf0 | elemType))
+		tw.varint(uint64(size))
+	}
+}
+
+// beginStruct starts a nested structure. For list items,
+// id should be 0 (no field header is written).
+func (tw *thriftWriter) beginStruct(id int) {
+	if id > 0 {
+		tw.fieldHeader(id, ctStruct)
+	}
+	tw.stack = append(tw.stack, tw.lastID)
+	tw.lastID = 0
+}
+
+func (tw *thriftWriter) endStruct() {
+	tw.buf.WriteByte(0)
+	if len(tw.stack) > 0 {
+		tw.lastID = tw.stack[len(tw.stack)-1]
+		tw.stack = tw.stack[:len(tw.stack)-1]
+	}
+}
+
+// columnChunkMeta contains information about a written column chunk
+type columnChunkMeta struct {
+	numValues  int64
+	offset     int64
+	size       int64
+	physType   int
+	columnName string
+}
+
+// encodeDefLevels encodes definition levels (0 = null, 1 = defined)
+// using the RLE/bit-packing hybrid encoding (RLE runs only)
+// prefixed by the length of the encoded data.
+func encodeDefLevels(defined []bool) []byte {
+	var data bytes.Buffer
+	var tmp [binary.MaxVarintLen64]byte
+	for i := 0; i < len(defined); {
+		j := i + 1
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		n := binary.PutUvarint(tmp[:], uint64(j-i)<<1)
+		data.Write(tmp[:n])
+		if defined[i] {
+			data.WriteByte(1)
+
+		} else {
+			data.WriteByte(0)
+		}
+		i = j
+	}
+	ans := make([]byte, 4, 4+data.Len())
+	binary.LittleEndian.PutUint32(ans, uint32(data.Len()))
+	return append(ans, data.Bytes()...)
+}
+
+// encodeValues encodes non-null values of a column using
+// the PLAIN encoding
+func encodeValues(col column, values []any) ([]byte, []bool, error) {
+	var data bytes.Buffer
+	var tmp [8]byte
+	defined := make([]bool, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		defined[i] = true
+		switch col.physType {
+		case typeInt64:
+			iv, ok := toInt64(v)
+			if !ok {
+				return nil, nil, fmt.Errorf("column %s: cannot store %v as an integer", col.name, v)
+			}
+			binary.LittleEndian.PutUint64(tmp[:], uint64(iv))
+			data.Write(tmp[:])
+		case typeDouble:
+			fv, ok := toFloat64(v)
+			if !ok {
+				return nil, nil, fmt.Errorf("column %s: cannot store %v as a number", col.name, v)
+			}
+			binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(fv))
+			data.Write(tmp[:])
+		default:
+			sv := toString(v)
+			binary.LittleEndian.PutUint32(tmp[:4], uint32(len(sv)))
+			data.Write(tmp[:4])
+			data.WriteString(sv)
+		}
+	}
+	return data.Bytes(), defined, nil
+}
+
+// writePage writes a column chunk consisting of a single data page
+func writePage(w io.Writer, offset int64, col column, values []any) (columnChunkMeta, error) {
+	encValues, defined, err := encodeValues(col, values)
+	if err != nil {
+		return columnChunkMeta{}, err
+	}
+	body := append(encodeDefLevels(defined), encValues...)
+	var hdr thriftWriter
+	hdr.i32Field(1, pageTypeData)
+	hdr.i32Field(2, int32(len(body)))
+	hdr.i32Field(3, int32(len(body)))
+	hdr.beginStruct(5)
+	hdr.i32Field(1, int32(len(values)))
+	hdr.i32Field(2, encodingPlain)
+	hdr.i32Field(3, encodingRLE)
+	hdr.i32Field(4, encodingRLE)
+	hdr.endStruct()
+	hdr.endStruct()
+	if _, err := w.Write(hdr.buf.Bytes()); err != nil {
+		return columnChunkMeta{}, err
+	}
+	if _, err := w.Write(body); err != nil {
+		return columnChunkMeta{}, err
+	}
+	return columnChunkMeta{
+		numValues:  int64(len(values)),
+		offset:     offset,
+		size:       int64(hdr.buf.Len() + len(body)),
+		physType:   col.physType,
+		columnName: col.name,
+	}, nil
+}
+
+func encodeFileMetadata(cols []column, numRows int64, rowGroups [][]columnChunkMeta, groupRows []int64) []byte {
+	var tw thriftWriter
+	tw.i32Field(1, 1)
+	tw.listField(2, ctStruct, len(cols)+1)
+	tw.beginStruct(0)
+	tw.stringField(4, "schema")
+	tw.i32Field(5, int32(len(cols)))
+	tw.endStruct()
+	for _, col := range cols {
+		tw.beginStruct(0)
+		tw.i32Field(1, int32(col.physType))
+		tw.i32Field(3, repetitionOptional)
+		tw.stringField(4, col.name)
+		if col.physType == typeByteArray {
+			tw.i32Field(6, convertedTypeUTF8)
+		}
+		tw.endStruct()
+	}
+	tw.i64Field(3, numRows)
+	tw.listField(4, ctStruct, len(rowGroups))
+	for i, chunks := range rowGroups {
+		var groupSize int64
+		for _, ch := range chunks {
+			groupSize += ch.size
+		}
+		tw.beginStruct(0)
+		tw.listField(1, ctStruct, len(chunks))
+		for _, ch := range chunks {
+			tw.beginStruct(0)
+			tw.i64Field(2, ch.offset)
+			tw.beginStruct(3)
+			tw.i32Field(1, int32(ch.physType))
+			tw.listField(2, ctI32, 2)
+			tw.zigzag(encodingPlain)
+			tw.zigzag(encodingRLE)
+			tw.listField(3, ctBinary, 1)
+			tw.binary(ch.columnName)
+			tw.i32Field(4, codecUncompressed)
+			tw.i64Field(5, ch.numValues)
+			tw.i64Field(6, ch.size)
+			tw.i64Field(7, ch.size)
+			tw.i64Field(9, ch.offset)
+			tw.endStruct()
+			tw.endStruct()
+		}
+		tw.i64Field(2, groupSize)
+		tw.i64Field(3, groupRows[i])
+		tw.endStruct()
+	}
+	tw.stringField(6, createdBy)
+	tw.endStruct()
+	return tw.buf.Bytes()
+}
+
+// tableEncoder writes a Parquet file. Row groups are written as they
+// come, the footer describing all of them once the file is finished.
+type tableEncoder struct {
+	w         io.Writer
+	cols      []column
+	offset    int64
+	rowGroups [][]columnChunkMeta
+	groupRows []int64
+	numRows   int64
+}
+
+func newTableEncoder(w io.Writer, cols []column) (*tableEncoder, error) {
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	return &tableEncoder{w: w, cols: cols, offset: int64(len(magic))}, nil
+}
+
+// writeRowGroup writes rows (with values ordered according
+// to the encoder columns) as a single row group
+func (enc *tableEncoder) writeRowGroup(rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	chunks := make([]columnChunkMeta, len(enc.cols))
+	values := make([]any, 0, len(rows))
+	for i, col := range enc.cols {
+		values = values[:0]
+		for _, row := range rows {
+			values = append(values, row[i])
+		}
+		meta, err := writePage(enc.w, enc.offset, col, values)
+		if err != nil {
+			return err
+		}
+		chunks[i] = meta
+		enc.offset += meta.size
+	}
+	enc.rowGroups = append(enc.rowGroups, chunks)
+	enc.groupRows = append(enc.groupRows, int64(len(rows)))
+	enc.numRows += int64(len(rows))
+	return nil
+}
+
+// finish writes the file footer
+func (enc *tableEncoder) finish() error {
+	footer := encodeFileMetadata(enc.cols, enc.numRows, enc.rowGroups, enc.groupRows)
+	if _, err := enc.w.Write(footer); err != nil {
+		return err
+	}
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], uint32(len(footer)))
+	if _, err := enc.w.Write(tmp[:]); err != nil {
+		return err
+	}
+	_, err := io.WriteString(enc.w, magic)
+	return err
+}
+
+// writeTable writes rows (with values ordered according to cols)
+// to a Parquet file. Rows are split into row groups of groupSize
+// rows.
+func writeTable(w io.Writer, cols []column, rows [][]any, groupSize int) error {
+	enc, err := newTableEncoder(w, cols)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(rows); start += groupSize {
+		end := start + groupSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := enc.writeRowGroup(rows[start:end]); err != nil {
+			return err
+		}
+	}
+	return enc.finish()
+}
+
+// tableFileMark is a position in a tableFile
+// the file can be truncated to
+type tableFileMark struct {
+	offset    int64
+	numGroups int
+	numRows   int64
+}
+
+// tableFile writes a Parquet file row group by row group to a temporary
+// file which replaces the target file once finished so readers never
+// see a partially written file
+type tableFile struct {
+	path string
+	tmp  *os.File
+	bw   *bufio.Writer
+	enc  *tableEncoder
+}
+
+func createTableFile(path string, cols []column) (*tableFile, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file for %s: %w", path, err)
+	}
+	ans := &tableFile{path: path, tmp: tmp, bw: bufio.NewWriter(tmp)}
+	ans.enc, err = newTableEncoder(ans.bw, cols)
+	if err != nil {
+		ans.discard()
+		return nil, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return ans, nil
+}
+
+func (tf *tableFile) writeRowGroup(rows [][]any) error {
+	if err := tf.enc.writeRowGroup(rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tf.path, err)
+	}
+	if err := tf.bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tf.path, err)
+	}
+	return nil
+}
+
+func (tf *tableFile) numRows() int64 {
+	return tf.enc.numRows
+}
+
+func (tf *tableFile) mark() tableFileMark {
+	return tableFileMark{
+		offset:    tf.enc.offset,
+		numGroups: len(tf.enc.rowGroups),
+		numRows:   tf.enc.numRows,
+	}
+}
+
+// truncate discards all the row groups written after the mark
+func (tf *tableFile) truncate(m tableFileMark) error {
+	if err := tf.tmp.Truncate(m.offset); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", tf.path, err)
+	}
+	if _, err := tf.tmp.Seek(m.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to truncate %s: %w", tf.path, err)
+	}
+	tf.enc.offset = m.offset
+	tf.enc.rowGroups = tf.enc.rowGroups[:m.numGroups]
+	tf.enc.groupRows = tf.enc.groupRows[:m.numGroups]
+	tf.enc.numRows = m.numRows
+	return nil
+}
+
+// finish writes the footer and moves the file into place
+func (tf *tableFile) finish() error {
+	if err := tf.enc.finish(); err != nil {
+		tf.discard()
+		return fmt.Errorf("failed to write %s: %w", tf.path, err)
+	}
+	if err := tf.bw.Flush(); err != nil {
+		tf.discard()
+		return fmt.Errorf("failed to write %s: %w", tf.path, err)
+	}
+	if err := tf.tmp.Chmod(0644); err != nil {
+		tf.discard()
+		return fmt.Errorf("failed to write %s: %w", tf.path, err)
+	}
+	if err := tf.tmp.Close(); err != nil {
+		os.Remove(tf.tmp.Name())
+		return fmt.Errorf("failed to write %s: %w", tf.path, err)
+	}
+	if err := os.Rename(tf.tmp.Name(), tf.path); err != nil {
+		os.Remove(tf.tmp.Name())
+		return fmt.Errorf("failed to move %s into place: %w", tf.path, err)
+	}
+	return nil
+}
+
+// discard removes the temporary file
+func (tf *tableFile) discard() {
+	tf.tmp.Close()
+	os.Remove(tf.tmp.Name())
+}
+
+// writeTableFile writes a Parquet file at once
+// (via a temporary file, see tableFile)
+func writeTableFile(path string, cols []column, rows [][]any, groupSize int) error {
+	tf, err := createTableFile(path, cols)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(rows); start += groupSize {
+		end := start + groupSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if err := tf.writeRowGroup(rows[start:end]); err != nil {
+			tf.discard()
+			return err
+		}
+	}
+	return tf.finish()
+}
@@ -0,0 +1,491 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/fs"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// FileSuffix is a suffix of files written by Writer
+	FileSuffix = ".parquet"
+)
+
+const (
+	// mergeNone keeps all the inserted rows
+	mergeNone = iota
+
+	// mergeReplace keeps only the last row for each key
+	mergeReplace
+
+	// mergeSum sums non-key (integer) columns of rows with the same key
+	mergeSum
+)
+
+// table buffers rows of a single output file
+type table struct {
+	cols []string
+	rows [][]any
+
+	// keyLen is a number of leading columns forming
+	// a key of rows merged by mergeReplace or mergeSum
+	keyLen int
+	merge  int
+
+	// rows of tables without merging are written to file
+	// in row groups of groupSize rows so they do not have
+	// to be kept in memory
+	path      string
+	groupSize int
+	file      *tableFile
+}
+
+// tableMark is a state of a table a savepoint can return to
+type tableMark struct {
+	numRows int
+	hasFile bool
+	file    tableFileMark
+}
+
+// add appends a row (and writes a row group if there
+// are enough rows buffered)
+func (t *table) add(row []any) error {
+	t.rows = append(t.rows, row)
+	if t.merge == mergeNone && len(t.rows) >= t.groupSize {
+		return t.flush()
+	}
+	return nil
+}
+
+// flush writes buffered rows of a table without merging as a row
+// group. Column types are inferred from the first written row group.
+func (t *table) flush() error {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	if t.file == nil {
+		var err error
+		t.file, err = createTableFile(t.path, t.columns(t.rows))
+		if err != nil {
+			return err
+		}
+	}
+	if err := t.file.writeRowGroup(t.rows); err != nil {
+		return err
+	}
+	t.rows = t.rows[:0]
+	return nil
+}
+
+// mark returns the current state of the table. Buffered rows of
+// a table without merging are written first so the state can be
+// restored just by truncating the file.
+func (t *table) mark() (tableMark, error) {
+	if t.merge == mergeNone {
+		if err := t.flush(); err != nil {
+			return tableMark{}, err
+		}
+	}
+	ans := tableMark{numRows: len(t.rows)}
+	if t.file != nil {
+		ans.hasFile = true
+		ans.file = t.file.mark()
+	}
+	return ans, nil
+}
+
+func (t *table) rollbackTo(m tableMark) error {
+	t.rows = t.rows[:m.numRows]
+	if t.file == nil {
+		return nil
+	}
+	if !m.hasFile {
+		t.file.discard()
+		t.file = nil
+		return nil
+	}
+	return t.file.truncate(m.file)
+}
+
+// finish writes all the data of the table to its final
+// location and returns the number of written rows
+func (t *table) finish() (int64, error) {
+	if t.merge != mergeNone {
+		rows := t.mergedRows()
+		return int64(len(rows)), writeTableFile(t.path, t.columns(rows), rows, t.groupSize)
+	}
+	if err := t.flush(); err != nil {
+		return 0, err
+	}
+	if t.file == nil {
+		var err error
+		t.file, err = createTableFile(t.path, t.columns(nil))
+		if err != nil {
+			return 0, err
+		}
+	}
+	file := t.file
+	t.file = nil
+	return file.numRows(), file.finish()
+}
+
+// discard removes all the data of the table
+// which have not been finished yet
+func (t *table) discard() {
+	if t.file != nil {
+		t.file.discard()
+		t.file = nil
+	}
+	t.rows = nil
+}
+
+// mergedRows returns rows with applied merging strategy
+func (t *table) mergedRows() [][]any {
+	if t.merge == mergeNone {
+		return t.rows
+	}
+	ans := make([][]any, 0, len(t.rows))
+	index := make(map[string]int)
+	for _, row := range t.rows {
+		key := fmt.Sprint(row[:t.keyLen]...)
+		idx, ok := index[key]
+		if !ok {
+			index[key] = len(ans)
+			ans = append(ans, append([]any{}, row...))
+			continue
+		}
+		if t.merge == mergeReplace {
+			ans[idx] = append([]any{}, row...)
+			continue
+		}
+		for i := t.keyLen; i < len(row); i++ {
+			prev, _ := toInt64(ans[idx][i])
+			curr, _ := toInt64(row[i])
+			ans[idx][i] = prev + curr
+		}
+	}
+	return ans
+}
+
+func (t *table) columns(rows [][]any) []column {
+	ans := make([]column, len(t.cols))
+	for i, name := range t.cols {
+		ans[i] = column{name: name, physType: inferType(rows, i)}
+	}
+	return ans
+}
+
+type insert struct {
+	table *table
+}
+
+// ExecContext checks ctx and writes the row (the operation
+// itself does not block so it cannot be interrupted)
+func (ins *insert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ins.Exec(values...)
+}
+
+func (ins *insert) Exec(values ...any) error {
+	if len(values) != len(ins.table.cols) {
+		return fmt.Errorf(
+			"invalid number of values (expected %d, got %d)", len(ins.table.cols), len(values))
+	}
+	row := make([]any, len(values))
+	for i, v := range values {
+		// the same way SQL writers do, empty strings are stored as NULLs
+		if sv, ok := v.(string); !ok || sv != "" {
+			row[i] = v
+		}
+	}
+	return ins.table.add(row)
+}
+
+// Writer writes each table to an Apache Parquet file
+// (<Dir>/<table>.parquet) with column types derived from
+// the inserted values. Rows of data tables are written to temporary
+// files in row groups as they come, and only the file footers are
+// written on commit. Tables merging rows (metadata, stats, attribute
+// values) are kept in memory until they are committed. The append
+// mode is not supported as Parquet files cannot be modified.
+type Writer struct {
+	Dir string
+
+	// OutputCompat specifies naming of tables (see db.OutputCompatV2 etc.)
+	OutputCompat string
+
+	// rowGroupSize is a max. number of rows in a row group
+	// (dfltRowGroupSize if not set)
+	rowGroupSize int
+
+	tables     map[string]*table
+	savepoints map[string]map[string]tableMark
+}
+
+func (w *Writer) tablePath(name string) string {
+	return filepath.Join(w.Dir, name+FileSuffix)
+}
+
+// knownTables lists all the tables a writer may produce
+func (w *Writer) knownTables() []string {
+	return []string{
+		db.TableName(db.LiveAttrsTable, w.OutputCompat),
+		"colcounts",
+		db.UDFeatsTable,
+		db.RunMetadataTable,
+		db.StatsTable,
+		db.ColumnInfoTable,
+		db.AttrValuesTable,
+		db.AttrPairsTable,
+	}
+}
+
+func (w *Writer) DatabaseExists() bool {
+	return fs.IsFile(w.tablePath(db.TableName(db.LiveAttrsTable, w.OutputCompat)))
+}
+
+func (w *Writer) Initialize(appendMode bool) error {
+	if appendMode {
+		return fmt.Errorf("parquet output does not support the append mode")
+	}
+	if w.Dir == "" {
+		return fmt.Errorf("parquet output directory not specified")
+	}
+	if err := os.MkdirAll(w.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create parquet output directory: %w", err)
+	}
+	w.tables = make(map[string]*table)
+	w.savepoints = make(map[string]map[string]tableMark)
+	log.Info().Str("directory", w.Dir).Msg("Writing data to parquet files")
+	return nil
+}
+
+func (w *Writer) getTable(name string, cols []string, keyLen, merge int) (*table, error) {
+	if w.tables == nil {
+		return nil, fmt.Errorf("cannot write table %s - no transaction active", name)
+	}
+	t, ok := w.tables[name]
+	if !ok {
+		groupSize := w.rowGroupSize
+		if groupSize <= 0 {
+			groupSize = dfltRowGroupSize
+		}
+		t = &table{
+			cols:      cols,
+			keyLen:    keyLen,
+			merge:     merge,
+			path:      w.tablePath(name),
+			groupSize: groupSize,
+		}
+		w.tables[name] = t
+		return t, nil
+	}
+	if strings.Join(t.cols, ",") != strings.Join(cols, ",") {
+		return nil, fmt.Errorf("inconsistent columns of table %s", name)
+	}
+	return t, nil
+}
+
+func (w *Writer) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	t, err := w.getTable(db.TableName(table, w.OutputCompat), attrs, 0, mergeNone)
+	if err != nil {
+		return nil, err
+	}
+	return &insert{table: t}, nil
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	ans := make([]string, 0, len(values))
+	for k := range values {
+		ans = append(ans, k)
+	}
+	sort.Strings(ans)
+	return ans
+}
+
+func (w *Writer) SetRunMetadata(corpusID string, values map[string]string) error {
+	t, err := w.getTable(db.RunMetadataTable, []string{"corpus_id", "name", "value"}, 2, mergeReplace)
+	if err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(values) {
+		t.rows = append(t.rows, []any{corpusID, k, values[k]})
+	}
+	return nil
+}
+
+// DeleteCorpus is not supported as the output is always
+// written from scratch (see Initialize)
+func (w *Writer) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return nil, fmt.Errorf("deleting corpus rows is not supported by the Parquet writer")
+}
+
+func (w *Writer) SetStats(corpusID string, values map[string]int) error {
+	t, err := w.getTable(db.StatsTable, []string{"corpus_id", "name", "value"}, 2, mergeReplace)
+	if err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(values) {
+		t.rows = append(t.rows, []any{corpusID, k, values[k]})
+	}
+	return nil
+}
+
+func (w *Writer) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	t, err := w.getTable(
+		db.ColumnInfoTable,
+		[]string{
+			"corpus_id", "table_name", "column_name", "source", "structure", "attr", "modifiers", "description"},
+		3,
+		mergeReplace,
+	)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		t.rows = append(
+			t.rows,
+			[]any{
+				corpusID, v.Table, v.Column, v.Source, v.Structure, v.Attr,
+				strings.Join(v.Modifiers, ","), v.Description,
+			},
+		)
+	}
+	return nil
+}
+
+func (w *Writer) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	t, err := w.getTable(
+		db.AttrValuesTable,
+		[]string{"corpus_id", "attr", "value", "num_atoms", "num_tokens"},
+		3,
+		mergeSum,
+	)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		t.rows = append(t.rows, []any{corpusID, v.Attr, v.Value, v.NumAtoms, v.NumTokens})
+	}
+	return nil
+}
+
+func (w *Writer) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	t, err := w.getTable(
+		db.AttrPairsTable,
+		[]string{"corpus_id", "attr1", "value1", "attr2", "value2", "num_atoms", "num_tokens"},
+		5,
+		mergeSum,
+	)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		t.rows = append(
+			t.rows, []any{corpusID, v.Attr1, v.Value1, v.Attr2, v.Value2, v.NumAtoms, v.NumTokens})
+	}
+	return nil
+}
+
+func (w *Writer) Commit() error {
+	if w.tables == nil {
+		return db.ErrNoActiveTransaction
+	}
+	tables := w.tables
+	w.tables = nil
+	w.savepoints = nil
+	for _, name := range sortedKeys(tables) {
+		numRows, err := tables[name].finish()
+		if err != nil {
+			for _, t := range tables {
+				t.discard()
+			}
+			return err
+		}
+		log.Info().Str("table", name).Int64("rows", numRows).Msg("Written parquet file")
+	}
+	// files of tables not produced by this run would mix old and new data
+	for _, name := range w.knownTables() {
+		if _, ok := tables[name]; ok {
+			continue
+		}
+		if fs.IsFile(w.tablePath(name)) {
+			if err := os.Remove(w.tablePath(name)); err != nil {
+				return fmt.Errorf("failed to remove stale file: %w", err)
+			}
+			log.Info().Str("table", name).Msg("Removed stale parquet file")
+		}
+	}
+	return nil
+}
+
+func (w *Writer) Savepoint(name string) error {
+	if w.tables == nil {
+		return db.ErrNoActiveTransaction
+	}
+	marks := make(map[string]tableMark, len(w.tables))
+	for k, t := range w.tables {
+		m, err := t.mark()
+		if err != nil {
+			return err
+		}
+		marks[k] = m
+	}
+	w.savepoints[name] = marks
+	return nil
+}
+
+func (w *Writer) RollbackToSavepoint(name string) error {
+	if w.tables == nil {
+		return db.ErrNoActiveTransaction
+	}
+	marks, ok := w.savepoints[name]
+	if !ok {
+		return fmt.Errorf("failed to roll back to savepoint %s: no such savepoint", name)
+	}
+	// tables created after the savepoint get an empty mark
+	for k, t := range w.tables {
+		if err := t.rollbackTo(marks[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) Rollback() error {
+	for _, t := range w.tables {
+		t.discard()
+	}
+	w.tables = nil
+	w.savepoints = nil
+	return nil
+}
+
+func (w *Writer) Close() {
+	w.Rollback()
+}
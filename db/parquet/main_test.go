@@ -0,0 +1,298 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	parquetgo "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// thriftReader decodes Thrift compact protocol structures into
+// maps (field id -> value) so tests can inspect written metadata
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (tr *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(tr.data[tr.pos:])
+	tr.pos += n
+	return v
+}
+
+func (tr *thriftReader) zigzag() int64 {
+	v := tr.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (tr *thriftReader) value(typ byte) any {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case ctI32, ctI64:
+		return tr.zigzag()
+	case ctBinary:
+		n := int(tr.varint())
+		tr.pos += n
+		return string(tr.data[tr.pos-n : tr.pos])
+	case ctList:
+		hdr := tr.data[tr.pos]
+		tr.pos++
+		size := int(hdr >> 4)
+		if size == 15 {
+			size = int(tr.varint())
+		}
+		ans := make([]any, size)
+		for i := range ans {
+			ans[i] = tr.value(hdr & 0x0f)
+		}
+		return ans
+	case ctStruct:
+		return tr.structure()
+	}
+	panic("unsupported type")
+}
+
+func (tr *thriftReader) structure() map[int]any {
+	ans := make(map[int]any)
+	lastID := 0
+	for {
+		hdr := tr.data[tr.pos]
+		tr.pos++
+		if hdr == 0 {
+			return ans
+		}
+		id := lastID + int(hdr>>4)
+		if hdr>>4 == 0 {
+			id = int(tr.zigzag())
+		}
+		ans[id] = tr.value(hdr & 0x0f)
+		lastID = id
+	}
+}
+
+// readColumn decodes all the values of a column
+// (nil for nulls) from all the row groups
+func readColumn(t *testing.T, data []byte, meta map[int]any, idx int) []any {
+	schema := meta[2].([]any)[idx+1].(map[int]any)
+	ans := make([]any, 0, 10)
+	for _, rg := range meta[4].([]any) {
+		chunk := rg.(map[int]any)[1].([]any)[idx].(map[int]any)[3].(map[int]any)
+		tr := &thriftReader{data: data, pos: int(chunk[9].(int64))}
+		hdr := tr.structure()
+		numValues := int(hdr[5].(map[int]any)[1].(int64))
+		defLen := int(binary.LittleEndian.Uint32(data[tr.pos:]))
+		tr.pos += 4
+		defEnd := tr.pos + defLen
+		defined := make([]bool, 0, numValues)
+		for tr.pos < defEnd {
+			run := int(tr.varint() >> 1)
+			v := tr.data[tr.pos]
+			tr.pos++
+			for i := 0; i < run; i++ {
+				defined = append(defined, v == 1)
+			}
+		}
+		assert.Len(t, defined, numValues)
+		for _, d := range defined {
+			if !d {
+				ans = append(ans, nil)
+				continue
+			}
+			switch schema[1].(int64) {
+			case typeInt64:
+				ans = append(ans, int64(binary.LittleEndian.Uint64(data[tr.pos:])))
+				tr.pos += 8
+			case typeDouble:
+				ans = append(ans, math.Float64frombits(binary.LittleEndian.Uint64(data[tr.pos:])))
+				tr.pos += 8
+			default:
+				n := int(binary.LittleEndian.Uint32(data[tr.pos:]))
+				ans = append(ans, string(data[tr.pos+4:tr.pos+4+n]))
+				tr.pos += 4 + n
+			}
+		}
+	}
+	return ans
+}
+
+func readMetadata(t *testing.T, data []byte) map[int]any {
+	assert.Equal(t, magic, string(data[:4]))
+	assert.Equal(t, magic, string(data[len(data)-4:]))
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	tr := &thriftReader{data: data[len(data)-8-footerLen : len(data)-8]}
+	ans := tr.structure()
+	assert.Equal(t, footerLen, tr.pos)
+	return ans
+}
+
+func TestWriteTable(t *testing.T) {
+	cols := []column{
+		{name: "doc_title", physType: typeByteArray},
+		{name: "poscount", physType: typeInt64},
+		{name: "arf", physType: typeDouble},
+	}
+	rows := [][]any{
+		{"first", 10, 1.5},
+		{nil, 20, nil},
+		{"third", nil, 3},
+		{"čtvrtý", 40, 4.25},
+		{"fifth", 50, 5.0},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, writeTable(&buf, cols, rows, 2))
+	data := buf.Bytes()
+	meta := readMetadata(t, data)
+	assert.Equal(t, int64(5), meta[3])
+	assert.Len(t, meta[4], 3)
+	schema := meta[2].([]any)
+	assert.Len(t, schema, 4)
+	assert.Equal(t, "doc_title", schema[1].(map[int]any)[4])
+	assert.Equal(t, int64(convertedTypeUTF8), schema[1].(map[int]any)[6])
+	assert.Equal(t, int64(typeInt64), schema[2].(map[int]any)[1])
+
+	assert.Equal(t, []any{"first", nil, "third", "čtvrtý", "fifth"}, readColumn(t, data, meta, 0))
+	assert.Equal(t, []any{int64(10), int64(20), nil, int64(40), int64(50)}, readColumn(t, data, meta, 1))
+	assert.Equal(t, []any{1.5, nil, 3.0, 4.25, 5.0}, readColumn(t, data, meta, 2))
+}
+
+func TestInferType(t *testing.T) {
+	rows := [][]any{{"a", 1, nil, 1}, {nil, 2, nil, 2.5}}
+	assert.Equal(t, typeByteArray, inferType(rows, 0))
+	assert.Equal(t, typeInt64, inferType(rows, 1))
+	assert.Equal(t, typeByteArray, inferType(rows, 2))
+	assert.Equal(t, typeDouble, inferType(rows, 3))
+}
+
+func TestWriterCommit(t *testing.T) {
+	w := &Writer{Dir: filepath.Join(t.TempDir(), "out")}
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "poscount", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1", 100, "susanne"))
+	assert.NoError(t, w.Savepoint("sp1"))
+	assert.NoError(t, ins.Exec("d2", 200, "susanne"))
+	assert.NoError(t, w.AddAttrValues("susanne", []db.AttrValueCount{{Attr: "doc_id", Value: "d2", NumAtoms: 1}}))
+	assert.NoError(t, w.RollbackToSavepoint("sp1"))
+	assert.NoError(t, ins.Exec("", 300, "susanne"))
+	assert.NoError(t, w.AddAttrValues("susanne", []db.AttrValueCount{{Attr: "doc_id", Value: "d1", NumAtoms: 1}}))
+	assert.NoError(t, w.AddAttrValues("susanne", []db.AttrValueCount{{Attr: "doc_id", Value: "d1", NumAtoms: 2}}))
+	assert.NoError(t, w.SetStats("susanne", map[string]int{db.StatsTokens: 10}))
+	assert.NoError(t, w.SetStats("susanne", map[string]int{db.StatsTokens: 20}))
+	assert.NoError(t, w.Commit())
+	assert.ErrorIs(t, w.Commit(), db.ErrNoActiveTransaction)
+	assert.True(t, w.DatabaseExists())
+
+	data, err := os.ReadFile(filepath.Join(w.Dir, db.LiveAttrsTable+FileSuffix))
+	assert.NoError(t, err)
+	meta := readMetadata(t, data)
+	assert.Equal(t, []any{"d1", nil}, readColumn(t, data, meta, 0))
+	assert.Equal(t, []any{int64(100), int64(300)}, readColumn(t, data, meta, 1))
+
+	data, err = os.ReadFile(filepath.Join(w.Dir, db.AttrValuesTable+FileSuffix))
+	assert.NoError(t, err)
+	meta = readMetadata(t, data)
+	assert.Equal(t, []any{"d1"}, readColumn(t, data, meta, 2))
+	assert.Equal(t, []any{int64(3)}, readColumn(t, data, meta, 3))
+
+	data, err = os.ReadFile(filepath.Join(w.Dir, db.StatsTable+FileSuffix))
+	assert.NoError(t, err)
+	meta = readMetadata(t, data)
+	assert.Equal(t, []any{int64(20)}, readColumn(t, data, meta, 2))
+
+	assert.Error(t, w.Initialize(true))
+}
+
+type testLiveAttrsRow struct {
+	DocID    *string `parquet:"doc_id,optional"`
+	Poscount *int64  `parquet:"poscount,optional"`
+}
+
+func TestWriterStreamedRowGroups(t *testing.T) {
+	w := &Writer{Dir: filepath.Join(t.TempDir(), "out"), rowGroupSize: 3}
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "poscount"})
+	assert.NoError(t, err)
+	for i := 0; i < 7; i++ {
+		assert.NoError(t, ins.Exec(fmt.Sprintf("d%d", i), i*10))
+	}
+	table := w.tables[db.LiveAttrsTable]
+	assert.Len(t, table.rows, 1)
+	assert.Equal(t, int64(6), table.file.numRows())
+
+	assert.NoError(t, w.Savepoint("sp1"))
+	for i := 7; i < 12; i++ {
+		assert.NoError(t, ins.Exec(fmt.Sprintf("d%d", i), i*10))
+	}
+	assert.NoError(t, w.RollbackToSavepoint("sp1"))
+	assert.NoError(t, ins.Exec("", 70))
+	assert.NoError(t, w.Commit())
+
+	path := filepath.Join(w.Dir, db.LiveAttrsTable+FileSuffix)
+	tmpFiles, err := filepath.Glob(filepath.Join(w.Dir, "*.tmp*"))
+	assert.NoError(t, err)
+	assert.Empty(t, tmpFiles)
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	assert.NoError(t, err)
+	pf, err := parquetgo.OpenFile(f, info.Size())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), pf.NumRows())
+	assert.Len(t, pf.RowGroups(), 4)
+
+	rows, err := parquetgo.Read[testLiveAttrsRow](f, info.Size())
+	assert.NoError(t, err)
+	assert.Len(t, rows, 8)
+	for i, row := range rows[:7] {
+		if assert.NotNil(t, row.DocID) && assert.NotNil(t, row.Poscount) {
+			assert.Equal(t, fmt.Sprintf("d%d", i), *row.DocID)
+			assert.Equal(t, int64(i*10), *row.Poscount)
+		}
+	}
+	assert.Nil(t, rows[7].DocID)
+	if assert.NotNil(t, rows[7].Poscount) {
+		assert.Equal(t, int64(70), *rows[7].Poscount)
+	}
+}
+
+func TestWriterRollbackRemovesFiles(t *testing.T) {
+	w := &Writer{Dir: filepath.Join(t.TempDir(), "out"), rowGroupSize: 2}
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id"})
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, ins.Exec(fmt.Sprintf("d%d", i)))
+	}
+	assert.NoError(t, w.Rollback())
+	entries, err := os.ReadDir(w.Dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import "fmt"
+
+func toInt64(v any) (int64, bool) {
+	switch tv := v.(type) {
+	case int:
+		return int64(tv), true
+	case int8:
+		return int64(tv), true
+	case int16:
+		return int64(tv), true
+	case int32:
+		return int64(tv), true
+	case int64:
+		return tv, true
+	case uint:
+		return int64(tv), true
+	case uint8:
+		return int64(tv), true
+	case uint16:
+		return int64(tv), true
+	case uint32:
+		return int64(tv), true
+	case uint64:
+		return int64(tv), true
+	}
+	return 0, false
+}
+
+func toFloat64(v any) (float64, bool) {
+	switch tv := v.(type) {
+	case float32:
+		return float64(tv), true
+	case float64:
+		return tv, true
+	}
+	iv, ok := toInt64(v)
+	return float64(iv), ok
+}
+
+func toString(v any) string {
+	if sv, ok := v.(string); ok {
+		return sv
+	}
+	return fmt.Sprint(v)
+}
+
+// inferType determines a physical type of a column from
+// its (non-null) values. Integer columns are stored as INT64,
+// columns containing also floating point numbers as DOUBLE
+// and everything else (including columns without any value)
+// as UTF-8 strings.
+func inferType(rows [][]any, idx int) int {
+	var hasInt, hasFloat bool
+	for _, row := range rows {
+		switch row[idx].(type) {
+		case nil:
+		case float32, float64:
+			hasFloat = true
+		default:
+			if _, ok := toInt64(row[idx]); !ok {
+				return typeByteArray
+			}
+			hasInt = true
+		}
+	}
+	if hasFloat {
+		return typeDouble
+	}
+	if hasInt {
+		return typeInt64
+	}
+	return typeByteArray
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import "fmt"
+
+const (
+	// PartitionByCorpus creates a separate partition for each corpus
+	// (i.e. for each corpus_id value). Partitions are created lazily
+	// once data of a new corpus are written.
+	PartitionByCorpus = "corpus"
+
+	// PartitionByCorpusHash distributes rows into a fixed number
+	// of partitions based on a hash of corpus_id
+	PartitionByCorpusHash = "corpusHash"
+
+	// DfltNumPartitions is a default number of partitions
+	// used by PartitionByCorpusHash
+	DfltNumPartitions = 8
+)
+
+// PartitioningConf configures partitioning of the colcounts table
+// (MySQL only). This is useful mainly for grouped parallel corpora
+// where all the languages share a single (possibly huge) table.
+type PartitioningConf struct {
+
+	// By is one of PartitionByCorpus, PartitionByCorpusHash
+	// (empty means no partitioning)
+	By string `json:"by,omitempty"`
+
+	// Partitions is a number of partitions for PartitionByCorpusHash
+	// (DfltNumPartitions if not set)
+	Partitions int `json:"partitions,omitempty"`
+}
+
+// IsConfigured tests whether a partitioning is configured
+func (c PartitioningConf) IsConfigured() bool {
+	return c.By != ""
+}
+
+// GetPartitions returns the configured number of partitions
+// or DfltNumPartitions if not configured
+func (c PartitioningConf) GetPartitions() int {
+	if c.Partitions <= 0 {
+		return DfltNumPartitions
+	}
+	return c.Partitions
+}
+
+// Validate tests whether the configuration contains
+// supported values
+func (c PartitioningConf) Validate() error {
+	switch c.By {
+	case "", PartitionByCorpus, PartitionByCorpusHash:
+	default:
+		return fmt.Errorf("invalid colcountsPartitioning.by value '%s' (supported: corpus, corpusHash)", c.By)
+	}
+	if c.Partitions < 0 {
+		return fmt.Errorf("invalid colcountsPartitioning.partitions value %d", c.Partitions)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// PrimaryKeyAutoIncrement makes the database assign
+	// ids of LiveAttrsTable rows (default)
+	PrimaryKeyAutoIncrement = "autoIncrement"
+
+	// PrimaryKeyItemHash derives ids of LiveAttrsTable rows
+	// from corpus_id and item_id (requires selfJoin)
+	PrimaryKeyItemHash = "itemHash"
+
+	// PrimaryKeyValuesHash derives ids of LiveAttrsTable rows
+	// from corpus_id and values of all the structural attributes
+	PrimaryKeyValuesHash = "valuesHash"
+)
+
+// ValidatePrimaryKey tests whether the strategy is supported
+// and whether the columns it depends on are available
+func ValidatePrimaryKey(strategy string, hasSelfJoin bool) error {
+	switch strategy {
+	case "", PrimaryKeyAutoIncrement, PrimaryKeyValuesHash:
+		return nil
+	case PrimaryKeyItemHash:
+		if !hasSelfJoin {
+			return fmt.Errorf("primaryKey %s requires selfJoin to be configured", strategy)
+		}
+		return nil
+	}
+	return fmt.Errorf(
+		"invalid primaryKey value '%s' (supported: %s, %s, %s)",
+		strategy, PrimaryKeyAutoIncrement, PrimaryKeyItemHash, PrimaryKeyValuesHash)
+}
+
+// IsDeterministicPrimaryKey tests whether ids of LiveAttrsTable
+// rows are provided by vte instead of the database
+func IsDeterministicPrimaryKey(strategy string) bool {
+	return strategy == PrimaryKeyItemHash || strategy == PrimaryKeyValuesHash
+}
+
+// HashPrimaryKey derives a positive 63-bit id from the provided
+// values so repeated imports of the same data produce the same ids
+func HashPrimaryKey(values ...string) int64 {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return int64(binary.BigEndian.Uint64(h.Sum(nil)[:8]) & 0x7fffffffffffffff)
+}
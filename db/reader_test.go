@@ -0,0 +1,106 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderDeleteCorpus(t *testing.T) {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, err)
+	reader := &Reader{DB: database}
+	defer reader.Close()
+	_, err = database.Exec(
+		"CREATE TABLE liveattrs_entry (corpus_id TEXT); " +
+			"INSERT INTO liveattrs_entry VALUES ('intercorp_v13_en'), ('intercorp_v13_en'), ('intercorp_v13_cs'); " +
+			"CREATE TABLE stats (corpus_id TEXT, name TEXT, value INTEGER); " +
+			"INSERT INTO stats VALUES ('intercorp_v13_en', 'tokens', 10)")
+	assert.NoError(t, err)
+	assert.True(t, reader.TableExists(LiveAttrsTable))
+	assert.False(t, reader.TableExists(ColCountsTable))
+
+	deleted, err := reader.DeleteCorpus("intercorp_v13_en", CorpusTables)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{LiveAttrsTable: 2, StatsTable: 1}, deleted)
+	numRows, err := reader.RowCount(LiveAttrsTable, "intercorp_v13_cs")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numRows)
+}
+
+func TestDeleteCorpusRowsRollback(t *testing.T) {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, err)
+	reader := &Reader{DB: database}
+	defer reader.Close()
+	_, err = database.Exec(
+		"CREATE TABLE liveattrs_entry (corpus_id TEXT); " +
+			"INSERT INTO liveattrs_entry VALUES ('intercorp_v13_en'), ('intercorp_v13_cs'); " +
+			"CREATE TABLE cache (key TEXT, value TEXT); " +
+			"INSERT INTO cache VALUES ('k1', 'v1')")
+	assert.NoError(t, err)
+
+	tx, err := database.Begin()
+	assert.NoError(t, err)
+	deleted, err := DeleteCorpusRows(tx, reader.Table, "intercorp_v13_en", CorpusTables)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{LiveAttrsTable: 1}, deleted)
+	assert.NoError(t, ClearCacheRows(tx, reader.Table))
+	assert.NoError(t, tx.Rollback())
+
+	numRows, err := reader.RowCount(LiveAttrsTable, "intercorp_v13_en")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numRows)
+	var numCached int
+	assert.NoError(t, database.QueryRow("SELECT COUNT(*) FROM cache").Scan(&numCached))
+	assert.Equal(t, 1, numCached)
+}
+
+func TestReaderRemoveCorpusRecordsHistory(t *testing.T) {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, err)
+	reader := &Reader{DB: database}
+	defer reader.Close()
+	_, err = database.Exec(
+		"CREATE TABLE liveattrs_entry (corpus_id TEXT); " +
+			"INSERT INTO liveattrs_entry VALUES ('intercorp_v13_en'), ('intercorp_v13_cs'); " +
+			"CREATE TABLE liveattrs_history (run_id TEXT, corpus_id TEXT, op TEXT, changed_at TEXT, data TEXT)")
+	assert.NoError(t, err)
+
+	deleted, err := reader.RemoveCorpus("intercorp_v13_en", "run1")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{LiveAttrsTable: 1}, deleted)
+	var runID, corpusID, op, data string
+	err = database.QueryRow("SELECT run_id, corpus_id, op, data FROM liveattrs_history").
+		Scan(&runID, &corpusID, &op, &data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"run1", "intercorp_v13_en", HistoryOpDelete, ""}, []string{runID, corpusID, op, data})
+
+	// without the history table, nothing is deleted
+	_, err = database.Exec("DROP TABLE liveattrs_history")
+	assert.NoError(t, err)
+	_, err = reader.RemoveCorpus("intercorp_v13_cs", "run2")
+	assert.Error(t, err)
+	numRows, err := reader.RowCount(LiveAttrsTable, "intercorp_v13_cs")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numRows)
+}
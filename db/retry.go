@@ -0,0 +1,92 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DfltRetryDelayMs is a default delay before the first retry
+	DfltRetryDelayMs = 500
+
+	// DfltRetryMaxDelayMs is a default max. delay between retries
+	DfltRetryMaxDelayMs = 30000
+)
+
+// RetryConf configures retrying of database operations failed
+// due to transient errors like a lost connection or a deadlock
+// (MySQL only). The delay doubles after each failed attempt.
+type RetryConf struct {
+
+	// MaxAttempts is a max. number of attempts of an operation
+	// (including the first one; zero or one means no retries)
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+
+	// DelayMs is a delay before the first retry
+	// (DfltRetryDelayMs if zero)
+	DelayMs int `json:"delayMs,omitempty"`
+
+	// MaxDelayMs limits the growing delay
+	// (DfltRetryMaxDelayMs if zero)
+	MaxDelayMs int `json:"maxDelayMs,omitempty"`
+}
+
+// Validate tests whether the configuration contains
+// supported values
+func (c RetryConf) Validate() error {
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("invalid retry.maxAttempts value %d", c.MaxAttempts)
+	}
+	if c.DelayMs < 0 {
+		return fmt.Errorf("invalid retry.delayMs value %d", c.DelayMs)
+	}
+	if c.MaxDelayMs < 0 {
+		return fmt.Errorf("invalid retry.maxDelayMs value %d", c.MaxDelayMs)
+	}
+	return nil
+}
+
+// GetMaxAttempts returns the configured number
+// of attempts (at least one)
+func (c RetryConf) GetMaxAttempts() int {
+	if c.MaxAttempts < 1 {
+		return 1
+	}
+	return c.MaxAttempts
+}
+
+// Delay returns a delay before a retry following
+// the failed attempt (numbered from 1)
+func (c RetryConf) Delay(attempt int) time.Duration {
+	delay := c.DelayMs
+	if delay == 0 {
+		delay = DfltRetryDelayMs
+	}
+	maxDelay := c.MaxDelayMs
+	if maxDelay == 0 {
+		maxDelay = DfltRetryMaxDelayMs
+	}
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return time.Duration(delay) * time.Millisecond
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryConfDelay(t *testing.T) {
+	var c RetryConf
+	assert.Equal(t, 1, c.GetMaxAttempts())
+	assert.Equal(t, 500*time.Millisecond, c.Delay(1))
+	assert.Equal(t, 1000*time.Millisecond, c.Delay(2))
+
+	c = RetryConf{MaxAttempts: 5, DelayMs: 100, MaxDelayMs: 300}
+	assert.Equal(t, 5, c.GetMaxAttempts())
+	assert.Equal(t, 100*time.Millisecond, c.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, c.Delay(2))
+	assert.Equal(t, 300*time.Millisecond, c.Delay(3))
+	assert.Equal(t, 300*time.Millisecond, c.Delay(10))
+
+	assert.NoError(t, c.Validate())
+	assert.Error(t, RetryConf{MaxAttempts: -1}.Validate())
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// LiveAttrsTable is a logical name of the table with structural
+	// attributes (backends may add a prefix or a suffix)
+	LiveAttrsTable = "liveattrs_entry"
+
+	// ColCountsTable is a logical name of the n-gram table
+	ColCountsTable = "colcounts"
+
+	ColumnTypeText    = "text"
+	ColumnTypeInteger = "integer"
+	ColumnTypeReal    = "real"
+)
+
+// TableSchema describes columns of a table by their
+// type categories (ColumnTypeText etc.)
+type TableSchema struct {
+	Name    string
+	Columns map[string]string
+}
+
+// ExpectedSchema describes tables and columns vte writes
+// data into for a provided configuration
+func ExpectedSchema(
+	structures map[string][]string,
+	useSelfJoin bool,
+	countColumns VertColumns,
+) []TableSchema {
+	liveattrs := TableSchema{
+		Name: LiveAttrsTable,
+		Columns: map[string]string{
+			"poscount":  ColumnTypeInteger,
+			"wordcount": ColumnTypeInteger,
+			"corpus_id": ColumnTypeText,
+		},
+	}
+	for s, attrs := range structures {
+		for _, a := range attrs {
+			liveattrs.Columns[fmt.Sprintf("%s_%s", s, a)] = ColumnTypeText
+		}
+	}
+	if useSelfJoin {
+		liveattrs.Columns["item_id"] = ColumnTypeText
+	}
+	ans := []TableSchema{liveattrs}
+	if len(countColumns) > 0 {
+		colcounts := TableSchema{
+			Name: ColCountsTable,
+			Columns: map[string]string{
+				"hash_id":   ColumnTypeText,
+				"corpus_id": ColumnTypeText,
+				"count":     ColumnTypeInteger,
+				// note: arf is declared as INTEGER by older versions
+				"arf": ColumnTypeInteger,
+			},
+		}
+		for _, c := range GenerateColCountNames(countColumns) {
+			colcounts.Columns[c] = ColumnTypeText
+		}
+		ans = append(ans, colcounts)
+	}
+	if len(countColumns.ExplodedUDFeats()) > 0 {
+		ans = append(ans, TableSchema{
+			Name: UDFeatsTable,
+			Columns: map[string]string{
+				"corpus_id": ColumnTypeText,
+				"col":       ColumnTypeInteger,
+				"name":      ColumnTypeText,
+				"value":     ColumnTypeText,
+				"count":     ColumnTypeInteger,
+			},
+		})
+	}
+	return ans
+}
+
+// NormalizeColumnType maps a declared SQL column type
+// to a type category (ColumnTypeText etc.). Unknown types are
+// returned lowercased.
+func NormalizeColumnType(declType string) string {
+	t := strings.ToUpper(declType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return ColumnTypeInteger
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "TEXT"),
+		strings.Contains(t, "CLOB"), strings.Contains(t, "STRING"):
+		return ColumnTypeText
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"),
+		strings.Contains(t, "DOUB"), strings.Contains(t, "DECIMAL"):
+		return ColumnTypeReal
+	}
+	return strings.ToLower(declType)
+}
+
+// SchemaDiff contains differences between an expected
+// and an actual database schema
+type SchemaDiff struct {
+	MissingTables  []string
+	MissingColumns []string
+	TypeMismatches []string
+}
+
+func (d *SchemaDiff) IsEmpty() bool {
+	return len(d.MissingTables) == 0 && len(d.MissingColumns) == 0 &&
+		len(d.TypeMismatches) == 0
+}
+
+// String creates a human-readable report of the differences
+func (d *SchemaDiff) String() string {
+	var ans strings.Builder
+	for _, t := range d.MissingTables {
+		fmt.Fprintf(&ans, "  - missing table %s\n", t)
+	}
+	for _, c := range d.MissingColumns {
+		fmt.Fprintf(&ans, "  - missing column %s\n", c)
+	}
+	for _, c := range d.TypeMismatches {
+		fmt.Fprintf(&ans, "  - type mismatch %s\n", c)
+	}
+	return strings.TrimRight(ans.String(), "\n")
+}
+
+// CompareSchema compares an expected schema with actual tables. The
+// actual tables are provided as logical table name -> column -> declared type.
+// Missing tables must not be present in the map at all.
+func CompareSchema(expected []TableSchema, actual map[string]map[string]string) *SchemaDiff {
+	ans := new(SchemaDiff)
+	for _, table := range expected {
+		actCols, ok := actual[table.Name]
+		if !ok {
+			ans.MissingTables = append(ans.MissingTables, table.Name)
+			continue
+		}
+		cols := make([]string, 0, len(table.Columns))
+		for c := range table.Columns {
+			cols = append(cols, c)
+		}
+		sort.Strings(cols)
+		for _, c := range cols {
+			declType, ok := actCols[c]
+			if !ok {
+				ans.MissingColumns = append(ans.MissingColumns, table.Name+"."+c)
+				continue
+			}
+			if actType := NormalizeColumnType(declType); actType != table.Columns[c] {
+				ans.TypeMismatches = append(
+					ans.TypeMismatches,
+					fmt.Sprintf("%s.%s: expected %s, found %s", table.Name, c, table.Columns[c], declType),
+				)
+			}
+		}
+	}
+	return ans
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSchema(t *testing.T) {
+	expected := ExpectedSchema(
+		map[string][]string{"doc": {"id"}},
+		false,
+		VertColumns{{Idx: 0, UDFeats: UDFeatsExplode}},
+	)
+	actual := map[string]map[string]string{
+		LiveAttrsTable: {
+			"doc_id":    "TEXT",
+			"poscount":  "INTEGER",
+			"wordcount": "int(11)",
+			"corpus_id": "varchar(63)",
+		},
+		ColCountsTable: {
+			"hash_id":   "varchar(40)",
+			"col0":      "TEXT",
+			"corpus_id": "TEXT",
+			"count":     "REAL",
+		},
+	}
+	diff := CompareSchema(expected, actual)
+	assert.Equal(t, []string{UDFeatsTable}, diff.MissingTables)
+	assert.Equal(t, []string{"colcounts.arf"}, diff.MissingColumns)
+	assert.Equal(t, []string{"colcounts.count: expected integer, found REAL"}, diff.TypeMismatches)
+	assert.False(t, diff.IsEmpty())
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// createWorkFile creates a temporary file next to the target database
+// (so it can be renamed atomically). With copyFrom specified, the file
+// contains a copy of the respective database, otherwise it is empty
+// (which sqlite treats as an empty database).
+func createWorkFile(dbPath, copyFrom string) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(dbPath), "."+filepath.Base(dbPath)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary database file: %w", err)
+	}
+	defer tmp.Close()
+	if copyFrom != "" {
+		if err := copyFileTo(tmp, copyFrom); err != nil {
+			os.Remove(tmp.Name())
+			return "", fmt.Errorf("failed to copy database to a temporary file: %w", err)
+		}
+	}
+	return tmp.Name(), nil
+}
+
+func copyFileTo(dst io.Writer, srcPath string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// publishWorkFile atomically replaces the target database
+// by a (closed) temporary database file. Permissions of
+// the original database (if any) are preserved.
+func publishWorkFile(workPath, dbPath string) error {
+	var mode os.FileMode = 0644
+	if info, err := os.Stat(dbPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(workPath, mode); err != nil {
+		return fmt.Errorf("failed to set permissions of temporary database file: %w", err)
+	}
+	if err := os.Rename(workPath, dbPath); err != nil {
+		return fmt.Errorf("failed to move temporary database %s to %s: %w", workPath, dbPath, err)
+	}
+	return nil
+}
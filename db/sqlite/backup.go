@@ -0,0 +1,61 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/rs/zerolog/log"
+)
+
+// backupDatabaseFile copies a database file to a timestamped
+// file next to it and removes backups exceeding the number
+// of backups to keep. SQLite index names are global within
+// a database file so unlike MySQL, renaming tables would
+// not work here.
+func backupDatabaseFile(dbPath string, keep int) (string, error) {
+	backupPath := db.BackupName(dbPath, time.Now())
+	dst, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create database backup: %w", err)
+	}
+	if err := copyFileTo(dst, dbPath); err != nil {
+		dst.Close()
+		os.Remove(backupPath)
+		return "", fmt.Errorf("failed to create database backup: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(backupPath)
+		return "", fmt.Errorf("failed to create database backup: %w", err)
+	}
+	existing, err := filepath.Glob(dbPath + db.BackupInfix + "*")
+	if err != nil {
+		return backupPath, fmt.Errorf("failed to list database backups: %w", err)
+	}
+	for _, expired := range db.ExpiredBackups(existing, keep) {
+		if err := os.Remove(expired); err != nil {
+			log.Warn().Err(err).Str("file", expired).Msg("failed to remove expired database backup")
+			continue
+		}
+		log.Info().Str("file", expired).Msg("removed expired database backup")
+	}
+	return backupPath, nil
+}
@@ -18,8 +18,11 @@ package sqlite
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"os"
 
+	"github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog/log"
 
 	"github.com/czcorpus/vert-tagextract/v3/db"
@@ -38,6 +41,67 @@ type Writer struct {
 	SelfJoinConf   db.SelfJoinConf
 	BibViewConf    db.BibViewConf
 	VertColumns    db.VertColumns
+
+	// OutputCompat specifies naming of tables (see db.OutputCompatV2 etc.)
+	OutputCompat string
+
+	// Atomic, if true, makes the writer build the database in
+	// a temporary file which replaces the one at Path only once
+	// the data are committed. Readers of Path thus never see
+	// a partially written database and failed runs leave
+	// the original database intact.
+	Atomic bool
+
+	// Backup configures copying of an existing database file
+	// before it is replaced by a non-append run
+	Backup db.BackupConf
+
+	// AttrValues, if true, makes the writer create
+	// a table of structural attribute values (see db.AttrValuesTable)
+	AttrValues bool
+
+	// AttrPairs, if true, makes the writer create a table
+	// of structural attribute value pairs (see db.AttrPairsTable)
+	AttrPairs bool
+
+	// Optimize configures post-commit optimizations
+	// (ANALYZE, VACUUM) of the database
+	Optimize db.OptimizeConf
+
+	// Collations maps liveattrs and colcounts columns
+	// to their collations (see db.Conf.Collations)
+	Collations map[string]string
+
+	// PrimaryKey specifies how ids of liveattrs
+	// rows are obtained (see db.PrimaryKeyAutoIncrement etc.)
+	PrimaryKey string
+
+	// History, if true, makes the writer create a table
+	// of liveattrs changes (see db.LiveAttrsHistoryTable)
+	History bool
+
+	// HelperViews lists helper views created along
+	// with the schema (see db.HelperViews)
+	HelperViews []string
+
+	// SQLite configures extensions and ICU collations
+	// loaded into each connection
+	SQLite db.SQLiteConf
+
+	// workPath is a temporary database file used in the Atomic mode
+	workPath string
+}
+
+// activePath returns a path of the database file actually written
+func (w *Writer) activePath() string {
+	if w.workPath != "" {
+		return w.workPath
+	}
+	return w.Path
+}
+
+func (w *Writer) laTable() string {
+	return db.TableName(db.LiveAttrsTable, w.OutputCompat)
 }
 
 func (w *Writer) DatabaseExists() bool {
@@ -47,11 +111,31 @@ func (w *Writer) DatabaseExists() bool {
 func (w *Writer) Initialize(appendMode bool) error {
 	var err error
 	dbExisted := fs.IsFile(w.Path)
-	w.database, err = openDatabase(w.Path)
+	if !appendMode && dbExisted && w.Backup.Enabled {
+		backupPath, err := backupDatabaseFile(w.Path, w.Backup.GetKeep())
+		if err != nil {
+			return err
+		}
+		log.Info().Str("database", w.Path).Str("backup", backupPath).Msg("Created database backup")
+	}
+	if w.Atomic {
+		var copyFrom string
+		if appendMode && dbExisted {
+			copyFrom = w.Path
+		}
+		w.workPath, err = createWorkFile(w.Path, copyFrom)
+		if err != nil {
+			return err
+		}
+		// in the create mode, we start with an empty database
+		dbExisted = copyFrom != ""
+		log.Info().Str("database", w.Path).Str("tmpFile", w.workPath).Msg("Writing database via a temporary file")
+	}
+	w.database, err = openDatabase(w.activePath(), w.SQLite)
 	if err != nil {
 		return err
 	}
-	log.Info().Msgf("Opened sqlite3 database %s", w.Path)
+	log.Info().Msgf("Opened sqlite3 database %s", w.activePath())
 
 	if !appendMode {
 		if dbExisted {
@@ -59,29 +143,70 @@ func (w *Writer) Initialize(appendMode bool) error {
 				Warn().
 				Str("database", w.Path).
 				Msg("The database already exists. Existing data will be deleted.")
-			err := dropExisting(w.database)
+			err := dropExisting(w.database, w.laTable())
 			if err != nil {
 				return err
 			}
 		}
 		err := createSchema(
 			w.database,
+			w.laTable(),
 			w.Structures,
 			w.IndexedCols,
 			w.SelfJoinConf.IsConfigured(),
 			w.VertColumns,
+			w.Collations,
+			w.PrimaryKey,
 		)
 		if err != nil {
 			return err
 		}
 		if w.BibViewConf.IsConfigured() {
-			err := createBibView(w.database, w.BibViewConf.Cols, w.BibViewConf.IDAttr)
+			err := createBibView(w.database, w.laTable(), w.BibViewConf.Cols, w.BibViewConf.IDAttr)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
+	if err := createCacheTable(w.database); err != nil {
+		return err
+	}
+	if err := createRunMetadataTable(w.database); err != nil {
+		return err
+	}
+	if err := createStatsTable(w.database); err != nil {
+		return err
+	}
+	if err := createColumnInfoTable(w.database); err != nil {
+		return err
+	}
+	if w.History {
+		if err := createHistoryTable(w.database); err != nil {
+			return err
+		}
+	}
+	if !appendMode {
+		if err := createHelperViews(w.database, w.laTable(), w.HelperViews); err != nil {
+			return err
+		}
+	}
+	if w.AttrValues {
+		if err := createAttrValuesTable(w.database); err != nil {
+			return err
+		}
+	}
+	if w.AttrPairs {
+		if err := createAttrPairsTable(w.database); err != nil {
+			return err
+		}
+	}
+	if appendMode {
+		if err := w.checkSchema(); err != nil {
+			return err
+		}
+	}
+
 	var dbConf []string
 	if len(w.PreconfQueries) > 0 {
 		dbConf = w.PreconfQueries
@@ -98,35 +223,193 @@ func (w *Writer) Initialize(appendMode bool) error {
 		w.database.Exec(cnf)
 	}
 	w.tx, err = w.database.Begin()
-	return err
+	if err != nil {
+		return err
+	}
+	if appendMode {
+		return clearCache(w.tx)
+	}
+	return nil
+}
+
+// checkSchema verifies that an existing database contains all
+// the tables and columns required by the configuration
+func (w *Writer) checkSchema() error {
+	expected := db.ExpectedSchema(w.Structures, w.SelfJoinConf.IsConfigured(), w.VertColumns)
+	actual, err := readSchema(w.database, w.OutputCompat, expected)
+	if err != nil {
+		return err
+	}
+	if diff := db.CompareSchema(expected, actual); !diff.IsEmpty() {
+		return fmt.Errorf("database schema does not match the configuration:\n%s", diff)
+	}
+	return nil
 }
 
 func (w *Writer) CreateBibView(cols []string, idAttr string) error {
-	return createBibView(w.database, cols, idAttr)
+	return createBibView(w.database, w.laTable(), cols, idAttr)
 }
 
 func (w *Writer) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
 	if w.tx == nil {
 		return nil, fmt.Errorf("cannot prepare insert - no transaction active")
 	}
-	stmt, err := prepareInsert(w.tx, table, attrs)
+	var onConflict string
+	if table == db.ColCountsTable {
+		onConflict = colCountsOnConflict
+	}
+	stmt, err := prepareInsert(w.tx, db.TableName(table, w.OutputCompat), attrs, onConflict)
 	if err != nil {
 		return nil, err
 	}
 	return &db.Insert{Stmt: stmt}, nil
 }
 
+func (w *Writer) SetRunMetadata(corpusID string, values map[string]string) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot set run metadata - no transaction active")
+	}
+	return setRunMetadata(w.tx, corpusID, values)
+}
+
+func (w *Writer) SetStats(corpusID string, values map[string]int) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot set stats - no transaction active")
+	}
+	return setStats(w.tx, corpusID, values)
+}
+
+func (w *Writer) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot set column info - no transaction active")
+	}
+	return setColumnInfo(w.tx, corpusID, values)
+}
+
+func (w *Writer) DeleteCorpus(corpusID string) (map[string]int, error) {
+	if w.tx == nil {
+		return nil, fmt.Errorf("cannot delete corpus - no transaction active")
+	}
+	names := &db.Reader{OutputCompat: w.OutputCompat}
+	ans, err := db.DeleteCorpusRows(w.tx, names.Table, corpusID, db.CorpusTables)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ClearCacheRows(w.tx, names.Table); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (w *Writer) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot add attribute values - no transaction active")
+	}
+	return addAttrValues(w.tx, corpusID, values)
+}
+
+func (w *Writer) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	if w.tx == nil {
+		return fmt.Errorf("cannot add attribute pairs - no transaction active")
+	}
+	return addAttrPairs(w.tx, corpusID, values)
+}
+
 func (w *Writer) Commit() error {
-	return w.tx.Commit()
+	if w.tx == nil {
+		return db.ErrNoActiveTransaction
+	}
+	err := w.tx.Commit()
+	w.tx = nil
+	if err != nil {
+		return err
+	}
+	if err := optimizeDatabase(w.database, w.Optimize, w.OutputCompat); err != nil {
+		log.Warn().Err(err).Msg("failed to optimize database")
+	}
+	if w.workPath == "" {
+		return nil
+	}
+	if err := w.database.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary database: %w", err)
+	}
+	w.database = nil
+	if err := publishWorkFile(w.workPath, w.Path); err != nil {
+		return err
+	}
+	log.Info().Str("database", w.Path).Msg("Moved temporary database into place")
+	w.workPath = ""
+	return nil
+}
+
+func (w *Writer) Savepoint(name string) error {
+	if w.tx == nil {
+		return db.ErrNoActiveTransaction
+	}
+	if _, err := w.tx.Exec("SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+	return nil
+}
+
+func (w *Writer) RollbackToSavepoint(name string) error {
+	if w.tx == nil {
+		return db.ErrNoActiveTransaction
+	}
+	if _, err := w.tx.Exec("ROLLBACK TO SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint %s: %w", name, err)
+	}
+	return nil
 }
 
 func (w *Writer) Rollback() error {
-	return w.tx.Rollback()
+	if w.tx == nil {
+		return nil
+	}
+	err := w.tx.Rollback()
+	w.tx = nil
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
 }
 
 func (w *Writer) Close() {
-	err := w.database.Close()
+	if w.database != nil {
+		if err := w.Rollback(); err != nil {
+			log.Warn().Err(err).Msg("failed to roll back unfinished transaction")
+		}
+		err := w.database.Close()
+		if err != nil {
+			log.Warn().Err(err).Msg("Error closing database")
+		}
+		w.database = nil
+	}
+	if w.workPath != "" {
+		// data have not been committed so the original database stays as it was
+		if err := os.Remove(w.workPath); err != nil {
+			log.Warn().Err(err).Str("tmpFile", w.workPath).Msg("failed to remove temporary database")
+		}
+		w.workPath = ""
+	}
+}
+
+// OpenReader opens an existing sqlite database for reading
+// (outputCompat must match the value used to create the database)
+func OpenReader(path, outputCompat string, sqliteConf db.SQLiteConf) (*db.Reader, error) {
+	if !fs.IsFile(path) {
+		return nil, fmt.Errorf("database %s does not exist", path)
+	}
+	database, err := openDatabase(path, sqliteConf)
 	if err != nil {
-		log.Warn().Err(err).Msg("Error closing database")
+		return nil, err
 	}
+	return &db.Reader{DB: database, OutputCompat: outputCompat}, nil
+}
+
+// IsOutOfMemoryErr tells whether an error has been caused
+// by SQLite failing to allocate memory
+func IsOutOfMemoryErr(err error) bool {
+	var sqErr sqlite3.Error
+	return errors.As(err, &sqErr) && sqErr.Code == sqlite3.ErrNomem
 }
@@ -0,0 +1,326 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWriter(t *testing.T) *Writer {
+	return &Writer{
+		Path:        filepath.Join(t.TempDir(), "test.db"),
+		Structures:  createStructures(),
+		VertColumns: db.VertColumns{{Idx: 0}},
+	}
+}
+
+func TestCloseWithoutInitialize(t *testing.T) {
+	w := newTestWriter(t)
+	w.Close()
+	w.Close()
+	assert.NoError(t, w.Rollback())
+}
+
+func TestCommitThenRollback(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.Commit())
+	assert.NoError(t, w.Rollback())
+	assert.ErrorIs(t, w.Commit(), db.ErrNoActiveTransaction)
+	w.Close()
+	w.Close()
+}
+
+func TestRollbackIsIdempotent(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.Rollback())
+	assert.NoError(t, w.Rollback())
+	assert.ErrorIs(t, w.Commit(), db.ErrNoActiveTransaction)
+	w.Close()
+}
+
+func TestRollbackAfterFailedCommit(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	// finish the transaction behind the writer's back so Commit fails
+	assert.NoError(t, w.tx.Rollback())
+	assert.Error(t, w.Commit())
+	assert.NoError(t, w.Rollback())
+	w.Close()
+}
+
+func TestCloseRollsBackOpenTransaction(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsWords: 10}))
+	w.Close()
+
+	reader, err := OpenReader(w.Path, w.OutputCompat, w.SQLite)
+	assert.NoError(t, err)
+	defer reader.Close()
+	stats, err := reader.Stats("corp")
+	assert.NoError(t, err)
+	assert.Empty(t, stats)
+}
+
+func TestAppendSchemaCheck(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.Commit())
+	w.Close()
+
+	assert.NoError(t, w.Initialize(true))
+	w.Close()
+
+	w.Structures["doc"] = append(w.Structures["doc"], "genre")
+	w.VertColumns = db.VertColumns{{Idx: 0}, {Idx: 2}}
+	err := w.Initialize(true)
+	assert.EqualError(
+		t,
+		err,
+		"database schema does not match the configuration:\n"+
+			"  - missing column liveattrs_entry.doc_genre\n"+
+			"  - missing column colcounts.col2",
+	)
+	w.Close()
+}
+
+func TestAppendClearsCache(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	_, err := w.tx.Exec("INSERT INTO cache (key, value) VALUES ('q1', 'v1')")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Commit())
+	w.Close()
+
+	assert.NoError(t, w.Initialize(true))
+	var cnt int
+	assert.NoError(t, w.tx.QueryRow("SELECT COUNT(*) FROM cache").Scan(&cnt))
+	assert.Equal(t, 0, cnt)
+	w.Close()
+}
+
+func TestOutputCompatV2(t *testing.T) {
+	w := newTestWriter(t)
+	w.OutputCompat = db.OutputCompatV2
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1", "corp"))
+	assert.NoError(t, w.Commit())
+	w.Close()
+	assert.NoError(t, w.Initialize(true))
+	w.Close()
+
+	reader, err := OpenReader(w.Path, w.OutputCompat, w.SQLite)
+	assert.NoError(t, err)
+	defer reader.Close()
+	var cnt int
+	assert.NoError(t, reader.DB.QueryRow("SELECT COUNT(*) FROM item").Scan(&cnt))
+	assert.Equal(t, 1, cnt)
+	assert.NoError(
+		t, reader.DB.QueryRow("SELECT COUNT(*) FROM "+reader.Table(db.LiveAttrsTable)).Scan(&cnt))
+	assert.Equal(t, 1, cnt)
+}
+
+func TestMigrationSQLV2ToV3(t *testing.T) {
+	w := newTestWriter(t)
+	w.OutputCompat = db.OutputCompatV2
+	w.BibViewConf = db.BibViewConf{Cols: []string{"doc_id", "doc_author"}, IDAttr: "doc_id"}
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1", "corp"))
+	assert.NoError(t, w.Commit())
+
+	changes, err := db.SchemaChanges(db.OutputCompatV2, db.OutputCompatV3)
+	assert.NoError(t, err)
+	for _, ch := range changes {
+		for _, stmt := range MigrationSQL(ch, db.LiveAttrsTable, w.BibViewConf) {
+			_, err := w.database.Exec(stmt)
+			assert.NoError(t, err, stmt)
+		}
+	}
+	w.Close()
+
+	w.OutputCompat = db.OutputCompatV3
+	if !assert.NoError(t, w.Initialize(true)) {
+		return
+	}
+	var id string
+	assert.NoError(t, w.tx.QueryRow("SELECT id FROM bibliography").Scan(&id))
+	assert.Equal(t, "d1", id)
+	w.Close()
+}
+
+func TestAtomicWriteKeepsOriginalOnFailure(t *testing.T) {
+	w := newTestWriter(t)
+	w.Atomic = true
+	assert.NoError(t, w.Initialize(false))
+	assert.NoFileExists(t, w.Path)
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsWords: 10}))
+	assert.NoError(t, w.Commit())
+	w.Close()
+	assert.FileExists(t, w.Path)
+
+	// a failed (uncommitted) run must not touch the published database
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsWords: 20}))
+	w.Close()
+	assert.NoError(t, w.Initialize(true))
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsWords: 30}))
+	w.Close()
+
+	tmpFiles, err := filepath.Glob(filepath.Join(filepath.Dir(w.Path), "*.tmp"))
+	assert.NoError(t, err)
+	assert.Empty(t, tmpFiles)
+
+	reader, err := OpenReader(w.Path, w.OutputCompat, w.SQLite)
+	assert.NoError(t, err)
+	defer reader.Close()
+	stats, err := reader.Stats("corp")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, stats[db.StatsWords])
+}
+
+func TestBackupBeforeCreate(t *testing.T) {
+	w := newTestWriter(t)
+	w.Backup = db.BackupConf{Enabled: true}
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsWords: 10}))
+	assert.NoError(t, w.Commit())
+	w.Close()
+
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.Commit())
+	w.Close()
+
+	backups, err := filepath.Glob(w.Path + db.BackupInfix + "*")
+	assert.NoError(t, err)
+	if !assert.Len(t, backups, 1) {
+		return
+	}
+	reader, err := OpenReader(backups[0], w.OutputCompat, w.SQLite)
+	assert.NoError(t, err)
+	defer reader.Close()
+	stats, err := reader.Stats("corp")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, stats[db.StatsWords])
+}
+
+func TestCommitOptimizesDatabase(t *testing.T) {
+	w := newTestWriter(t)
+	w.Optimize = db.OptimizeConf{Analyze: []string{db.StatsTable}, Compact: []string{db.OptimizeAll}}
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsWords: 10}))
+	assert.NoError(t, w.Commit())
+	var numStats int
+	err := w.database.QueryRow("SELECT COUNT(*) FROM sqlite_stat1 WHERE tbl = ?", db.StatsTable).Scan(&numStats)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numStats)
+	w.Close()
+}
+
+func TestHelperViews(t *testing.T) {
+	w := newTestWriter(t)
+	w.HelperViews = []string{db.LiveAttrsStatsView, db.ColcountsIPMView}
+	assert.NoError(t, w.Initialize(false))
+	assert.NoError(t, w.SetStats("corp", map[string]int{db.StatsTokens: 2000}))
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "poscount", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1", 500, "corp"))
+	ins, err = w.PrepareInsert("colcounts", []string{"hash_id", "col0", "count", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("h1", "word", 4, "corp"))
+	assert.NoError(t, w.Commit())
+	w.Close()
+
+	reader, err := OpenReader(w.Path, w.OutputCompat, w.SQLite)
+	assert.NoError(t, err)
+	defer reader.Close()
+	var share float64
+	assert.NoError(t, reader.DB.QueryRow("SELECT token_share FROM liveattrs_stats WHERE doc_id = 'd1'").Scan(&share))
+	assert.InDelta(t, 0.25, share, 1e-9)
+	var ipm float64
+	assert.NoError(t, reader.DB.QueryRow("SELECT ipm FROM colcounts_ipm WHERE col0 = 'word'").Scan(&ipm))
+	assert.InDelta(t, 2000.0, ipm, 1e-9)
+}
+
+func TestColCountsAddToCorpus(t *testing.T) {
+	w := newTestWriter(t)
+	assert.NoError(t, w.Initialize(false))
+	ins, err := w.PrepareInsert(db.ColCountsTable, []string{"hash_id", "col0", "count", "arf", "corpus_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("h1", "word", 4, 2.5, "corp"))
+	assert.NoError(t, ins.Exec("h1", "word", 2, 1.5, "corp2"))
+	assert.NoError(t, ins.Exec("h1", "word", 3, 1.5, "corp"))
+	var count int
+	var arf float64
+	err = w.tx.QueryRow("SELECT count, arf FROM colcounts WHERE corpus_id = 'corp'").Scan(&count, &arf)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, count)
+	assert.Equal(t, -1.0, arf)
+	err = w.tx.QueryRow("SELECT count, arf FROM colcounts WHERE corpus_id = 'corp2'").Scan(&count, &arf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 1.5, arf)
+	assert.NoError(t, w.Rollback())
+	w.Close()
+}
+
+func TestHistory(t *testing.T) {
+	w := newTestWriter(t)
+	w.History = true
+	insertDoc := func(hw db.Writer, appendMode bool, docID string) {
+		assert.NoError(t, hw.Initialize(appendMode))
+		ins, err := hw.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "poscount", "corpus_id"})
+		assert.NoError(t, err)
+		assert.NoError(t, ins.Exec(docID, 10, "corp"))
+		assert.NoError(t, hw.Commit())
+		hw.Close()
+	}
+	insertDoc(db.WithHistory(w, "corp", "run1"), false, "d1")
+	w2 := *w
+	insertDoc(db.WithHistory(&w2, "corp", "run2"), true, "d2")
+
+	reader, err := OpenReader(w.Path, w.OutputCompat, w.SQLite)
+	assert.NoError(t, err)
+	defer reader.Close()
+	rows, err := reader.DB.Query("SELECT run_id, op, IFNULL(data, '') FROM liveattrs_history ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	ans := make([]string, 0, 3)
+	for rows.Next() {
+		var runID, op, data string
+		assert.NoError(t, rows.Scan(&runID, &op, &data))
+		ans = append(ans, runID+" "+op+" "+data)
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"run1 delete ",
+			`run1 insert {"corpus_id":"corp","doc_id":"d1","poscount":10}`,
+			`run2 insert {"corpus_id":"corp","doc_id":"d2","poscount":10}`,
+		},
+		ans,
+	)
+}
@@ -24,35 +24,88 @@ liveattrs (tables and their indices, views)
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/czcorpus/vert-tagextract/v3/db"
 
-	_ "github.com/mattn/go-sqlite3" // load the driver
+	"github.com/mattn/go-sqlite3"
 )
 
+var (
+	customDrivers   = make(map[string]string)
+	customDriversMu sync.Mutex
+)
+
+// driverName returns a name of a registered sql driver loading
+// configured extensions and ICU collations into each connection.
+// As database/sql does not allow registering a driver twice,
+// drivers are cached by their configuration.
+func driverName(conf db.SQLiteConf) string {
+	if !conf.IsConfigured() {
+		return "sqlite3"
+	}
+	customDriversMu.Lock()
+	defer customDriversMu.Unlock()
+	key := conf.Key()
+	if name, ok := customDrivers[key]; ok {
+		return name
+	}
+	name := fmt.Sprintf("sqlite3_vte_%d", len(customDrivers))
+	collations := conf.ICUCollations
+	sql.Register(name, &sqlite3.SQLiteDriver{
+		Extensions: conf.Extensions,
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for collName, locale := range collations {
+				_, err := conn.Exec("SELECT icu_load_collation(?, ?)", []driver.Value{locale, collName})
+				if err != nil {
+					return fmt.Errorf("failed to load ICU collation %s (%s): %w", collName, locale, err)
+				}
+			}
+			return nil
+		},
+	})
+	customDrivers[key] = name
+	return name
+}
+
 // openDatabase opens a sqlite3 database specified by
 // its filesystem path. In case of an error it panics.
-func openDatabase(dbPath string) (*sql.DB, error) {
+func openDatabase(dbPath string, conf db.SQLiteConf) (*sql.DB, error) {
 	var err error
-	if db, err := sql.Open("sqlite3", dbPath); err == nil {
+	if db, err := sql.Open(driverName(conf), dbPath); err == nil {
 		return db, nil
 	}
 	return nil, fmt.Errorf("failed to open text types db: %s", err)
 }
 
+// columnDef creates a TEXT column definition with an optional collation
+func columnDef(col string, collations map[string]string) string {
+	if coll, ok := collations[col]; ok {
+		return fmt.Sprintf("%s TEXT COLLATE %s", col, coll)
+	}
+	return col + " TEXT"
+}
+
 // prepareInsert creates a prepared statement for an INSERT
 // operation.
-func prepareInsert(database *sql.Tx, table string, cols []string) (*sql.Stmt, error) {
+// colCountsOnConflict makes inserted colcounts rows of n-grams already
+// stored for the corpus (see cnf.ExistingCorpusAdd) increase the stored
+// count. ARF values of different runs cannot be combined so the ARF
+// of such n-grams is stored as unknown.
+const colCountsOnConflict = " ON CONFLICT DO UPDATE SET count = count + excluded.count, arf = -1"
+
+func prepareInsert(database *sql.Tx, table string, cols []string, onConflict string) (*sql.Stmt, error) {
 	valReplac := make([]string, len(cols))
 	for i := range cols {
 		valReplac[i] = "?"
 	}
 	ans, err := database.Prepare(
-		fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, joinArgs(cols), joinArgs(valReplac)))
+		fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s", table, joinArgs(cols), joinArgs(valReplac), onConflict))
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare INSERT: %s", err)
 	}
@@ -117,25 +170,53 @@ func generateViewColDefs(cols []string, idAttr string) []string {
 
 // createBibView creates a database view needed
 // by liveattrs to fetch bibliography information.
-func createBibView(database *sql.DB, cols []string, idAttr string) error {
+func createBibView(database *sql.DB, laTable string, cols []string, idAttr string) error {
 	colDefs := generateViewColDefs(cols, idAttr)
-	_, err := database.Exec(fmt.Sprintf("CREATE VIEW bibliography AS SELECT %s FROM liveattrs_entry", joinArgs(colDefs)))
+	_, err := database.Exec(fmt.Sprintf("CREATE VIEW bibliography AS SELECT %s FROM %s", joinArgs(colDefs), laTable))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func createAuxIndices(database *sql.DB, cols []string) error {
+// createHelperViews creates helper views (see db.HelperViews)
+// joining data tables with corpus totals
+func createHelperViews(database *sql.DB, laTable string, views []string) error {
+	for _, v := range views {
+		var query string
+		switch v {
+		case db.LiveAttrsStatsView:
+			query = fmt.Sprintf(
+				"CREATE VIEW %s AS SELECT la.*, s.value AS corpus_tokens, "+
+					"CAST(la.poscount AS REAL) / s.value AS token_share "+
+					"FROM %s AS la LEFT JOIN %s AS s ON s.corpus_id = la.corpus_id AND s.name = '%s'",
+				v, laTable, db.StatsTable, db.StatsTokens)
+		case db.ColcountsIPMView:
+			query = fmt.Sprintf(
+				"CREATE VIEW %s AS SELECT c.*, c.count * 1000000.0 / s.value AS ipm "+
+					"FROM colcounts AS c LEFT JOIN %s AS s ON s.corpus_id = c.corpus_id AND s.name = '%s'",
+				v, db.StatsTable, db.StatsTokens)
+		default:
+			return fmt.Errorf("unsupported helper view %s", v)
+		}
+		if _, err := database.Exec(query); err != nil {
+			return fmt.Errorf("failed to create view '%s': %s", v, err)
+		}
+		log.Info().Str("view", v).Msg("Created helper view")
+	}
+	return nil
+}
+
+func createAuxIndices(database *sql.DB, laTable string, cols []string) error {
 	var err error
 	for _, c := range cols {
-		_, err = database.Exec(fmt.Sprintf("CREATE INDEX %s_idx ON liveattrs_entry(%s)", c, c))
+		_, err = database.Exec(fmt.Sprintf("CREATE INDEX %s_idx ON %s(%s)", c, laTable, c))
 		if err != nil {
 			return err
 		}
 		log.Info().
 			Str("index", c+"_idx").
-			Str("table", "liveattrs_entry").
+			Str("table", laTable).
 			Str("column", c).
 			Msg("Created custom index")
 	}
@@ -145,65 +226,292 @@ func createAuxIndices(database *sql.DB, cols []string) error {
 // dropExisting drops existing tables/views.
 // It is safe to call this even if one or more
 // of these does not exist.
-func dropExisting(database *sql.DB) error {
+func dropExisting(database *sql.DB, laTable string) error {
 	log.Info().Msg("Attempting to drop possible existing tables and views")
 	var err error
-	_, err = database.Exec("DROP TABLE IF EXISTS cache")
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.CacheTable)
 	if err != nil {
-		return fmt.Errorf("failed to drop table 'cache': %s", err)
+		return fmt.Errorf("failed to drop table '%s': %s", db.CacheTable, err)
 	}
 	_, err = database.Exec("DROP VIEW IF EXISTS bibliography")
 	if err != nil {
 		return fmt.Errorf("failed to drop view 'bibliography': %s", err)
 	}
-	_, err = database.Exec("DROP TABLE IF EXISTS liveattrs_entry")
+	for _, v := range db.HelperViews() {
+		_, err = database.Exec("DROP VIEW IF EXISTS " + v)
+		if err != nil {
+			return fmt.Errorf("failed to drop view '%s': %s", v, err)
+		}
+	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + laTable)
 	if err != nil {
-		return fmt.Errorf("failed to drop table 'liveattrs_entry': %s", err)
+		return fmt.Errorf("failed to drop table '%s': %s", laTable, err)
 	}
 	_, err = database.Exec("DROP TABLE IF EXISTS colcounts")
 	if err != nil {
 		return fmt.Errorf("failed to drop table 'colcounts': %s", err)
 	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.RunMetadataTable)
+	if err != nil {
+		return fmt.Errorf("failed to drop table '%s': %s", db.RunMetadataTable, err)
+	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.StatsTable)
+	if err != nil {
+		return fmt.Errorf("failed to drop table '%s': %s", db.StatsTable, err)
+	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.ColumnInfoTable)
+	if err != nil {
+		return fmt.Errorf("failed to drop table '%s': %s", db.ColumnInfoTable, err)
+	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.UDFeatsTable)
+	if err != nil {
+		return fmt.Errorf("failed to drop table '%s': %s", db.UDFeatsTable, err)
+	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.AttrValuesTable)
+	if err != nil {
+		return fmt.Errorf("failed to drop table '%s': %s", db.AttrValuesTable, err)
+	}
+	_, err = database.Exec("DROP TABLE IF EXISTS " + db.AttrPairsTable)
+	if err != nil {
+		return fmt.Errorf("failed to drop table '%s': %s", db.AttrPairsTable, err)
+	}
+	return nil
+}
+
+// createCacheTable creates the liveattrs cache table
+// in case it does not exist yet
+func createCacheTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (key TEXT PRIMARY KEY, value TEXT)", db.CacheTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.CacheTable, err)
+	}
+	return nil
+}
+
+// clearCache removes all the cached liveattrs data
+// as they are not valid once new data are added
+func clearCache(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM " + db.CacheTable)
+	if err != nil {
+		return fmt.Errorf("failed to clear table '%s': %s", db.CacheTable, err)
+	}
+	return nil
+}
+
+// createRunMetadataTable creates a table for run metadata
+// in case it does not exist yet (which may be the case
+// for databases created by older versions)
+func createRunMetadataTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (corpus_id TEXT, name TEXT, value TEXT, PRIMARY KEY(corpus_id, name))",
+		db.RunMetadataTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.RunMetadataTable, err)
+	}
+	return nil
+}
+
+// setRunMetadata inserts or replaces run metadata for a corpus
+func setRunMetadata(tx *sql.Tx, corpusID string, values map[string]string) error {
+	for k, v := range values {
+		_, err := tx.Exec(
+			fmt.Sprintf("INSERT OR REPLACE INTO %s (corpus_id, name, value) VALUES (?, ?, ?)", db.RunMetadataTable),
+			corpusID, k, v)
+		if err != nil {
+			return fmt.Errorf("failed to set run metadata: %s", err)
+		}
+	}
+	return nil
+}
+
+// createStatsTable creates a table for aggregate totals
+// in case it does not exist yet
+func createStatsTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (corpus_id TEXT, name TEXT, value INTEGER, PRIMARY KEY(corpus_id, name))",
+		db.StatsTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.StatsTable, err)
+	}
+	return nil
+}
+
+// setStats inserts or replaces aggregate totals for a corpus
+func setStats(tx *sql.Tx, corpusID string, values map[string]int) error {
+	for k, v := range values {
+		_, err := tx.Exec(
+			fmt.Sprintf("INSERT OR REPLACE INTO %s (corpus_id, name, value) VALUES (?, ?, ?)", db.StatsTable),
+			corpusID, k, v)
+		if err != nil {
+			return fmt.Errorf("failed to set stats: %s", err)
+		}
+	}
+	return nil
+}
+
+// createColumnInfoTable creates a table describing columns
+// in case it does not exist yet
+func createColumnInfoTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (corpus_id TEXT, table_name TEXT, column_name TEXT, "+
+			"source TEXT, structure TEXT, attr TEXT, modifiers TEXT, description TEXT, "+
+			"PRIMARY KEY(corpus_id, table_name, column_name))",
+		db.ColumnInfoTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.ColumnInfoTable, err)
+	}
+	return nil
+}
+
+// createHistoryTable creates a table of liveattrs changes (see db.HistoryConf).
+// The table is not dropped by dropExisting as it spans more runs.
+func createHistoryTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY AUTOINCREMENT, run_id TEXT, "+
+			"corpus_id TEXT, op TEXT, changed_at TEXT, data TEXT)",
+		db.LiveAttrsHistoryTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.LiveAttrsHistoryTable, err)
+	}
+	_, err = database.Exec(fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS %s_corpus_id_changed_at_idx ON %s(corpus_id, changed_at)",
+		db.LiveAttrsHistoryTable, db.LiveAttrsHistoryTable))
+	if err != nil {
+		return fmt.Errorf("failed to create index on '%s': %s", db.LiveAttrsHistoryTable, err)
+	}
+	return nil
+}
+
+// setColumnInfo replaces descriptions of columns for a corpus
+func setColumnInfo(tx *sql.Tx, corpusID string, values []db.ColumnInfo) error {
+	_, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE corpus_id = ?", db.ColumnInfoTable), corpusID)
+	if err != nil {
+		return fmt.Errorf("failed to set column info: %s", err)
+	}
+	for _, v := range values {
+		_, err := tx.Exec(
+			fmt.Sprintf(
+				"INSERT INTO %s (corpus_id, table_name, column_name, source, structure, attr, modifiers, "+
+					"description) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+				db.ColumnInfoTable),
+			corpusID, v.Table, v.Column, v.Source, v.Structure, v.Attr,
+			strings.Join(v.Modifiers, ","), v.Description)
+		if err != nil {
+			return fmt.Errorf("failed to set column info: %s", err)
+		}
+	}
+	return nil
+}
+
+// createAttrValuesTable creates a table of structural attribute
+// values in case it does not exist yet
+func createAttrValuesTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (corpus_id TEXT, attr TEXT, value TEXT, "+
+			"num_atoms INTEGER, num_tokens INTEGER, PRIMARY KEY(corpus_id, attr, value))",
+		db.AttrValuesTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.AttrValuesTable, err)
+	}
+	return nil
+}
+
+// addAttrValues adds numbers of atoms and tokens
+// of attribute values to the stored ones
+func addAttrValues(tx *sql.Tx, corpusID string, values []db.AttrValueCount) error {
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT INTO %s (corpus_id, attr, value, num_atoms, num_tokens) VALUES (?, ?, ?, ?, ?) "+
+			"ON CONFLICT(corpus_id, attr, value) DO UPDATE SET "+
+			"num_atoms = num_atoms + excluded.num_atoms, num_tokens = num_tokens + excluded.num_tokens",
+		db.AttrValuesTable))
+	if err != nil {
+		return fmt.Errorf("failed to add attribute values: %s", err)
+	}
+	defer stmt.Close()
+	for _, v := range values {
+		if _, err := stmt.Exec(corpusID, v.Attr, v.Value, v.NumAtoms, v.NumTokens); err != nil {
+			return fmt.Errorf("failed to add attribute values: %s", err)
+		}
+	}
+	return nil
+}
+
+// createAttrPairsTable creates a table of structural attribute
+// value pairs in case it does not exist yet
+func createAttrPairsTable(database *sql.DB) error {
+	_, err := database.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (corpus_id TEXT, attr1 TEXT, value1 TEXT, attr2 TEXT, value2 TEXT, "+
+			"num_atoms INTEGER, num_tokens INTEGER, PRIMARY KEY(corpus_id, attr1, value1, attr2, value2))",
+		db.AttrPairsTable))
+	if err != nil {
+		return fmt.Errorf("failed to create table '%s': %s", db.AttrPairsTable, err)
+	}
+	return nil
+}
+
+// addAttrPairs adds numbers of atoms and tokens
+// of attribute value pairs to the stored ones
+func addAttrPairs(tx *sql.Tx, corpusID string, values []db.AttrPairCount) error {
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT INTO %s (corpus_id, attr1, value1, attr2, value2, num_atoms, num_tokens) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?) "+
+			"ON CONFLICT(corpus_id, attr1, value1, attr2, value2) DO UPDATE SET "+
+			"num_atoms = num_atoms + excluded.num_atoms, num_tokens = num_tokens + excluded.num_tokens",
+		db.AttrPairsTable))
+	if err != nil {
+		return fmt.Errorf("failed to add attribute pairs: %s", err)
+	}
+	defer stmt.Close()
+	for _, v := range values {
+		_, err := stmt.Exec(corpusID, v.Attr1, v.Value1, v.Attr2, v.Value2, v.NumAtoms, v.NumTokens)
+		if err != nil {
+			return fmt.Errorf("failed to add attribute pairs: %s", err)
+		}
+	}
 	return nil
 }
 
 // createSchema creates all the required tables, views and indices
 func createSchema(
 	database *sql.DB,
+	laTable string,
 	structures map[string][]string,
 	indexedCols []string,
 	useSelfJoin bool,
 	countColumns db.VertColumns,
+	collations map[string]string,
+	primaryKey string,
 ) error {
 	log.Info().Msg("Attempting to create tables and views")
 
 	var dbErr error
-	_, dbErr = database.Exec("CREATE TABLE cache (key TEXT PRIMARY KEY, value TEXT)")
-	if dbErr != nil {
-		return fmt.Errorf("failed to create table 'cache': %s", dbErr)
-	}
-
 	cols := generateColNames(structures)
 	colsDefs := make([]string, len(cols))
 	for i, col := range cols {
-		colsDefs[i] = fmt.Sprintf("%s TEXT", col)
+		colsDefs[i] = columnDef(col, collations)
 	}
 	auxColDefs := generateAuxColDefs(useSelfJoin)
 	allCollsDefs := append(colsDefs, auxColDefs...)
-	_, dbErr = database.Exec(fmt.Sprintf("CREATE TABLE liveattrs_entry (id INTEGER PRIMARY KEY AUTOINCREMENT, %s)", joinArgs(allCollsDefs)))
+	idDef := "id INTEGER PRIMARY KEY AUTOINCREMENT"
+	if db.IsDeterministicPrimaryKey(primaryKey) {
+		idDef = "id INTEGER PRIMARY KEY"
+	}
+	_, dbErr = database.Exec(fmt.Sprintf(
+		"CREATE TABLE %s (%s, %s)", laTable, idDef, joinArgs(allCollsDefs)))
 	if dbErr != nil {
-		return fmt.Errorf("failed to create table 'liveattrs_entry': %s", dbErr)
+		return fmt.Errorf("failed to create table '%s': %s", laTable, dbErr)
 	}
 
 	if useSelfJoin {
 		_, dbErr = database.Exec(
-			"CREATE UNIQUE INDEX item_id_corpus_id_idx ON liveattrs_entry(item_id, corpus_id)")
+			fmt.Sprintf("CREATE UNIQUE INDEX item_id_corpus_id_idx ON %s(item_id, corpus_id)", laTable))
 		if dbErr != nil {
 			return fmt.Errorf(
-				"failed to create index item_id_idx on liveattrs_entry(item_id): %s", dbErr)
+				"failed to create index item_id_idx on %s(item_id): %s", laTable, dbErr)
 		}
 	}
-	dbErr = createAuxIndices(database, indexedCols)
+	dbErr = createAuxIndices(database, laTable, indexedCols)
 	if dbErr != nil {
 		return fmt.Errorf("failed to create a custom index: %s", dbErr)
 	}
@@ -211,10 +519,10 @@ func createSchema(
 	if len(countColumns) > 0 {
 		colDefs := db.GenerateColCountNames(countColumns)
 		for i, c := range colDefs {
-			colDefs[i] = c + " TEXT"
+			colDefs[i] = columnDef(c, collations)
 		}
 		_, dbErr = database.Exec(fmt.Sprintf(
-			"CREATE TABLE colcounts (hash_id varchar(40), %s, corpus_id TEXT, count INTEGER, arf INTEGER, PRIMARY KEY(hash_id))",
+			"CREATE TABLE colcounts (hash_id varchar(40), %s, corpus_id TEXT, count INTEGER, arf INTEGER, PRIMARY KEY(hash_id, corpus_id))",
 			strings.Join(colDefs, ", ")))
 		if dbErr != nil {
 			return fmt.Errorf("failed to create table 'colcounts': %s", dbErr)
@@ -224,5 +532,111 @@ func createSchema(
 			return fmt.Errorf("failed to create index colcounts_corpus_id_idx on colcounts(corpus_id): %s", dbErr)
 		}
 	}
+	if len(countColumns.ExplodedUDFeats()) > 0 {
+		_, dbErr = database.Exec(fmt.Sprintf(
+			"CREATE TABLE %s (corpus_id TEXT, col INTEGER, name TEXT, value TEXT, count INTEGER)",
+			db.UDFeatsTable))
+		if dbErr != nil {
+			return fmt.Errorf("failed to create table '%s': %s", db.UDFeatsTable, dbErr)
+		}
+		_, dbErr = database.Exec(fmt.Sprintf(
+			"CREATE INDEX %s_corpus_id_idx ON %s(corpus_id)", db.UDFeatsTable, db.UDFeatsTable))
+		if dbErr != nil {
+			return fmt.Errorf(
+				"failed to create index %s_corpus_id_idx on %s(corpus_id): %s", db.UDFeatsTable, db.UDFeatsTable, dbErr)
+		}
+	}
+	return nil
+}
+
+// readSchema reads declared column types of provided tables (referred
+// by their logical names). Tables which do not exist are not present
+// in the result.
+func readSchema(
+	database *sql.DB,
+	outputCompat string,
+	tables []db.TableSchema,
+) (map[string]map[string]string, error) {
+	ans := make(map[string]map[string]string)
+	for _, table := range tables {
+		rows, err := database.Query(
+			fmt.Sprintf("PRAGMA table_info(%s)", db.TableName(table.Name, outputCompat)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema of %s: %w", table.Name, err)
+		}
+		cols := make(map[string]string)
+		for rows.Next() {
+			var cid, notNull, pk int
+			var name, declType string
+			var dflt sql.NullString
+			if err := rows.Scan(&cid, &name, &declType, &notNull, &dflt, &pk); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to read schema of %s: %w", table.Name, err)
+			}
+			cols[name] = declType
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema of %s: %w", table.Name, err)
+		}
+		if len(cols) > 0 {
+			ans[table.Name] = cols
+		}
+	}
+	return ans, nil
+}
+
+// MigrationSQL returns statements applying a schema change (see db.SchemaChanges).
+// The laTable argument is a name of the liveattrs table in the target version.
+// Changes which cannot (or need not) be applied by SQL are returned as comments.
+func MigrationSQL(change db.SchemaChange, laTable string, bibView db.BibViewConf) []string {
+	switch change.Kind {
+	case db.SchemaChangeRenameTable:
+		return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", change.Table, change.NewName)}
+	case db.SchemaChangeRecreateView:
+		if !bibView.IsConfigured() {
+			return []string{}
+		}
+		return []string{
+			fmt.Sprintf("DROP VIEW IF EXISTS %s;", change.Table),
+			fmt.Sprintf(
+				"CREATE VIEW %s AS SELECT %s FROM %s;",
+				change.Table, joinArgs(generateViewColDefs(bibView.Cols, bibView.IDAttr)), laTable),
+		}
+	}
+	return []string{"-- " + change.String()}
+}
+
+// optimizeDatabase runs the configured post-commit optimizations.
+// As SQLite cannot compact individual tables, the whole database
+// is vacuumed in case any table is configured to be compacted.
+func optimizeDatabase(database *sql.DB, conf db.OptimizeConf, outputCompat string) error {
+	if len(conf.Compact) > 0 {
+		if _, err := database.Exec("VACUUM"); err != nil {
+			return fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		log.Info().Msg("Vacuumed database")
+	}
+	analyzed := make([]string, 0, 10)
+	for _, t := range db.OptimizedTables(conf.Analyze) {
+		name := db.TableName(t, outputCompat)
+		var exists bool
+		err := database.QueryRow(
+			"SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to analyze table %s: %w", name, err)
+		}
+		if !exists {
+			continue
+		}
+		if _, err := database.Exec(fmt.Sprintf("ANALYZE `%s`", name)); err != nil {
+			return fmt.Errorf("failed to analyze table %s: %w", name, err)
+		}
+		analyzed = append(analyzed, name)
+	}
+	if len(analyzed) > 0 {
+		log.Info().Strs("tables", analyzed).Msg("Analyzed tables")
+	}
 	return nil
 }
@@ -69,7 +69,7 @@ func TestGenerateViewColDefs(t *testing.T) {
 func TestCreateSchema(t *testing.T) {
 	database := createDatabase()
 	structs := createStructures()
-	createSchema(database, structs, []string{}, false, db.VertColumns{{Idx: 1}})
+	createSchema(database, db.LiveAttrsTable, structs, []string{}, false, db.VertColumns{{Idx: 1}}, nil, "")
 	// cid name type notnull dflt_value pk
 	res, err := database.Query("PRAGMA table_info(liveattrs_entry)")
 	if err != nil {
@@ -107,7 +107,7 @@ func TestDropExisdting(t *testing.T) {
 	db.Exec("CREATE TABLE cache (key TEXT PRIMARY KEY, value TEXT")
 	db.Exec("CREATE TABLE liveattrs_entry (id INT PRIMARY KEY, name TEXT")
 	db.Exec("CREATE VIEW bibliography AS SELECT * FROM liveattrs_entry")
-	dropExisting(db)
+	dropExisting(db, "liveattrs_entry")
 
 	res, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table'")
 	if err != nil {
@@ -127,7 +127,7 @@ func TestDropExisdting(t *testing.T) {
 func TestCreateBibView(t *testing.T) {
 	db := createDatabase()
 	db.Exec("CREATE TABLE liveattrs_entry (id INT PRIMARY KEY, doc_id TEXT, doc_year TEXT, doc_author TEXT)")
-	createBibView(db, []string{"doc_id", "doc_author"}, "doc_id")
+	createBibView(db, "liveattrs_entry", []string{"doc_id", "doc_author"}, "doc_id")
 
 	res, err := db.Query("PRAGMA table_info(bibliography)")
 	if err != nil {
@@ -154,3 +154,27 @@ func TestCreateBibView(t *testing.T) {
 	assert.Equal(t, 2, len(colTest))
 
 }
+
+func TestCreateSchemaCollations(t *testing.T) {
+	database := createDatabase()
+	database.SetMaxOpenConns(1)
+	structs := createStructures()
+	err := createSchema(
+		database, db.LiveAttrsTable, structs, []string{}, false, db.VertColumns{},
+		map[string]string{"doc_author": "NOCASE"}, "")
+	assert.NoError(t, err)
+	for _, v := range []string{"b", "A", "C"} {
+		_, err := database.Exec("INSERT INTO liveattrs_entry (doc_author) VALUES (?)", v)
+		assert.NoError(t, err)
+	}
+	rows, err := database.Query("SELECT doc_author FROM liveattrs_entry ORDER BY doc_author")
+	assert.NoError(t, err)
+	defer rows.Close()
+	ans := make([]string, 0, 3)
+	for rows.Next() {
+		var v string
+		assert.NoError(t, rows.Scan(&v))
+		ans = append(ans, v)
+	}
+	assert.Equal(t, []string{"A", "b", "C"}, ans)
+}
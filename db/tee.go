@@ -0,0 +1,185 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TeeTarget is a named Writer used by Tee. The name
+// is used only to identify the target in error messages.
+type TeeTarget struct {
+	Name   string
+	Writer Writer
+}
+
+// Tee returns a Writer passing all the data to all the targets.
+// Operations are applied to all the targets even if some of them
+// fail and all the errors are returned (joined).
+func Tee(targets ...TeeTarget) Writer {
+	return &teeWriter{targets: targets}
+}
+
+type teeWriter struct {
+	targets []TeeTarget
+}
+
+// each calls fn for all the targets and joins their errors
+func (tw *teeWriter) each(fn func(w Writer) error) error {
+	var errs []error
+	for _, t := range tw.targets {
+		if err := fn(t.Writer); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DatabaseExists returns true only if databases
+// of all the targets exist
+func (tw *teeWriter) DatabaseExists() bool {
+	for _, t := range tw.targets {
+		if !t.Writer.DatabaseExists() {
+			return false
+		}
+	}
+	return len(tw.targets) > 0
+}
+
+func (tw *teeWriter) Initialize(appendMode bool) error {
+	return tw.each(func(w Writer) error {
+		return w.Initialize(appendMode)
+	})
+}
+
+func (tw *teeWriter) PrepareInsert(table string, attrs []string) (InsertOperation, error) {
+	ins := make([]InsertOperation, 0, len(tw.targets))
+	err := tw.each(func(w Writer) error {
+		op, err := w.PrepareInsert(table, attrs)
+		if err != nil {
+			return err
+		}
+		ins = append(ins, op)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &teeInsert{ins: ins, targets: tw.targets}, nil
+}
+
+func (tw *teeWriter) SetRunMetadata(corpusID string, values map[string]string) error {
+	return tw.each(func(w Writer) error {
+		return w.SetRunMetadata(corpusID, values)
+	})
+}
+
+func (tw *teeWriter) SetStats(corpusID string, values map[string]int) error {
+	return tw.each(func(w Writer) error {
+		return w.SetStats(corpusID, values)
+	})
+}
+
+func (tw *teeWriter) AddAttrValues(corpusID string, values []AttrValueCount) error {
+	return tw.each(func(w Writer) error {
+		return w.AddAttrValues(corpusID, values)
+	})
+}
+
+func (tw *teeWriter) AddAttrPairs(corpusID string, values []AttrPairCount) error {
+	return tw.each(func(w Writer) error {
+		return w.AddAttrPairs(corpusID, values)
+	})
+}
+
+func (tw *teeWriter) SetColumnInfo(corpusID string, values []ColumnInfo) error {
+	return tw.each(func(w Writer) error {
+		return w.SetColumnInfo(corpusID, values)
+	})
+}
+
+// DeleteCorpus returns numbers of deleted rows
+// reported by the first target
+func (tw *teeWriter) DeleteCorpus(corpusID string) (map[string]int, error) {
+	var ans map[string]int
+	err := tw.each(func(w Writer) error {
+		deleted, err := w.DeleteCorpus(corpusID)
+		if ans == nil {
+			ans = deleted
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+func (tw *teeWriter) Commit() error {
+	return tw.each(func(w Writer) error {
+		return w.Commit()
+	})
+}
+
+func (tw *teeWriter) Savepoint(name string) error {
+	return tw.each(func(w Writer) error {
+		return w.Savepoint(name)
+	})
+}
+
+func (tw *teeWriter) RollbackToSavepoint(name string) error {
+	return tw.each(func(w Writer) error {
+		return w.RollbackToSavepoint(name)
+	})
+}
+
+func (tw *teeWriter) Rollback() error {
+	return tw.each(func(w Writer) error {
+		return w.Rollback()
+	})
+}
+
+func (tw *teeWriter) Close() {
+	for _, t := range tw.targets {
+		t.Writer.Close()
+	}
+}
+
+type teeInsert struct {
+	ins     []InsertOperation
+	targets []TeeTarget
+}
+
+// Exec passes a copy of values to each of the inserts
+// as they are allowed to modify the values
+func (ti *teeInsert) Exec(values ...any) error {
+	return ti.ExecContext(context.Background(), values...)
+}
+
+func (ti *teeInsert) ExecContext(ctx context.Context, values ...any) error {
+	var errs []error
+	for i, ins := range ti.ins {
+		tmp := make([]any, len(values))
+		copy(tmp, values)
+		if err := ins.ExecContext(ctx, tmp...); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ti.targets[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
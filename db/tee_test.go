@@ -0,0 +1,95 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingInsert struct {
+	rows [][]any
+}
+
+func (ri *recordingInsert) Exec(values ...any) error {
+	ri.rows = append(ri.rows, append([]any{}, values...))
+	values[0] = "modified"
+	return nil
+}
+
+func (ri *recordingInsert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ri.Exec(values...)
+}
+
+type stubWriter struct {
+	Writer
+	exists    bool
+	commitErr error
+	committed bool
+	ins       recordingInsert
+}
+
+func (sw *stubWriter) DatabaseExists() bool { return sw.exists }
+func (sw *stubWriter) PrepareInsert(table string, attrs []string) (InsertOperation, error) {
+	return &sw.ins, nil
+}
+func (sw *stubWriter) Commit() error { sw.committed = true; return sw.commitErr }
+func (sw *stubWriter) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return map[string]int{LiveAttrsTable: 2}, nil
+}
+
+func TestTeeInsert(t *testing.T) {
+	w1, w2 := &stubWriter{exists: true}, &stubWriter{}
+	tee := Tee(TeeTarget{Name: "w1", Writer: w1}, TeeTarget{Name: "w2", Writer: w2})
+	assert.False(t, tee.DatabaseExists())
+	ins, err := tee.PrepareInsert(LiveAttrsTable, []string{"doc_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1"))
+	assert.Equal(t, [][]any{{"d1"}}, w1.ins.rows)
+	assert.Equal(t, [][]any{{"d1"}}, w2.ins.rows)
+}
+
+func TestTeeCommitAggregatesErrors(t *testing.T) {
+	w1 := &stubWriter{commitErr: fmt.Errorf("disk full")}
+	w2 := &stubWriter{commitErr: fmt.Errorf("connection lost")}
+	w3 := &stubWriter{}
+	err := Tee(
+		TeeTarget{Name: "w1", Writer: w1},
+		TeeTarget{Name: "w2", Writer: w2},
+		TeeTarget{Name: "w3", Writer: w3},
+	).Commit()
+	assert.EqualError(t, err, "w1: disk full\nw2: connection lost")
+	assert.True(t, w1.committed && w2.committed && w3.committed)
+}
+
+func TestTeeInsertCancelled(t *testing.T) {
+	w1, w2 := &stubWriter{}, &stubWriter{}
+	tee := Tee(TeeTarget{Name: "w1", Writer: w1}, TeeTarget{Name: "w2", Writer: w2})
+	ins, err := tee.PrepareInsert(LiveAttrsTable, []string{"doc_id"})
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, ins.ExecContext(ctx, "d1"), context.Canceled)
+	assert.Empty(t, w1.ins.rows)
+	assert.Empty(t, w2.ins.rows)
+}
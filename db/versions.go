@@ -0,0 +1,146 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// SchemaChangeRenameTable - a table has been renamed (Table -> NewName)
+	SchemaChangeRenameTable = "renameTable"
+
+	// SchemaChangeAddTable - a new table has been introduced
+	SchemaChangeAddTable = "addTable"
+
+	// SchemaChangeRecreateView - a view must be recreated as it refers
+	// to a changed table
+	SchemaChangeRecreateView = "recreateView"
+
+	// BibViewName is a logical name of the bibliography view
+	BibViewName = "bibliography"
+)
+
+// SchemaChange describes a single difference between schemas
+// generated by different major versions. Table names are logical,
+// i.e. without any backend-specific prefix.
+type SchemaChange struct {
+	Kind    string `json:"kind"`
+	Table   string `json:"table"`
+	NewName string `json:"newName,omitempty"`
+
+	// Automatic specifies that the change is applied by vte
+	// itself during the next create/append run
+	Automatic bool `json:"automatic"`
+
+	Note string `json:"note,omitempty"`
+}
+
+func (sc SchemaChange) String() string {
+	var ans strings.Builder
+	switch sc.Kind {
+	case SchemaChangeRenameTable:
+		fmt.Fprintf(&ans, "rename table %s to %s", sc.Table, sc.NewName)
+	case SchemaChangeAddTable:
+		fmt.Fprintf(&ans, "add table %s", sc.Table)
+	case SchemaChangeRecreateView:
+		fmt.Fprintf(&ans, "recreate view %s", sc.Table)
+	default:
+		fmt.Fprintf(&ans, "%s %s", sc.Kind, sc.Table)
+	}
+	if sc.Automatic {
+		ans.WriteString(" (automatic)")
+	}
+	if sc.Note != "" {
+		ans.WriteString(" - " + sc.Note)
+	}
+	return ans.String()
+}
+
+// v2ToV3Changes lists schema changes between databases created
+// by vert-tagextract v2 and v3
+var v2ToV3Changes = []SchemaChange{
+	{
+		Kind:    SchemaChangeRenameTable,
+		Table:   LegacyLiveAttrsTable,
+		NewName: LiveAttrsTable,
+	},
+	{
+		Kind:  SchemaChangeRecreateView,
+		Table: BibViewName,
+		Note:  "only if bibView is configured",
+	},
+	{
+		Kind:      SchemaChangeAddTable,
+		Table:     RunMetadataTable,
+		Automatic: true,
+	},
+	{
+		Kind:      SchemaChangeAddTable,
+		Table:     StatsTable,
+		Automatic: true,
+		Note:      "totals of data inserted before the upgrade are not available",
+	},
+	{
+		Kind:      SchemaChangeAddTable,
+		Table:     ColumnInfoTable,
+		Automatic: true,
+	},
+	{
+		Kind:      SchemaChangeAddTable,
+		Table:     CacheTable,
+		Automatic: true,
+		Note:      "MySQL: the table is now prefixed by the grouped corpus name",
+	},
+	{
+		Kind:  SchemaChangeAddTable,
+		Table: UDFeatsTable,
+		Note:  "only if udFeats: explode is configured (requires a new 'create' run)",
+	},
+}
+
+// SchemaChanges describes how generated schemas changed between
+// two major versions (see OutputCompatV2, OutputCompatV3).
+// For a downgrade, only the changes needed by older consumers
+// are returned (added tables are kept as they are ignored).
+func SchemaChanges(from, to string) ([]SchemaChange, error) {
+	for _, v := range []string{from, to} {
+		if v == "" {
+			return nil, fmt.Errorf("both source and target versions must be specified")
+		}
+		if err := ValidateOutputCompat(v); err != nil {
+			return nil, err
+		}
+	}
+	if from == to {
+		return []SchemaChange{}, nil
+	}
+	if from == OutputCompatV2 {
+		return v2ToV3Changes, nil
+	}
+	ans := make([]SchemaChange, 0, 2)
+	for _, ch := range v2ToV3Changes {
+		switch ch.Kind {
+		case SchemaChangeRenameTable:
+			ans = append(ans, SchemaChange{Kind: ch.Kind, Table: ch.NewName, NewName: ch.Table})
+		case SchemaChangeRecreateView:
+			ans = append(ans, ch)
+		}
+	}
+	return ans, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import "fmt"
+
+const (
+	// LiveAttrsStatsView is a helper view joining LiveAttrsTable rows
+	// with corpus totals (corpus size and a token share of each atom)
+	LiveAttrsStatsView = "liveattrs_stats"
+
+	// ColcountsIPMView is a helper view of colcounts with
+	// n-gram frequencies normalized to instances per million
+	ColcountsIPMView = "colcounts_ipm"
+)
+
+var helperViews = []string{LiveAttrsStatsView, ColcountsIPMView}
+
+// HelperViews lists names of all the supported helper views
+func HelperViews() []string {
+	return helperViews
+}
+
+// ValidateHelperViews tests whether all the views are supported
+// and whether the data they depend on are configured
+func ValidateHelperViews(views []string, hasColcounts bool) error {
+	for _, v := range views {
+		switch v {
+		case LiveAttrsStatsView:
+		case ColcountsIPMView:
+			if !hasColcounts {
+				return fmt.Errorf("helper view %s requires countColumns to be configured", v)
+			}
+		default:
+			return fmt.Errorf("unsupported helper view '%s' (supported: %s, %s)", v, LiveAttrsStatsView, ColcountsIPMView)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+// The NATS client protocol is a simple text-based one so instead
+// of depending on a full-featured client library, we implement
+// just the part needed for publishing.
+
+const (
+	dfltNATSPort = "4222"
+	natsTimeout  = 10 * time.Second
+)
+
+type natsConnectOpts struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// natsConn is a publish-only connection to a NATS server
+type natsConn struct {
+	conn  net.Conn
+	mu    sync.Mutex
+	w     *bufio.Writer
+	err   error
+	pongs chan struct{}
+	done  chan struct{}
+}
+
+// dialNATS connects to a NATS server specified by a URL
+// (nats://[user[:password]@]host[:port])
+func dialNATS(brokerURL string) (*natsConn, error) {
+	u, err := url.Parse(brokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker URL: %w", err)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported broker URL scheme '%s' (supported: nats)", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), dfltNATSPort)
+	}
+	conn, err := net.DialTimeout("tcp", host, natsTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS server %s: %w", host, err)
+	}
+	nc := &natsConn{
+		conn:  conn,
+		w:     bufio.NewWriter(conn),
+		pongs: make(chan struct{}, 16),
+		done:  make(chan struct{}),
+	}
+	if err := nc.handshake(u); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to NATS server %s: %w", host, err)
+	}
+	return nc, nil
+}
+
+func (nc *natsConn) handshake(u *url.URL) error {
+	r := bufio.NewReader(nc.conn)
+	nc.conn.SetDeadline(time.Now().Add(natsTimeout))
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected server greeting %s", strings.TrimSpace(line))
+	}
+	opts := natsConnectOpts{Name: "vert-tagextract", Lang: "go", Version: "3"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts.User = u.User.Username()
+			opts.Pass = pass
+
+		} else {
+			opts.AuthToken = u.User.Username()
+		}
+	}
+	rawOpts, err := sonic.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(nc.w, "CONNECT %s\r\nPING\r\n", rawOpts)
+	if err := nc.w.Flush(); err != nil {
+		return err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			return errors.New(line)
+		}
+	}
+	nc.conn.SetDeadline(time.Time{})
+	go nc.readLoop(r)
+	return nil
+}
+
+func (nc *natsConn) setErr(err error) {
+	nc.mu.Lock()
+	if nc.err == nil {
+		nc.err = err
+	}
+	nc.mu.Unlock()
+}
+
+// readLoop answers server's PINGs and watches for errors
+func (nc *natsConn) readLoop(r *bufio.Reader) {
+	defer close(nc.done)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			nc.setErr(fmt.Errorf("NATS connection closed: %w", err))
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			nc.mu.Lock()
+			nc.w.WriteString("PONG\r\n")
+			nc.w.Flush()
+			nc.mu.Unlock()
+		case line == "PONG":
+			select {
+			case nc.pongs <- struct{}{}:
+			default:
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			nc.setErr(errors.New(line))
+		}
+	}
+}
+
+// Publish sends a message to a subject. The message
+// is buffered so it may not be sent until Flush is called.
+func (nc *natsConn) Publish(subject string, data []byte) error {
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	if nc.err != nil {
+		return nc.err
+	}
+	fmt.Fprintf(nc.w, "PUB %s %d\r\n", subject, len(data))
+	nc.w.Write(data)
+	if _, err := nc.w.WriteString("\r\n"); err != nil {
+		nc.err = err
+		return err
+	}
+	return nil
+}
+
+// Flush sends all the buffered messages and waits
+// for the server to process them
+func (nc *natsConn) Flush() error {
+	nc.mu.Lock()
+	if nc.err != nil {
+		nc.mu.Unlock()
+		return nc.err
+	}
+	nc.w.WriteString("PING\r\n")
+	err := nc.w.Flush()
+	nc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	select {
+	case <-nc.pongs:
+	case <-nc.done:
+	case <-time.After(natsTimeout):
+		return fmt.Errorf("NATS server did not respond in time")
+	}
+	nc.mu.Lock()
+	defer nc.mu.Unlock()
+	return nc.err
+}
+
+// Close flushes buffered messages and closes the connection
+func (nc *natsConn) Close() error {
+	err := nc.Flush()
+	nc.conn.Close()
+	<-nc.done
+	return err
+}
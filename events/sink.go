@@ -0,0 +1,159 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// EventAtom is a type of events containing
+	// metadata of an inserted atom
+	EventAtom = "atom"
+
+	// EventSummary is a type of the event published
+	// once a run is finished (no matter whether successfully)
+	EventSummary = "summary"
+)
+
+// Event is a message published to a broker
+type Event struct {
+	Type     string    `json:"type"`
+	Corpus   string    `json:"corpus"`
+	Datetime time.Time `json:"datetime"`
+
+	// Attrs contains column names and values of an atom
+	// (as written to the db.LiveAttrsTable), EventAtom only
+	Attrs map[string]any `json:"attrs,omitempty"`
+
+	// Summary contains the summary of a run, EventSummary only
+	Summary *proc.Summary `json:"summary,omitempty"`
+}
+
+type publisher interface {
+	Publish(subject string, data []byte) error
+	Close() error
+}
+
+// Sink publishes extraction events to a message broker.
+// Publishing is best-effort - failures are logged but they
+// do not affect the extraction.
+type Sink struct {
+	pub          publisher
+	subject      string
+	corpus       string
+	publishAtoms bool
+
+	// failed is set once publishing fails for the first time
+	// (so only the first failure is logged)
+	failed atomic.Bool
+}
+
+// NewSink connects to a broker configured in conf.Events
+func NewSink(conf *cnf.VTEConf) (*Sink, error) {
+	conn, err := dialNATS(conf.Events.URL)
+	if err != nil {
+		return nil, err
+	}
+	subject := conf.Events.GetSubject(conf.Corpus)
+	log.Info().Str("subject", subject).Msg("Publishing extraction events")
+	return &Sink{
+		pub:          conn,
+		subject:      subject,
+		corpus:       conf.Corpus,
+		publishAtoms: conf.Events.PublishAtoms,
+	}, nil
+}
+
+func (s *Sink) publish(evt Event) {
+	evt.Corpus = s.corpus
+	evt.Datetime = time.Now()
+	data, err := sonic.Marshal(evt)
+	if err == nil {
+		err = s.pub.Publish(s.subject, data)
+	}
+	if err != nil && !s.failed.Swap(true) {
+		log.Warn().Err(err).Str("subject", s.subject).Msg("failed to publish event, further failures are not reported")
+	}
+}
+
+// PublishSummary publishes a summary of a finished run
+func (s *Sink) PublishSummary(summary *proc.Summary) {
+	s.publish(Event{Type: EventSummary, Summary: summary})
+}
+
+// WrapWriter returns a db.Writer publishing inserted atoms
+// (if configured) and passing all the data to w
+func (s *Sink) WrapWriter(w db.Writer) db.Writer {
+	if !s.publishAtoms {
+		return w
+	}
+	return &writer{Writer: w, sink: s}
+}
+
+// Close sends all the pending events and closes
+// the connection to the broker
+func (s *Sink) Close() {
+	if err := s.pub.Close(); err != nil && !s.failed.Load() {
+		log.Warn().Err(err).Str("subject", s.subject).Msg("failed to publish events")
+	}
+}
+
+// writer is a db.Writer decorator publishing inserted atoms
+type writer struct {
+	db.Writer
+	sink *Sink
+}
+
+func (w *writer) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	ins, err := w.Writer.PrepareInsert(table, attrs)
+	if err != nil || table != db.LiveAttrsTable {
+		return ins, err
+	}
+	return &atomInsert{ins: ins, attrs: attrs, sink: w.sink}, nil
+}
+
+type atomInsert struct {
+	ins   db.InsertOperation
+	attrs []string
+	sink  *Sink
+}
+
+func (ai *atomInsert) Exec(values ...any) error {
+	return ai.ExecContext(context.Background(), values...)
+}
+
+func (ai *atomInsert) ExecContext(ctx context.Context, values ...any) error {
+	evt := Event{Type: EventAtom, Attrs: make(map[string]any, len(ai.attrs))}
+	// the underlying insert may modify values
+	for i, attr := range ai.attrs {
+		evt.Attrs[attr] = values[i]
+	}
+	if err := ai.ins.ExecContext(ctx, values...); err != nil {
+		return err
+	}
+	ai.sink.publish(evt)
+	return nil
+}
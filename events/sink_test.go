@@ -0,0 +1,128 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/stretchr/testify/assert"
+)
+
+type nullInsert struct{}
+
+func (ins nullInsert) Exec(values ...any) error {
+	return nil
+}
+
+func (ins nullInsert) ExecContext(ctx context.Context, values ...any) error {
+	return nil
+}
+
+type nullWriter struct {
+	db.Writer
+}
+
+func (w nullWriter) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	return nullInsert{}, nil
+}
+
+// runFakeServer accepts a single connection, performs the NATS
+// handshake and sends all the published payloads to the returned
+// channel (the channel is closed once the client disconnects)
+func runFakeServer(t *testing.T) (string, chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	msgs := make(chan string, 10)
+	go func() {
+		defer close(msgs)
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case line == "PING\r\n":
+				fmt.Fprint(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "PUB "):
+				var subject string
+				var size int
+				fmt.Sscanf(line, "PUB %s %d", &subject, &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				msgs <- subject + " " + string(payload[:size])
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String(), msgs
+}
+
+func TestSinkPublishesEvents(t *testing.T) {
+	url, msgs := runFakeServer(t)
+	conf := &cnf.VTEConf{Corpus: "susanne", Events: cnf.EventsConf{URL: url, PublishAtoms: true}}
+	sink, err := NewSink(conf)
+	assert.NoError(t, err)
+	w := sink.WrapWriter(nullWriter{})
+	ins, err := w.PrepareInsert(db.LiveAttrsTable, []string{"doc_id", "poscount"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("d1", 10))
+	ins, err = w.PrepareInsert("colcounts", []string{"col0"})
+	assert.NoError(t, err)
+	assert.NoError(t, ins.Exec("foo"))
+	sink.PublishSummary(proc.NewSummary("susanne"))
+	sink.Close()
+
+	var received []string
+	for msg := range msgs {
+		received = append(received, msg)
+	}
+	assert.Len(t, received, 2)
+	subject, payload, _ := strings.Cut(received[0], " ")
+	assert.Equal(t, "vte.susanne", subject)
+	var evt Event
+	assert.NoError(t, sonic.UnmarshalString(payload, &evt))
+	assert.Equal(t, EventAtom, evt.Type)
+	assert.Equal(t, "susanne", evt.Corpus)
+	assert.Equal(t, "d1", evt.Attrs["doc_id"])
+	_, payload, _ = strings.Cut(received[1], " ")
+	assert.NoError(t, sonic.UnmarshalString(payload, &evt))
+	assert.Equal(t, EventSummary, evt.Type)
+}
+
+func TestDialNATSInvalidScheme(t *testing.T) {
+	_, err := dialNATS("kafka://localhost:9092")
+	assert.ErrorContains(t, err, "unsupported broker URL scheme")
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fsck verifies consistency of databases generated by vte
+package fsck
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a single problem found in a database
+type Issue struct {
+	Check      string `json:"check"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Report contains results of all the performed checks
+type Report struct {
+	Corpus  string         `json:"corpus"`
+	Corpora map[string]int `json:"corpora"`
+	Checks  []string       `json:"checks"`
+	Issues  []Issue        `json:"issues"`
+}
+
+func (r *Report) addIssue(check, severity, suggestion, msg string, args ...any) {
+	r.Issues = append(r.Issues, Issue{
+		Check:      check,
+		Severity:   severity,
+		Message:    fmt.Sprintf(msg, args...),
+		Suggestion: suggestion,
+	})
+}
+
+// HasErrors tells whether there is at least one issue
+// with SeverityError
+func (r *Report) HasErrors() bool {
+	for _, iss := range r.Issues {
+		if iss.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText writes a human-readable form of the report
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "corpus: %s\n", r.Corpus)
+	corpora := make([]string, 0, len(r.Corpora))
+	for k := range r.Corpora {
+		corpora = append(corpora, k)
+	}
+	sort.Strings(corpora)
+	for _, c := range corpora {
+		fmt.Fprintf(w, "  corpus_id %s: %d rows\n", c, r.Corpora[c])
+	}
+	fmt.Fprintf(w, "performed checks: %d\n", len(r.Checks))
+	if len(r.Issues) == 0 {
+		fmt.Fprintln(w, "no issues found")
+		return
+	}
+	for _, iss := range r.Issues {
+		fmt.Fprintf(w, "[%s] %s: %s\n", iss.Severity, iss.Check, iss.Message)
+		if iss.Suggestion != "" {
+			fmt.Fprintf(w, "    suggestion: %s\n", iss.Suggestion)
+		}
+	}
+}
+
+// countDuplicates returns number of groups (defined by `groupBy`)
+// with more than one row along with an example value of `column`
+func countDuplicates(reader *db.Reader, table, column, groupBy string) (int, string, error) {
+	rows, err := reader.DB.Query(fmt.Sprintf(
+		"SELECT MIN(%s) FROM %s GROUP BY %s HAVING COUNT(*) > 1", column, table, groupBy))
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+	var ans int
+	var example string
+	for rows.Next() {
+		if ans == 0 {
+			var v sql.NullString
+			if err := rows.Scan(&v); err != nil {
+				return 0, "", err
+			}
+			example = v.String
+		}
+		ans++
+	}
+	return ans, example, rows.Err()
+}
+
+// rowsPerCorpus counts rows of a table per corpus_id
+func rowsPerCorpus(reader *db.Reader, table string) (map[string]int, int, error) {
+	rows, err := reader.DB.Query(fmt.Sprintf(
+		"SELECT corpus_id, COUNT(*) FROM %s GROUP BY corpus_id", table))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	ans := make(map[string]int)
+	var numEmpty int
+	for rows.Next() {
+		var corpusID sql.NullString
+		var cnt int
+		if err := rows.Scan(&corpusID, &cnt); err != nil {
+			return nil, 0, err
+		}
+		if corpusID.String == "" {
+			numEmpty += cnt
+
+		} else {
+			ans[corpusID.String] = cnt
+		}
+	}
+	return ans, numEmpty, rows.Err()
+}
+
+func checkCorpusIDs(reader *db.Reader, conf *cnf.VTEConf, report *Report) error {
+	report.Checks = append(report.Checks, "corpusIds")
+	corpora, numEmpty, err := rowsPerCorpus(reader, reader.Table(db.LiveAttrsTable))
+	if err != nil {
+		return err
+	}
+	report.Corpora = corpora
+	if numEmpty > 0 {
+		report.addIssue(
+			"corpusIds", SeverityError,
+			"re-create the database using 'vte create'",
+			"%d rows of %s have no corpus_id", numEmpty, db.LiveAttrsTable)
+	}
+	if _, ok := corpora[conf.CorpusID()]; !ok {
+		report.addIssue(
+			"corpusIds", SeverityError,
+			"run 'vte create' (or 'vte append') with the configuration",
+			"no data found for the configured corpus %s", conf.CorpusID())
+	}
+	return nil
+}
+
+func checkStatsAtoms(reader *db.Reader, conf *cnf.VTEConf, report *Report) error {
+	report.Checks = append(report.Checks, "statsAtoms")
+	stats, err := reader.Stats(conf.CorpusID())
+	if err != nil {
+		return err
+	}
+	numAtoms, ok := stats[db.StatsAtoms]
+	if !ok {
+		report.addIssue(
+			"statsAtoms", SeverityWarning,
+			"re-run the extraction to store corpus totals",
+			"no stored corpus totals for %s", conf.CorpusID())
+		return nil
+	}
+	if numRows := report.Corpora[conf.CorpusID()]; numRows != numAtoms {
+		report.addIssue(
+			"statsAtoms", SeverityWarning,
+			"the database was likely modified after the extraction; consider re-creating it",
+			"stored number of atoms (%d) differs from number of %s rows (%d)",
+			numAtoms, db.LiveAttrsTable, numRows)
+	}
+	return nil
+}
+
+func checkColCounts(reader *db.Reader, report *Report) error {
+	report.Checks = append(report.Checks, "hashIdUniqueness", "colcountsOrphans")
+	table := reader.Table(db.ColCountsTable)
+	numDup, example, err := countDuplicates(reader, table, "hash_id", "hash_id, corpus_id")
+	if err != nil {
+		return err
+	}
+	if numDup > 0 {
+		report.addIssue(
+			"hashIdUniqueness", SeverityError,
+			"re-create the database (older versions did not enforce the uniqueness)",
+			"%d hash_id values of %s are not unique within a corpus (e.g. %s)", numDup, db.ColCountsTable, example)
+	}
+	ccCorpora, numEmpty, err := rowsPerCorpus(reader, table)
+	if err != nil {
+		return err
+	}
+	if numEmpty > 0 {
+		report.addIssue(
+			"colcountsOrphans", SeverityError,
+			fmt.Sprintf("DELETE FROM %s WHERE corpus_id IS NULL OR corpus_id = ''", table),
+			"%d rows of %s have no corpus_id", numEmpty, db.ColCountsTable)
+	}
+	for corpusID, cnt := range ccCorpora {
+		if _, ok := report.Corpora[corpusID]; !ok {
+			report.addIssue(
+				"colcountsOrphans", SeverityWarning,
+				fmt.Sprintf("DELETE FROM %s WHERE corpus_id = '%s'", table, corpusID),
+				"%d rows of %s belong to corpus %s which has no structural data",
+				cnt, db.ColCountsTable, corpusID)
+		}
+	}
+	return nil
+}
+
+func checkBibView(reader *db.Reader, report *Report) error {
+	report.Checks = append(report.Checks, "bibViewIds")
+	numDup, example, err := countDuplicates(reader, reader.Table("bibliography"), "id", "id")
+	if err != nil {
+		return err
+	}
+	if numDup > 0 {
+		report.addIssue(
+			"bibViewIds", SeverityError,
+			"set bibView.idAttr to a structural attribute with unique values and re-create the database",
+			"%d ids of the bibliography view are not unique (e.g. %s)", numDup, example)
+	}
+	return nil
+}
+
+func checkItemIDs(reader *db.Reader, report *Report) error {
+	report.Checks = append(report.Checks, "itemIdUniqueness", "alignedOrphans")
+	table := reader.Table(db.LiveAttrsTable)
+	numDup, example, err := countDuplicates(reader, table, "item_id", "corpus_id, item_id")
+	if err != nil {
+		return err
+	}
+	if numDup > 0 {
+		report.addIssue(
+			"itemIdUniqueness", SeverityError,
+			"adjust selfJoin.argColumns so the generated values are unique within a corpus",
+			"%d item_id values are not unique within their corpus (e.g. %s)", numDup, example)
+	}
+	if len(report.Corpora) < 2 {
+		return nil
+	}
+	rows, err := reader.DB.Query(fmt.Sprintf(
+		"SELECT a.corpus_id, COUNT(*) FROM %s AS a WHERE NOT EXISTS "+
+			"(SELECT 1 FROM %s AS b WHERE b.item_id = a.item_id AND b.corpus_id <> a.corpus_id) "+
+			"GROUP BY a.corpus_id",
+		table, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var corpusID string
+		var cnt int
+		if err := rows.Scan(&corpusID, &cnt); err != nil {
+			return err
+		}
+		report.addIssue(
+			"alignedOrphans", SeverityWarning,
+			"make sure all the aligned corpora were appended and they use the same selfJoin settings",
+			"%d rows of corpus %s have no aligned counterpart in other corpora", cnt, corpusID)
+	}
+	return rows.Err()
+}
+
+// Check verifies consistency of a database generated for a provided
+// configuration. Returned error means that the checks could not be
+// performed, found problems are reported via Report.Issues.
+func Check(reader *db.Reader, conf *cnf.VTEConf) (*Report, error) {
+	report := &Report{Corpus: conf.Corpus, Issues: []Issue{}}
+	if err := checkCorpusIDs(reader, conf, report); err != nil {
+		return nil, fmt.Errorf("failed to check corpus_id values: %w", err)
+	}
+	if err := checkStatsAtoms(reader, conf, report); err != nil {
+		return nil, fmt.Errorf("failed to check corpus totals: %w", err)
+	}
+	if len(conf.Ngrams.VertColumns) > 0 {
+		if err := checkColCounts(reader, report); err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", db.ColCountsTable, err)
+		}
+	}
+	if conf.BibView.IsConfigured() {
+		if err := checkBibView(reader, report); err != nil {
+			return nil, fmt.Errorf("failed to check bibliography view: %w", err)
+		}
+	}
+	if conf.SelfJoin.IsConfigured() {
+		if err := checkItemIDs(reader, report); err != nil {
+			return nil, fmt.Errorf("failed to check item_id values: %w", err)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fsck
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReader(t *testing.T) *db.Reader {
+	database, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.SetMaxOpenConns(1)
+	queries := []string{
+		"CREATE TABLE liveattrs_entry (id INTEGER PRIMARY KEY, doc_id TEXT, corpus_id TEXT, item_id TEXT)",
+		"CREATE TABLE colcounts (hash_id TEXT, col0 TEXT, corpus_id TEXT, count INTEGER, arf INTEGER)",
+		"CREATE TABLE stats (corpus_id TEXT, name TEXT, value INTEGER)",
+		"INSERT INTO liveattrs_entry (doc_id, corpus_id, item_id) VALUES " +
+			"('d1', 'c1', 'a'), ('d2', 'c1', 'b'), ('d1', 'c2', 'a'), ('d2', 'c2', 'a')",
+		"INSERT INTO colcounts VALUES ('h1', 'x', 'c1', 1, 1), ('h1', 'y', 'c1', 1, 1), ('h2', 'z', 'c3', 1, 1)",
+		"INSERT INTO stats VALUES ('c1', 'atoms', 2)",
+	}
+	for _, q := range queries {
+		if _, err := database.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &db.Reader{DB: database}
+}
+
+func TestCheck(t *testing.T) {
+	reader := createTestReader(t)
+	defer reader.Close()
+	conf := &cnf.VTEConf{
+		Corpus:   "c1",
+		Ngrams:   cnf.NgramConf{VertColumns: db.VertColumns{{Idx: 0}}},
+		SelfJoin: db.SelfJoinConf{GeneratorFn: "identity", ArgColumns: []string{"doc_id"}},
+	}
+	report, err := Check(reader, conf)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"c1": 2, "c2": 2}, report.Corpora)
+	checks := make([]string, len(report.Issues))
+	for i, iss := range report.Issues {
+		checks[i] = iss.Check
+	}
+	assert.Equal(
+		t,
+		[]string{"hashIdUniqueness", "colcountsOrphans", "itemIdUniqueness", "alignedOrphans"},
+		checks,
+	)
+	assert.Equal(t, "1 rows of corpus c1 have no aligned counterpart in other corpora", report.Issues[3].Message)
+	assert.True(t, report.HasErrors())
+}
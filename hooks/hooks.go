@@ -0,0 +1,130 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"plugin"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxReportedOutput is a max. number of bytes of a failed
+	// command's output included in the returned error
+	maxReportedOutput = 1000
+)
+
+// PostCommitHook is an interface Go plug-in hooks must implement
+// (the configured symbol is expected to be a variable of a type
+// implementing the interface)
+type PostCommitHook interface {
+	Run(ctx context.Context, summary *proc.Summary) error
+}
+
+// commandEnv returns the process environment extended by
+// variables describing the run
+func commandEnv(conf *cnf.VTEConf) []string {
+	return append(
+		os.Environ(),
+		"VTE_HOOK_CORPUS="+conf.Corpus,
+		"VTE_HOOK_DB_TYPE="+conf.DB.Type,
+		"VTE_HOOK_DB_NAME="+conf.DB.Name,
+	)
+}
+
+func runCommand(ctx context.Context, conf *cnf.VTEConf, command []string, summary *proc.Summary) error {
+	input, err := sonic.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = commandEnv(conf)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		out := strings.TrimSpace(string(output))
+		if len(out) > maxReportedOutput {
+			out = "..." + out[len(out)-maxReportedOutput:]
+		}
+		if out != "" {
+			return fmt.Errorf("command %s failed: %w (output: %s)", command[0], err, out)
+		}
+		return fmt.Errorf("command %s failed: %w", command[0], err)
+	}
+	log.Debug().Str("command", command[0]).Str("output", string(output)).Msg("post-commit hook output")
+	return nil
+}
+
+func loadPlugin(libPath, fn string) (PostCommitHook, error) {
+	fullPath, err := proc.FindPluginLib(libPath)
+	if err != nil {
+		return nil, err
+	}
+	p, err := plugin.Open(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(fn)
+	if err != nil {
+		return nil, err
+	}
+	hook, ok := sym.(PostCommitHook)
+	if !ok {
+		return nil, fmt.Errorf("symbol %s in %s does not implement PostCommitHook", fn, fullPath)
+	}
+	return hook, nil
+}
+
+func runHook(conf *cnf.VTEConf, hookConf cnf.PostCommitHookConf, summary *proc.Summary) error {
+	ctx, cancel := context.WithTimeout(
+		context.Background(), time.Duration(hookConf.GetTimeoutSecs())*time.Second)
+	defer cancel()
+	if len(hookConf.Command) > 0 {
+		return runCommand(ctx, conf, hookConf.Command, summary)
+	}
+	hook, err := loadPlugin(hookConf.Lib, hookConf.Fn)
+	if err != nil {
+		return fmt.Errorf("failed to load hook plug-in: %w", err)
+	}
+	return hook.Run(ctx, summary)
+}
+
+// RunPostCommit runs all the configured post-commit hooks in
+// the configured order. A failed hook does not prevent the other
+// ones from running. Errors of all the failed hooks are returned.
+func RunPostCommit(conf *cnf.VTEConf, summary *proc.Summary) []error {
+	var errs []error
+	for i, hookConf := range conf.PostCommitHooks {
+		t0 := time.Now()
+		if err := runHook(conf, hookConf, summary); err != nil {
+			errs = append(errs, fmt.Errorf("post-commit hook %d: %w", i+1, err))
+			continue
+		}
+		log.Info().Int("hook", i+1).Dur("procTime", time.Since(t0)).Msg("post-commit hook finished")
+	}
+	return errs
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPostCommit(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "summary.json")
+	conf := &cnf.VTEConf{
+		Corpus: "susanne",
+		PostCommitHooks: []cnf.PostCommitHookConf{
+			{Command: []string{"sh", "-c", "echo broken; exit 3"}},
+			{Command: []string{"sh", "-c", "cat > " + out + " && test \"$VTE_HOOK_CORPUS\" = susanne"}},
+		},
+	}
+	summary := proc.NewSummary("susanne")
+	summary.ProcessedAtoms = 42
+	errs := RunPostCommit(conf, summary)
+	assert.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "post-commit hook 1")
+	assert.ErrorContains(t, errs[0], "output: broken")
+
+	data, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	var received proc.Summary
+	assert.NoError(t, sonic.Unmarshal(data, &received))
+	assert.Equal(t, 42, received.ProcessedAtoms)
+}
+
+func TestRunPostCommitTimeout(t *testing.T) {
+	conf := &cnf.VTEConf{
+		PostCommitHooks: []cnf.PostCommitHookConf{{Command: []string{"sleep", "10"}, TimeoutSecs: 1}},
+	}
+	assert.Len(t, RunPostCommit(conf, proc.NewSummary("susanne")), 1)
+}
+
+func TestValidateHookConf(t *testing.T) {
+	assert.NoError(t, cnf.PostCommitHookConf{Command: []string{"true"}}.Validate())
+	assert.NoError(t, cnf.PostCommitHookConf{Lib: "hook.so", Fn: "Hook"}.Validate())
+	assert.Error(t, cnf.PostCommitHookConf{}.Validate())
+	assert.Error(t, cnf.PostCommitHookConf{Lib: "hook.so"}.Validate())
+	assert.Error(t, cnf.PostCommitHookConf{Command: []string{"true"}, Fn: "Hook"}.Validate())
+}
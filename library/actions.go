@@ -18,7 +18,9 @@ package library
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -26,19 +28,133 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
 	"github.com/czcorpus/vert-tagextract/v3/db/colgen"
 	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/events"
 	"github.com/czcorpus/vert-tagextract/v3/fs"
+	"github.com/czcorpus/vert-tagextract/v3/hooks"
+	"github.com/czcorpus/vert-tagextract/v3/notify"
 	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
 
 	"github.com/tomachalek/vertigo/v6"
 )
 
-func sendErrStatus(statusChan chan proc.Status, file string, err error) {
-	statusChan <- proc.Status{
+// runReporter forwards statuses to a consumer and also
+// collects them into a summary of the whole run
+type runReporter struct {
+	sync.Mutex
+	statusChan chan proc.Status
+	summary    *proc.Summary
+	fatalErr   error
+
+	// events is an optional sink the summary is published to
+	events *events.Sink
+}
+
+func (r *runReporter) send(status proc.Status) {
+	r.Lock()
+	r.summary.Update(status)
+	r.Unlock()
+	r.statusChan <- status
+}
+
+// forward returns a channel whose statuses are sent via the reporter
+// (as statuses of file) and a function which closes the channel and
+// waits until all the statuses are sent
+func (r *runReporter) forward(file string) (chan proc.Status, func()) {
+	ch := make(chan proc.Status, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for upd := range ch {
+			upd.File = file
+			r.send(upd)
+		}
+	}()
+	return ch, func() {
+		close(ch)
+		<-done
+	}
+}
+
+// finishFile closes results of a processed file in the summary
+// and sends them as a status
+func (r *runReporter) finishFile(file, outcome string, attempts int) {
+	r.Lock()
+	r.summary.SetFileOutcome(outcome, attempts)
+	fs := r.summary.CloseFile()
+	r.Unlock()
+	if fs == nil {
+		return
+	}
+	log.Info().
+		Str("file", fs.File).
+		Int("processedLines", fs.ProcessedLines).
+		Int("processedAtoms", fs.ProcessedAtoms).
+		Int("processedTokens", fs.ProcessedTokens).
+		Int("numErrors", fs.NumErrors).
+		Str("outcome", fs.Outcome).
+		Int("attempts", fs.Attempts).
+		Dur("procTime", fs.Duration()).
+		Msg("file processed")
+	r.statusChan <- proc.Status{
+		Datetime:    time.Now(),
+		File:        file,
+		FileSummary: fs,
+	}
+}
+
+func (r *runReporter) addStructures(counts map[string]int) {
+	r.Lock()
+	r.summary.AddStructures(counts)
+	r.Unlock()
+}
+
+func (r *runReporter) sendErrStatus(file string, err error) {
+	r.send(proc.Status{
 		Datetime: time.Now(),
 		File:     file,
 		Error:    err,
+	})
+}
+
+func (r *runReporter) finish(conf *cnf.VTEConf, fatalErr error) {
+	r.Lock()
+	defer r.Unlock()
+	r.summary.Finish(fatalErr)
+	r.fatalErr = fatalErr
+	log.Info().
+		Bool("failed", r.summary.Failed).
+		Int("processedFiles", r.summary.ProcessedFiles).
+		Int("processedLines", r.summary.ProcessedLines).
+		Int("processedAtoms", r.summary.ProcessedAtoms).
+		Int("processedTokens", r.summary.ProcessedTokens).
+		Int("acceptedTokens", r.summary.AcceptedTokens).
+		Int("numErrors", r.summary.NumErrors).
+		Interface("errorsByCategory", r.summary.ErrorsByCategory).
+		Int("skippedFiles", r.summary.SkippedFiles).
+		Int("missingColumns", r.summary.MissingColumns).
+		Int("columnCountViolations", r.summary.NumColumnCountViolations).
+		Int("normalizedValues", r.summary.NormalizedValues).
+		Interface("structures", r.summary.Structures).
+		Msg("extraction summary")
+	if fatalErr == nil && !r.summary.Failed {
+		for _, err := range hooks.RunPostCommit(conf, r.summary) {
+			log.Error().Err(err).Msg("post-commit hook failed")
+			r.summary.HookErrors = append(r.summary.HookErrors, err.Error())
+		}
+	}
+	if r.events != nil {
+		r.events.PublishSummary(r.summary)
+		r.events.Close()
+	}
+	if conf.Notifications.IsConfigured() {
+		if err := notify.Send(&conf.Notifications, r.summary); err != nil {
+			log.Error().Err(err).Msg("failed to send notifications")
+		}
 	}
 }
 
@@ -60,110 +176,567 @@ func determineLineReportingStep(filePath string) int {
 	return step
 }
 
+// resolveVerticalFiles returns a list of vertical files to be processed
+// based on verticalFile/verticalFiles configuration
+func resolveVerticalFiles(conf *cnf.VTEConf) ([]string, error) {
+	if conf.VerticalFile != "" && len(conf.VerticalFiles) > 0 {
+		return nil, fmt.Errorf("cannot use verticalFile and verticalFiles at the same time")
+	}
+	if conf.VerticalFile != "" && (fs.IsFile(conf.VerticalFile) || strings.HasPrefix(conf.VerticalFile, "|")) {
+		return []string{conf.VerticalFile}, nil
+
+	} else if conf.VerticalFile != "" && fs.IsDir(conf.VerticalFile) {
+		return fs.ListFilesInDir(conf.VerticalFile)
+
+	} else if len(conf.VerticalFiles) > 0 && fs.AllFilesExist(conf.VerticalFiles) {
+		return conf.VerticalFiles, nil
+	}
+	return nil, fmt.Errorf("neither verticalFile nor verticalFiles provide a valid data source")
+}
+
+// primaryDBConf returns a copy of the configuration with db.readHost
+// ignored. This is used when reading data needed by a write operation
+// as a read replica may not be up to date.
+func primaryDBConf(conf *cnf.VTEConf) *cnf.VTEConf {
+	ans := *conf
+	ans.DB.ReadHost = ""
+	return &ans
+}
+
+// previousStats reads aggregate totals stored by previous
+// runs so appended data can be added to them. In case the information
+// is not available (e.g. database created by an older version), empty
+// stats are returned.
+func previousStats(conf *cnf.VTEConf) *proc.CorpusStats {
+	reader, err := factory.NewDatabaseReader(primaryDBConf(conf))
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read previous corpus stats, assuming empty")
+		return proc.NewCorpusStats()
+	}
+	defer reader.Close()
+	ans, err := reader.Stats(conf.CorpusID())
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read previous corpus stats, assuming empty")
+		return proc.NewCorpusStats()
+	}
+	return proc.CorpusStatsFromMap(ans)
+}
+
+// checkHashAlgorithm makes sure appended data use the same
+// hash_id algorithm as the data stored by previous runs
+func checkHashAlgorithm(conf *cnf.VTEConf) error {
+	reader, err := factory.NewDatabaseReader(primaryDBConf(conf))
+	if err != nil {
+		return fmt.Errorf("failed to check hash_id algorithm: %w", err)
+	}
+	defer reader.Close()
+	meta, err := reader.RunMetadata(conf.CorpusID())
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read previous run metadata, hash_id algorithm not checked")
+		return nil
+	}
+	prev, ok := meta[db.RunMetadataHashAlgorithm]
+	if ok && prev != conf.Ngrams.HashIDAlgorithm() {
+		return fmt.Errorf(
+			"hash_id algorithm mismatch - stored data use %s, configured %s",
+			prev, conf.Ngrams.HashIDAlgorithm())
+	}
+	return nil
+}
+
+// storeRunInfo writes run metadata, aggregate stats and column
+// provenance of the current run
+func storeRunInfo(
+	dbWriter db.Writer,
+	conf *cnf.VTEConf,
+	configHash string,
+	stats *proc.CorpusStats,
+	structCounts string,
+) error {
+	err := dbWriter.SetRunMetadata(
+		conf.CorpusID(),
+		map[string]string{
+			db.RunMetadataCreated:       time.Now().Format(time.RFC3339),
+			db.RunMetadataHashAlgorithm: conf.Ngrams.HashIDAlgorithm(),
+			db.RunMetadataConfigHash:    configHash,
+			db.RunMetadataVersion:       vteVersion(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+	statsValues := stats.AsMap()
+	if structCounts == cnf.StructCountsSummary {
+		for k := range statsValues {
+			if strings.HasPrefix(k, db.StatsStructPrefix) {
+				delete(statsValues, k)
+			}
+		}
+	}
+	if err := dbWriter.SetStats(conf.CorpusID(), statsValues); err != nil {
+		return err
+	}
+	return dbWriter.SetColumnInfo(conf.CorpusID(), conf.ColumnProvenance())
+}
+
 // ExtractData extracts structural and/or positional attributes from a vertical file
 // based on the specification in the 'conf' argument.
 // The returned status channel is for getting extraction status information including possible errors
 func ExtractData(ctx context.Context, conf *cnf.VTEConf, appendData bool) (chan proc.Status, error) {
+	statusChan, _, err := extractData(ctx, conf, appendData)
+	return statusChan, err
+}
+
+// alignedColGenFn returns a function generating item_id values
+// of aligned structures (nil in case selfJoin is not configured)
+func alignedColGenFn(conf *cnf.VTEConf) colgen.AlignedColGenFn {
+	if !conf.SelfJoin.IsConfigured() {
+		return nil
+	}
+	return func(args map[string]interface{}) (ident string, err error) {
+		var colgenFn colgen.AlignedUnboundColGenFn
+		defer func() {
+			if r := recover(); r != nil {
+				ident = ""
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		colgenFn, err = colgen.GetFuncByName(conf.SelfJoin.GeneratorFn)
+		if err != nil {
+			return
+		}
+		ident, err = colgenFn(args, conf.SelfJoin.ArgColumns)
+		return
+	}
+}
+
+// errExtractorSetup marks errors which occur before a vertical
+// file is actually processed (typically a misconfiguration) so
+// there is no point in skipping or retrying the file
+var errExtractorSetup = errors.New("failed to set up extractor")
+
+// processVerticalFile extracts data from a single vertical file.
+// Statuses are sent via the reporter. Once the function returns,
+// all the statuses of the file have been already sent.
+func processVerticalFile(
+	ctx context.Context,
+	conf *cnf.VTEConf,
+	dbWriter db.Writer,
+	verticalFile string,
+	reporter *runReporter,
+	columnCountChecker *proc.ColumnCountChecker,
+	sampler *proc.AtomSampler,
+	pseudonyms *proc.PseudonymDict,
+	corpusCounts *proc.CorpusCounts,
+) (*proc.CorpusStats, error) {
+	log.Info().Str("vertical", verticalFile).Msg("Processing vertical")
+	parserConf := &vertigo.ParserConf{
+		InputFilePath:         verticalFile,
+		StructAttrAccumulator: "nil",
+		Encoding:              conf.Encoding,
+		LogProgressEachNth:    determineLineReportingStep(verticalFile),
+	}
+
+	subStatusChan, closeSubStatus := reporter.forward(verticalFile)
+	defer closeSubStatus()
+	tte, err := proc.NewTTExtractor(ctx, dbWriter, conf, alignedColGenFn(conf), subStatusChan)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errExtractorSetup, err)
+	}
+	if columnCountChecker != nil {
+		tte.SetColumnCountChecker(columnCountChecker)
+	}
+	tte.SetAtomSampler(sampler)
+	if pseudonyms != nil {
+		tte.SetPseudonymDict(pseudonyms)
+	}
+	tte.SetCorpusCounts(corpusCounts)
+	if err := tte.Run(parserConf); err != nil {
+		return nil, err
+	}
+	return tte.GetStats(), nil
+}
+
+// extractData starts the extraction and returns both the status channel
+// and the reporter which contains the run summary once the channel is closed.
+func extractData(ctx context.Context, conf *cnf.VTEConf, appendData bool) (chan proc.Status, *runReporter, error) {
 	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
-		return nil, fmt.Errorf("failed to process file: %w", err)
+		return nil, nil, fmt.Errorf("failed to process file: %w", err)
+	}
+	if err := db.ValidateOutputCompat(conf.OutputCompat); err != nil {
+		return nil, nil, err
+	}
+	if err := conf.ValidateCorpusID(); err != nil {
+		return nil, nil, err
 	}
 	statusChan := make(chan proc.Status)
 	dbWriter, err := factory.NewDatabaseWriter(conf)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	// until the writer is handed over to the processing goroutine,
+	// it must be closed on any error
+	writerHandedOver := false
+	defer func() {
+		if !writerHandedOver {
+			dbWriter.Close()
+		}
+	}()
 	dbExisted := dbWriter.DatabaseExists()
 	if !dbExisted && appendData {
 		err := fmt.Errorf("update flag is set but the database %s does not exist", conf.DB.Name)
-		return nil, err
+		return nil, nil, err
+	}
+	var replaceCorpus bool
+	if appendData {
+		replaceCorpus, err = checkExistingCorpus(conf)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	var filesToProc []string
-	if conf.VerticalFile != "" && len(conf.VerticalFiles) > 0 {
-		return nil, fmt.Errorf("cannot use verticalFile and verticalFiles at the same time")
+	filesToProc, err := resolveVerticalFiles(conf)
+	if err != nil {
+		return nil, nil, err
 	}
-	if conf.VerticalFile != "" && (fs.IsFile(conf.VerticalFile) || strings.HasPrefix(conf.VerticalFile, "|")) {
-		filesToProc = []string{conf.VerticalFile}
 
-	} else if conf.VerticalFile != "" && fs.IsDir(conf.VerticalFile) {
-		var err error
-		filesToProc, err = fs.ListFilesInDir(conf.VerticalFile)
+	stats := proc.NewCorpusStats()
+	// replaced rows are deleted only once the writer is initialized
+	// so their stats and hash_id algorithm must not be taken over
+	if appendData && !replaceCorpus {
+		stats = previousStats(conf)
+		if err := checkHashAlgorithm(conf); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	filePolicy, err := conf.FileErrorPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	structCounts, err := conf.StructCountsPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := conf.ValidateErrorBudgets(); err != nil {
+		return nil, nil, err
+	}
+	for _, hookConf := range conf.PostCommitHooks {
+		if err := hookConf.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	sampler, err := proc.NewAtomSampler(conf.Sample)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sampler != nil && conf.AtomParentStructure != "" {
+		return nil, nil, fmt.Errorf("sampling cannot be used along with atomParentStructure")
+	}
+	var manifestPath string
+	if conf.Manifest.Enabled {
+		manifestPath, err = conf.ManifestPath()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+	}
+	configHash, err := conf.DataConfigHash()
+	if err != nil {
+		return nil, nil, err
+	}
+	var pseudonyms *proc.PseudonymDict
+	var pseudonymsKey []byte
+	if conf.Anonymize.IsActive() && conf.Anonymize.MappingFile != "" {
+		pseudonymsKey, err = conf.Anonymize.MappingKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		if appendData {
+			pseudonyms, err = proc.LoadPseudonymDict(conf.Anonymize.MappingFile, pseudonymsKey)
+			if err != nil {
+				return nil, nil, err
+			}
 
-	} else if len(conf.VerticalFiles) > 0 && fs.AllFilesExist(conf.VerticalFiles) {
-		filesToProc = conf.VerticalFiles
+		} else {
+			pseudonyms = proc.NewPseudonymDict()
+		}
+	}
+	var wordDict *ptcount.WordDict
+	if conf.Ngrams.WordDictFile != "" {
+		wordDict, err = ptcount.LoadWordDict(conf.Ngrams.WordDictFile)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	// counts of all the files are stored at once after the last file
+	corpusCounts := proc.NewCorpusCounts(wordDict)
 
-	} else {
-		return nil, fmt.Errorf("neither verticalFile nor verticalFiles provide a valid data source")
+	reporter := &runReporter{
+		statusChan: statusChan,
+		summary:    proc.NewSummary(conf.Corpus),
+	}
+	if conf.Events.IsConfigured() {
+		reporter.events, err = events.NewSink(conf)
+		if err != nil {
+			return nil, nil, err
+		}
+		dbWriter = reporter.events.WrapWriter(dbWriter)
 	}
 
+	writerHandedOver = true
 	go func() {
 		defer dbWriter.Close()
 		defer close(statusChan)
-		var wg sync.WaitGroup
-		wg.Add(len(filesToProc))
+		var fatalErr error
+		defer func() {
+			reporter.finish(conf, fatalErr)
+		}()
+		var columnCountChecker *proc.ColumnCountChecker
+		if conf.ColumnCountCheck.Enabled {
+			columnCountChecker = proc.NewColumnCountChecker(conf.ColumnCountCheck.Tolerance)
+		}
 
 		err := dbWriter.Initialize(appendData)
 		if err != nil {
-			wg.Done()
-			sendErrStatus(statusChan, "", err)
+			fatalErr = err
+			reporter.sendErrStatus("", err)
 			return
 		}
-		for _, verticalFile := range filesToProc {
-			log.Info().Str("vertical", verticalFile).Msg("Processing vertical")
-			parserConf := &vertigo.ParserConf{
-				InputFilePath:         verticalFile,
-				StructAttrAccumulator: "nil",
-				Encoding:              conf.Encoding,
-				LogProgressEachNth:    determineLineReportingStep(verticalFile),
-			}
-
-			var fn colgen.AlignedColGenFn
-			if conf.SelfJoin.IsConfigured() {
-				fn = func(args map[string]interface{}) (ident string, err error) {
-					var colgenFn colgen.AlignedUnboundColGenFn
-					defer func() {
-						if r := recover(); r != nil {
-							ident = ""
-							err = fmt.Errorf("%v", r)
-						}
-					}()
-					colgenFn, err = colgen.GetFuncByName(conf.SelfJoin.GeneratorFn)
-					if err != nil {
-						return
-					}
-					ident, err = colgenFn(args, conf.SelfJoin.ArgColumns)
-					return
+		if replaceCorpus {
+			if err := replaceExistingCorpus(dbWriter, conf); err != nil {
+				fatalErr = err
+				reporter.sendErrStatus("", err)
+				dbWriter.Rollback()
+				return
+			}
+		}
+		for i, verticalFile := range filesToProc {
+			if ctx.Err() != nil {
+				fatalErr = ctx.Err()
+				reporter.sendErrStatus(verticalFile, fatalErr)
+				break
+			}
+			if sampler.Complete() {
+				log.Info().Str("vertical", verticalFile).Msg("sample complete, skipping remaining files")
+				break
+			}
+			// savepoints allow discarding data of a failed file
+			savepoint := fmt.Sprintf("vte_file_%d", i)
+			if filePolicy != cnf.FileErrorAbort {
+				if err := dbWriter.Savepoint(savepoint); err != nil {
+					fatalErr = err
+					reporter.sendErrStatus(verticalFile, err)
+					break
 				}
 			}
-
-			subStatusChan := make(chan proc.Status, 10)
-			go func() {
-				defer wg.Done()
-				for upd := range subStatusChan {
-					upd.File = verticalFile
-					statusChan <- upd
+			var attempts int
+			outcome := proc.FileOutcomeOK
+			atomsBefore := sampler.Seen()
+			for {
+				attempts++
+				sampler.Rewind(atomsBefore)
+				fileStats, err := processVerticalFile(
+					ctx, conf, dbWriter, verticalFile, reporter, columnCountChecker, sampler, pseudonyms,
+					corpusCounts)
+				if err == nil {
+					stats.Merge(fileStats)
+					reporter.addStructures(fileStats.Structures)
+					break
 				}
-			}()
-			tte, err := proc.NewTTExtractor(ctx, dbWriter, conf, fn, subStatusChan)
-			if err != nil {
-				close(subStatusChan)
-				sendErrStatus(statusChan, "", err)
+				reporter.sendErrStatus(verticalFile, err)
+				if filePolicy == cnf.FileErrorAbort || ctx.Err() != nil || errors.Is(err, errExtractorSetup) {
+					fatalErr = err
+					outcome = proc.FileOutcomeFailed
+					break
+				}
+				if err := dbWriter.RollbackToSavepoint(savepoint); err != nil {
+					fatalErr = err
+					outcome = proc.FileOutcomeFailed
+					reporter.sendErrStatus(verticalFile, err)
+					break
+				}
+				if filePolicy == cnf.FileErrorRetry && attempts <= conf.GetFileRetries() {
+					log.Warn().
+						Err(err).
+						Str("vertical", verticalFile).
+						Int("attempt", attempts).
+						Msg("failed to process vertical file, retrying")
+					continue
+				}
+				log.Warn().Err(err).Str("vertical", verticalFile).Msg("skipping failed vertical file")
+				sampler.Rewind(atomsBefore)
+				outcome = proc.FileOutcomeSkipped
+				break
+			}
+			reporter.finishFile(verticalFile, outcome, attempts)
+			if fatalErr != nil {
+				break
+			}
+		}
+		if fatalErr == nil {
+			countsStatusChan, closeCountsStatus := reporter.forward("")
+			fatalErr = corpusCounts.Store(countsStatusChan)
+			closeCountsStatus()
+			if fatalErr != nil {
+				reporter.sendErrStatus("", fatalErr)
 			}
-			err = tte.Run(parserConf)
-			close(subStatusChan)
+			stats.ARFTokens += corpusCounts.ARFTokens()
+		}
+		if fatalErr != nil {
+			if err := dbWriter.Rollback(); err != nil {
+				reporter.sendErrStatus("", err)
+			}
+			return
+		}
+		err = storeRunInfo(dbWriter, conf, configHash, stats, structCounts)
+		if err != nil {
+			fatalErr = err
+			reporter.sendErrStatus("", err)
+			if err := dbWriter.Rollback(); err != nil {
+				reporter.sendErrStatus("", err)
+			}
+			return
+		}
+		// the mapping is written before the commit so committed
+		// pseudonyms cannot end up without it
+		var pseudonymsTmp string
+		if pseudonyms != nil {
+			pseudonymsTmp, err = pseudonyms.SaveTemp(conf.Anonymize.MappingFile, pseudonymsKey)
 			if err != nil {
-				sendErrStatus(statusChan, verticalFile, err)
+				fatalErr = err
+				reporter.sendErrStatus("", err)
+				if err := dbWriter.Rollback(); err != nil {
+					reporter.sendErrStatus("", err)
+				}
+				return
 			}
 		}
-		wg.Wait()
 		err = dbWriter.Commit()
 		if err != nil {
-			sendErrStatus(statusChan, "", err)
+			fatalErr = err
+			reporter.sendErrStatus("", err)
+			if pseudonymsTmp != "" {
+				os.Remove(pseudonymsTmp)
+			}
+			return
+		}
+		if pseudonyms != nil {
+			if err := os.Rename(pseudonymsTmp, conf.Anonymize.MappingFile); err != nil {
+				fatalErr = fmt.Errorf(
+					"failed to save pseudonyms (mapping left in %s): %w", pseudonymsTmp, err)
+				reporter.sendErrStatus("", fatalErr)
+				return
+			}
+			log.Info().
+				Str("path", conf.Anonymize.MappingFile).
+				Int("numPseudonyms", pseudonyms.Len()).
+				Msg("Pseudonymization mapping written")
+		}
+		if wordDict != nil {
+			if err := wordDict.Save(conf.Ngrams.WordDictFile); err != nil {
+				fatalErr = err
+				reporter.sendErrStatus("", err)
+				return
+			}
+			log.Info().
+				Str("path", conf.Ngrams.WordDictFile).
+				Int("numWords", wordDict.Size()).
+				Msg("Word dictionary written")
+		}
+		if manifestPath != "" {
+			reporter.Lock()
+			files := reporter.summary.Files
+			reporter.Unlock()
+			manifest, err := buildManifest(conf, appendData, files, stats)
+			if err == nil {
+				err = writeManifest(manifestPath, manifest)
+			}
+			if err != nil {
+				fatalErr = err
+				reporter.sendErrStatus("", err)
+				return
+			}
+			log.Info().Str("path", manifestPath).Msg("Manifest written")
+		}
+		if conf.KonText.IsConfigured() {
+			if err := writeKonTextLiveAttrs(conf.KonText.Path, buildKonTextLiveAttrs(conf)); err != nil {
+				fatalErr = err
+				reporter.sendErrStatus("", err)
+				return
+			}
+			log.Info().Str("path", conf.KonText.Path).Msg("KonText liveattrs configuration written")
 		}
 	}()
 
-	return statusChan, nil
+	return statusChan, reporter, nil
+}
+
+// ExtractInventory collects all the distinct values of a single positional
+// attribute from all the configured vertical files. Unlike ExtractData, no
+// database is involved.
+func ExtractInventory(ctx context.Context, conf *cnf.VTEConf, column db.VertColumn) ([]proc.InventoryItem, error) {
+	filesToProc, err := resolveVerticalFiles(conf)
+	if err != nil {
+		return nil, err
+	}
+	numBucket, err := modders.NewNumBucket(conf.Ngrams.NumericBuckets)
+	if err != nil {
+		return nil, err
+	}
+	inventory := proc.NewValueInventory(column)
+	inventory.SetNumBucket(numBucket)
+	for _, verticalFile := range filesToProc {
+		log.Info().Str("vertical", verticalFile).Msg("Processing vertical")
+		parserConf := &vertigo.ParserConf{
+			InputFilePath:         verticalFile,
+			StructAttrAccumulator: "nil",
+			Encoding:              conf.Encoding,
+			LogProgressEachNth:    determineLineReportingStep(verticalFile),
+		}
+		if err := vertigo.ParseVerticalFile(ctx, parserConf, inventory); err != nil {
+			return nil, fmt.Errorf("failed to extract inventory from %s: %w", verticalFile, err)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	log.Info().
+		Int("numTokens", inventory.NumTokens()).
+		Int("numErrors", inventory.NumErrors()).
+		Msg("extracted value inventory")
+	return inventory.Items(), nil
+}
+
+// ProfileStructures collects information about structures of the
+// configured vertical files (reading at most maxLines lines, 0 = all)
+// and suggests an atom structure. No database is involved.
+func ProfileStructures(ctx context.Context, conf *cnf.VTEConf, maxLines int) (*proc.StructureReport, error) {
+	filesToProc, err := resolveVerticalFiles(conf)
+	if err != nil {
+		return nil, err
+	}
+	profiler := proc.NewStructureProfiler(maxLines)
+	for _, verticalFile := range filesToProc {
+		if profiler.Done() {
+			break
+		}
+		log.Info().Str("vertical", verticalFile).Msg("Processing vertical")
+		parserConf := &vertigo.ParserConf{
+			InputFilePath:         verticalFile,
+			StructAttrAccumulator: "nil",
+			Encoding:              conf.Encoding,
+			LogProgressEachNth:    determineLineReportingStep(verticalFile),
+		}
+		parseCtx, stop := context.WithCancel(ctx)
+		profiler.SetStopFunc(stop)
+		err := vertigo.ParseVerticalFile(parseCtx, parserConf, profiler)
+		stop()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil && !profiler.Done() {
+			return nil, fmt.Errorf("failed to profile structures of %s: %w", verticalFile, err)
+		}
+	}
+	return profiler.Report(), nil
 }
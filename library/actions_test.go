@@ -0,0 +1,165 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestVertical(t *testing.T, dir, name string, numDocs int) string {
+	var buff strings.Builder
+	for i := 0; i < numDocs; i++ {
+		fmt.Fprintf(&buff, "<doc id=\"%s-%d\">\n<p>\n", name, i)
+		for j := 0; j < 20; j++ {
+			fmt.Fprintf(&buff, "%s-word%d\tlemma%d\tN\n", name, j, j%7)
+		}
+		buff.WriteString("</p>\n</doc>\n")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(buff.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func createTestConf(t *testing.T) *cnf.VTEConf {
+	dir := t.TempDir()
+	return &cnf.VTEConf{
+		Corpus: "test",
+		VerticalFiles: []string{
+			writeTestVertical(t, dir, "vert1.txt", 1000),
+			writeTestVertical(t, dir, "vert2.txt", 1000),
+		},
+		Encoding:      "utf-8",
+		AtomStructure: "p",
+		Structures:    map[string][]string{"doc": {"id"}},
+		Ngrams: cnf.NgramConf{
+			NgramSize:   1,
+			CalcARF:     true,
+			VertColumns: db.VertColumns{{Idx: 0}, {Idx: 1}},
+		},
+		DB: db.Conf{
+			Type: "sqlite",
+			Name: filepath.Join(dir, "test.db"),
+		},
+	}
+}
+
+func numStoredWords(t *testing.T, conf *cnf.VTEConf) int {
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	stats, err := reader.Stats(conf.Corpus)
+	assert.NoError(t, err)
+	return stats[db.StatsWords]
+}
+
+func TestExtractNoCancel(t *testing.T) {
+	conf := createTestConf(t)
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.False(t, summary.Failed)
+	assert.Equal(t, 2, summary.ProcessedFiles)
+	assert.Equal(t, 2*1000*20, numStoredWords(t, conf))
+}
+
+func TestExtractCancelledBeforeStart(t *testing.T) {
+	conf := createTestConf(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err := Extract(ctx, conf, false)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, summary.Failed)
+	assert.Equal(t, 0, numStoredWords(t, conf))
+}
+
+func TestExtractCancelledDuringFile(t *testing.T) {
+	conf := createTestConf(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	summary, err := Extract(ctx, conf, false, WithProgressFunc(func(st proc.Status) {
+		if st.ProcessedLines > 0 {
+			cancel()
+		}
+	}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, summary.Failed)
+	assert.Equal(t, 0, numStoredWords(t, conf))
+}
+
+func TestExtractCancelledBetweenFiles(t *testing.T) {
+	conf := createTestConf(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	summary, err := Extract(ctx, conf, false, WithProgressFunc(func(st proc.Status) {
+		if st.File == conf.VerticalFiles[1] {
+			cancel()
+		}
+	}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, summary.Failed)
+	assert.Equal(t, 0, numStoredWords(t, conf))
+}
+
+func TestExtractCancelledAppendKeepsData(t *testing.T) {
+	conf := createTestConf(t)
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	conf.ExistingCorpus = cnf.ExistingCorpusAdd
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err = Extract(ctx, conf, true, WithProgressFunc(func(st proc.Status) {
+		if st.ProcessedLines > 0 {
+			cancel()
+		}
+	}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2*1000*20, numStoredWords(t, conf))
+}
+
+func TestExtractColumnCountCheck(t *testing.T) {
+	conf := createBrokenVerticalConf(t)
+	conf.Ngrams.MissingColumn = cnf.MissingColumnEmpty
+	conf.ColumnCountCheck = cnf.ColumnCountCheckConf{Enabled: true, Tolerance: 1}
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.NumColumnCountViolations)
+	assert.Equal(
+		t,
+		[]proc.ColumnCountViolation{
+			{File: conf.VerticalFiles[0], Line: 3, NumColumns: 1, Expected: 3},
+		},
+		summary.ColumnCountViolations,
+	)
+
+	conf.ColumnCountCheck.Tolerance = 0
+	summary, err = Extract(context.Background(), conf, false)
+	assert.ErrorIs(t, err, proc.ErrorTooManyColumnCountViolations)
+	assert.True(t, summary.Failed)
+}
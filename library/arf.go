@@ -0,0 +1,231 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// ARFResult describes a finished ARF recomputation
+type ARFResult struct {
+	NumNgrams      int `json:"numNgrams"`
+	NumUpdatedRows int `json:"numUpdatedRows"`
+	NumTokens      int `json:"numTokens"`
+}
+
+// arfSink is a db.Writer collecting ARF values of counted
+// n-grams (by their hash_id). All the other data are discarded.
+type arfSink struct {
+	values map[string]float64
+}
+
+func (s *arfSink) DatabaseExists() bool {
+	return false
+}
+
+func (s *arfSink) Initialize(appendMode bool) error {
+	return nil
+}
+
+func (s *arfSink) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	if table != db.ColCountsTable {
+		return discardInsert{}, nil
+	}
+	ins := &arfSinkInsert{sink: s, arfIdx: -1, hashIdx: -1}
+	for i, attr := range attrs {
+		switch attr {
+		case "arf":
+			ins.arfIdx = i
+		case "hash_id":
+			ins.hashIdx = i
+		}
+	}
+	if ins.arfIdx < 0 || ins.hashIdx < 0 {
+		return nil, fmt.Errorf("cannot collect ARF - missing arf or hash_id column")
+	}
+	return ins, nil
+}
+
+func (s *arfSink) SetRunMetadata(corpusID string, values map[string]string) error {
+	return nil
+}
+
+func (s *arfSink) SetStats(corpusID string, values map[string]int) error {
+	return nil
+}
+
+func (s *arfSink) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	return nil
+}
+
+func (s *arfSink) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	return nil
+}
+
+func (s *arfSink) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	return nil
+}
+
+func (s *arfSink) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (s *arfSink) Commit() error {
+	return nil
+}
+
+func (s *arfSink) Savepoint(name string) error {
+	return nil
+}
+
+func (s *arfSink) RollbackToSavepoint(name string) error {
+	return nil
+}
+
+func (s *arfSink) Rollback() error {
+	return nil
+}
+
+func (s *arfSink) Close() {}
+
+type arfSinkInsert struct {
+	sink    *arfSink
+	arfIdx  int
+	hashIdx int
+}
+
+func (ins *arfSinkInsert) Exec(values ...any) error {
+	hashID, ok := values[ins.hashIdx].(string)
+	if !ok {
+		return fmt.Errorf("cannot collect ARF - invalid hash_id value %v", values[ins.hashIdx])
+	}
+	if arf, ok := values[ins.arfIdx].(float64); ok {
+		ins.sink.values[hashID] = arf
+	}
+	return nil
+}
+
+func (ins *arfSinkInsert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ins.Exec(values...)
+}
+
+// RecomputeARF parses configured vertical files again, calculates
+// ARF of n-grams the same way an extraction with ngrams.calcARF
+// enabled would and replaces ARF values stored in an existing colcounts
+// table. Structural attributes (liveattrs) and n-gram counts are not
+// modified. The configuration must be the same as the one used to
+// create the database (except for calcARF) so the recalculated n-grams
+// match the stored ones.
+func RecomputeARF(ctx context.Context, conf *cnf.VTEConf) (*ARFResult, error) {
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return nil, err
+	}
+	if len(conf.Ngrams.VertColumns) == 0 {
+		return nil, fmt.Errorf("cannot recompute ARF - no counted columns configured")
+	}
+	if conf.DB.Colcounts.IsConfigured() {
+		return nil, fmt.Errorf("cannot recompute ARF - not supported for colcounts stored in %s", conf.DB.Colcounts.Type)
+	}
+	filesToProc, err := resolveVerticalFiles(conf)
+	if err != nil {
+		return nil, err
+	}
+	// updates must not be sent to a read replica
+	uconf := *conf
+	uconf.DB.ReadHost = ""
+	database, err := factory.NewDatabaseReader(&uconf)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	aconf := *conf
+	aconf.Ngrams.CalcARF = true
+	aconf.AttrValues = cnf.AttrValuesConf{}
+	aconf.AttrPairs = cnf.AttrPairsConf{}
+	sampler, err := proc.NewAtomSampler(conf.Sample)
+	if err != nil {
+		return nil, err
+	}
+	sink := &arfSink{values: make(map[string]float64)}
+	ans := &ARFResult{}
+	corpusCounts := proc.NewCorpusCounts(nil)
+	for _, verticalFile := range filesToProc {
+		if sampler.Complete() {
+			break
+		}
+		log.Info().Str("vertical", verticalFile).Msg("Calculating ARF")
+		statusChan := make(chan proc.Status, 10)
+		go func() {
+			for upd := range statusChan {
+				if upd.Error != nil {
+					log.Warn().Err(upd.Error).Str("vertical", verticalFile).Msg("vertical processing error")
+				}
+			}
+		}()
+		tte, err := proc.NewTTExtractor(ctx, sink, &aconf, alignedColGenFn(&aconf), statusChan)
+		if err != nil {
+			close(statusChan)
+			return nil, err
+		}
+		tte.SetAtomSampler(sampler)
+		tte.SetCorpusCounts(corpusCounts)
+		err = tte.Run(&vertigo.ParserConf{
+			InputFilePath:         verticalFile,
+			StructAttrAccumulator: "nil",
+			Encoding:              conf.Encoding,
+			LogProgressEachNth:    determineLineReportingStep(verticalFile),
+		})
+		close(statusChan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate ARF for %s: %w", verticalFile, err)
+		}
+	}
+	statusChan := make(chan proc.Status, 10)
+	go func() {
+		for range statusChan {
+		}
+	}()
+	err = corpusCounts.Store(statusChan)
+	close(statusChan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate ARF: %w", err)
+	}
+	ans.NumTokens = corpusCounts.ARFTokens()
+	ans.NumNgrams = len(sink.values)
+	ans.NumUpdatedRows, err = database.UpdateARF(conf.CorpusID(), sink.values, ans.NumTokens)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().
+		Int("numNgrams", ans.NumNgrams).
+		Int("numUpdatedRows", ans.NumUpdatedRows).
+		Int("numTokens", ans.NumTokens).
+		Msg("ARF recomputed")
+	return ans, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func storedARF(t *testing.T, conf *cnf.VTEConf) map[string]float64 {
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	rows, err := reader.DB.Query(fmt.Sprintf("SELECT hash_id, arf FROM %s", reader.Table(db.ColCountsTable)))
+	assert.NoError(t, err)
+	defer rows.Close()
+	ans := make(map[string]float64)
+	for rows.Next() {
+		var hashID string
+		var arf float64
+		assert.NoError(t, rows.Scan(&hashID, &arf))
+		ans[hashID] = arf
+	}
+	return ans
+}
+
+func TestRecomputeARF(t *testing.T) {
+	conf := createTestConf(t)
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	expected := storedARF(t, conf)
+
+	conf.Ngrams.CalcARF = false
+	conf.DB.Name = filepath.Join(t.TempDir(), "noarf.db")
+	_, err = Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	for _, arf := range storedARF(t, conf) {
+		assert.Equal(t, -1.0, arf)
+	}
+
+	result, err := RecomputeARF(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Equal(t, len(expected), result.NumNgrams)
+	assert.Equal(t, len(expected), result.NumUpdatedRows)
+	assert.Equal(t, 2*1000*20, result.NumTokens)
+	assert.Equal(t, expected, storedARF(t, conf))
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/clickhouse"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+)
+
+// corpusDBConfs returns configurations for accessing the primary
+// database and all the mirrors (always on their main hosts)
+func corpusDBConfs(conf *cnf.VTEConf) []*cnf.VTEConf {
+	ans := []*cnf.VTEConf{primaryDBConf(conf)}
+	for _, mirror := range conf.DB.Mirrors {
+		mconf := *conf
+		mconf.DB = mirror
+		mconf.DB.ReadHost = ""
+		ans = append(ans, &mconf)
+	}
+	return ans
+}
+
+// RemovedRows contains numbers of deleted rows of individual
+// tables of a database (see RemoveCorpus)
+type RemovedRows struct {
+	Database string         `json:"database"`
+	Tables   map[string]int `json:"tables"`
+}
+
+// deleteCorpusRows removes all the rows of the configured corpus
+// (i.e. its corpus_id) from the primary database and all the mirrors.
+// Each database is modified within its own transaction which also
+// records the removal to the history table (if enabled).
+func deleteCorpusRows(conf *cnf.VTEConf) ([]RemovedRows, error) {
+	ans := make([]RemovedRows, 0, len(conf.DB.Mirrors)+1)
+	var runID string
+	if conf.DB.History.Enabled {
+		runID = db.NewRunID(time.Now())
+	}
+	for _, dbConf := range corpusDBConfs(conf) {
+		reader, err := factory.NewDatabaseReader(dbConf)
+		if err != nil {
+			return ans, fmt.Errorf("failed to delete rows of corpus %s: %w", conf.CorpusID(), err)
+		}
+		deleted, err := reader.RemoveCorpus(conf.CorpusID(), runID)
+		reader.Close()
+		if err != nil {
+			return ans, err
+		}
+		for table, numRows := range deleted {
+			log.Info().
+				Str("database", dbConf.DB.Name).
+				Str("table", table).
+				Str("corpusId", conf.CorpusID()).
+				Int("numRows", numRows).
+				Msg("deleted rows of the corpus")
+		}
+		ans = append(ans, RemovedRows{Database: dbConf.DB.Name, Tables: deleted})
+	}
+	return ans, nil
+}
+
+// RemoveCorpus deletes all the rows of the configured corpus (i.e. of its
+// corpus_id) from a (typically grouped) database and all its mirrors
+// including colcounts stored in ClickHouse. Rows of other corpora are
+// kept. It is an error in case the database contains no rows of the corpus.
+func RemoveCorpus(conf *cnf.VTEConf) ([]RemovedRows, error) {
+	if err := conf.ValidateCorpusID(); err != nil {
+		return nil, err
+	}
+	reader, err := factory.NewDatabaseReader(primaryDBConf(conf))
+	if err != nil {
+		return nil, err
+	}
+	numRows, err := reader.RowCount(db.LiveAttrsTable, conf.CorpusID())
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	if numRows == 0 {
+		return nil, fmt.Errorf("no rows of corpus %s found in the database", conf.CorpusID())
+	}
+	ans, err := deleteCorpusRows(conf)
+	if err != nil {
+		return ans, err
+	}
+	for _, dbConf := range corpusDBConfs(conf) {
+		if !dbConf.DB.Colcounts.IsConfigured() {
+			continue
+		}
+		if err := clickhouse.DeleteCorpus(dbConf.DB.Colcounts, conf.CorpusID()); err != nil {
+			return ans, err
+		}
+	}
+	return ans, nil
+}
+
+// checkExistingCorpus applies conf.ExistingCorpus before data are
+// appended to a database already containing rows of the corpus.
+// The returned value is true in case the existing rows are to be
+// replaced. They are not deleted here - the deletion is up to
+// the writer so it is made within the same transaction as
+// the inserts (see replaceExistingCorpus).
+func checkExistingCorpus(conf *cnf.VTEConf) (bool, error) {
+	policy, err := conf.ExistingCorpusPolicy()
+	if err != nil {
+		return false, err
+	}
+	if policy == cnf.ExistingCorpusAdd {
+		return false, nil
+	}
+	reader, err := factory.NewDatabaseReader(primaryDBConf(conf))
+	if err != nil {
+		return false, fmt.Errorf("failed to check existing rows of corpus %s: %w", conf.CorpusID(), err)
+	}
+	numRows, err := reader.RowCount(db.LiveAttrsTable, conf.CorpusID())
+	reader.Close()
+	if err != nil {
+		return false, fmt.Errorf("failed to check existing rows of corpus %s: %w", conf.CorpusID(), err)
+	}
+	if numRows == 0 {
+		return false, nil
+	}
+	if policy == cnf.ExistingCorpusFail {
+		return false, fmt.Errorf(
+			"the database already contains %d rows of corpus %s - use existingCorpus 'replace' "+
+				"(-replace-corpus) to delete them first or 'add' (-add-to-corpus) to keep them",
+			numRows, conf.CorpusID())
+	}
+	log.Warn().
+		Str("corpusId", conf.CorpusID()).
+		Int("numRows", numRows).
+		Msg("going to replace existing rows of the corpus")
+	return true, nil
+}
+
+// replaceExistingCorpus deletes all the previous rows of the configured
+// corpus within the current transaction of the writer (i.e. the rows
+// are kept in case the extraction fails)
+func replaceExistingCorpus(dbWriter db.Writer, conf *cnf.VTEConf) error {
+	deleted, err := dbWriter.DeleteCorpus(conf.CorpusID())
+	if err != nil {
+		return fmt.Errorf("failed to delete previous rows of corpus %s: %w", conf.CorpusID(), err)
+	}
+	for table, numRows := range deleted {
+		log.Info().
+			Str("table", table).
+			Str("corpusId", conf.CorpusID()).
+			Int("numRows", numRows).
+			Msg("deleted previous rows of the corpus")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveCorpus(t *testing.T) {
+	conf := createTestConf(t)
+	conf.ParallelCorpus = "test"
+	conf.Corpus = "test_en"
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	conf.Corpus = "test_cs"
+	_, err = Extract(context.Background(), conf, true)
+	assert.NoError(t, err)
+
+	conf.Corpus = "test_en"
+	removed, err := RemoveCorpus(conf)
+	assert.NoError(t, err)
+	assert.Len(t, removed, 1)
+	assert.Equal(t, 2000, removed[0].Tables[db.LiveAttrsTable])
+	assert.Equal(t, 0, numStoredWords(t, conf))
+
+	conf.Corpus = "test_cs"
+	assert.Equal(t, 2*1000*20, numStoredWords(t, conf))
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	numRows, err := reader.RowCount(db.LiveAttrsTable, "test_cs")
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, numRows)
+
+	conf.Corpus = "test_en"
+	_, err = RemoveCorpus(conf)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+)
+
+type extractOptions struct {
+	progressFn func(proc.Status)
+}
+
+// Option configures Extract
+type Option func(opts *extractOptions)
+
+// WithProgressFunc sets a function called for each status
+// (progress information, errors) produced during the extraction.
+// The function is called synchronously from the goroutine running
+// Extract so it should not block for too long.
+func WithProgressFunc(fn func(proc.Status)) Option {
+	return func(opts *extractOptions) {
+		opts.progressFn = fn
+	}
+}
+
+// Extract runs the same extraction as ExtractData but it blocks until
+// the extraction is finished. This means there is no channel the caller
+// has to consume and progress can be observed via WithProgressFunc.
+// The returned summary is available even in case of an error which
+// stopped the processing (but not in case the extraction could not
+// be started at all).
+//
+// In case conf.Degradation is configured and the run fails in
+// a matching way, the whole run is repeated with settings of individual
+// degradation steps until it succeeds or there are no more steps.
+func Extract(ctx context.Context, conf *cnf.VTEConf, appendData bool, options ...Option) (*proc.Summary, error) {
+	var opts extractOptions
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if err := conf.Degradation.Validate(); err != nil {
+		return nil, err
+	}
+	summary, err := extractOnce(ctx, conf, appendData, &opts)
+	for i, step := range conf.Degradation.Steps {
+		if err == nil || summary == nil || !isDegradable(conf, err) || ctx.Err() != nil {
+			break
+		}
+		log.Warn().
+			Err(err).
+			Int("step", i+1).
+			Msg("extraction failed, retrying with degraded settings")
+		summary, err = extractOnce(ctx, conf.WithDegradation(step), appendData, &opts)
+		if summary != nil {
+			summary.DegradationStep = i + 1
+		}
+	}
+	return summary, err
+}
+
+// isDegradable tells whether a failed run should be retried
+// with degraded settings
+func isDegradable(conf *cnf.VTEConf, err error) bool {
+	if errors.Is(err, proc.ErrorTooManyParsingErrors) {
+		return conf.Degradation.Triggers(cnf.DegradeOnTooManyErrors)
+	}
+	if factory.IsOutOfMemoryErr(err) {
+		return conf.Degradation.Triggers(cnf.DegradeOnOutOfMemory)
+	}
+	return false
+}
+
+func extractOnce(ctx context.Context, conf *cnf.VTEConf, appendData bool, opts *extractOptions) (*proc.Summary, error) {
+	statusChan, reporter, err := extractData(ctx, conf, appendData)
+	if err != nil {
+		return nil, err
+	}
+	for status := range statusChan {
+		if opts.progressFn != nil {
+			opts.progressFn(status)
+		}
+	}
+	reporter.Lock()
+	defer reporter.Unlock()
+	return reporter.summary, reporter.fatalErr
+}
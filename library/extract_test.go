@@ -0,0 +1,409 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount"
+	"github.com/stretchr/testify/assert"
+)
+
+func createBrokenVerticalConf(t *testing.T) *cnf.VTEConf {
+	conf := createTestConf(t)
+	path := filepath.Join(filepath.Dir(conf.DB.Name), "broken.txt")
+	data := "<doc id=\"d1\">\n<p>\nfoo\tfoo\tN\nbar\nbaz\tbaz\tN\n</p>\n</doc>\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf.VerticalFiles = []string{path}
+	conf.Ngrams.MissingColumn = cnf.MissingColumnError
+	conf.MaxNumErrors = 0
+	return conf
+}
+
+func TestExtractDegradesOnTooManyErrors(t *testing.T) {
+	conf := createBrokenVerticalConf(t)
+	maxErrors := 10
+	conf.Degradation = cnf.DegradationConf{
+		Steps: []cnf.DegradationStep{{MaxNumErrors: &maxErrors}},
+	}
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.False(t, summary.Failed)
+	assert.Equal(t, 1, summary.DegradationStep)
+	assert.Equal(t, 2, numStoredWords(t, conf))
+	assert.Equal(t, 0, conf.MaxNumErrors)
+}
+
+func TestExtractDegradationNotTriggered(t *testing.T) {
+	conf := createBrokenVerticalConf(t)
+	maxErrors := 10
+	conf.Degradation = cnf.DegradationConf{
+		On:    []string{cnf.DegradeOnOutOfMemory},
+		Steps: []cnf.DegradationStep{{MaxNumErrors: &maxErrors}},
+	}
+	summary, err := Extract(context.Background(), conf, false)
+	assert.ErrorIs(t, err, proc.ErrorTooManyParsingErrors)
+	assert.True(t, summary.Failed)
+	assert.Equal(t, 0, summary.DegradationStep)
+}
+
+func TestExtractAppendHashAlgorithmMismatch(t *testing.T) {
+	conf := createTestConf(t)
+	conf.Ngrams.HashAlgorithm = ptcount.HashIDXXHash64
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	conf.Ngrams.HashAlgorithm = ""
+	conf.ExistingCorpus = cnf.ExistingCorpusAdd
+	_, err = Extract(context.Background(), conf, true)
+	assert.Error(t, err)
+}
+
+func TestExtractAppendExistingCorpus(t *testing.T) {
+	conf := createTestConf(t)
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	_, err = Extract(context.Background(), conf, true)
+	assert.ErrorContains(t, err, "already contains")
+	assert.Equal(t, 2*1000*20, numStoredWords(t, conf))
+
+	conf.ExistingCorpus = cnf.ExistingCorpusReplace
+	_, err = Extract(context.Background(), conf, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*1000*20, numStoredWords(t, conf))
+
+	conf.ExistingCorpus = cnf.ExistingCorpusAdd
+	_, err = Extract(context.Background(), conf, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*2*1000*20, numStoredWords(t, conf))
+}
+
+func TestExtractReplaceCorpusKeepsRowsOnFailure(t *testing.T) {
+	conf := createTestConf(t)
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	broken := createBrokenVerticalConf(t)
+	conf.VerticalFiles = broken.VerticalFiles
+	conf.Ngrams.MissingColumn = broken.Ngrams.MissingColumn
+	conf.ExistingCorpus = cnf.ExistingCorpusReplace
+	summary, err := Extract(context.Background(), conf, true)
+	assert.Error(t, err)
+	if assert.NotNil(t, summary) {
+		assert.True(t, summary.Failed)
+	}
+	assert.Equal(t, 2*1000*20, numStoredWords(t, conf))
+}
+
+func TestExtractSkipsFailedFile(t *testing.T) {
+	conf := createBrokenVerticalConf(t)
+	conf.VerticalFiles = append(conf.VerticalFiles, writeTestVertical(t, t.TempDir(), "vert3.txt", 10))
+	conf.OnFileError = cnf.FileErrorRetry
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.False(t, summary.Failed)
+	assert.Equal(t, 1, summary.SkippedFiles)
+	if assert.Len(t, summary.Files, 2) {
+		assert.Equal(t, proc.FileOutcomeSkipped, summary.Files[0].Outcome)
+		assert.Equal(t, 2, summary.Files[0].Attempts)
+		assert.Equal(t, proc.FileOutcomeOK, summary.Files[1].Outcome)
+	}
+	assert.Equal(t, 10*20, numStoredWords(t, conf))
+}
+
+func TestExtractWritesManifest(t *testing.T) {
+	conf := createTestConf(t)
+	conf.Manifest.Enabled = true
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(conf.DB.Name + ".manifest.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var manifest Manifest
+	assert.NoError(t, sonic.Unmarshal(data, &manifest))
+	configHash, err := conf.DataConfigHash()
+	assert.NoError(t, err)
+	assert.Equal(t, configHash, manifest.ConfigHash)
+	assert.Equal(t, conf.Corpus, manifest.Corpus)
+	if assert.NotEmpty(t, manifest.Verticals) {
+		assert.Len(t, manifest.Verticals[0].SHA256, 64)
+	}
+	assert.Greater(t, manifest.RowCounts[db.LiveAttrsTable], 0)
+	assert.Equal(t, manifest.Stats[db.StatsWords], numStoredWords(t, conf))
+}
+
+func TestExtractSample(t *testing.T) {
+	conf := createTestConf(t)
+	conf.Sample = cnf.SampleConf{Ratio: 0.1}
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, summary.ProcessedAtoms)
+	assert.Equal(t, 200*20, numStoredWords(t, conf))
+
+	conf.Sample = cnf.SampleConf{Atoms: 5}
+	summary, err = Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, summary.ProcessedAtoms)
+	assert.Equal(t, 5*20, numStoredWords(t, conf))
+	if assert.Len(t, summary.Files, 1) {
+		assert.Equal(t, proc.FileOutcomeOK, summary.Files[0].Outcome)
+	}
+	conf.Sample = cnf.SampleConf{Lines: 24*3 - 10}
+	summary, err = Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.ProcessedAtoms)
+	assert.Equal(t, 3*20, numStoredWords(t, conf))
+}
+
+func TestExtractStructCounts(t *testing.T) {
+	conf := createTestConf(t)
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"doc": 2000, "p": 2000}, summary.Structures)
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	stats, err := reader.Stats(conf.Corpus)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, stats[db.StatsStructPrefix+"doc"])
+	reader.Close()
+
+	conf.StructCounts = cnf.StructCountsSummary
+	summary, err = Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, summary.Structures["doc"])
+	reader, err = factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	stats, err = reader.Stats(conf.Corpus)
+	assert.NoError(t, err)
+	assert.NotContains(t, stats, db.StatsStructPrefix+"doc")
+}
+
+func TestExtractAttrValues(t *testing.T) {
+	conf := createTestConf(t)
+	conf.Ngrams = cnf.NgramConf{}
+	conf.AttrValues = cnf.AttrValuesConf{Enabled: true}
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	conf.ExistingCorpus = cnf.ExistingCorpusAdd
+	_, err = Extract(context.Background(), conf, true)
+	assert.NoError(t, err)
+
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	var numValues, numAtoms, numTokens int
+	err = reader.DB.QueryRow(
+		"SELECT COUNT(*), MAX(num_atoms), SUM(num_tokens) FROM "+reader.Table(db.AttrValuesTable)+
+			" WHERE attr = 'doc_id'",
+	).Scan(&numValues, &numAtoms, &numTokens)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, numValues)
+	assert.Equal(t, 2, numAtoms)
+	assert.Equal(t, 2*2000*20, numTokens)
+
+	conf.AttrValues.Attrs = []string{"doc_genre"}
+	_, err = Extract(context.Background(), conf, false)
+	assert.ErrorContains(t, err, "not configured in structures")
+}
+
+func TestExtractAttrPairs(t *testing.T) {
+	conf := createTestConf(t)
+	vert := "<doc genre=\"fiction\" period=\"1990s\">\n<p>\na\tb\tN\nc\td\tN\n</p>\n</doc>\n" +
+		"<doc genre=\"fiction\" period=\"1990s\">\n<p>\ne\tf\tN\n</p>\n</doc>\n" +
+		"<doc genre=\"news\" period=\"1990s\">\n<p>\ng\th\tN\n</p>\n</doc>\n"
+	conf.VerticalFiles = []string{filepath.Join(t.TempDir(), "vert.txt")}
+	if err := os.WriteFile(conf.VerticalFiles[0], []byte(vert), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf.Structures = map[string][]string{"doc": {"genre", "period"}}
+	conf.AttrPairs = cnf.AttrPairsConf{Pairs: [][2]string{{"doc_genre", "doc_period"}}}
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	rows, err := reader.DB.Query(
+		"SELECT value1, value2, num_atoms, num_tokens FROM " + reader.Table(db.AttrPairsTable) +
+			" ORDER BY value1")
+	assert.NoError(t, err)
+	defer rows.Close()
+	ans := make([]string, 0, 2)
+	for rows.Next() {
+		var v1, v2 string
+		var numAtoms, numTokens int
+		assert.NoError(t, rows.Scan(&v1, &v2, &numAtoms, &numTokens))
+		ans = append(ans, fmt.Sprintf("%s/%s: %d, %d", v1, v2, numAtoms, numTokens))
+	}
+	assert.Equal(t, []string{"fiction/1990s: 2, 3", "news/1990s: 1, 1"}, ans)
+}
+
+func TestExtractInvalidUTF8(t *testing.T) {
+	conf := createTestConf(t)
+	conf.Ngrams = cnf.NgramConf{}
+	vert := "<doc title=\"a\xffb\">\n<p>\na\tb\tN\n</p>\n</doc>\n"
+	conf.VerticalFiles = []string{filepath.Join(t.TempDir(), "vert.txt")}
+	if err := os.WriteFile(conf.VerticalFiles[0], []byte(vert), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf.Structures = map[string][]string{"doc": {"title"}}
+	conf.InvalidUTF8 = cnf.InvalidUTF8Replace
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	var title string
+	err = reader.DB.QueryRow("SELECT doc_title FROM " + reader.Table(db.LiveAttrsTable)).Scan(&title)
+	reader.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "a�b", title)
+
+	conf.InvalidUTF8 = cnf.InvalidUTF8Fail
+	_, err = Extract(context.Background(), conf, false)
+	assert.ErrorContains(t, err, "invalid UTF-8 in value of doc.title")
+}
+
+func TestExtractWhitespaceCollapse(t *testing.T) {
+	conf := createTestConf(t)
+	conf.Ngrams = cnf.NgramConf{}
+	vert := "<doc title=\" a  b \">\n<p>\na\tb\tN\n</p>\n</doc>\n<doc title=\"a b\">\n<p>\na\tb\tN\n</p>\n</doc>\n"
+	conf.VerticalFiles = []string{filepath.Join(t.TempDir(), "vert.txt")}
+	if err := os.WriteFile(conf.VerticalFiles[0], []byte(vert), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf.Structures = map[string][]string{"doc": {"title"}}
+	conf.Whitespace = cnf.WhitespaceCollapse
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.NormalizedValues)
+
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	var numValues int
+	err = reader.DB.QueryRow(
+		"SELECT COUNT(DISTINCT doc_title) FROM " + reader.Table(db.LiveAttrsTable)).Scan(&numValues)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, numValues)
+
+	var modifiers string
+	err = reader.DB.QueryRow(
+		"SELECT modifiers FROM "+reader.Table(db.ColumnInfoTable)+
+			" WHERE corpus_id = ? AND column_name = 'doc_title'", conf.Corpus).Scan(&modifiers)
+	assert.NoError(t, err)
+	assert.Equal(t, "whitespace:collapse", modifiers)
+}
+
+func TestExtractSyntheticAtoms(t *testing.T) {
+	conf := createTestConf(t)
+	vert := "a\tb\tN\nc\td\tN\ne\tf\tN\n<g/>\ng\th\tN\ni\tj\tN\n"
+	conf.VerticalFiles = []string{filepath.Join(t.TempDir(), "plain.txt")}
+	if err := os.WriteFile(conf.VerticalFiles[0], []byte(vert), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf.AtomStructure = "chunk"
+	conf.Structures = map[string][]string{"chunk": {"id"}}
+	conf.SyntheticAtoms = cnf.SyntheticAtomsConf{Enabled: true, Tokens: 2}
+	summary, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.ProcessedAtoms)
+	assert.Equal(t, 5, numStoredWords(t, conf))
+
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	defer reader.Close()
+	rows, err := reader.DB.Query(
+		"SELECT chunk_id, poscount FROM " + reader.Table(db.LiveAttrsTable) + " ORDER BY chunk_id")
+	assert.NoError(t, err)
+	defer rows.Close()
+	ans := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var poscount int
+		assert.NoError(t, rows.Scan(&id, &poscount))
+		ans[id] = poscount
+	}
+	assert.Equal(t, map[string]int{"plain.txt:1": 2, "plain.txt:2": 2, "plain.txt:3": 1}, ans)
+
+	conf.SyntheticAtoms.Tokens = 0
+	summary, err = Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.ProcessedAtoms)
+}
+
+func TestExtractMergesCountsOfFiles(t *testing.T) {
+	conf := createTestConf(t)
+	dir := filepath.Dir(conf.DB.Name)
+	conf.VerticalFiles = nil
+	for i, words := range []string{"a\nb\na\nb\n", "a\nc\nc\nc\n"} {
+		path := filepath.Join(dir, fmt.Sprintf("part%d.txt", i))
+		data := "<doc id=\"d1\">\n<p>\n" + words + "</p>\n</doc>\n"
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		conf.VerticalFiles = append(conf.VerticalFiles, path)
+	}
+	conf.Ngrams.VertColumns = db.VertColumns{{Idx: 0}}
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	reader, err := factory.NewDatabaseReader(conf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reader.Close()
+	rows, err := reader.DB.Query(
+		fmt.Sprintf("SELECT col0, count, arf FROM %s WHERE corpus_id = ?", reader.Table(db.ColCountsTable)),
+		conf.CorpusID())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	arf := make(map[string]float64)
+	for rows.Next() {
+		var value string
+		var count int
+		var arfValue float64
+		assert.NoError(t, rows.Scan(&value, &count, &arfValue))
+		counts[value] = count
+		arf[value] = arfValue
+	}
+	assert.Equal(t, map[string]int{"a": 3, "b": 2, "c": 3}, counts)
+	// positions are global: 'a' occurs at 0, 2, 4 and 'c' at 5, 6, 7 (of 8 tokens)
+	assert.Equal(t, 2.5, arf["a"])
+	assert.Equal(t, 1.75, arf["c"])
+	stats, err := reader.Stats(conf.Corpus)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, stats[db.StatsARFTokens])
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+)
+
+// KonTextMetadata contains liveattrs related items of KonText
+// corpus metadata configuration (named the same way as in KonText)
+type KonTextMetadata struct {
+	Database        string `json:"database"`
+	IDAttr          string `json:"id_attr,omitempty"`
+	LabelAttr       string `json:"label_attr,omitempty"`
+	GroupDuplicates bool   `json:"group_duplicates"`
+}
+
+// KonTextLiveAttrs is a configuration fragment for wiring
+// a corpus with generated data into the KonText liveattrs plug-in
+type KonTextLiveAttrs struct {
+	Corpus string `json:"corpus"`
+
+	// GroupedName is a name of a group of aligned corpora
+	// sharing liveattrs data (parallel corpora only)
+	GroupedName string `json:"groupedName,omitempty"`
+
+	DBType string `json:"dbType"`
+
+	// Attrs lists all the structural attributes
+	// available in liveattrs (struct.attr)
+	Attrs []string `json:"attrs"`
+
+	// BibViewCols lists attributes of the bibliography
+	// view (struct.attr)
+	BibViewCols []string `json:"bibViewCols,omitempty"`
+
+	Metadata KonTextMetadata `json:"metadata"`
+}
+
+// dottedAttr converts a liveattrs column name (e.g. doc_title)
+// or an attribute name (e.g. doc.title) to the form used by KonText
+func dottedAttr(name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return strings.Replace(name, "_", ".", 1)
+}
+
+// buildKonTextLiveAttrs creates a KonText liveattrs
+// configuration fragment for the configured corpus
+func buildKonTextLiveAttrs(conf *cnf.VTEConf) *KonTextLiveAttrs {
+	ans := &KonTextLiveAttrs{
+		Corpus:      conf.Corpus,
+		GroupedName: conf.ParallelCorpus,
+		DBType:      conf.DB.Type,
+		Attrs:       make([]string, 0, 20),
+		Metadata: KonTextMetadata{
+			Database: conf.DB.Name,
+		},
+	}
+	structs := make([]string, 0, len(conf.Structures))
+	for st := range conf.Structures {
+		structs = append(structs, st)
+	}
+	sort.Strings(structs)
+	for _, st := range structs {
+		for _, attr := range conf.Structures[st] {
+			ans.Attrs = append(ans.Attrs, st+"."+attr)
+		}
+	}
+	if conf.BibView.IsConfigured() {
+		idAttr := dottedAttr(conf.BibView.IDAttr)
+		ans.Metadata.IDAttr = idAttr
+		for _, col := range conf.BibView.Cols {
+			attr := dottedAttr(col)
+			ans.BibViewCols = append(ans.BibViewCols, attr)
+			if ans.Metadata.LabelAttr == "" && attr != idAttr {
+				ans.Metadata.LabelAttr = attr
+			}
+		}
+		// with bibliography items larger than atoms, more liveattrs
+		// rows share the same bibliography entry
+		bibStruct, _ := conf.BibView.IDAttrElements()
+		ans.Metadata.GroupDuplicates = bibStruct != conf.AtomStructure
+	}
+	return ans
+}
+
+// writeKonTextLiveAttrs writes a KonText liveattrs
+// configuration fragment to a JSON file
+func writeKonTextLiveAttrs(path string, fragment *KonTextLiveAttrs) error {
+	data, err := sonic.ConfigStd.MarshalIndent(fragment, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write KonText configuration: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write KonText configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildKonTextLiveAttrs(t *testing.T) {
+	conf := &cnf.VTEConf{
+		Corpus:        "susanne",
+		AtomStructure: "p",
+		Structures: map[string][]string{
+			"p":   {"id"},
+			"doc": {"id", "title", "author"},
+		},
+	}
+	conf.DB.Type = "sqlite"
+	conf.DB.Name = "/data/susanne.db"
+	conf.BibView.IDAttr = "doc_id"
+	conf.BibView.Cols = []string{"doc_id", "doc_title", "doc_author"}
+	ans := buildKonTextLiveAttrs(conf)
+	assert.Equal(t, []string{"doc.id", "doc.title", "doc.author", "p.id"}, ans.Attrs)
+	assert.Equal(t, []string{"doc.id", "doc.title", "doc.author"}, ans.BibViewCols)
+	assert.Equal(t, "/data/susanne.db", ans.Metadata.Database)
+	assert.Equal(t, "doc.id", ans.Metadata.IDAttr)
+	assert.Equal(t, "doc.title", ans.Metadata.LabelAttr)
+	assert.True(t, ans.Metadata.GroupDuplicates)
+	assert.Empty(t, ans.GroupedName)
+}
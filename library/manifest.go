@@ -0,0 +1,168 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+)
+
+const modulePath = "github.com/czcorpus/vert-tagextract/v3"
+
+// ManifestFile describes a vertical file processed by a run
+type ManifestFile struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256,omitempty"`
+	Outcome string `json:"outcome"`
+}
+
+// Manifest describes provenance of a generated database so
+// installations can verify they use matching data and configuration
+type Manifest struct {
+	Corpus        string         `json:"corpus"`
+	Created       time.Time      `json:"created"`
+	Append        bool           `json:"append"`
+	VTEVersion    string         `json:"vteVersion"`
+	ConfigHash    string         `json:"configHash"`
+	HashAlgorithm string         `json:"hashAlgorithm"`
+	OutputCompat  string         `json:"outputCompat"`
+	DBType        string         `json:"dbType"`
+	Verticals     []ManifestFile `json:"verticals"`
+
+	// RowCounts contains numbers of the corpus rows
+	// per table (logical names)
+	RowCounts map[string]int `json:"rowCounts"`
+
+	Stats map[string]int `json:"stats"`
+}
+
+// vteVersion returns a version of the vert-tagextract module
+// as recorded in the build information (either as the main module
+// or as a dependency of a program using the library)
+func vteVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == modulePath {
+		ans := info.Main.Version
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				ans += " (" + s.Value + ")"
+			}
+		}
+		return ans
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildManifest creates a manifest for committed data. Checksums
+// are calculated only for successfully processed vertical files.
+func buildManifest(
+	conf *cnf.VTEConf,
+	appendData bool,
+	files []*proc.FileSummary,
+	stats *proc.CorpusStats,
+) (*Manifest, error) {
+	configHash, err := conf.DataConfigHash()
+	if err != nil {
+		return nil, err
+	}
+	ans := &Manifest{
+		Corpus:        conf.Corpus,
+		Created:       time.Now(),
+		Append:        appendData,
+		VTEVersion:    vteVersion(),
+		ConfigHash:    configHash,
+		HashAlgorithm: conf.Ngrams.HashIDAlgorithm(),
+		OutputCompat:  conf.OutputCompat,
+		DBType:        conf.DB.Type,
+		Verticals:     make([]ManifestFile, 0, len(files)),
+		RowCounts:     make(map[string]int),
+		Stats:         stats.AsMap(),
+	}
+	if ans.OutputCompat == "" {
+		ans.OutputCompat = db.OutputCompatV3
+	}
+	for _, f := range files {
+		item := ManifestFile{Path: f.File, Outcome: f.Outcome}
+		if info, err := os.Stat(f.File); err == nil {
+			item.Size = info.Size()
+		}
+		if f.Outcome == proc.FileOutcomeOK {
+			item.SHA256, err = fileSHA256(f.File)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate checksum of %s: %w", f.File, err)
+			}
+		}
+		ans.Verticals = append(ans.Verticals, item)
+	}
+	reader, err := factory.NewDatabaseReader(primaryDBConf(conf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row counts: %w", err)
+	}
+	defer reader.Close()
+	for _, tbl := range db.ExpectedSchema(conf.Structures, conf.SelfJoin.IsConfigured(), conf.Ngrams.VertColumns) {
+		ans.RowCounts[tbl.Name], err = reader.RowCount(tbl.Name, conf.CorpusID())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ans, nil
+}
+
+// writeManifest writes a manifest to a JSON file
+func writeManifest(path string, manifest *Manifest) error {
+	data, err := sonic.ConfigStd.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// AtomRecord contains metadata of a single atom exactly
+// as they would be written to the structural attributes
+// table (see db.LiveAttrsTable)
+type AtomRecord struct {
+
+	// File is a vertical file the atom comes from
+	File string
+
+	// Attrs maps column names (e.g. doc_title, poscount,
+	// corpus_id) to their values
+	Attrs map[string]any
+}
+
+// AtomConsumer receives extracted atoms. Returning an error
+// stops the processing.
+type AtomConsumer func(atom AtomRecord) error
+
+// atomSink is a db.Writer passing atoms to a consumer
+// instead of writing them to a database
+type atomSink struct {
+	file     string
+	consumer AtomConsumer
+	err      error
+	onError  func()
+
+	// stopped is set once the consumer fails
+	stopped atomic.Bool
+}
+
+func (s *atomSink) DatabaseExists() bool {
+	return false
+}
+
+func (s *atomSink) Initialize(appendMode bool) error {
+	return nil
+}
+
+func (s *atomSink) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	if table != db.LiveAttrsTable {
+		return discardInsert{}, nil
+	}
+	return &atomSinkInsert{sink: s, attrs: attrs}, nil
+}
+
+func (s *atomSink) SetRunMetadata(corpusID string, values map[string]string) error {
+	return nil
+}
+
+func (s *atomSink) SetStats(corpusID string, values map[string]int) error {
+	return nil
+}
+
+func (s *atomSink) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	return nil
+}
+
+func (s *atomSink) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	return nil
+}
+
+func (s *atomSink) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	return nil
+}
+
+func (s *atomSink) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (s *atomSink) Commit() error {
+	return nil
+}
+
+func (s *atomSink) Savepoint(name string) error {
+	return nil
+}
+
+func (s *atomSink) RollbackToSavepoint(name string) error {
+	return nil
+}
+
+func (s *atomSink) Rollback() error {
+	return nil
+}
+
+func (s *atomSink) Close() {}
+
+type atomSinkInsert struct {
+	sink  *atomSink
+	attrs []string
+}
+
+func (ins *atomSinkInsert) Exec(values ...any) error {
+	if ins.sink.err != nil {
+		return ins.sink.err
+	}
+	atom := AtomRecord{File: ins.sink.file, Attrs: make(map[string]any, len(ins.attrs))}
+	for i, attr := range ins.attrs {
+		atom.Attrs[attr] = values[i]
+	}
+	if err := ins.sink.consumer(atom); err != nil {
+		// the extractor may tolerate insert errors so we
+		// have to stop the processing by ourselves
+		ins.sink.err = err
+		ins.sink.stopped.Store(true)
+		ins.sink.onError()
+		return err
+	}
+	return nil
+}
+
+func (ins *atomSinkInsert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ins.Exec(values...)
+}
+
+type discardInsert struct{}
+
+func (ins discardInsert) Exec(values ...any) error {
+	return nil
+}
+
+func (ins discardInsert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ins.Exec(values...)
+}
+
+// StreamAtoms parses configured vertical files and passes extracted
+// atoms to a consumer without using any database. This allows
+// embedding applications to use custom sinks (e.g. message queues).
+// N-grams and other aggregate data are not calculated. In case
+// the consumer returns an error, the processing stops and the error
+// is returned.
+func StreamAtoms(ctx context.Context, conf *cnf.VTEConf, consumer AtomConsumer) error {
+	filesToProc, err := resolveVerticalFiles(conf)
+	if err != nil {
+		return err
+	}
+	sconf := *conf
+	sconf.Ngrams = cnf.NgramConf{}
+	sconf.AttrValues = cnf.AttrValuesConf{}
+	sconf.AttrPairs = cnf.AttrPairsConf{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sink := &atomSink{consumer: consumer, onError: cancel}
+	for _, verticalFile := range filesToProc {
+		sink.file = verticalFile
+		statusChan := make(chan proc.Status, 10)
+		go func() {
+			for upd := range statusChan {
+				if upd.Error != nil && !sink.stopped.Load() {
+					log.Warn().Err(upd.Error).Str("vertical", verticalFile).Msg("vertical processing error")
+				}
+			}
+		}()
+		tte, err := proc.NewTTExtractor(ctx, sink, &sconf, alignedColGenFn(&sconf), statusChan)
+		if err != nil {
+			close(statusChan)
+			return err
+		}
+		err = tte.Run(&vertigo.ParserConf{
+			InputFilePath:         verticalFile,
+			StructAttrAccumulator: "nil",
+			Encoding:              conf.Encoding,
+			LogProgressEachNth:    determineLineReportingStep(verticalFile),
+		})
+		close(statusChan)
+		if sink.err != nil {
+			return sink.err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to process %s: %w", verticalFile, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamAtoms(t *testing.T) {
+	conf := createTestConf(t)
+	var numAtoms int
+	err := StreamAtoms(context.Background(), conf, func(atom AtomRecord) error {
+		if numAtoms == 0 {
+			assert.Equal(t, "vert1.txt-0", atom.Attrs["doc_id"])
+			assert.Equal(t, 20, atom.Attrs["poscount"])
+			assert.Equal(t, conf.VerticalFiles[0], atom.File)
+		}
+		numAtoms++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, numAtoms)
+
+	errStop := errors.New("consumer failed")
+	numAtoms = 0
+	err = StreamAtoms(context.Background(), conf, func(atom AtomRecord) error {
+		numAtoms++
+		if numAtoms == 10 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 10, numAtoms)
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/czcorpus/vert-tagextract/v3/verify"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// Verify parses configured vertical files and compares derived
+// atoms (rows of the structural attributes table) with an existing
+// database. The database is not modified. With sampleAtoms > 0, only
+// the first sampleAtoms atoms are compared and the parsing stops then.
+// N-gram data are not compared.
+func Verify(ctx context.Context, conf *cnf.VTEConf, sampleAtoms int) (*verify.Report, error) {
+	if err := conf.Ngrams.UpgradeLegacy(); err != nil {
+		return nil, err
+	}
+	filesToProc, err := resolveVerticalFiles(conf)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := factory.NewDatabaseReader(conf)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	report := &verify.Report{
+		Corpus:    conf.Corpus,
+		Verticals: filesToProc,
+		Examples:  []string{},
+	}
+	report.StoredAtoms, err = reader.RowCount(db.LiveAttrsTable, conf.CorpusID())
+	if err != nil {
+		return nil, err
+	}
+
+	// n-grams are not compared so there is no need to count them
+	vconf := *conf
+	vconf.Ngrams = cnf.NgramConf{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var limitReached atomic.Bool
+	checker := verify.NewChecker(reader, report, sampleAtoms, func() {
+		limitReached.Store(true)
+		cancel()
+	})
+	for _, verticalFile := range filesToProc {
+		if limitReached.Load() {
+			break
+		}
+		log.Info().Str("vertical", verticalFile).Msg("Verifying vertical")
+		statusChan := make(chan proc.Status, 10)
+		go func() {
+			for upd := range statusChan {
+				if upd.Error != nil && !limitReached.Load() {
+					log.Warn().Err(upd.Error).Str("vertical", verticalFile).Msg("vertical processing error")
+				}
+			}
+		}()
+		tte, err := proc.NewTTExtractor(ctx, checker, &vconf, alignedColGenFn(&vconf), statusChan)
+		if err != nil {
+			close(statusChan)
+			return nil, err
+		}
+		err = tte.Run(&vertigo.ParserConf{
+			InputFilePath:         verticalFile,
+			StructAttrAccumulator: "nil",
+			Encoding:              conf.Encoding,
+			LogProgressEachNth:    determineLineReportingStep(verticalFile),
+		})
+		close(statusChan)
+		if err != nil && !limitReached.Load() {
+			return nil, fmt.Errorf("failed to verify %s: %w", verticalFile, err)
+		}
+	}
+	report.Sampled = limitReached.Load()
+	return report, nil
+}
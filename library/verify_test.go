@@ -0,0 +1,54 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package library
+
+import (
+	"context"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db/factory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	conf := createTestConf(t)
+	_, err := Extract(context.Background(), conf, false)
+	assert.NoError(t, err)
+
+	report, err := Verify(context.Background(), conf, 0)
+	assert.NoError(t, err)
+	assert.False(t, report.HasDrift())
+	assert.Equal(t, report.StoredAtoms, report.ParsedAtoms)
+
+	report, err = Verify(context.Background(), conf, 5)
+	assert.NoError(t, err)
+	assert.True(t, report.Sampled)
+	assert.Equal(t, 5, report.ParsedAtoms)
+	assert.False(t, report.HasDrift())
+
+	reader, err := factory.NewDatabaseReader(conf)
+	assert.NoError(t, err)
+	_, err = reader.DB.Exec("DELETE FROM liveattrs_entry WHERE id = 1")
+	assert.NoError(t, err)
+	reader.Close()
+
+	report, err = Verify(context.Background(), conf, 0)
+	assert.NoError(t, err)
+	assert.True(t, report.HasDrift())
+	assert.Equal(t, 1, report.MissingAtoms)
+	assert.Len(t, report.Examples, 1)
+}
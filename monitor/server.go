@@ -0,0 +1,109 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	_ "embed"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/bytedance/sonic"
+	"github.com/rs/zerolog/log"
+)
+
+//go:embed dashboard.html
+var dashboardPage []byte
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// isSameOrigin tests whether a request has been sent by a page
+// served by the dashboard itself. Browsers always attach Origin
+// to POST requests so a page of a different site cannot pass.
+// Requests without Origin (e.g. curl) are accepted.
+func isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// listenAddr returns addr with a missing host replaced
+// by localhost so the dashboard is not exposed by default
+// (e.g. ':8080' => 'localhost:8080')
+func listenAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
+
+// NewServer creates an HTTP server providing a simple
+// dashboard for a running extraction process:
+//
+//   - GET /        - the dashboard page
+//   - GET /status  - current state as JSON
+//   - POST /cancel - stop the process (cross-origin requests are rejected)
+//
+// In case addr does not contain a host, the server listens on localhost only.
+func NewServer(addr string, tracker *Tracker) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboardPage)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, tracker.Snapshot())
+	})
+	mux.HandleFunc("/cancel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !isSameOrigin(r) {
+			log.Warn().
+				Str("remoteAddr", r.RemoteAddr).
+				Str("origin", r.Header.Get("Origin")).
+				Msg("rejected cross-origin cancel request")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		log.Warn().Str("remoteAddr", r.RemoteAddr).Msg("cancel requested via HTTP dashboard")
+		tracker.Cancel()
+		writeJSON(w, http.StatusOK, tracker.Snapshot())
+	})
+	return &http.Server{
+		Addr:    listenAddr(addr),
+		Handler: mux,
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelRejectsCrossOrigin(t *testing.T) {
+	cancelled := false
+	tracker := NewTracker("test", func() { cancelled = true })
+	srv := NewServer(":8080", tracker)
+	assert.Equal(t, "localhost:8080", srv.Addr)
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/cancel", nil)
+	req.Header.Set("Origin", "http://example.com")
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.False(t, cancelled)
+
+	req = httptest.NewRequest(http.MethodPost, "http://localhost:8080/cancel", nil)
+	req.Header.Set("Origin", "http://localhost:8080")
+	rec = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, cancelled)
+}
+
+func TestListenAddr(t *testing.T) {
+	assert.Equal(t, "localhost:8080", listenAddr(":8080"))
+	assert.Equal(t, "0.0.0.0:8080", listenAddr("0.0.0.0:8080"))
+	assert.Equal(t, "127.0.0.1:80", listenAddr("127.0.0.1:80"))
+}
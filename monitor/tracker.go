@@ -0,0 +1,193 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/czcorpus/cnc-gokit/collections"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+)
+
+const (
+	maxRecentErrors      = 50
+	maxThroughputSamples = 300
+
+	// throughputSampleInterval specifies how often (at most) we
+	// store a new throughput value
+	throughputSampleInterval = time.Second
+)
+
+// ErrorRecord is a simplified, JSON-serializable
+// version of an error reported via proc.Status
+type ErrorRecord struct {
+	Datetime time.Time `json:"datetime"`
+	File     string    `json:"file"`
+	Line     int       `json:"line"`
+	Message  string    `json:"message"`
+}
+
+// ThroughputSample describes processing speed
+// (in vertical lines per second) at some point of time
+type ThroughputSample struct {
+	Datetime    time.Time `json:"datetime"`
+	LinesPerSec float64   `json:"linesPerSec"`
+}
+
+// Snapshot is a current state of a tracked extraction run
+type Snapshot struct {
+	Corpus         string             `json:"corpus"`
+	Started        time.Time          `json:"started"`
+	Finished       bool               `json:"finished"`
+	Cancelled      bool               `json:"cancelled"`
+	CurrFile       string             `json:"currFile"`
+	ProcessedLines int                `json:"processedLines"`
+	ProcessedAtoms int                `json:"processedAtoms"`
+	NumErrors      int                `json:"numErrors"`
+	RecentErrors   []ErrorRecord      `json:"recentErrors"`
+	Throughput     []ThroughputSample `json:"throughput"`
+
+	// Files contains results of already processed files
+	Files []proc.FileSummary `json:"files,omitempty"`
+}
+
+// Tracker collects status updates of a running extraction
+// so they can be presented e.g. via an HTTP dashboard.
+// All the methods are safe for concurrent use.
+type Tracker struct {
+	sync.Mutex
+	corpus         string
+	started        time.Time
+	finished       bool
+	cancelled      bool
+	cancelFn       context.CancelFunc
+	currFile       string
+	totalLines     int
+	fileLines      int
+	processedAtoms int
+	numErrors      int
+	lastSample     time.Time
+	lastSampleLine int
+	recentErrors   *collections.CircularList[ErrorRecord]
+	throughput     *collections.CircularList[ThroughputSample]
+	files          []proc.FileSummary
+}
+
+// Update registers a new status obtained from
+// an extraction process.
+func (t *Tracker) Update(status proc.Status) {
+	t.Lock()
+	defer t.Unlock()
+	if status.File != "" && status.File != t.currFile {
+		t.totalLines += t.fileLines
+		t.fileLines = 0
+		t.currFile = status.File
+	}
+	if status.FileSummary != nil {
+		t.files = append(t.files, *status.FileSummary)
+		return
+	}
+	if status.ProcessedLines > t.fileLines {
+		t.fileLines = status.ProcessedLines
+	}
+	if status.ProcessedAtoms > 0 {
+		t.processedAtoms = status.ProcessedAtoms
+	}
+	if status.Error != nil {
+		t.numErrors++
+		t.recentErrors.Append(ErrorRecord{
+			Datetime: status.Datetime,
+			File:     status.File,
+			Line:     status.ProcessedLines,
+			Message:  status.Error.Error(),
+		})
+	}
+	now := time.Now()
+	if now.Sub(t.lastSample) >= throughputSampleInterval {
+		lines := t.totalLines + t.fileLines
+		t.throughput.Append(ThroughputSample{
+			Datetime:    now,
+			LinesPerSec: float64(lines-t.lastSampleLine) / now.Sub(t.lastSample).Seconds(),
+		})
+		t.lastSample = now
+		t.lastSampleLine = lines
+	}
+}
+
+// SetFinished marks the tracked process as finished
+func (t *Tracker) SetFinished() {
+	t.Lock()
+	defer t.Unlock()
+	t.finished = true
+}
+
+// Cancel stops the tracked process (if still running).
+func (t *Tracker) Cancel() {
+	t.Lock()
+	defer t.Unlock()
+	if !t.finished && !t.cancelled {
+		t.cancelled = true
+		t.cancelFn()
+	}
+}
+
+// Snapshot returns a copy of the current state
+func (t *Tracker) Snapshot() Snapshot {
+	t.Lock()
+	defer t.Unlock()
+	ans := Snapshot{
+		Corpus:         t.corpus,
+		Started:        t.started,
+		Finished:       t.finished,
+		Cancelled:      t.cancelled,
+		CurrFile:       t.currFile,
+		ProcessedLines: t.totalLines + t.fileLines,
+		ProcessedAtoms: t.processedAtoms,
+		NumErrors:      t.numErrors,
+		RecentErrors:   make([]ErrorRecord, 0, t.recentErrors.Len()),
+		Throughput:     make([]ThroughputSample, 0, t.throughput.Len()),
+	}
+	if len(t.files) > 0 {
+		ans.Files = make([]proc.FileSummary, len(t.files))
+		copy(ans.Files, t.files)
+	}
+	t.recentErrors.ForEach(func(i int, item ErrorRecord) bool {
+		ans.RecentErrors = append(ans.RecentErrors, item)
+		return true
+	})
+	t.throughput.ForEach(func(i int, item ThroughputSample) bool {
+		ans.Throughput = append(ans.Throughput, item)
+		return true
+	})
+	return ans
+}
+
+// NewTracker creates a new tracker for a process
+// which can be stopped by the provided cancelFn.
+func NewTracker(corpus string, cancelFn context.CancelFunc) *Tracker {
+	now := time.Now()
+	return &Tracker{
+		corpus:       corpus,
+		started:      now,
+		lastSample:   now,
+		cancelFn:     cancelFn,
+		recentErrors: collections.NewCircularList[ErrorRecord](maxRecentErrors),
+		throughput:   collections.NewCircularList[ThroughputSample](maxThroughputSamples),
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/czcorpus/cnc-gokit/mail"
+	"github.com/rs/zerolog/log"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+)
+
+const (
+	webhookTimeout = 30 * time.Second
+)
+
+func postWebhook(url string, payload []byte) error {
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to call webhook %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to call webhook %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func sendEmail(conf *mail.NotificationConf, summary *proc.Summary) error {
+	state := "finished"
+	if summary.Failed {
+		state = "FAILED"
+	}
+	msg := mail.Notification{
+		Subject: fmt.Sprintf("vert-tagextract: %s %s", summary.Corpus, state),
+		Paragraphs: []string{
+			fmt.Sprintf("Data extraction for corpus %s %s.", summary.Corpus, state),
+			fmt.Sprintf("started: %s", summary.Started.Format(time.RFC3339)),
+			fmt.Sprintf("finished: %s", summary.Finished.Format(time.RFC3339)),
+			fmt.Sprintf("processed files: %d", summary.ProcessedFiles),
+			fmt.Sprintf("processed lines: %d", summary.ProcessedLines),
+			fmt.Sprintf("processed atoms: %d", summary.ProcessedAtoms),
+			fmt.Sprintf("number of errors: %d", summary.NumErrors),
+		},
+	}
+	for _, fs := range summary.FailedFiles() {
+		msg.Paragraphs = append(
+			msg.Paragraphs,
+			fmt.Sprintf("file %s: %d error(s), last: %s", fs.File, fs.NumErrors, fs.LastError))
+	}
+	if summary.LastError != "" {
+		msg.Paragraphs = append(msg.Paragraphs, fmt.Sprintf("last error: %s", summary.LastError))
+	}
+	if err := mail.SendNotification(conf, time.Local, msg); err != nil {
+		return fmt.Errorf("failed to send e-mail notification: %w", err)
+	}
+	return nil
+}
+
+// Send sends notifications about a finished run as configured.
+// All the configured channels are always tried, the returned error
+// joins all the errors encountered.
+func Send(conf *cnf.NotificationConf, summary *proc.Summary) error {
+	if conf.OnlyFailures && !summary.Failed {
+		return nil
+	}
+	var errs []error
+	if len(conf.Webhooks) > 0 {
+		payload, err := sonic.Marshal(summary)
+		if err != nil {
+			return fmt.Errorf("failed to send notifications: %w", err)
+		}
+		for _, url := range conf.Webhooks {
+			if err := postWebhook(url, payload); err != nil {
+				errs = append(errs, err)
+
+			} else {
+				log.Info().Str("url", url).Msg("sent webhook notification")
+			}
+		}
+	}
+	if conf.Email != nil && len(conf.Email.Recipients) > 0 {
+		if err := sendEmail(conf.Email, summary); err != nil {
+			errs = append(errs, err)
+
+		} else {
+			log.Info().Strs("recipients", conf.Email.Recipients).Msg("sent e-mail notification")
+		}
+	}
+	return errors.Join(errs...)
+}
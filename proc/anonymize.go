@@ -0,0 +1,50 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// redactStructAttrs applies configured redaction rules
+// (see cnf.AnonymizeConf) to attributes of a structure
+func (tte *TTExtractor) redactStructAttrs(st *vertigo.Structure) {
+	if tte.anonymize == nil {
+		return
+	}
+	for _, rule := range tte.anonymize.Rules {
+		attr, found := strings.CutPrefix(rule.Attr, st.Name+"_")
+		if !found {
+			continue
+		}
+		if v, ok := st.Attrs[attr]; ok {
+			st.Attrs[attr] = tte.anonymize.Redact(rule, v)
+			if tte.pseudonyms != nil && rule.Action == cnf.RedactHash && v != "" {
+				tte.pseudonyms.Add(rule.Attr, st.Attrs[attr], v)
+			}
+		}
+	}
+}
+
+// SetPseudonymDict sets a dictionary collecting original values
+// of hashed attributes (see cnf.AnonymizeConf.MappingFile)
+func (tte *TTExtractor) SetPseudonymDict(pd *PseudonymDict) {
+	tte.pseudonyms = pd
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"github.com/czcorpus/vert-tagextract/v3/ptcount"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// arfPass is a vertigo.LineProcessor used for the second pass
+// over a vertical file to calculate ARF. It uses the same structure
+// accumulator kind, token handling and atom boundary detection as
+// TTExtractor so the n-grams are exactly the same as the counted ones
+// (including nested atom and atom parent configurations). Errors are
+// ignored as they have been already handled in the first pass.
+//
+// The main filter is applied too and positions used for ARF are
+// indices within the stream of accepted tokens, i.e. ARF describes
+// the selected subcorpus.
+type arfPass struct {
+	tte              *TTExtractor
+	calc             *ptcount.ARFCalculator
+	attrAccum        AttrAccumulator
+	window           *ptcount.NgramWindow
+	lastAtomOpenLine int
+
+	// sampling selects the same atoms as the first pass
+	sampling sampleState
+
+	// stop stops the pass once the sample is complete
+	stop func()
+
+	// pos is a global position of the current token within
+	// the stream of tokens accepted by the main filter (see
+	// TTExtractor.GetNumAcceptedTokens, CorpusCounts)
+	pos int
+}
+
+func (p *arfPass) ProcToken(tk *vertigo.Token, line int, err error) error {
+	if err != nil || p.sampling.ignores() || !p.tte.filter.Apply(tk, p.attrAccum) {
+		return nil
+	}
+	switch p.tte.ngramTokenAction(tk, p.attrAccum) {
+	case tokenDrop:
+		p.pos++
+		return nil
+	case tokenBoundary:
+		p.pos++
+		p.window.Reset()
+		return nil
+	}
+	attributes, _, err := p.tte.encodeTokenAttributes(tk, line)
+	if err != nil || attributes == nil {
+		// tokens skipped by the 1st pass are not included in
+		// the token total so they do not occupy any position
+		p.window.Reset()
+		return nil
+	}
+	if ngram := p.window.Add(attributes, p.tte.columnModders, p.tte.valueDict); ngram != nil {
+		p.calc.AddOccurrence(ngram.UniqueID(), p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *arfPass) ProcStruct(st *vertigo.Structure, line int, err error) error {
+	if err != nil || st == nil || p.sampling.complete {
+		return nil
+	}
+	if err := p.attrAccum.begin(line, st); err != nil {
+		return nil
+	}
+	if st.IsEmpty {
+		p.attrAccum.end(line, st.Name)
+	}
+	if st.Name == p.tte.atomStruct {
+		p.sampling.atomOpen(line)
+
+	} else {
+		p.sampling.structOpen(line)
+	}
+	if p.sampling.complete {
+		p.stop()
+		return nil
+	}
+	if st.Name == p.tte.atomStruct {
+		if p.sampling.skipping && st.IsEmpty {
+			p.sampling.atomClose()
+		}
+		p.lastAtomOpenLine = line
+	}
+	return nil
+}
+
+func (p *arfPass) ProcStructClose(st *vertigo.StructureClose, line int, err error) error {
+	if err != nil || p.sampling.complete {
+		return nil
+	}
+	accumItem, err := p.attrAccum.end(line, st.Name)
+	if err != nil {
+		return nil
+	}
+	if accumItem.elm.Name == p.tte.atomStruct {
+		wasSkipping := p.sampling.skipping
+		p.sampling.atomClose()
+		if wasSkipping {
+			return nil
+		}
+
+	} else if p.sampling.skipping {
+		return nil
+	}
+	if p.tte.isAtomEnd(accumItem, p.lastAtomOpenLine) {
+		p.window.Reset()
+	}
+	return nil
+}
+
+// newARFPass creates a processor for the ARF pass. It must be
+// called after the first pass is finished. The stop function
+// is expected to stop the pass without an error.
+func (tte *TTExtractor) newARFPass(calc *ptcount.ARFCalculator, stop func()) *arfPass {
+	var accum AttrAccumulator
+	if _, ok := tte.attrAccum.(*structStack); ok {
+		accum = newStructStack()
+
+	} else {
+		accum = newDefaultAccum()
+	}
+	return &arfPass{
+		tte:              tte,
+		calc:             calc,
+		attrAccum:        accum,
+		window:           ptcount.NewNgramWindow(tte.ngramConf),
+		lastAtomOpenLine: -1,
+		sampling:         newSampleState(tte.sampler, tte.sampleFrom),
+		stop:             stop,
+		pos:              tte.positionOffset,
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/czcorpus/vert-tagextract/v3/status"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+const (
+	// MaxReportedColumnCountViolations limits number of violation
+	// locations kept in a run summary
+	MaxReportedColumnCountViolations = 100
+)
+
+var (
+	ErrorTooManyColumnCountViolations = errors.New("too many column count violations")
+)
+
+// ColumnCountViolation describes a token line with a number
+// of columns different from the first token line of the run
+type ColumnCountViolation = status.ColumnCountViolation
+
+// ColumnCountChecker checks that all the token lines of a run
+// (possibly spread among multiple files) have the same number of columns
+// as the first one. It is not thread-safe - files of a run must be
+// processed sequentially.
+type ColumnCountChecker struct {
+	tolerance     int
+	expected      int
+	numViolations int
+}
+
+// Check tests a token against the expected number of columns. In case of
+// a violation, its description is returned. Once the number of violations
+// exceeds the tolerance, ErrorTooManyColumnCountViolations is returned.
+func (cc *ColumnCountChecker) Check(tk *vertigo.Token, line int) (*ColumnCountViolation, error) {
+	numColumns := len(tk.Attrs) + 1
+	if cc.expected == 0 {
+		cc.expected = numColumns
+		return nil, nil
+	}
+	if numColumns == cc.expected {
+		return nil, nil
+	}
+	cc.numViolations++
+	violation := &ColumnCountViolation{
+		Line:       line,
+		NumColumns: numColumns,
+		Expected:   cc.expected,
+	}
+	if cc.numViolations > cc.tolerance {
+		return violation, fmt.Errorf("%w (tolerance: %d)", ErrorTooManyColumnCountViolations, cc.tolerance)
+	}
+	return violation, nil
+}
+
+// NumViolations returns total number of violations found so far
+func (cc *ColumnCountChecker) NumViolations() int {
+	return cc.numViolations
+}
+
+// NewColumnCountChecker creates a new checker allowing
+// up to `tolerance` violations
+func NewColumnCountChecker(tolerance int) *ColumnCountChecker {
+	return &ColumnCountChecker{tolerance: tolerance}
+}
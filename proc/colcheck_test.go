@@ -0,0 +1,44 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+func TestColumnCountChecker(t *testing.T) {
+	cc := NewColumnCountChecker(1)
+	v, err := cc.Check(&vertigo.Token{Word: "a", Attrs: []string{"b", "c"}}, 1)
+	assert.Nil(t, v)
+	assert.NoError(t, err)
+
+	v, err = cc.Check(&vertigo.Token{Word: "a", Attrs: []string{"b"}}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &ColumnCountViolation{Line: 2, NumColumns: 2, Expected: 3}, v)
+
+	v, err = cc.Check(&vertigo.Token{Word: "a", Attrs: []string{"b", "c"}}, 3)
+	assert.Nil(t, v)
+	assert.NoError(t, err)
+
+	v, err = cc.Check(&vertigo.Token{Word: "a", Attrs: []string{"b", "c", "d"}}, 4)
+	assert.NotNil(t, v)
+	assert.ErrorIs(t, err, ErrorTooManyColumnCountViolations)
+	assert.Equal(t, 2, cc.NumViolations())
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"github.com/czcorpus/vert-tagextract/v3/ptcount"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// countedFile is a vertical file with counts collected
+// by CorpusCounts. The extractor is kept for the ARF pass.
+type countedFile struct {
+	tte  *TTExtractor
+	conf *vertigo.ParserConf
+}
+
+// CorpusCounts collects n-gram (colcounts) and UD features counts
+// of multiple vertical files so they are stored just once for
+// the whole corpus (see TTExtractor.SetCorpusCounts). In case ARF
+// is configured, it is calculated over all the collected files using
+// global token positions.
+type CorpusCounts struct {
+	wordDict  *ptcount.WordDict
+	counts    map[string]*ptcount.NgramCounter
+	udFeats   map[udFeatKey]int
+	numTokens int
+	arfTokens int
+	files     []countedFile
+
+	// last is the last added extractor used to store the counts
+	last *TTExtractor
+}
+
+// NewCorpusCounts creates a new CorpusCounts instance. All the counted
+// n-grams are encoded using wd (nil = a new empty dictionary).
+func NewCorpusCounts(wd *ptcount.WordDict) *CorpusCounts {
+	if wd == nil {
+		wd = ptcount.NewWordDict()
+	}
+	return &CorpusCounts{
+		wordDict: wd,
+		counts:   make(map[string]*ptcount.NgramCounter),
+		udFeats:  make(map[udFeatKey]int),
+	}
+}
+
+// WordDict returns the dictionary shared by all the collected files
+func (cc *CorpusCounts) WordDict() *ptcount.WordDict {
+	return cc.wordDict
+}
+
+// NumTokens returns number of accepted tokens of all the collected files
+func (cc *CorpusCounts) NumTokens() int {
+	return cc.numTokens
+}
+
+// ARFTokens returns the token total used to calculate ARF
+// (zero in case ARF has not been calculated)
+func (cc *CorpusCounts) ARFTokens() int {
+	return cc.arfTokens
+}
+
+// add merges counts of a successfully processed file. Positions
+// of the file's tokens continue from the previously added file.
+func (cc *CorpusCounts) add(tte *TTExtractor, conf *vertigo.ParserConf) {
+	for k, v := range tte.colCounts {
+		if cnt, ok := cc.counts[k]; ok {
+			cnt.AddCount(v.Count())
+
+		} else {
+			cc.counts[k] = v
+		}
+	}
+	for k, v := range tte.udFeatCounts {
+		cc.udFeats[k] += v
+	}
+	tte.colCounts = nil
+	tte.udFeatCounts = nil
+	tte.positionOffset = cc.numTokens
+	cc.numTokens += tte.GetNumAcceptedTokens()
+	cc.last = tte
+	if tte.ngramConf.CalcARF {
+		cc.files = append(cc.files, countedFile{tte: tte, conf: conf})
+	}
+	log.Info().
+		Str("file", conf.InputFilePath).
+		Int("positionOffset", tte.positionOffset).
+		Int("numNgrams", len(cc.counts)).
+		Msg("file counts collected")
+}
+
+// Store calculates ARF (if configured) and stores the collected
+// counts using the database of the last added extractor. It should
+// be called once all the files are processed. Progress is reported
+// via statusChan.
+func (cc *CorpusCounts) Store(statusChan chan<- Status) error {
+	if cc.last == nil {
+		return nil
+	}
+	if len(cc.files) > 0 {
+		log.Info().
+			Int("numFiles", len(cc.files)).
+			Msg("calculating ARF (processing the verticals again)")
+		calc := ptcount.NewARFCalculator(cc.counts, cc.numTokens)
+		for _, f := range cc.files {
+			if err := f.tte.calcARF(f.conf, calc); err != nil {
+				return err
+			}
+		}
+		calc.Finalize()
+		cc.arfTokens = calc.NumTokens()
+	}
+	log.Info().Msg("Saving defined positional attributes counts into the database")
+	if err := cc.last.insertCounts(cc.counts, statusChan); err != nil {
+		return err
+	}
+	if len(cc.udFeats) > 0 {
+		log.Info().Msg("Saving UD features counts into the database")
+		if err := cc.last.insertUDFeatCounts(cc.udFeats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
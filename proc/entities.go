@@ -0,0 +1,84 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"html"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+const (
+	// maxEntityDecodingRounds limits repeated decoding
+	// of HTML entities (see cnf.HTMLEntitiesDecodeRepeated)
+	maxEntityDecodingRounds = 5
+)
+
+// decodeEntities decodes HTML entities in v. With repeated set to true,
+// decoding is repeated as long as the value changes. The second returned
+// value tells whether the value was (or still is in case of a single
+// decoding) double-encoded.
+func decodeEntities(v string, repeated bool) (string, bool) {
+	if !strings.Contains(v, "&") {
+		return v, false
+	}
+	ans := html.UnescapeString(v)
+	if ans == v || !strings.Contains(ans, "&") {
+		return ans, false
+	}
+	next := html.UnescapeString(ans)
+	if next == ans {
+		return ans, false
+	}
+	if !repeated {
+		return ans, true
+	}
+	for i := 2; i < maxEntityDecodingRounds && next != ans; i++ {
+		ans = next
+		next = html.UnescapeString(ans)
+	}
+	return next, true
+}
+
+// decodeStructAttrEntities decodes HTML entities in exported attributes
+// of a structure according to the configured policy
+// (see cnf.HTMLEntitiesKeep etc.)
+func (tte *TTExtractor) decodeStructAttrEntities(st *vertigo.Structure, line int) {
+	if tte.htmlEntitiesPolicy == cnf.HTMLEntitiesKeep {
+		return
+	}
+	repeated := tte.htmlEntitiesPolicy == cnf.HTMLEntitiesDecodeRepeated
+	for _, attr := range tte.structures[st.Name] {
+		v, ok := st.Attrs[attr]
+		if !ok {
+			continue
+		}
+		decoded, doubleEncoded := decodeEntities(v, repeated)
+		st.Attrs[attr] = decoded
+		if doubleEncoded {
+			log.Warn().
+				Int("lineNumber", line).
+				Str("structure", st.Name).
+				Str("attr", attr).
+				Bool("fixed", repeated).
+				Msg("double-encoded HTML entities in structural attribute value")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeEntities(t *testing.T) {
+	v, double := decodeEntities("Tom &amp; Jerry", false)
+	assert.Equal(t, "Tom & Jerry", v)
+	assert.False(t, double)
+
+	v, double = decodeEntities("&quot;Tom&quot; &amp;amp; Jerry", false)
+	assert.Equal(t, "\"Tom\" &amp; Jerry", v)
+	assert.True(t, double)
+
+	v, double = decodeEntities("Tom &amp;amp;amp; Jerry", true)
+	assert.Equal(t, "Tom & Jerry", v)
+	assert.True(t, double)
+
+	v, double = decodeEntities("Tom & Jerry", true)
+	assert.Equal(t, "Tom & Jerry", v)
+	assert.False(t, double)
+
+	v, double = decodeEntities("no entities", true)
+	assert.Equal(t, "no entities", v)
+	assert.False(t, double)
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import "fmt"
+
+// errorBudget counts processing errors and decides whether
+// the processing should stop. Without configured budgets, all
+// the errors share a single limit. Otherwise, each category
+// has its own limit so a single noisy category cannot consume
+// the budget of the others.
+type errorBudget struct {
+	maxNumErrors int
+	budgets      map[string]int
+	total        int
+	counts       map[string]int
+}
+
+func newErrorBudget(maxNumErrors int, budgets map[string]int) *errorBudget {
+	return &errorBudget{
+		maxNumErrors: maxNumErrors,
+		budgets:      budgets,
+		counts:       make(map[string]int),
+	}
+}
+
+func (eb *errorBudget) limit(category string) int {
+	if v, ok := eb.budgets[category]; ok {
+		return v
+	}
+	return eb.maxNumErrors
+}
+
+// add counts an error of a category and returns ErrorTooManyParsingErrors
+// (possibly wrapped) in case the respective limit is exceeded
+func (eb *errorBudget) add(category string) error {
+	eb.total++
+	eb.counts[category]++
+	if len(eb.budgets) == 0 {
+		if eb.total > eb.maxNumErrors {
+			return ErrorTooManyParsingErrors
+		}
+		return nil
+	}
+	if eb.counts[category] > eb.limit(category) {
+		return fmt.Errorf("%w (category %s)", ErrorTooManyParsingErrors, category)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorBudgetTotal(t *testing.T) {
+	eb := newErrorBudget(2, nil)
+	assert.NoError(t, eb.add(cnf.ErrCategoryParse))
+	assert.NoError(t, eb.add(cnf.ErrCategoryInsert))
+	assert.ErrorIs(t, eb.add(cnf.ErrCategoryColumn), ErrorTooManyParsingErrors)
+}
+
+func TestErrorBudgetCategories(t *testing.T) {
+	eb := newErrorBudget(1, map[string]int{cnf.ErrCategoryColumn: 3, cnf.ErrCategoryInsert: 0})
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, eb.add(cnf.ErrCategoryColumn))
+	}
+	assert.NoError(t, eb.add(cnf.ErrCategoryParse))
+	err := eb.add(cnf.ErrCategoryColumn)
+	assert.ErrorIs(t, err, ErrorTooManyParsingErrors)
+	assert.ErrorContains(t, err, "category column")
+	assert.ErrorIs(t, eb.add(cnf.ErrCategoryParse), ErrorTooManyParsingErrors)
+	assert.ErrorIs(t, eb.add(cnf.ErrCategoryInsert), ErrorTooManyParsingErrors)
+}
@@ -39,7 +39,10 @@ type LineFilter interface {
 	Apply(tk *vertigo.Token, attrAcc AttrAccumulator) bool
 }
 
-func findPluginLib(pathSuff string) (string, error) {
+// FindPluginLib searches for a plug-in file. In case pathSuff does
+// not point to an existing file, the working directory and
+// the default system plug-in directory are tried.
+func FindPluginLib(pathSuff string) (string, error) {
 	paths := []string{
 		pathSuff,
 		filepath.Join(fs.GetWorkingDir(), pathSuff),
@@ -70,7 +73,7 @@ func (df *PassAllFilter) Apply(tk *vertigo.Token, attrAcc AttrAccumulator) bool
 // directory, /usr/local/lib/gloomy).
 func LoadCustomFilter(libPath string, fn string) (LineFilter, error) {
 	if libPath != "" && fn != "" {
-		fullPath, err := findPluginLib(libPath)
+		fullPath, err := FindPluginLib(libPath)
 		if err != nil {
 			return nil, err
 		}
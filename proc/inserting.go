@@ -18,9 +18,10 @@ package proc
 
 import (
 	"context"
-	"crypto/sha1"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -31,6 +32,8 @@ import (
 	"github.com/czcorpus/vert-tagextract/v3/db/colgen"
 	"github.com/czcorpus/vert-tagextract/v3/ptcount"
 	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
+	"github.com/czcorpus/vert-tagextract/v3/status"
+	"github.com/czcorpus/vert-tagextract/v3/ud"
 
 	_ "github.com/mattn/go-sqlite3" // sqlite3 driver load
 	"github.com/tomachalek/vertigo/v6"
@@ -54,39 +57,179 @@ type Status struct {
 	File           string
 	ProcessedAtoms int
 	ProcessedLines int
+
+	// MissingColumns is number of counted column values
+	// missing in tokens (see cnf.NgramConf.MissingColumn)
+	MissingColumns int
 	Error          error
+
+	// ErrorCategory is set along with Error in case the error
+	// is counted in an error budget (see cnf.ErrorCategories)
+	ErrorCategory string
+
+	// ProcessedTokens and AcceptedTokens (by the configured filter)
+	// are reported once a file is processed
+	ProcessedTokens int
+	AcceptedTokens  int
+
+	// NormalizedValues is a number of structural attribute values
+	// changed by whitespace normalization (reported once a file
+	// is processed)
+	NormalizedValues int
+
+	// ColumnCountViolation is set in case a token line has
+	// a different number of columns than expected (see ColumnCountChecker)
+	ColumnCountViolation *ColumnCountViolation
+
+	// FileSummary is set once processing of a file is finished
+	// (including possible errors reported after the parsing)
+	FileSummary *FileSummary
+}
+
+// Record returns a JSON-serializable form of the status
+func (s Status) Record() status.Record {
+	ans := status.Record{
+		Datetime:             s.Datetime,
+		File:                 s.File,
+		ProcessedAtoms:       s.ProcessedAtoms,
+		ProcessedLines:       s.ProcessedLines,
+		FileSummary:          s.FileSummary,
+		ErrorCategory:        s.ErrorCategory,
+		MissingColumns:       s.MissingColumns,
+		ProcessedTokens:      s.ProcessedTokens,
+		AcceptedTokens:       s.AcceptedTokens,
+		NormalizedValues:     s.NormalizedValues,
+		ColumnCountViolation: s.ColumnCountViolation,
+	}
+	if s.Error != nil {
+		ans.Error = s.Error.Error()
+	}
+	return ans
+}
+
+// udFeatKey identifies a single UD feature value
+// within a counted column
+type udFeatKey struct {
+	col   int
+	name  string
+	value string
+}
+
+// attrValueKey identifies a value of a structural attribute
+type attrValueKey struct {
+	attr  string
+	value string
+}
+
+// attrPairKey identifies a combination of values
+// of two structural attributes
+type attrPairKey struct {
+	attr1  string
+	value1 string
+	attr2  string
+	value2 string
+}
+
+// truncateAttrValue shortens a value to the max. size
+// of stored attribute values (db.DfltColcountVarcharSize)
+func truncateAttrValue(v string) string {
+	if utf8.RuneCountInString(v) <= db.DfltColcountVarcharSize {
+		return v
+	}
+	return string([]rune(v)[:db.DfltColcountVarcharSize])
 }
 
 // TTExtractor handles writing parsed data
 // to a sqlite3 database. Parsed values are
 // received pasivelly by implementing vertigo.LineProcessor
 type TTExtractor struct {
-	ctx                context.Context
-	atomCounter        int
-	lineCounter        int
-	errorCounter       int
-	maxNumErrors       int
-	tokenInAtomCounter int
-	tokenCounter       int
-	corpusID           string
-	database           db.Writer
-	docInsert          db.InsertOperation
-	dbConf             *db.Conf
-	attrAccum          AttrAccumulator
-	atomStruct         string
-	atomParentStruct   string
-	lastAtomOpenLine   int
-	structures         map[string][]string
-	attrNames          []string
-	colgenFn           colgen.AlignedColGenFn
-	currAtomAttrs      map[string]interface{}
-	ngramConf          *cnf.NgramConf
-	currSentence       [][]int
-	valueDict          *ptcount.WordDict
-	columnModders      []*modders.StringTransformerChain
-	colCounts          map[string]*ptcount.NgramCounter
-	filter             LineFilter
-	statusChan         chan<- Status
+	ctx                   context.Context
+	atomCounter           int
+	lineCounter           int
+	errorBudget           *errorBudget
+	tokenInAtomCounter    int
+	stats                 *CorpusStats
+	missingColumnsCounter int
+	missingColumnPolicy   string
+	invalidUTF8Policy     string
+	htmlEntitiesPolicy    string
+	whitespacePolicy      string
+	normalizedValues      int
+	anonymize             *cnf.AnonymizeConf
+	pseudonyms            *PseudonymDict
+	columnRangeReported   bool
+	columnCountChecker    *ColumnCountChecker
+	ngramSeparator        string
+	hashIDFn              ptcount.HashIDFunc
+	hashBuff              []byte
+	udFeatsColumns        db.VertColumns
+	udFeatCounts          map[udFeatKey]int
+	attrValuesAttrs       []string
+	attrValues            map[attrValueKey]*db.AttrValueCount
+	attrPairs             [][2]string
+	attrPairCounts        map[attrPairKey]*db.AttrPairCount
+	corpusID              string
+	database              db.Writer
+	docInsert             db.InsertOperation
+	dbConf                *db.Conf
+	attrAccum             AttrAccumulator
+	atomStruct            string
+	atomParentStruct      string
+	syntheticAtoms        *cnf.SyntheticAtomsConf
+	lastAtomOpenLine      int
+	structures            map[string][]string
+	attrNames             []string
+	colgenFn              colgen.AlignedColGenFn
+	tokenArgColumns       []int
+	tokenArgLimit         int
+	atomTokenArgs         [][]string
+	currAtomAttrs         map[string]interface{}
+	ngramConf             *cnf.NgramConf
+	window                *ptcount.NgramWindow
+	valueDict             *ptcount.WordDict
+	columnModders         []*modders.StringTransformerChain
+	colCounts             map[string]*ptcount.NgramCounter
+	filter                LineFilter
+
+	// ngramFilter selects tokens (already accepted by filter)
+	// to be counted as n-grams (see cnf.NgramConf.Filter)
+	ngramFilter LineFilter
+
+	// columnConstraints exclude tokens with unwanted values
+	// of counted columns from counting
+	columnConstraints ptcount.ColumnConstraints
+
+	// punctuation specifies how punctuation tokens are counted
+	punctuation *ptcount.PunctuationHandler
+
+	// positionOffset is a global position of the first accepted
+	// token of the processed file (set once the file is added
+	// to corpusCounts)
+	positionOffset int
+
+	// corpusCounts collects counts of all the files of a run
+	// (nil = counts are stored at the end of Run, see SetCorpusCounts)
+	corpusCounts *CorpusCounts
+
+	// sampler selects atoms to be processed (nil = all the atoms,
+	// see SetAtomSampler)
+	sampler *AtomSampler
+
+	// sampleFrom is a global position of the sampler
+	// at the beginning of the file
+	sampleFrom SamplerPosition
+
+	sampling sampleState
+
+	// pkAttrs contains sorted names of columns used to derive
+	// deterministic ids of liveattrs rows (see db.PrimaryKeyValuesHash)
+	pkAttrs []string
+
+	// stopParsing stops the current pass over the vertical
+	// without an error (e.g. once a sample is complete)
+	stopParsing context.CancelFunc
+
+	statusChan chan<- Status
 }
 
 // NewTTExtractor is a factory function to
@@ -102,28 +245,136 @@ func NewTTExtractor(
 	if err != nil {
 		return nil, err
 	}
+	var ngramFilter LineFilter = &PassAllFilter{}
+	if conf.Ngrams.Filter != nil {
+		ngramFilter, err = LoadCustomFilter(conf.Ngrams.Filter.Lib, conf.Ngrams.Filter.Fn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load n-gram filter: %w", err)
+		}
+	}
+	missingColumnPolicy, err := conf.Ngrams.MissingColumnPolicy()
+	if err != nil {
+		return nil, err
+	}
+	invalidUTF8Policy, err := conf.InvalidUTF8Policy()
+	if err != nil {
+		return nil, err
+	}
+	htmlEntitiesPolicy, err := conf.HTMLEntitiesPolicy()
+	if err != nil {
+		return nil, err
+	}
+	whitespacePolicy, err := conf.WhitespacePolicy()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conf.Ngrams.WindowMode(); err != nil {
+		return nil, err
+	}
+	if err := conf.Ngrams.VertColumns.Validate(); err != nil {
+		return nil, err
+	}
+	columnConstraints, err := ptcount.NewColumnConstraints(conf.Ngrams.VertColumns)
+	if err != nil {
+		return nil, err
+	}
+	ngramSeparator, err := conf.Ngrams.NgramSeparator()
+	if err != nil {
+		return nil, err
+	}
+	hashIDFn, err := ptcount.NewHashIDFunc(conf.Ngrams.HashIDAlgorithm())
+	if err != nil {
+		return nil, err
+	}
+	numBucket, err := modders.NewNumBucket(conf.Ngrams.NumericBuckets)
+	if err != nil {
+		return nil, err
+	}
+	punctuation, err := ptcount.NewPunctuationHandler(&conf.Ngrams)
+	if err != nil {
+		return nil, err
+	}
 	ans := &TTExtractor{
-		ctx:              ctx,
-		database:         database,
-		dbConf:           &conf.DB,
-		corpusID:         conf.Corpus,
-		atomStruct:       conf.AtomStructure,
-		atomParentStruct: conf.AtomParentStructure,
-		lastAtomOpenLine: -1,
-		structures:       conf.Structures,
-		colgenFn:         colgenFn,
-		ngramConf:        &conf.Ngrams,
-		colCounts:        make(map[string]*ptcount.NgramCounter),
-		columnModders:    make([]*modders.StringTransformerChain, conf.Ngrams.VertColumns.MaxColumn()+1),
-		filter:           filter,
-		maxNumErrors:     conf.MaxNumErrors,
-		currSentence:     make([][]int, 0, 20),
-		valueDict:        ptcount.NewWordDict(),
-		statusChan:       statusChan,
+		ctx:                 ctx,
+		database:            database,
+		dbConf:              &conf.DB,
+		corpusID:            conf.CorpusID(),
+		atomStruct:          conf.AtomStructure,
+		atomParentStruct:    conf.AtomParentStructure,
+		lastAtomOpenLine:    -1,
+		structures:          conf.Structures,
+		colgenFn:            colgenFn,
+		ngramConf:           &conf.Ngrams,
+		colCounts:           make(map[string]*ptcount.NgramCounter),
+		columnModders:       make([]*modders.StringTransformerChain, conf.Ngrams.VertColumns.MaxColumn()+1),
+		filter:              filter,
+		ngramFilter:         ngramFilter,
+		columnConstraints:   columnConstraints,
+		punctuation:         punctuation,
+		errorBudget:         newErrorBudget(conf.MaxNumErrors, conf.ErrorBudgets),
+		window:              ptcount.NewNgramWindow(&conf.Ngrams),
+		valueDict:           ptcount.NewWordDict(),
+		stats:               NewCorpusStats(),
+		missingColumnPolicy: missingColumnPolicy,
+		invalidUTF8Policy:   invalidUTF8Policy,
+		htmlEntitiesPolicy:  htmlEntitiesPolicy,
+		whitespacePolicy:    whitespacePolicy,
+		ngramSeparator:      ngramSeparator,
+		hashIDFn:            hashIDFn,
+		statusChan:          statusChan,
 	}
 
 	for _, m := range conf.Ngrams.VertColumns {
 		ans.columnModders[m.Idx] = modders.NewStringTransformerChain(m.ModFn)
+		ans.columnModders[m.Idx].SetNumBucket(numBucket)
+		switch m.UDFeats {
+		case "":
+		case db.UDFeatsNormalize, db.UDFeatsExplode:
+			ans.columnModders[m.Idx].Prepend(modders.UDFeats{})
+		default:
+			return nil, fmt.Errorf("invalid udFeats value '%s' for column %d", m.UDFeats, m.Idx)
+		}
+	}
+	if conf.SelfJoin.UsesTokens() {
+		for _, col := range conf.SelfJoin.TokenColumns {
+			if col < 0 {
+				return nil, fmt.Errorf("invalid selfJoin token column %d", col)
+			}
+		}
+		ans.tokenArgColumns = conf.SelfJoin.TokenColumns
+		ans.tokenArgLimit = conf.SelfJoin.GetTokenLimit()
+		ans.atomTokenArgs = make([][]string, len(ans.tokenArgColumns))
+	}
+	if conf.AttrValues.Enabled {
+		var err error
+		ans.attrValuesAttrs, err = conf.AttrValues.SelectedAttrs(conf.Structures)
+		if err != nil {
+			return nil, err
+		}
+		ans.attrValues = make(map[attrValueKey]*db.AttrValueCount)
+	}
+	if conf.SyntheticAtoms.Enabled {
+		if err := conf.SyntheticAtoms.Validate(conf); err != nil {
+			return nil, err
+		}
+		ans.syntheticAtoms = &conf.SyntheticAtoms
+	}
+	if conf.Anonymize.IsActive() {
+		if err := conf.Anonymize.Validate(conf.Structures); err != nil {
+			return nil, err
+		}
+		ans.anonymize = &conf.Anonymize
+	}
+	if conf.AttrPairs.IsConfigured() {
+		if err := conf.AttrPairs.Validate(conf.Structures); err != nil {
+			return nil, err
+		}
+		ans.attrPairs = conf.AttrPairs.Pairs
+		ans.attrPairCounts = make(map[attrPairKey]*db.AttrPairCount)
+	}
+	ans.udFeatsColumns = conf.Ngrams.VertColumns.ExplodedUDFeats()
+	if len(ans.udFeatsColumns) > 0 {
+		ans.udFeatCounts = make(map[udFeatKey]int)
 	}
 	if conf.StackStructEval {
 		ans.attrAccum = newStructStack()
@@ -135,81 +386,297 @@ func NewTTExtractor(
 	return ans, nil
 }
 
+// SetColumnCountChecker enables checking of column count consistency.
+// The checker is expected to be shared by all the extractors of a run.
+func (tte *TTExtractor) SetColumnCountChecker(checker *ColumnCountChecker) {
+	tte.columnCountChecker = checker
+}
+
+// SetCorpusCounts makes the extractor add its n-gram and UD features
+// counts to cc once Run finishes successfully instead of storing them.
+// The extractor's word dictionary is replaced by the one of cc.
+// It must be called before Run.
+func (tte *TTExtractor) SetCorpusCounts(cc *CorpusCounts) {
+	tte.corpusCounts = cc
+	tte.valueDict = cc.WordDict()
+}
+
+// SetAtomSampler sets a sampler selecting atoms to be processed.
+// The sampler is expected to be shared by all the files of a run
+// (in the order they are processed).
+func (tte *TTExtractor) SetAtomSampler(sampler *AtomSampler) {
+	tte.sampler = sampler
+	tte.sampleFrom = sampler.Seen()
+	tte.sampling = newSampleState(sampler, tte.sampleFrom)
+}
+
+// GetNumTokens returns number of all the processed tokens
 func (tte *TTExtractor) GetNumTokens() int {
-	return tte.tokenCounter
+	return tte.stats.Tokens
+}
+
+// GetNumAcceptedTokens returns number of tokens accepted by the
+// configured filter (excluding tokens skipped due to missing columns
+// or errors). This is the token total used for both ARF and relative
+// frequencies (see db.StatsWords).
+func (tte *TTExtractor) GetNumAcceptedTokens() int {
+	return tte.stats.Words
+}
+
+// GetStats returns aggregate totals of processed data
+func (tte *TTExtractor) GetStats() *CorpusStats {
+	tte.stats.Atoms = tte.atomCounter
+	return tte.stats
 }
 
 func (tte *TTExtractor) WordDict() *ptcount.WordDict {
 	return tte.valueDict
 }
 
+// SetWordDict replaces the extractor's own (empty) word dictionary
+// e.g. by one loaded from a file or shared with other extractors.
+// It must be called before Run.
+func (tte *TTExtractor) SetWordDict(wd *ptcount.WordDict) {
+	tte.valueDict = wd
+}
+
 func (tte *TTExtractor) GetColCounts() map[string]*ptcount.NgramCounter {
 	return tte.colCounts
 }
 
 // handleProcError reports a provided error err by sending it via
-// statusChan and also evaluates total number of errors and in case
-// it is too high (compared with a limit defined in maxNumErrors)
-// it returns ErrorTooManyParsingErrors which should be considered a processing
-// stop signal (but it's still up to the consumer).
-func (tte *TTExtractor) handleProcError(lineNum int, err error) error {
+// statusChan and also evaluates number of errors and in case
+// it is too high (compared with maxNumErrors or a budget of the error
+// category, see errorBudget) it returns ErrorTooManyParsingErrors which
+// should be considered a processing stop signal (but it's still up to
+// the consumer).
+func (tte *TTExtractor) handleProcError(lineNum int, category string, err error) error {
 	tte.statusChan <- Status{
 		Datetime:       time.Now(),
 		ProcessedAtoms: tte.atomCounter,
+		MissingColumns: tte.missingColumnsCounter,
 		ProcessedLines: lineNum,
 		Error:          err,
+		ErrorCategory:  category,
 	}
-	log.Error().Err(err).Int("lineNumber", lineNum).Msg("parsing error")
-	tte.errorCounter++
-	if tte.errorCounter > tte.maxNumErrors {
-		return ErrorTooManyParsingErrors
-	}
-	return nil
+	log.Error().Err(err).Int("lineNumber", lineNum).Str("category", category).Msg("parsing error")
+	return tte.errorBudget.add(category)
 }
 
 // ProcToken is a part of vertigo.LineProcessor implementation.
 // It is called by Vertigo parser when a token line is encountered.
 func (tte *TTExtractor) ProcToken(tk *vertigo.Token, line int, err error) error {
+	if tte.sampling.ignores() {
+		tte.lineCounter = line
+		return nil
+	}
 	if err != nil {
-		return tte.handleProcError(line, err)
+		return tte.handleProcError(line, cnf.ErrCategoryParse, err)
 	}
 	tte.lineCounter = line
+	tte.stats.Tokens++
+	if tte.columnCountChecker != nil {
+		violation, err := tte.columnCountChecker.Check(tk, line)
+		if violation != nil {
+			tte.statusChan <- Status{
+				Datetime:             time.Now(),
+				ProcessedAtoms:       tte.atomCounter,
+				MissingColumns:       tte.missingColumnsCounter,
+				ProcessedLines:       line,
+				Error:                violation,
+				ColumnCountViolation: violation,
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
 	if tte.filter.Apply(tk, tte.attrAccum) {
+		tte.collectTokenArgs(tk)
 		tte.tokenInAtomCounter++
-		tte.tokenCounter = tk.Idx
-		attributes := make([]int, tte.ngramConf.MaxRequiredColumn()+1)
-		for _, vertCol := range tte.ngramConf.VertColumns {
-			v := tk.PosAttrByIndex(vertCol.Idx)
-			attributes[vertCol.Idx] = tte.valueDict.Add(tte.columnModders[vertCol.Idx].Transform(v))
-		}
-
-		tte.currSentence = append(tte.currSentence, attributes)
-		if len(tte.currSentence) >= tte.ngramConf.NgramSize {
-			ngram := ptcount.NewNgramCounter(tte.ngramConf.NgramSize)
-			startPos := len(tte.currSentence) - tte.ngramConf.NgramSize
-			for i := startPos; i < len(tte.currSentence); i++ {
-				ngram.AddToken(tte.currSentence[i])
-			}
-			key := ngram.UniqueID()
-			cnt, ok := tte.colCounts[key]
-			if !ok {
-				tte.colCounts[key] = ngram
-
-			} else {
-				cnt.IncCount()
-			}
+		if err := tte.countToken(tk, line); err != nil {
+			return err
 		}
 	}
 	if line%1000 == 0 {
 		tte.statusChan <- Status{
 			Datetime:       time.Now(),
 			ProcessedAtoms: tte.atomCounter,
+			MissingColumns: tte.missingColumnsCounter,
 			ProcessedLines: line,
 		}
 	}
 	return nil
 }
 
+// tokenAction specifies how a token accepted by the main
+// filter is handled by n-gram counting
+type tokenAction int
+
+const (
+	// tokenCount - the token is counted
+	tokenCount tokenAction = iota
+
+	// tokenDrop - the token is not counted but n-grams span over it
+	tokenDrop
+
+	// tokenBoundary - the token is not counted and no n-gram spans over it
+	tokenBoundary
+)
+
+// ngramTokenAction determines how a token is handled by n-gram
+// counting based on n-gram filter, column constraints and
+// punctuation policy. Both the counting and the ARF pass must
+// use this method so they produce the same n-grams.
+func (tte *TTExtractor) ngramTokenAction(tk *vertigo.Token, accum AttrAccumulator) tokenAction {
+	if !tte.ngramFilter.Apply(tk, accum) || !tte.columnConstraints.Accept(tk) {
+		return tokenBoundary
+	}
+	switch tte.punctuation.Action(tk) {
+	case cnf.PunctuationDrop:
+		return tokenDrop
+	case cnf.PunctuationBoundary:
+		return tokenBoundary
+	}
+	return tokenCount
+}
+
+// isAtomEnd tells whether a closed structure ends an atom (either
+// the atom structure or the atom parent structure without any atom
+// opened within it). N-grams cannot span over atom ends.
+func (tte *TTExtractor) isAtomEnd(item *AccumItem, lastAtomOpenLine int) bool {
+	return item.elm.Name == tte.atomStruct ||
+		item.elm.Name == tte.atomParentStruct && lastAtomOpenLine < item.lineOpen
+}
+
+// countToken adds a token (already accepted by the main filter)
+// to the n-gram counts
+func (tte *TTExtractor) countToken(tk *vertigo.Token, line int) error {
+	switch tte.ngramTokenAction(tk, tte.attrAccum) {
+	case tokenDrop:
+		tte.stats.Words++
+		return nil
+	case tokenBoundary:
+		tte.stats.Words++
+		tte.window.Reset()
+		return nil
+	}
+	attributes, err := tte.tokenAttributes(tk, line)
+	if err != nil {
+		tte.window.Reset()
+		return tte.handleProcError(line, cnf.ErrCategoryColumn, err)
+	}
+	if attributes == nil {
+		// skipped token - no n-gram can span over it
+		tte.window.Reset()
+		return nil
+	}
+	tte.stats.Words++
+	tte.countUDFeats(tk, line)
+	if ngram := tte.window.Add(attributes, tte.columnModders, tte.valueDict); ngram != nil {
+		key := ngram.UniqueID()
+		cnt, ok := tte.colCounts[key]
+		if !ok {
+			tte.colCounts[key] = ngram
+
+		} else {
+			cnt.IncCount()
+		}
+	}
+	return nil
+}
+
+// tokenAttributes encodes values of counted columns of a token.
+// Missing (or empty) values are handled based on the configured
+// policy. In case the token should be skipped, nil is returned.
+func (tte *TTExtractor) tokenAttributes(tk *vertigo.Token, line int) ([]int, error) {
+	attributes, numMissing, err := tte.encodeTokenAttributes(tk, line)
+	tte.missingColumnsCounter += numMissing
+	return attributes, err
+}
+
+// encodeTokenAttributes is a side-effect free (except for the value
+// dictionary) variant of tokenAttributes which also returns the number
+// of missing values
+func (tte *TTExtractor) encodeTokenAttributes(tk *vertigo.Token, line int) ([]int, int, error) {
+	attributes := make([]int, tte.ngramConf.MaxRequiredColumn()+1)
+	var numMissing int
+	numColumns := len(tk.Attrs) + 1
+	for _, vertCol := range tte.ngramConf.VertColumns {
+		if vertCol.Idx >= numColumns {
+			if tte.missingColumnPolicy == cnf.MissingColumnError {
+				return nil, numMissing, fmt.Errorf(
+					"line %d: configured column %d exceeds number of token columns (%d)",
+					line, vertCol.Idx, numColumns)
+			}
+			if !tte.columnRangeReported {
+				log.Warn().
+					Int("lineNumber", line).
+					Int("column", vertCol.Idx).
+					Int("numColumns", numColumns).
+					Msg("configured column exceeds number of token columns, handling as missing value")
+				tte.columnRangeReported = true
+			}
+		}
+		v := tk.PosAttrByIndex(vertCol.Idx)
+		if v == "" {
+			numMissing++
+			switch tte.missingColumnPolicy {
+			case cnf.MissingColumnSkip:
+				return nil, numMissing, nil
+			case cnf.MissingColumnError:
+				return nil, numMissing, fmt.Errorf("line %d: missing value of column %d", line, vertCol.Idx)
+			case cnf.MissingColumnSentinel:
+				attributes[vertCol.Idx] = tte.valueDict.Add(tte.ngramConf.GetMissingColumnSentinel())
+				continue
+			}
+		}
+		attributes[vertCol.Idx] = tte.valueDict.Add(tte.columnModders[vertCol.Idx].PreTransform(v))
+	}
+	return attributes, numMissing, nil
+}
+
+// collectTokenArgs stores values of columns used as column
+// generator arguments (see db.SelfJoinConf.TokenColumns)
+func (tte *TTExtractor) collectTokenArgs(tk *vertigo.Token) {
+	for i, col := range tte.tokenArgColumns {
+		if len(tte.atomTokenArgs[i]) < tte.tokenArgLimit {
+			tte.atomTokenArgs[i] = append(tte.atomTokenArgs[i], tk.PosAttrByIndex(col))
+		}
+	}
+}
+
+func (tte *TTExtractor) resetTokenArgs() {
+	for i := range tte.atomTokenArgs {
+		tte.atomTokenArgs[i] = tte.atomTokenArgs[i][:0]
+	}
+}
+
+// generateItemID calls the configured column generator function.
+// In case token arguments are configured, they are added to the
+// provided attributes first.
+func (tte *TTExtractor) generateItemID(attrs map[string]interface{}) (string, error) {
+	for i, col := range tte.tokenArgColumns {
+		attrs[db.TokenArgName(col)] = strings.Join(tte.atomTokenArgs[i], " ")
+	}
+	return tte.colgenFn(attrs)
+}
+
+// countUDFeats counts individual UD features of columns
+// configured with db.UDFeatsExplode
+func (tte *TTExtractor) countUDFeats(tk *vertigo.Token, line int) {
+	for _, col := range tte.udFeatsColumns {
+		feats, err := ud.ParseFeats(tk.PosAttrByIndex(col.Idx))
+		if err != nil {
+			log.Warn().Err(err).Int("lineNumber", line).Msg("failed to count UD features")
+			continue
+		}
+		for _, f := range feats {
+			tte.udFeatCounts[udFeatKey{col: col.Idx, name: f.Name, value: f.Value}]++
+		}
+	}
+}
+
 func (tte *TTExtractor) getCurrentAccumAttrs() map[string]interface{} {
 	attrs := make(map[string]interface{})
 	tte.attrAccum.ForEachAttr(func(s string, k string, v string) bool {
@@ -230,22 +697,52 @@ func (tte *TTExtractor) ProcStruct(st *vertigo.Structure, line int, err error) e
 		return fmt.Errorf("received stop signal: %s", s)
 	default:
 	}
+	if tte.sampling.complete {
+		return nil
+	}
 	if err != nil { // error from the Vertigo parser
-		return tte.handleProcError(line, err)
+		if tte.sampling.skipping {
+			return nil
+		}
+		return tte.handleProcError(line, cnf.ErrCategoryParse, err)
 	}
 	tte.lineCounter = line
+	if err := tte.sanitizeStructAttrs(st, line); err != nil && !tte.sampling.skipping {
+		return err
+	}
+	tte.decodeStructAttrEntities(st, line)
+	tte.normalizeStructAttrWhitespace(st)
+	tte.redactStructAttrs(st)
 	err2 := tte.attrAccum.begin(line, st)
-	if err2 != nil {
-		return tte.handleProcError(line, err2)
+	if err2 != nil && !tte.sampling.skipping {
+		return tte.handleProcError(line, cnf.ErrCategoryParse, err2)
 	}
 	if st.IsEmpty {
 		_, err3 := tte.attrAccum.end(line, st.Name)
-		if err3 != nil {
-			return tte.handleProcError(line, err3)
+		if err3 != nil && !tte.sampling.skipping {
+			return tte.handleProcError(line, cnf.ErrCategoryParse, err3)
 		}
 	}
 
 	if st != nil {
+		if st.Name == tte.atomStruct {
+			tte.sampling.atomOpen(line)
+
+		} else {
+			tte.sampling.structOpen(line)
+		}
+		if tte.sampling.complete {
+			log.Info().Int("line", line).Msg("sample complete, ignoring the rest of the vertical")
+			tte.stopParsing()
+			return nil
+		}
+		if tte.sampling.skipping && st.IsEmpty && st.Name == tte.atomStruct {
+			tte.sampling.atomClose()
+		}
+		if tte.sampling.skipping {
+			return nil
+		}
+		tte.stats.Structures[st.Name]++
 		if st.Name == tte.atomStruct {
 			tte.lastAtomOpenLine = line
 			tte.tokenInAtomCounter = 0
@@ -255,11 +752,12 @@ func (tte *TTExtractor) ProcStruct(st *vertigo.Structure, line int, err error) e
 			attrs["corpus_id"] = tte.corpusID
 			tte.currAtomAttrs = attrs
 			tte.atomCounter++
-			if tte.colgenFn != nil {
+			tte.resetTokenArgs()
+			if tte.colgenFn != nil && len(tte.tokenArgColumns) == 0 {
 				var err4 error
 				attrs["item_id"], err4 = tte.colgenFn(attrs)
 				if err4 != nil {
-					return tte.handleProcError(line, err4)
+					return tte.handleProcError(line, cnf.ErrCategoryColgen, err4)
 				}
 			}
 
@@ -268,11 +766,12 @@ func (tte *TTExtractor) ProcStruct(st *vertigo.Structure, line int, err error) e
 			attrs["wordcount"] = 0 // This value is currently unused
 			attrs["poscount"] = 0  // This value is updated once we hit the closing tag
 			attrs["corpus_id"] = tte.corpusID
-			if tte.colgenFn != nil {
+			tte.resetTokenArgs()
+			if tte.colgenFn != nil && len(tte.tokenArgColumns) == 0 {
 				var err5 error
 				attrs["item_id"], err5 = tte.colgenFn(attrs)
 				if err5 != nil {
-					return tte.handleProcError(line, err5)
+					return tte.handleProcError(line, cnf.ErrCategoryColgen, err5)
 				}
 			}
 			tte.currAtomAttrs = attrs
@@ -282,6 +781,7 @@ func (tte *TTExtractor) ProcStruct(st *vertigo.Structure, line int, err error) e
 		tte.statusChan <- Status{
 			Datetime:       time.Now(),
 			ProcessedAtoms: tte.atomCounter,
+			MissingColumns: tte.missingColumnsCounter,
 			ProcessedLines: line,
 		}
 	}
@@ -297,22 +797,46 @@ func (tte *TTExtractor) ProcStructClose(st *vertigo.StructureClose, line int, er
 		return fmt.Errorf("received stop signal: %s", s)
 	default:
 	}
+	if tte.sampling.complete {
+		return nil
+	}
 	if err != nil { // error from the Vertigo parser
-		return tte.handleProcError(line, err)
+		if tte.sampling.skipping {
+			return nil
+		}
+		return tte.handleProcError(line, cnf.ErrCategoryParse, err)
 	}
 	accumItem, err2 := tte.attrAccum.end(line, st.Name)
+	tte.lineCounter = line
+	if tte.sampling.skipping {
+		if err2 == nil && accumItem.elm.Name == tte.atomStruct {
+			tte.sampling.atomClose()
+		}
+		return nil
+	}
 	if err2 != nil {
-		return tte.handleProcError(line, err2)
+		return tte.handleProcError(line, cnf.ErrCategoryParse, err2)
 	}
-	tte.lineCounter = line
-	if accumItem.elm.Name == tte.atomStruct ||
-		accumItem.elm.Name == tte.atomParentStruct && tte.lastAtomOpenLine < accumItem.lineOpen {
+	if accumItem.elm.Name == tte.atomStruct {
+		tte.sampling.atomClose()
+	}
+	if tte.isAtomEnd(accumItem, tte.lastAtomOpenLine) {
 		if tte.currAtomAttrs == nil {
 			return fmt.Errorf(
 				"currAtomAttrs not initialized for accum. structure: %s, curr. elm.: %s, line: %d",
 				st.Name, accumItem.elm.Name, line)
 		}
 		tte.currAtomAttrs["poscount"] = tte.tokenInAtomCounter
+		if tte.colgenFn != nil && len(tte.tokenArgColumns) > 0 {
+			itemID, err := tte.generateItemID(tte.currAtomAttrs)
+			if err != nil {
+				return tte.handleProcError(line, cnf.ErrCategoryColgen, err)
+			}
+			tte.currAtomAttrs["item_id"] = itemID
+		}
+		if db.IsDeterministicPrimaryKey(tte.dbConf.PrimaryKey) {
+			tte.currAtomAttrs["id"] = tte.atomPrimaryKey(tte.currAtomAttrs)
+		}
 		values := make([]any, len(tte.attrNames))
 		for i, n := range tte.attrNames {
 			if tte.currAtomAttrs[n] != nil {
@@ -322,20 +846,22 @@ func (tte *TTExtractor) ProcStructClose(st *vertigo.StructureClose, line int, er
 				values[i] = "" // liveattrs plug-in does not like NULLs
 			}
 		}
-		err := tte.docInsert.Exec(values...)
+		err := tte.docInsert.ExecContext(tte.ctx, values...)
 		if err != nil {
-			return tte.handleProcError(line, err)
+			return tte.handleProcError(line, cnf.ErrCategoryInsert, err)
 
 		}
+		tte.countAttrValues()
 		tte.currAtomAttrs = make(map[string]interface{})
 
 		// also reset the current sentence
-		tte.currSentence = tte.currSentence[:0]
+		tte.window.Reset()
 	}
 	if line%1000 == 0 {
 		tte.statusChan <- Status{
 			Datetime:       time.Now(),
 			ProcessedAtoms: tte.atomCounter,
+			MissingColumns: tte.missingColumnsCounter,
 			ProcessedLines: line,
 		}
 	}
@@ -375,18 +901,124 @@ func (tte *TTExtractor) generateAttrList() []string {
 	if tte.colgenFn != nil {
 		attrNames = append(attrNames, "item_id")
 	}
+	if db.IsDeterministicPrimaryKey(tte.dbConf.PrimaryKey) {
+		attrNames = append(attrNames, "id")
+	}
 	return attrNames
 }
 
+// atomPrimaryKey derives an id of a liveattrs row according
+// to the configured primary key strategy (see db.PrimaryKeyItemHash etc.)
+func (tte *TTExtractor) atomPrimaryKey(attrs map[string]any) int64 {
+	if tte.dbConf.PrimaryKey == db.PrimaryKeyItemHash {
+		return db.HashPrimaryKey(tte.corpusID, fmt.Sprint(attrs["item_id"]))
+	}
+	if tte.pkAttrs == nil {
+		tte.pkAttrs = make([]string, 0, tte.calcNumAttrs())
+		for s, items := range tte.structures {
+			for _, item := range items {
+				tte.pkAttrs = append(tte.pkAttrs, fmt.Sprintf("%s_%s", s, item))
+			}
+		}
+		sort.Strings(tte.pkAttrs)
+	}
+	values := make([]string, 0, len(tte.pkAttrs)+1)
+	values = append(values, tte.corpusID)
+	for _, a := range tte.pkAttrs {
+		if v, ok := attrs[a]; ok && v != nil {
+			values = append(values, fmt.Sprint(v))
+
+		} else {
+			values = append(values, "")
+		}
+	}
+	return db.HashPrimaryKey(values...)
+}
+
 func (tte *TTExtractor) generateHashID(ng *ptcount.NgramCounter) string {
-	hasher := sha1.New()
+	tte.hashBuff = tte.hashBuff[:0]
 	for _, vc := range tte.ngramConf.VertColumns {
-		hasher.Write([]byte(ng.ColumnNgram(vc.Idx, tte.valueDict)))
+		tte.hashBuff = append(
+			tte.hashBuff, ng.ColumnNgramSep(vc.Idx, tte.valueDict, tte.ngramSeparator)...)
+	}
+	return tte.hashIDFn(tte.hashBuff)
+}
+
+func (tte *TTExtractor) insertUDFeatCounts(counts map[udFeatKey]int) error {
+	ins, err := tte.database.PrepareInsert(
+		db.UDFeatsTable, []string{"corpus_id", "col", "name", "value", "count"})
+	if err != nil {
+		return err
+	}
+	for k, v := range counts {
+		if err := ins.ExecContext(tte.ctx, tte.corpusID, k.col, k.name, k.value, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// currAtomAttrValue returns a value of a structural attribute
+// of the current atom as stored in attribute value tables
+func (tte *TTExtractor) currAtomAttrValue(attr string) string {
+	if v := tte.currAtomAttrs[attr]; v != nil {
+		return truncateAttrValue(fmt.Sprint(v))
+	}
+	return ""
+}
+
+// countAttrValues adds the current atom to the numbers
+// of atoms and tokens of its structural attribute values
+// (and value pairs)
+func (tte *TTExtractor) countAttrValues() {
+	for _, attr := range tte.attrValuesAttrs {
+		value := tte.currAtomAttrValue(attr)
+		key := attrValueKey{attr: attr, value: value}
+		cnt, ok := tte.attrValues[key]
+		if !ok {
+			cnt = &db.AttrValueCount{Attr: attr, Value: value}
+			tte.attrValues[key] = cnt
+		}
+		cnt.NumAtoms++
+		cnt.NumTokens += tte.tokenInAtomCounter
+	}
+	for _, pair := range tte.attrPairs {
+		key := attrPairKey{
+			attr1:  pair[0],
+			value1: tte.currAtomAttrValue(pair[0]),
+			attr2:  pair[1],
+			value2: tte.currAtomAttrValue(pair[1]),
+		}
+		cnt, ok := tte.attrPairCounts[key]
+		if !ok {
+			cnt = &db.AttrPairCount{Attr1: key.attr1, Value1: key.value1, Attr2: key.attr2, Value2: key.value2}
+			tte.attrPairCounts[key] = cnt
+		}
+		cnt.NumAtoms++
+		cnt.NumTokens += tte.tokenInAtomCounter
 	}
-	return fmt.Sprintf("%x", hasher.Sum(nil))
 }
 
-func (tte *TTExtractor) insertCounts() error {
+func (tte *TTExtractor) insertAttrPairs() error {
+	values := make([]db.AttrPairCount, 0, len(tte.attrPairCounts))
+	for _, v := range tte.attrPairCounts {
+		values = append(values, *v)
+	}
+	return tte.database.AddAttrPairs(tte.corpusID, values)
+}
+
+func (tte *TTExtractor) insertAttrValues() error {
+	values := make([]db.AttrValueCount, 0, len(tte.attrValues))
+	for _, v := range tte.attrValues {
+		values = append(values, *v)
+	}
+	return tte.database.AddAttrValues(tte.corpusID, values)
+}
+
+func (tte *TTExtractor) insertCounts(
+	counts map[string]*ptcount.NgramCounter,
+	statusChan chan<- Status,
+) error {
 	colItems := append(
 		db.GenerateColCountNames(tte.ngramConf.VertColumns),
 		"corpus_id", "count", "arf", "hash_id")
@@ -395,7 +1027,7 @@ func (tte *TTExtractor) insertCounts() error {
 		return nil
 	}
 	i := 0
-	for _, count := range tte.colCounts {
+	for _, count := range counts {
 		select {
 		case s := <-tte.ctx.Done():
 			return fmt.Errorf("received stop signal: %s", s)
@@ -404,7 +1036,7 @@ func (tte *TTExtractor) insertCounts() error {
 
 		args := make([]interface{}, len(tte.ngramConf.VertColumns)+4)
 		for i, vc := range tte.ngramConf.VertColumns {
-			args[i] = count.ColumnNgram(vc.Idx, tte.valueDict)
+			args[i] = count.ColumnNgramSep(vc.Idx, tte.valueDict, tte.ngramSeparator)
 		}
 
 		numCol := len(tte.ngramConf.VertColumns)
@@ -417,15 +1049,16 @@ func (tte *TTExtractor) insertCounts() error {
 			args[numCol+2] = -1
 		}
 		args[numCol+3] = tte.generateHashID(count)
-		err = ins.Exec(args...)
+		err = ins.ExecContext(tte.ctx, args...)
 		if err != nil {
 			return err
 		}
 
 		if i > 0 && i%1000 == 0 {
-			tte.statusChan <- Status{
+			statusChan <- Status{
 				Datetime:       time.Now(),
 				ProcessedAtoms: tte.atomCounter,
+				MissingColumns: tte.missingColumnsCounter,
 				ProcessedLines: tte.lineCounter,
 			}
 			if i%100000 == 0 {
@@ -450,44 +1083,87 @@ func (tte *TTExtractor) Run(conf *vertigo.ParserConf) error {
 	log.Info().Str("file", conf.InputFilePath).Msg("Starting to process vertical file")
 	tte.attrNames = tte.generateAttrList()
 	var err error
-	tte.docInsert, err = tte.database.PrepareInsert("liveattrs_entry", tte.attrNames)
+	tte.docInsert, err = tte.database.PrepareInsert(db.LiveAttrsTable, tte.attrNames)
 	if err != nil {
 		return err
 	}
-	parserErr := vertigo.ParseVerticalFile(tte.ctx, conf, tte)
+	parseCtx, stopParsing := context.WithCancel(tte.ctx)
+	defer stopParsing()
+	tte.stopParsing = stopParsing
+	parserErr := tte.parseVertical(parseCtx, conf, tte)
+	if tte.sampler != nil {
+		tte.sampler.seen = SamplerPosition{
+			Atoms: tte.sampling.next,
+			Lines: tte.sampling.lineOffset + tte.lineCounter + 1,
+		}
+	}
+	if tte.ctx.Err() != nil {
+		// depending on the phase, the parser either stops silently
+		// or reports its own stop error - we prefer the context error
+		parserErr = tte.ctx.Err()
+	}
 	if parserErr != nil {
-		tte.database.Rollback()
 		tte.statusChan <- Status{
 			Datetime:       time.Now(),
 			Error:          parserErr,
 			ProcessedAtoms: tte.atomCounter,
+			MissingColumns: tte.missingColumnsCounter,
 			ProcessedLines: -1,
 		}
-		return fmt.Errorf("failed to parse vertical file: %s", parserErr)
+		return fmt.Errorf("failed to parse vertical file: %w", parserErr)
+	}
+	tte.statusChan <- Status{
+		Datetime:         time.Now(),
+		ProcessedAtoms:   tte.atomCounter,
+		MissingColumns:   tte.missingColumnsCounter,
+		ProcessedLines:   tte.lineCounter,
+		ProcessedTokens:  tte.GetNumTokens(),
+		AcceptedTokens:   tte.GetNumAcceptedTokens(),
+		NormalizedValues: tte.normalizedValues,
+	}
+	log.Info().
+		Str("file", conf.InputFilePath).
+		Int("numTokens", tte.GetNumTokens()).
+		Int("numAcceptedTokens", tte.GetNumAcceptedTokens()).
+		Msg("file token totals")
+	if len(tte.attrValues) > 0 {
+		log.Info().Msg("Saving structural attribute values into the database")
+		if err := tte.insertAttrValues(); err != nil {
+			return err
+		}
+	}
+	if len(tte.attrPairCounts) > 0 {
+		log.Info().Msg("Saving structural attribute value pairs into the database")
+		if err := tte.insertAttrPairs(); err != nil {
+			return err
+		}
 	}
 	if len(tte.ngramConf.VertColumns) > 0 {
-		if tte.ngramConf.CalcARF {
-			log.Info().
-				Msg("calculating ARF (processing the vertical again)")
-			arfCalc := ptcount.NewARFCalculator(
-				tte.GetColCounts(),
-				tte.ngramConf,
-				tte.GetNumTokens(),
-				tte.columnModders,
-				tte.WordDict(),
-				tte.atomStruct,
-			)
-			parserErr := vertigo.ParseVerticalFile(tte.ctx, conf, arfCalc)
-			if parserErr != nil {
-				return fmt.Errorf("ERROR: %s", parserErr)
-			}
-			arfCalc.Finalize()
+		if tte.corpusCounts != nil {
+			tte.corpusCounts.add(tte, conf)
+			return nil
 		}
-		log.Info().Msg("Saving defined positional attributes counts into the database")
-		err = tte.insertCounts()
-		if err != nil {
+		counts := NewCorpusCounts(tte.valueDict)
+		counts.add(tte, conf)
+		if err := counts.Store(tte.statusChan); err != nil {
 			return err
 		}
+		tte.stats.ARFTokens += counts.ARFTokens()
+	}
+	return nil
+}
+
+// calcARF processes the vertical file again to add n-gram
+// occurrences to calc (see newARFPass)
+func (tte *TTExtractor) calcARF(conf *vertigo.ParserConf, calc *ptcount.ARFCalculator) error {
+	arfCtx, stopARF := context.WithCancel(tte.ctx)
+	parserErr := tte.parseVertical(arfCtx, conf, tte.newARFPass(calc, stopARF))
+	stopARF()
+	if tte.ctx.Err() != nil {
+		parserErr = tte.ctx.Err()
+	}
+	if parserErr != nil {
+		return fmt.Errorf("failed to calculate ARF: %w", parserErr)
 	}
 	return nil
 }
@@ -0,0 +1,280 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
+	"github.com/stretchr/testify/assert"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+func newAttrTestExtractor(policy string) *TTExtractor {
+	ngramConf := &cnf.NgramConf{
+		NgramSize:   1,
+		VertColumns: db.VertColumns{{Idx: 0}, {Idx: 2}},
+	}
+	return &TTExtractor{
+		ngramConf: ngramConf,
+		window:    ptcount.NewNgramWindow(ngramConf),
+		valueDict: ptcount.NewWordDict(),
+		columnModders: []*modders.StringTransformerChain{
+			modders.NewStringTransformerChain(""),
+			nil,
+			modders.NewStringTransformerChain(""),
+		},
+		missingColumnPolicy: policy,
+	}
+}
+
+func TestTokenAttributesMissingColumn(t *testing.T) {
+	tk := &vertigo.Token{Word: "foo", Attrs: []string{"bar"}}
+
+	tte := newAttrTestExtractor(cnf.MissingColumnEmpty)
+	attrs, err := tte.tokenAttributes(tk, 10)
+	assert.NoError(t, err)
+	assert.Len(t, attrs, 3)
+	assert.Equal(t, 1, tte.missingColumnsCounter)
+
+	tte = newAttrTestExtractor(cnf.MissingColumnSkip)
+	attrs, err = tte.tokenAttributes(tk, 10)
+	assert.NoError(t, err)
+	assert.Nil(t, attrs)
+
+	tte = newAttrTestExtractor(cnf.MissingColumnError)
+	_, err = tte.tokenAttributes(tk, 10)
+	assert.Error(t, err)
+
+	tte = newAttrTestExtractor(cnf.MissingColumnSentinel)
+	attrs, err = tte.tokenAttributes(tk, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, cnf.DfltMissingColumnSentinel, tte.valueDict.Get(attrs[2]))
+}
+
+func TestTokenAttributesManyColumns(t *testing.T) {
+	tk := &vertigo.Token{Word: "w0", Attrs: make([]string, 44)}
+	for i := range tk.Attrs {
+		tk.Attrs[i] = fmt.Sprintf("w%d", i+1)
+	}
+	ngramConf := &cnf.NgramConf{
+		NgramSize:   1,
+		VertColumns: db.VertColumns{{Idx: 0}, {Idx: 33}, {Idx: 44}},
+	}
+	tte := &TTExtractor{
+		ngramConf:           ngramConf,
+		valueDict:           ptcount.NewWordDict(),
+		columnModders:       make([]*modders.StringTransformerChain, ngramConf.MaxRequiredColumn()+1),
+		missingColumnPolicy: cnf.MissingColumnError,
+	}
+	for _, c := range ngramConf.VertColumns {
+		tte.columnModders[c.Idx] = modders.NewStringTransformerChain("")
+	}
+	attrs, err := tte.tokenAttributes(tk, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "w33", tte.valueDict.Get(attrs[33]))
+	assert.Equal(t, "w44", tte.valueDict.Get(attrs[44]))
+
+	tk.Attrs = tk.Attrs[:40]
+	_, err = tte.tokenAttributes(tk, 10)
+	assert.EqualError(t, err, "line 10: configured column 44 exceeds number of token columns (41)")
+}
+
+func TestGenerateItemIDWithTokenArgs(t *testing.T) {
+	tte := &TTExtractor{
+		tokenArgColumns: []int{0, 1},
+		tokenArgLimit:   2,
+		atomTokenArgs:   make([][]string, 2),
+		colgenFn: func(attrs map[string]interface{}) (string, error) {
+			return attrs["doc_id"].(string) + "|" + attrs["tokens_col0"].(string) + "|" +
+				attrs["tokens_col1"].(string), nil
+		},
+	}
+	tte.collectTokenArgs(&vertigo.Token{Word: "Hello", Attrs: []string{"hello"}})
+	tte.collectTokenArgs(&vertigo.Token{Word: "World", Attrs: []string{"world"}})
+	tte.collectTokenArgs(&vertigo.Token{Word: "!", Attrs: []string{"!"}})
+	ident, err := tte.generateItemID(map[string]interface{}{"doc_id": "d1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "d1|Hello World|hello world", ident)
+
+	tte.resetTokenArgs()
+	ident, err = tte.generateItemID(map[string]interface{}{"doc_id": "d2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "d2||", ident)
+}
+
+func TestAtomPrimaryKey(t *testing.T) {
+	tte := &TTExtractor{
+		corpusID:   "susanne",
+		structures: map[string][]string{"doc": {"id", "title"}, "p": {"type"}},
+		dbConf:     &db.Conf{PrimaryKey: db.PrimaryKeyValuesHash},
+	}
+	attrs := map[string]any{"doc_id": "d1", "doc_title": "T", "p_type": "x", "poscount": 10}
+	id1 := tte.atomPrimaryKey(attrs)
+	assert.Greater(t, id1, int64(0))
+	attrs["poscount"] = 20
+	assert.Equal(t, id1, tte.atomPrimaryKey(attrs))
+	attrs["p_type"] = "y"
+	assert.NotEqual(t, id1, tte.atomPrimaryKey(attrs))
+
+	tte.dbConf.PrimaryKey = db.PrimaryKeyItemHash
+	assert.Equal(
+		t,
+		db.HashPrimaryKey("susanne", "d1:1"),
+		tte.atomPrimaryKey(map[string]any{"item_id": "d1:1", "p_type": "z"}),
+	)
+}
+
+type wordFilter struct {
+	exclude string
+}
+
+func (wf *wordFilter) Apply(tk *vertigo.Token, attrAcc AttrAccumulator) bool {
+	return tk.Word != wf.exclude
+}
+
+func TestNgramFilterSplitsNgrams(t *testing.T) {
+	tte := newAttrTestExtractor(cnf.MissingColumnEmpty)
+	tte.ngramConf.NgramSize = 2
+	tte.ngramConf.VertColumns = db.VertColumns{{Idx: 0}}
+	tte.filter = &PassAllFilter{}
+	tte.ngramFilter = &wordFilter{exclude: "x"}
+	tte.colCounts = make(map[string]*ptcount.NgramCounter)
+	tte.stats = NewCorpusStats()
+	for i, w := range []string{"a", "b", "x", "c", "d"} {
+		assert.NoError(t, tte.ProcToken(&vertigo.Token{Idx: i, Word: w}, i+1, nil))
+	}
+	ngrams := make([]string, 0, len(tte.colCounts))
+	for _, cnt := range tte.colCounts {
+		ngrams = append(ngrams, cnt.ColumnNgram(0, tte.valueDict))
+	}
+	assert.ElementsMatch(t, []string{"a b", "c d"}, ngrams)
+	assert.Equal(t, 5, tte.stats.Words)
+}
+
+func TestPunctuationPolicy(t *testing.T) {
+	words := []string{"a", "b", ",", "c", "..."}
+	for policy, expected := range map[string][]string{
+		cnf.PunctuationKeep:     {"a b", "b ,", ", c", "c ..."},
+		cnf.PunctuationDrop:     {"a b", "b c"},
+		cnf.PunctuationBoundary: {"a b"},
+	} {
+		tte := newAttrTestExtractor(cnf.MissingColumnEmpty)
+		tte.ngramConf.NgramSize = 2
+		tte.ngramConf.VertColumns = db.VertColumns{{Idx: 0}}
+		tte.ngramConf.Punctuation = policy
+		tte.filter = &PassAllFilter{}
+		tte.ngramFilter = &PassAllFilter{}
+		tte.colCounts = make(map[string]*ptcount.NgramCounter)
+		tte.stats = NewCorpusStats()
+		var err error
+		tte.punctuation, err = ptcount.NewPunctuationHandler(tte.ngramConf)
+		assert.NoError(t, err)
+		for i, w := range words {
+			assert.NoError(t, tte.ProcToken(&vertigo.Token{Idx: i, Word: w}, i+1, nil))
+		}
+		ngrams := make([]string, 0, len(tte.colCounts))
+		for _, cnt := range tte.colCounts {
+			ngrams = append(ngrams, cnt.ColumnNgram(0, tte.valueDict))
+		}
+		assert.ElementsMatch(t, expected, ngrams, policy)
+		assert.Equal(t, len(words), tte.stats.Words)
+	}
+}
+
+func TestDisjointNgramWindow(t *testing.T) {
+	tte := newAttrTestExtractor(cnf.MissingColumnEmpty)
+	tte.ngramConf.NgramSize = 2
+	tte.ngramConf.VertColumns = db.VertColumns{{Idx: 0}}
+	tte.ngramConf.Window = cnf.NgramWindowDisjoint
+	tte.filter = &PassAllFilter{}
+	tte.ngramFilter = &PassAllFilter{}
+	tte.colCounts = make(map[string]*ptcount.NgramCounter)
+	tte.stats = NewCorpusStats()
+	for i, w := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, tte.ProcToken(&vertigo.Token{Idx: i, Word: w}, i+1, nil))
+	}
+	ngrams := make([]string, 0, len(tte.colCounts))
+	for _, cnt := range tte.colCounts {
+		ngrams = append(ngrams, cnt.ColumnNgram(0, tte.valueDict))
+	}
+	assert.ElementsMatch(t, []string{"a b", "c d"}, ngrams)
+}
+
+func TestARFPassBoundariesAndFilter(t *testing.T) {
+	tte := newAttrTestExtractor(cnf.MissingColumnEmpty)
+	tte.ngramConf.NgramSize = 2
+	tte.ngramConf.VertColumns = db.VertColumns{{Idx: 0}}
+	tte.filter = &wordFilter{exclude: "x"}
+	tte.ngramFilter = &PassAllFilter{}
+	tte.atomStruct = "s"
+	tte.atomParentStruct = "p"
+	tte.attrAccum = newStructStack()
+
+	counts := make(map[string]*ptcount.NgramCounter)
+	window := ptcount.NewNgramWindow(tte.ngramConf)
+	for _, w := range []string{"a", "b", "c", "d"} {
+		ngram := window.Add([]int{tte.valueDict.Add(w)}, tte.columnModders, tte.valueDict)
+		if ngram != nil {
+			counts[ngram.ColumnNgram(0, tte.valueDict)] = ngram
+		}
+	}
+	calc := make(map[string]*ptcount.NgramCounter)
+	for _, cnt := range counts {
+		calc[cnt.UniqueID()] = cnt
+	}
+	pass := tte.newARFPass(ptcount.NewARFCalculator(calc, 4), func() {})
+	line := 0
+	var idx int
+	for _, part := range [][]string{{"a", "x", "b"}, {"c", "d"}} {
+		assert.NoError(t, pass.ProcStruct(&vertigo.Structure{Name: "p"}, line, nil))
+		line++
+		for _, w := range part {
+			assert.NoError(t, pass.ProcToken(&vertigo.Token{Idx: idx, Word: w}, line, nil))
+			idx++
+			line++
+		}
+		assert.NoError(t, pass.ProcStructClose(&vertigo.StructureClose{Name: "p"}, line, nil))
+		line++
+	}
+	assert.True(t, counts["a b"].HasARF())
+	assert.Equal(t, 1, counts["a b"].ARF().FirstIdx) // filtered 'x' does not occupy a position
+	assert.True(t, counts["c d"].HasARF())
+	assert.Equal(t, 3, counts["c d"].ARF().FirstIdx)
+	assert.False(t, counts["b c"].HasARF())
+}
+
+func TestARFPassPositionOffset(t *testing.T) {
+	tte := newAttrTestExtractor(cnf.MissingColumnEmpty)
+	tte.ngramConf.VertColumns = db.VertColumns{{Idx: 0}}
+	tte.filter = &PassAllFilter{}
+	tte.ngramFilter = &PassAllFilter{}
+	tte.attrAccum = newDefaultAccum()
+	tte.positionOffset = 100
+
+	ngram := ptcount.NewNgramWindow(tte.ngramConf).Add(
+		[]int{tte.valueDict.Add("a")}, tte.columnModders, tte.valueDict)
+	counts := map[string]*ptcount.NgramCounter{ngram.UniqueID(): ngram}
+	pass := tte.newARFPass(ptcount.NewARFCalculator(counts, 2), func() {})
+	assert.NoError(t, pass.ProcToken(&vertigo.Token{Idx: 0, Word: "b"}, 1, nil))
+	assert.NoError(t, pass.ProcToken(&vertigo.Token{Idx: 1, Word: "a"}, 2, nil))
+	assert.Equal(t, 101, ngram.ARF().FirstIdx)
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"sort"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// InventoryItem is a distinct value of a positional
+// attribute along with its absolute frequency
+type InventoryItem struct {
+	Value string
+	Count int
+}
+
+// ValueInventory collects all the distinct values of a single
+// positional attribute (e.g. a tagset) with their frequencies.
+// Compared with 1-gram counting, no structural attributes, ARF
+// or database are involved. It implements vertigo.LineProcessor.
+type ValueInventory struct {
+	column    int
+	modder    *modders.StringTransformerChain
+	counts    map[string]int
+	numTokens int
+	numErrors int
+}
+
+// ProcToken is a part of vertigo.LineProcessor implementation.
+func (vi *ValueInventory) ProcToken(tk *vertigo.Token, line int, err error) error {
+	if err != nil {
+		log.Error().Err(err).Int("lineNumber", line).Msg("parsing error")
+		vi.numErrors++
+		return nil
+	}
+	vi.counts[vi.modder.Transform(tk.PosAttrByIndex(vi.column))]++
+	vi.numTokens++
+	return nil
+}
+
+// ProcStruct is a part of vertigo.LineProcessor implementation.
+func (vi *ValueInventory) ProcStruct(st *vertigo.Structure, line int, err error) error {
+	return nil
+}
+
+// ProcStructClose is a part of vertigo.LineProcessor implementation.
+func (vi *ValueInventory) ProcStructClose(st *vertigo.StructureClose, line int, err error) error {
+	return nil
+}
+
+// NumTokens returns number of processed tokens
+func (vi *ValueInventory) NumTokens() int {
+	return vi.numTokens
+}
+
+// NumErrors returns number of parsing errors encountered
+// (lines with errors are skipped)
+func (vi *ValueInventory) NumErrors() int {
+	return vi.numErrors
+}
+
+// Items returns collected values ordered by their frequencies
+// (most frequent first) and then alphabetically
+func (vi *ValueInventory) Items() []InventoryItem {
+	ans := make([]InventoryItem, 0, len(vi.counts))
+	for k, v := range vi.counts {
+		ans = append(ans, InventoryItem{Value: k, Count: v})
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].Count != ans[j].Count {
+			return ans[i].Count > ans[j].Count
+		}
+		return ans[i].Value < ans[j].Value
+	})
+	return ans
+}
+
+// NewValueInventory creates a new ValueInventory for a specified
+// positional attribute. The column's modFn is applied to values.
+func NewValueInventory(column db.VertColumn) *ValueInventory {
+	return &ValueInventory{
+		column: column.Idx,
+		modder: modders.NewStringTransformerChain(column.ModFn),
+		counts: make(map[string]int),
+	}
+}
+
+// SetNumBucket configures custom numeric buckets
+// for the 'numBucket' modder (if used by the column)
+func (vi *ValueInventory) SetNumBucket(nb *modders.NumBucket) {
+	vi.modder.SetNumBucket(nb)
+}
@@ -0,0 +1,38 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+func TestValueInventory(t *testing.T) {
+	inv := NewValueInventory(db.VertColumn{Idx: 1, ModFn: "toLower"})
+	for _, attrs := range [][]string{{"x", "NN"}, {"y", "VB"}, {"z", "nn"}, {"w"}} {
+		inv.ProcToken(&vertigo.Token{Word: attrs[0], Attrs: attrs[1:]}, 0, nil)
+	}
+	assert.Equal(t, 4, inv.NumTokens())
+	assert.Equal(
+		t,
+		[]InventoryItem{{Value: "nn", Count: 2}, {Value: "", Count: 1}, {Value: "vb", Count: 1}},
+		inv.Items(),
+	)
+}
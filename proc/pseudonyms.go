@@ -0,0 +1,178 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+// encryptedPseudonymsMagic starts each encrypted mapping file
+// so it can be distinguished from a plain JSON one
+var encryptedPseudonymsMagic = []byte("VTE-PSEUDONYMS-AES256GCM\n")
+
+// PseudonymDict collects a mapping between pseudonyms (hashed values,
+// see cnf.RedactHash) and original values of structural attributes.
+// It is shared by extractors of all the processed vertical files.
+type PseudonymDict struct {
+	sync.Mutex
+
+	// data maps [struct]_[attr] -> pseudonym -> original value
+	data map[string]map[string]string
+}
+
+// Add stores a pseudonym of a value of an attribute
+func (pd *PseudonymDict) Add(attr, pseudonym, original string) {
+	pd.Lock()
+	defer pd.Unlock()
+	values, ok := pd.data[attr]
+	if !ok {
+		values = make(map[string]string)
+		pd.data[attr] = values
+	}
+	values[pseudonym] = original
+}
+
+// Len returns the total number of stored pseudonyms
+func (pd *PseudonymDict) Len() int {
+	pd.Lock()
+	defer pd.Unlock()
+	var ans int
+	for _, values := range pd.data {
+		ans += len(values)
+	}
+	return ans
+}
+
+// Mapping returns a copy of the stored mapping
+// ([struct]_[attr] -> pseudonym -> original value)
+func (pd *PseudonymDict) Mapping() map[string]map[string]string {
+	pd.Lock()
+	defer pd.Unlock()
+	ans := make(map[string]map[string]string, len(pd.data))
+	for attr, values := range pd.data {
+		ans[attr] = make(map[string]string, len(values))
+		for k, v := range values {
+			ans[attr][k] = v
+		}
+	}
+	return ans
+}
+
+// Save writes the mapping as JSON to a file readable only by its owner.
+// In case a key is provided, the data are encrypted using AES-256-GCM.
+func (pd *PseudonymDict) Save(path string, key []byte) error {
+	tmpPath, err := pd.SaveTemp(path, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save pseudonyms: %w", err)
+	}
+	return nil
+}
+
+// SaveTemp writes the mapping the same way as Save but to a temporary
+// file located next to path. The returned path of the file is expected
+// to be renamed to path once the data using the mapping are stored.
+func (pd *PseudonymDict) SaveTemp(path string, key []byte) (string, error) {
+	pd.Lock()
+	data, err := sonic.ConfigStd.Marshal(pd.data)
+	pd.Unlock()
+	if err != nil {
+		return "", fmt.Errorf("failed to save pseudonyms: %w", err)
+	}
+	if key != nil {
+		gcm, err := newPseudonymsCipher(key)
+		if err != nil {
+			return "", fmt.Errorf("failed to save pseudonyms: %w", err)
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", fmt.Errorf("failed to save pseudonyms: %w", err)
+		}
+		sealed := append([]byte{}, encryptedPseudonymsMagic...)
+		sealed = append(sealed, nonce...)
+		data = gcm.Seal(sealed, nonce, data, nil)
+	}
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to save pseudonyms: %w", err)
+	}
+	return tmpPath, nil
+}
+
+func newPseudonymsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewPseudonymDict creates an empty mapping
+func NewPseudonymDict() *PseudonymDict {
+	return &PseudonymDict{data: make(map[string]map[string]string)}
+}
+
+// LoadPseudonymDict loads a mapping stored by PseudonymDict.Save.
+// A key is required in case the file is encrypted. In case the file
+// does not exist, an empty mapping is returned.
+func LoadPseudonymDict(path string, key []byte) (*PseudonymDict, error) {
+	ans := NewPseudonymDict()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ans, nil
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load pseudonyms: %w", err)
+	}
+	if bytes.HasPrefix(data, encryptedPseudonymsMagic) {
+		if key == nil {
+			return nil, fmt.Errorf("failed to load pseudonyms: file %s is encrypted and no key is set", path)
+		}
+		gcm, err := newPseudonymsCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pseudonyms: %w", err)
+		}
+		data = data[len(encryptedPseudonymsMagic):]
+		if len(data) < gcm.NonceSize() {
+			return nil, fmt.Errorf("failed to load pseudonyms: file %s is truncated", path)
+		}
+		data, err = gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load pseudonyms (invalid key?): %w", err)
+		}
+	}
+	if err := sonic.Unmarshal(data, &ans.data); err != nil {
+		return nil, fmt.Errorf("failed to load pseudonyms: %w", err)
+	}
+	if ans.data == nil {
+		ans.data = make(map[string]map[string]string)
+	}
+	return ans, nil
+}
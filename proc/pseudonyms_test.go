@@ -0,0 +1,81 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymDictSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pseudonyms.json")
+	pd := NewPseudonymDict()
+	pd.Add("sp_name", "0a1b", "Jan Novák")
+	pd.Add("sp_name", "2c3d", "Eva Malá")
+	assert.NoError(t, pd.Save(path, nil))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := LoadPseudonymDict(path, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, pd.Mapping(), loaded.Mapping())
+}
+
+func TestPseudonymDictEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pseudonyms.bin")
+	key := bytes.Repeat([]byte{7}, 32)
+	pd := NewPseudonymDict()
+	pd.Add("sp_name", "0a1b", "Jan Novák")
+	assert.NoError(t, pd.Save(path, key))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "Novák")
+
+	_, err = LoadPseudonymDict(path, nil)
+	assert.ErrorContains(t, err, "encrypted")
+	_, err = LoadPseudonymDict(path, bytes.Repeat([]byte{8}, 32))
+	assert.ErrorContains(t, err, "invalid key")
+	loaded, err := LoadPseudonymDict(path, key)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jan Novák", loaded.Mapping()["sp_name"]["0a1b"])
+}
+
+func TestLoadPseudonymDictMissing(t *testing.T) {
+	pd, err := LoadPseudonymDict(filepath.Join(t.TempDir(), "none.json"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pd.Len())
+}
+
+func TestPseudonymDictSaveTemp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pseudonyms.json")
+	pd := NewPseudonymDict()
+	pd.Add("sp_name", "0a1b", "Jan Novák")
+	tmpPath, err := pd.SaveTemp(path, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, path, tmpPath)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	loaded, err := LoadPseudonymDict(tmpPath, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, pd.Mapping(), loaded.Mapping())
+}
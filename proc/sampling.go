@@ -0,0 +1,178 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"math"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+)
+
+// AtomSampler selects atoms to be processed in case only
+// a sample of a corpus is required and it also stops processing
+// once configured limits are reached. Decisions depend only
+// on a global index of an atom and a global line number (i.e.
+// counted across all the processed files) so repeated passes over
+// a vertical (e.g. for ARF) select exactly the same atoms. The sampler
+// is shared by all the files of a run.
+type AtomSampler struct {
+	ratio        float64
+	maxAtoms     int
+	maxReadAtoms int
+	maxLines     int
+
+	// seen describes atoms and lines encountered by the main
+	// passes so far
+	seen SamplerPosition
+}
+
+// SamplerPosition is a global position of a sampler
+// (see AtomSampler.Seen, AtomSampler.Rewind)
+type SamplerPosition struct {
+
+	// Atoms is a number of encountered atoms (i.e. the index of the next atom)
+	Atoms int
+
+	// Lines is a number of encountered lines
+	Lines int
+}
+
+// NewAtomSampler creates a sampler for the configuration.
+// In case sampling is not configured, nil is returned (which
+// is a valid sampler accepting all the atoms).
+func NewAtomSampler(conf cnf.SampleConf) (*AtomSampler, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	if !conf.IsConfigured() {
+		return nil, nil
+	}
+	ratio := conf.Ratio
+	if ratio == 0 {
+		ratio = 1
+	}
+	return &AtomSampler{
+		ratio:        ratio,
+		maxAtoms:     conf.Atoms,
+		maxReadAtoms: conf.MaxAtoms,
+		maxLines:     conf.Lines,
+	}, nil
+}
+
+// numAccepted returns number of accepted atoms among
+// the first n atoms
+func (s *AtomSampler) numAccepted(n int) int {
+	return int(math.Floor(float64(n)*s.ratio + 1e-9))
+}
+
+// accepts tests whether an atom with a global index idx is sampled
+func (s *AtomSampler) accepts(idx int) bool {
+	return s.numAccepted(idx+1) > s.numAccepted(idx)
+}
+
+// completeAt tests whether the sample is complete before an atom
+// with a global index idx opened at a global line number line
+// (i.e. no more atoms will be accepted)
+func (s *AtomSampler) completeAt(idx, line int) bool {
+	return s.maxAtoms > 0 && s.numAccepted(idx) >= s.maxAtoms ||
+		s.maxReadAtoms > 0 && idx >= s.maxReadAtoms ||
+		s.maxLines > 0 && line >= s.maxLines
+}
+
+// Seen returns the current global position
+func (s *AtomSampler) Seen() SamplerPosition {
+	if s == nil {
+		return SamplerPosition{}
+	}
+	return s.seen
+}
+
+// Rewind sets the global position back to a value obtained
+// via Seen. This is used once data of a failed file
+// are discarded.
+func (s *AtomSampler) Rewind(pos SamplerPosition) {
+	if s != nil {
+		s.seen = pos
+	}
+}
+
+// Complete tests whether no more atoms will be accepted
+func (s *AtomSampler) Complete() bool {
+	return s != nil && s.completeAt(s.seen.Atoms, s.seen.Lines)
+}
+
+// sampleState tracks sampling decisions within
+// a single pass over a vertical file
+type sampleState struct {
+	sampler *AtomSampler
+
+	// next is a global index of the next atom
+	next int
+
+	// lineOffset is a global line number of the first
+	// line of the file
+	lineOffset int
+
+	// inAtom is true within any (including skipped) atom
+	inAtom bool
+
+	// skipping is true within atoms not included in the sample
+	skipping bool
+
+	// complete is true once the sample is complete and
+	// the rest of the vertical can be ignored
+	complete bool
+}
+
+func newSampleState(sampler *AtomSampler, pos SamplerPosition) sampleState {
+	return sampleState{sampler: sampler, next: pos.Atoms, lineOffset: pos.Lines}
+}
+
+// atomOpen evaluates a newly opened atom
+func (ss *sampleState) atomOpen(line int) {
+	if ss.sampler == nil {
+		return
+	}
+	if ss.sampler.completeAt(ss.next, ss.lineOffset+line) {
+		ss.complete = true
+		return
+	}
+	ss.inAtom = true
+	ss.skipping = !ss.sampler.accepts(ss.next)
+	ss.next++
+}
+
+// structOpen evaluates a newly opened non-atom structure. Once
+// the sample is complete, any following structure outside an atom
+// ends it so e.g. a document containing no sampled atoms is not counted.
+func (ss *sampleState) structOpen(line int) {
+	if ss.sampler != nil && !ss.inAtom && ss.sampler.completeAt(ss.next, ss.lineOffset+line) {
+		ss.complete = true
+	}
+}
+
+// atomClose ends a (possibly skipped) atom
+func (ss *sampleState) atomClose() {
+	ss.inAtom = false
+	ss.skipping = false
+}
+
+// ignores tests whether a currently processed line
+// should be ignored
+func (ss *sampleState) ignores() bool {
+	return ss.skipping || ss.complete
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomSampler(t *testing.T) {
+	s, err := NewAtomSampler(cnf.SampleConf{})
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+	assert.False(t, s.Complete())
+
+	_, err = NewAtomSampler(cnf.SampleConf{Ratio: 1.5})
+	assert.Error(t, err)
+
+	s, err = NewAtomSampler(cnf.SampleConf{Ratio: 0.25, Atoms: 3})
+	assert.NoError(t, err)
+	ss := sampleState{sampler: s}
+	accepted := make([]int, 0, 3)
+	for i := 0; i < 100; i++ {
+		ss.atomOpen(i * 10)
+		if ss.complete {
+			break
+		}
+		if !ss.skipping {
+			accepted = append(accepted, i)
+		}
+		ss.atomClose()
+	}
+	assert.Equal(t, []int{3, 7, 11}, accepted)
+	assert.Equal(t, 12, ss.next)
+
+	s, err = NewAtomSampler(cnf.SampleConf{Ratio: 0.25, MaxAtoms: 10})
+	assert.NoError(t, err)
+	ss = sampleState{sampler: s}
+	accepted = accepted[:0]
+	for i := 0; i < 100; i++ {
+		ss.atomOpen(i * 10)
+		if ss.complete {
+			break
+		}
+		if !ss.skipping {
+			accepted = append(accepted, i)
+		}
+		ss.atomClose()
+	}
+	assert.Equal(t, []int{3, 7}, accepted)
+	assert.Equal(t, 10, ss.next)
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// CorpusStats contains aggregate totals of processed data.
+// Words are tokens accepted by a configured filter.
+// ARFTokens is the token total used for ARF calculation.
+type CorpusStats struct {
+	Tokens     int
+	Words      int
+	ARFTokens  int
+	Atoms      int
+	Structures map[string]int
+}
+
+// Merge adds values of other to the stats
+func (cs *CorpusStats) Merge(other *CorpusStats) {
+	cs.Tokens += other.Tokens
+	cs.Words += other.Words
+	cs.ARFTokens += other.ARFTokens
+	cs.Atoms += other.Atoms
+	for k, v := range other.Structures {
+		cs.Structures[k] += v
+	}
+}
+
+// AsMap converts the stats into a key-value form
+// as stored in the database (see db.StatsTable)
+func (cs *CorpusStats) AsMap() map[string]int {
+	ans := map[string]int{
+		db.StatsTokens: cs.Tokens,
+		db.StatsWords:  cs.Words,
+		db.StatsAtoms:  cs.Atoms,
+	}
+	if cs.ARFTokens > 0 {
+		ans[db.StatsARFTokens] = cs.ARFTokens
+	}
+	for k, v := range cs.Structures {
+		ans[db.StatsStructPrefix+k] = v
+	}
+	return ans
+}
+
+// NewCorpusStats creates an empty CorpusStats instance
+func NewCorpusStats() *CorpusStats {
+	return &CorpusStats{Structures: make(map[string]int)}
+}
+
+// CorpusStatsFromMap creates CorpusStats out of values
+// stored in the database (see AsMap)
+func CorpusStatsFromMap(values map[string]int) *CorpusStats {
+	ans := NewCorpusStats()
+	for k, v := range values {
+		switch k {
+		case db.StatsTokens:
+			ans.Tokens = v
+		case db.StatsWords:
+			ans.Words = v
+		case db.StatsARFTokens:
+			ans.ARFTokens = v
+		case db.StatsAtoms:
+			ans.Atoms = v
+		default:
+			if strings.HasPrefix(k, db.StatsStructPrefix) {
+				ans.Structures[strings.TrimPrefix(k, db.StatsStructPrefix)] = v
+			}
+		}
+	}
+	return ans
+}
@@ -0,0 +1,34 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorpusStatsRoundTrip(t *testing.T) {
+	stats := &CorpusStats{Tokens: 10, Words: 8, Atoms: 2, Structures: map[string]int{"doc": 1, "p": 2}}
+	m := stats.AsMap()
+	assert.Equal(t, 2, m["struct:p"])
+	assert.Equal(t, stats, CorpusStatsFromMap(m))
+
+	stats.Merge(stats)
+	assert.Equal(t, 20, stats.Tokens)
+	assert.Equal(t, 4, stats.Structures["p"])
+}
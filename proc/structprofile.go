@@ -0,0 +1,329 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tomachalek/vertigo/v6"
+)
+
+const (
+	// minAtomCoverage is a min. portion of tokens an atom structure
+	// candidate must cover (tokens outside atoms are not counted)
+	minAtomCoverage = 0.95
+
+	// minAtomAvgTokens is an average atom size below which a structure
+	// is considered too fine (e.g. sentences producing huge tables)
+	minAtomAvgTokens = 100
+)
+
+// StructureProfile describes occurrences of a structure
+// within a (sample of a) vertical file
+type StructureProfile struct {
+	Name string `json:"name"`
+
+	// Count is a number of occurrences (including self-closing ones)
+	Count int `json:"count"`
+
+	// NumEmpty is a number of self-closing occurrences
+	NumEmpty int `json:"numEmpty"`
+
+	// Depth is a min. nesting depth of the structure (0 = top level)
+	Depth int `json:"depth"`
+
+	// Coverage is a portion of tokens within the structure
+	Coverage float64 `json:"coverage"`
+
+	MinTokens int     `json:"minTokens"`
+	MaxTokens int     `json:"maxTokens"`
+	AvgTokens float64 `json:"avgTokens"`
+
+	// Attrs lists names of all the attributes found
+	Attrs []string `json:"attrs"`
+}
+
+// StructureReport contains profiles of all the structures found
+// in a vertical along with a suggested atom structure
+type StructureReport struct {
+	NumTokens  int                `json:"numTokens"`
+	NumLines   int                `json:"numLines"`
+	Complete   bool               `json:"complete"`
+	Structures []StructureProfile `json:"structures"`
+
+	// SuggestedAtom is a suggested value of atomStructure
+	// (empty if no structure is suitable)
+	SuggestedAtom string `json:"suggestedAtomStructure"`
+	Reason        string `json:"reason"`
+}
+
+type openStructure struct {
+	name      string
+	numTokens int
+}
+
+type structureStats struct {
+	count     int
+	numEmpty  int
+	depth     int
+	covered   int
+	numTokens int
+	minTokens int
+	maxTokens int
+	attrs     map[string]bool
+}
+
+// StructureProfiler collects information about structures of
+// a vertical file to help with choosing a proper atom structure.
+// Overlapping structures are tolerated. It implements
+// vertigo.LineProcessor.
+type StructureProfiler struct {
+	maxLines  int
+	numLines  int
+	numTokens int
+	stack     []openStructure
+	stats     map[string]*structureStats
+
+	// stop is called once maxLines is reached
+	stop func()
+}
+
+func (sp *StructureProfiler) countLine() {
+	sp.numLines++
+	if sp.maxLines > 0 && sp.numLines >= sp.maxLines && sp.stop != nil {
+		sp.stop()
+		sp.stop = nil
+	}
+}
+
+func (sp *StructureProfiler) getStats(name string, depth int) *structureStats {
+	st, ok := sp.stats[name]
+	if !ok {
+		st = &structureStats{depth: depth, minTokens: -1, attrs: make(map[string]bool)}
+		sp.stats[name] = st
+	}
+	if depth < st.depth {
+		st.depth = depth
+	}
+	return st
+}
+
+func (sp *StructureProfiler) closeStructure(item openStructure) {
+	st := sp.stats[item.name]
+	st.numTokens += item.numTokens
+	if st.minTokens < 0 || item.numTokens < st.minTokens {
+		st.minTokens = item.numTokens
+	}
+	if item.numTokens > st.maxTokens {
+		st.maxTokens = item.numTokens
+	}
+}
+
+// ProcToken is a part of vertigo.LineProcessor implementation.
+func (sp *StructureProfiler) ProcToken(tk *vertigo.Token, line int, err error) error {
+	if sp.Done() {
+		return nil
+	}
+	sp.countLine()
+	if err != nil {
+		return nil
+	}
+	sp.numTokens++
+	for i := range sp.stack {
+		sp.stack[i].numTokens++
+		counted := false
+		for j := 0; j < i; j++ {
+			if sp.stack[j].name == sp.stack[i].name {
+				counted = true
+				break
+			}
+		}
+		if !counted {
+			sp.stats[sp.stack[i].name].covered++
+		}
+	}
+	return nil
+}
+
+// ProcStruct is a part of vertigo.LineProcessor implementation.
+func (sp *StructureProfiler) ProcStruct(st *vertigo.Structure, line int, err error) error {
+	if sp.Done() {
+		return nil
+	}
+	sp.countLine()
+	if err != nil || st == nil {
+		return nil
+	}
+	stats := sp.getStats(st.Name, len(sp.stack))
+	stats.count++
+	for k := range st.Attrs {
+		stats.attrs[k] = true
+	}
+	if st.IsEmpty {
+		stats.numEmpty++
+		return nil
+	}
+	sp.stack = append(sp.stack, openStructure{name: st.Name})
+	return nil
+}
+
+// ProcStructClose is a part of vertigo.LineProcessor implementation.
+func (sp *StructureProfiler) ProcStructClose(st *vertigo.StructureClose, line int, err error) error {
+	if sp.Done() {
+		return nil
+	}
+	sp.countLine()
+	if err != nil || st == nil {
+		return nil
+	}
+	for i := len(sp.stack) - 1; i >= 0; i-- {
+		if sp.stack[i].name == st.Name {
+			sp.closeStructure(sp.stack[i])
+			sp.stack = append(sp.stack[:i], sp.stack[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Done tells whether the max. number of lines has been reached
+func (sp *StructureProfiler) Done() bool {
+	return sp.maxLines > 0 && sp.numLines >= sp.maxLines
+}
+
+// SetStopFunc sets a function stopping the parser
+// once the max. number of lines is reached
+func (sp *StructureProfiler) SetStopFunc(stop func()) {
+	sp.stop = stop
+}
+
+// Report creates profiles of all the found structures
+// (structures still open are considered closed) and suggests
+// an atom structure.
+func (sp *StructureProfiler) Report() *StructureReport {
+	for len(sp.stack) > 0 {
+		sp.closeStructure(sp.stack[len(sp.stack)-1])
+		sp.stack = sp.stack[:len(sp.stack)-1]
+	}
+	ans := &StructureReport{
+		NumTokens:  sp.numTokens,
+		NumLines:   sp.numLines,
+		Complete:   !sp.Done(),
+		Structures: make([]StructureProfile, 0, len(sp.stats)),
+	}
+	for name, st := range sp.stats {
+		prof := StructureProfile{
+			Name:      name,
+			Count:     st.count,
+			NumEmpty:  st.numEmpty,
+			Depth:     st.depth,
+			MaxTokens: st.maxTokens,
+			Attrs:     make([]string, 0, len(st.attrs)),
+		}
+		if st.minTokens >= 0 {
+			prof.MinTokens = st.minTokens
+		}
+		if sp.numTokens > 0 {
+			prof.Coverage = float64(st.covered) / float64(sp.numTokens)
+		}
+		if nonEmpty := st.count - st.numEmpty; nonEmpty > 0 {
+			prof.AvgTokens = float64(st.numTokens) / float64(nonEmpty)
+		}
+		for attr := range st.attrs {
+			prof.Attrs = append(prof.Attrs, attr)
+		}
+		sort.Strings(prof.Attrs)
+		ans.Structures = append(ans.Structures, prof)
+	}
+	sort.Slice(ans.Structures, func(i, j int) bool {
+		if ans.Structures[i].Depth != ans.Structures[j].Depth {
+			return ans.Structures[i].Depth < ans.Structures[j].Depth
+		}
+		return ans.Structures[i].Name < ans.Structures[j].Name
+	})
+	ans.SuggestedAtom, ans.Reason = SuggestAtomStructure(ans.Structures)
+	return ans
+}
+
+// SuggestAtomStructure suggests an atom structure based on structure
+// profiles. Candidates must cover almost all the tokens (so no data are
+// lost). Structures with attributes are preferred (otherwise rows contain
+// no metadata) and too fine structures (e.g. sentences) are avoided. From
+// the remaining candidates, the most frequent one is chosen as too coarse
+// structures (e.g. a single corpus-wide element) provide no granularity.
+// The second return value explains the choice.
+func SuggestAtomStructure(profiles []StructureProfile) (string, string) {
+	candidates := make([]StructureProfile, 0, len(profiles))
+	for _, p := range profiles {
+		if p.Count > p.NumEmpty && p.Coverage >= minAtomCoverage {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Sprintf(
+			"no structure covers at least %.0f%% of tokens - consider syntheticAtoms", minAtomCoverage*100)
+	}
+	notes := make([]string, 0, 2)
+	withAttrs := filterProfiles(candidates, func(p StructureProfile) bool { return len(p.Attrs) > 0 })
+	if len(withAttrs) > 0 {
+		candidates = withAttrs
+
+	} else {
+		notes = append(notes, "no candidate has attributes")
+	}
+	coarse := filterProfiles(candidates, func(p StructureProfile) bool { return p.AvgTokens >= minAtomAvgTokens })
+	if len(coarse) > 0 {
+		candidates = coarse
+
+	} else {
+		notes = append(notes, fmt.Sprintf("all candidates have less than %d tokens on average", minAtomAvgTokens))
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		return candidates[i].Depth < candidates[j].Depth
+	})
+	best := candidates[0]
+	reason := fmt.Sprintf(
+		"covers %.1f%% of tokens, %d occurrences with %.1f tokens on average",
+		best.Coverage*100, best.Count, best.AvgTokens)
+	for _, n := range notes {
+		reason += "; " + n
+	}
+	return best.Name, reason
+}
+
+func filterProfiles(profiles []StructureProfile, pred func(p StructureProfile) bool) []StructureProfile {
+	ans := make([]StructureProfile, 0, len(profiles))
+	for _, p := range profiles {
+		if pred(p) {
+			ans = append(ans, p)
+		}
+	}
+	return ans
+}
+
+// NewStructureProfiler creates a profiler reading at most maxLines
+// lines (0 = no limit, counted across all the processed files)
+func NewStructureProfiler(maxLines int) *StructureProfiler {
+	return &StructureProfiler{
+		maxLines: maxLines,
+		stats:    make(map[string]*structureStats),
+	}
+}
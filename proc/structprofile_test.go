@@ -0,0 +1,80 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+func TestStructureProfiler(t *testing.T) {
+	sp := NewStructureProfiler(0)
+	open := func(name string, attrs map[string]string) {
+		assert.NoError(t, sp.ProcStruct(&vertigo.Structure{Name: name, Attrs: attrs}, 0, nil))
+	}
+	closeSt := func(name string) {
+		assert.NoError(t, sp.ProcStructClose(&vertigo.StructureClose{Name: name}, 0, nil))
+	}
+	token := func() {
+		assert.NoError(t, sp.ProcToken(&vertigo.Token{}, 0, nil))
+	}
+	token()
+	for i := 0; i < 2; i++ {
+		open("doc", map[string]string{"id": "x"})
+		for j := 0; j < 3; j++ {
+			open("s", nil)
+			token()
+			token()
+			closeSt("s")
+		}
+		assert.NoError(t, sp.ProcStruct(&vertigo.Structure{Name: "g", IsEmpty: true}, 0, nil))
+		closeSt("doc")
+	}
+	report := sp.Report()
+	assert.Equal(t, 13, report.NumTokens)
+	assert.True(t, report.Complete)
+	assert.Len(t, report.Structures, 3)
+	doc := report.Structures[0]
+	assert.Equal(t, "doc", doc.Name)
+	assert.Equal(t, 2, doc.Count)
+	assert.InDelta(t, 12.0/13.0, doc.Coverage, 0.001)
+	assert.Equal(t, 6.0, doc.AvgTokens)
+	assert.Equal(t, []string{"id"}, doc.Attrs)
+	assert.Equal(t, "g", report.Structures[1].Name)
+	assert.Equal(t, 1, report.Structures[1].Depth)
+	assert.Equal(t, 2, report.Structures[1].NumEmpty)
+	// no structure covers enough tokens
+	assert.Equal(t, "", report.SuggestedAtom)
+}
+
+func TestSuggestAtomStructure(t *testing.T) {
+	profiles := []StructureProfile{
+		{Name: "corpus", Count: 1, Coverage: 1, AvgTokens: 1000000, Attrs: []string{"name"}},
+		{Name: "doc", Count: 100, Depth: 1, Coverage: 1, AvgTokens: 10000, Attrs: []string{"id"}},
+		{Name: "p", Count: 10000, Depth: 2, Coverage: 1, AvgTokens: 100},
+		{Name: "s", Count: 50000, Depth: 3, Coverage: 1, AvgTokens: 20, Attrs: []string{"id"}},
+		{Name: "note", Count: 500000, Depth: 2, Coverage: 0.1, AvgTokens: 200, Attrs: []string{"type"}},
+	}
+	atom, _ := SuggestAtomStructure(profiles)
+	assert.Equal(t, "doc", atom)
+
+	atom, reason := SuggestAtomStructure(profiles[3:])
+	assert.Equal(t, "s", atom)
+	assert.Contains(t, reason, "less than 100 tokens")
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"time"
+
+	"github.com/czcorpus/vert-tagextract/v3/status"
+)
+
+const (
+	// FileOutcomeOK - see status.FileOutcomeOK
+	FileOutcomeOK = status.FileOutcomeOK
+
+	// FileOutcomeSkipped - see status.FileOutcomeSkipped
+	FileOutcomeSkipped = status.FileOutcomeSkipped
+
+	// FileOutcomeFailed - see status.FileOutcomeFailed
+	FileOutcomeFailed = status.FileOutcomeFailed
+)
+
+// FileSummary contains information about a single processed
+// vertical file. It is built from the statuses sent during
+// the file processing.
+type FileSummary = status.FileSummary
+
+func updateFileSummary(fs *FileSummary, status Status) {
+	if status.ProcessedLines > fs.ProcessedLines {
+		fs.ProcessedLines = status.ProcessedLines
+	}
+	if status.ProcessedAtoms > fs.ProcessedAtoms {
+		fs.ProcessedAtoms = status.ProcessedAtoms
+	}
+	if status.MissingColumns > fs.MissingColumns {
+		fs.MissingColumns = status.MissingColumns
+	}
+	if status.ProcessedTokens > fs.ProcessedTokens {
+		fs.ProcessedTokens = status.ProcessedTokens
+	}
+	if status.AcceptedTokens > fs.AcceptedTokens {
+		fs.AcceptedTokens = status.AcceptedTokens
+	}
+	if status.NormalizedValues > fs.NormalizedValues {
+		fs.NormalizedValues = status.NormalizedValues
+	}
+	if status.Error != nil {
+		fs.NumErrors++
+		fs.LastError = status.Error.Error()
+	}
+}
+
+// Summary contains overall information about a finished
+// extraction run. It is built from the statuses sent during
+// the processing. Data are stored in the embedded status.Summary
+// which is also what consumers should use to decode a serialized
+// summary.
+type Summary struct {
+	status.Summary
+
+	currFile *FileSummary
+}
+
+// Update adds information from a status
+func (s *Summary) Update(status Status) {
+	if status.File != "" && (s.currFile == nil || status.File != s.currFile.File) {
+		s.CloseFile()
+		s.currFile = &FileSummary{File: status.File, Started: status.Datetime}
+		if s.currFile.Started.IsZero() {
+			s.currFile.Started = time.Now()
+		}
+		s.ProcessedFiles++
+	}
+	if s.currFile != nil {
+		updateFileSummary(s.currFile, status)
+	}
+	if status.ColumnCountViolation != nil {
+		s.NumColumnCountViolations++
+		if len(s.ColumnCountViolations) < MaxReportedColumnCountViolations {
+			violation := *status.ColumnCountViolation
+			violation.File = status.File
+			s.ColumnCountViolations = append(s.ColumnCountViolations, violation)
+		}
+	}
+	if status.Error != nil {
+		s.NumErrors++
+		s.LastError = status.Error.Error()
+		if status.ErrorCategory != "" {
+			if s.ErrorsByCategory == nil {
+				s.ErrorsByCategory = make(map[string]int)
+			}
+			s.ErrorsByCategory[status.ErrorCategory]++
+		}
+	}
+}
+
+// CloseFile finishes the currently processed file and adds its
+// results to the totals. The closed file summary is returned
+// (nil in case there is no file being processed). In case the
+// outcome is not set, FileOutcomeOK is used.
+func (s *Summary) CloseFile() *FileSummary {
+	if s.currFile == nil {
+		return nil
+	}
+	fs := s.currFile
+	fs.Finished = time.Now()
+	if fs.Outcome == "" {
+		fs.Outcome = FileOutcomeOK
+	}
+	if fs.Outcome == FileOutcomeSkipped {
+		s.SkippedFiles++
+	}
+	s.ProcessedLines += fs.ProcessedLines
+	s.ProcessedAtoms += fs.ProcessedAtoms
+	s.MissingColumns += fs.MissingColumns
+	s.ProcessedTokens += fs.ProcessedTokens
+	s.AcceptedTokens += fs.AcceptedTokens
+	s.NormalizedValues += fs.NormalizedValues
+	s.Files = append(s.Files, fs)
+	s.currFile = nil
+	return fs
+}
+
+// AddStructures adds numbers of structures of a processed file
+// to the totals
+func (s *Summary) AddStructures(counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	if s.Structures == nil {
+		s.Structures = make(map[string]int)
+	}
+	for k, v := range counts {
+		s.Structures[k] += v
+	}
+}
+
+// SetFileOutcome sets outcome and number of attempts
+// of the currently processed file
+func (s *Summary) SetFileOutcome(outcome string, attempts int) {
+	if s.currFile != nil {
+		s.currFile.Outcome = outcome
+		s.currFile.Attempts = attempts
+	}
+}
+
+// Finish closes the summary. The 'err' argument
+// should contain a possible error which stopped
+// the whole processing.
+func (s *Summary) Finish(err error) {
+	s.CloseFile()
+	s.Finished = time.Now()
+	if err != nil {
+		s.Failed = true
+		s.LastError = err.Error()
+	}
+}
+
+// NewSummary creates a new summary for a run starting just now
+func NewSummary(corpus string) *Summary {
+	return &Summary{
+		Summary: status.Summary{
+			Corpus:  corpus,
+			Started: time.Now(),
+		},
+	}
+}
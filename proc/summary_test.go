@@ -0,0 +1,48 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryPerFileResults(t *testing.T) {
+	s := NewSummary("susanne")
+	now := time.Now()
+	s.Update(Status{Datetime: now, File: "a.vrt", ProcessedLines: 10, ProcessedAtoms: 2})
+	s.Update(Status{Datetime: now, File: "a.vrt", ProcessedLines: 20, ProcessedTokens: 15, AcceptedTokens: 12})
+	fs := s.CloseFile()
+	assert.Equal(t, "a.vrt", fs.File)
+	s.Update(Status{Datetime: now, File: "b.vrt", ProcessedLines: 5, Error: errors.New("broken line")})
+	s.Finish(nil)
+
+	assert.Equal(t, 2, s.ProcessedFiles)
+	assert.Equal(t, 25, s.ProcessedLines)
+	assert.Equal(t, 15, s.ProcessedTokens)
+	assert.Len(t, s.Files, 2)
+	assert.Equal(t, 20, s.Files[0].ProcessedLines)
+	assert.Equal(t, 12, s.Files[0].AcceptedTokens)
+	assert.Equal(t, 0, s.Files[0].NumErrors)
+	failed := s.FailedFiles()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "b.vrt", failed[0].File)
+	assert.Equal(t, "broken line", failed[0].LastError)
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// syntheticAtoms is a vertigo.LineProcessor wrapping another processor
+// (TTExtractor, arfPass) which generates atom structures for verticals
+// without any structures (see cnf.SyntheticAtomsConf). An atom is opened
+// by the first line after the previous atom has been closed and it is
+// closed once it contains the configured number of tokens and no other
+// structure is open. As the boundaries depend only on the vertical,
+// all the passes over a file produce the same atoms.
+type syntheticAtoms struct {
+	lproc      vertigo.LineProcessor
+	structName string
+	fileName   string
+
+	// size is a number of tokens of an atom (0 = whole file)
+	size int
+
+	numTokens int
+	numAtoms  int
+	lastLine  int
+	isOpen    bool
+
+	// depth is a number of currently open (real) structures
+	depth int
+}
+
+func (sa *syntheticAtoms) open(line int) error {
+	sa.numAtoms++
+	sa.numTokens = 0
+	sa.isOpen = true
+	return sa.lproc.ProcStruct(
+		&vertigo.Structure{
+			Name: sa.structName,
+			Attrs: map[string]string{
+				"id":   fmt.Sprintf("%s:%d", sa.fileName, sa.numAtoms),
+				"file": sa.fileName,
+				"num":  strconv.Itoa(sa.numAtoms),
+			},
+		},
+		line,
+		nil,
+	)
+}
+
+func (sa *syntheticAtoms) close(line int) error {
+	sa.isOpen = false
+	return sa.lproc.ProcStructClose(&vertigo.StructureClose{Name: sa.structName}, line, nil)
+}
+
+func (sa *syntheticAtoms) closeIfComplete(line int) error {
+	if sa.size > 0 && sa.numTokens >= sa.size && sa.depth == 0 {
+		return sa.close(line)
+	}
+	return nil
+}
+
+func (sa *syntheticAtoms) ProcToken(tk *vertigo.Token, line int, err error) error {
+	sa.lastLine = line
+	if !sa.isOpen {
+		if err := sa.open(line); err != nil {
+			return err
+		}
+	}
+	if err := sa.lproc.ProcToken(tk, line, err); err != nil {
+		return err
+	}
+	sa.numTokens++
+	return sa.closeIfComplete(line)
+}
+
+func (sa *syntheticAtoms) ProcStruct(st *vertigo.Structure, line int, err error) error {
+	sa.lastLine = line
+	if !sa.isOpen {
+		if err := sa.open(line); err != nil {
+			return err
+		}
+	}
+	if err == nil && st != nil && !st.IsEmpty {
+		sa.depth++
+	}
+	return sa.lproc.ProcStruct(st, line, err)
+}
+
+func (sa *syntheticAtoms) ProcStructClose(st *vertigo.StructureClose, line int, err error) error {
+	sa.lastLine = line
+	if err := sa.lproc.ProcStructClose(st, line, err); err != nil {
+		return err
+	}
+	if err == nil && sa.depth > 0 {
+		sa.depth--
+	}
+	return sa.closeIfComplete(line)
+}
+
+// finish closes the last atom. It must be called
+// once the parser is finished.
+func (sa *syntheticAtoms) finish() error {
+	if sa.isOpen {
+		return sa.close(sa.lastLine)
+	}
+	return nil
+}
+
+func newSyntheticAtoms(lproc vertigo.LineProcessor, structName, fileName string, size int) *syntheticAtoms {
+	return &syntheticAtoms{
+		lproc:      lproc,
+		structName: structName,
+		fileName:   fileName,
+		size:       size,
+	}
+}
+
+// parseVertical parses a vertical file using a provided processor.
+// In case synthetic atoms are configured, the processor is wrapped
+// by syntheticAtoms.
+func (tte *TTExtractor) parseVertical(
+	ctx context.Context,
+	conf *vertigo.ParserConf,
+	lproc vertigo.LineProcessor,
+) error {
+	if tte.syntheticAtoms == nil {
+		return vertigo.ParseVerticalFile(ctx, conf, lproc)
+	}
+	sa := newSyntheticAtoms(
+		lproc, tte.atomStruct, filepath.Base(conf.InputFilePath), tte.syntheticAtoms.Tokens)
+	if err := vertigo.ParseVerticalFile(ctx, conf, sa); err != nil {
+		return err
+	}
+	return sa.finish()
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/rs/zerolog/log"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// sanitizeStructAttrs applies the configured policy (see cnf.InvalidUTF8Keep
+// etc.) to exported attributes of a structure containing invalid UTF-8
+// byte sequences. With cnf.InvalidUTF8Fail, an error stopping
+// the processing is returned.
+func (tte *TTExtractor) sanitizeStructAttrs(st *vertigo.Structure, line int) error {
+	if tte.invalidUTF8Policy == cnf.InvalidUTF8Keep {
+		return nil
+	}
+	for _, attr := range tte.structures[st.Name] {
+		v, ok := st.Attrs[attr]
+		if !ok || utf8.ValidString(v) {
+			continue
+		}
+		if tte.invalidUTF8Policy == cnf.InvalidUTF8Fail {
+			return fmt.Errorf("line %d: invalid UTF-8 in value of %s.%s", line, st.Name, attr)
+		}
+		log.Warn().
+			Int("lineNumber", line).
+			Str("structure", st.Name).
+			Str("attr", attr).
+			Str("policy", tte.invalidUTF8Policy).
+			Msg("invalid UTF-8 in structural attribute value")
+		if tte.invalidUTF8Policy == cnf.InvalidUTF8Replace {
+			st.Attrs[attr] = strings.ToValidUTF8(v, string(utf8.RuneError))
+
+		} else {
+			st.Attrs[attr] = ""
+		}
+	}
+	return nil
+}
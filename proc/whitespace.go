@@ -0,0 +1,55 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proc
+
+import (
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// normalizeWhitespace applies a whitespace policy
+// (see cnf.WhitespaceKeep etc.) to a value
+func normalizeWhitespace(v, policy string) string {
+	switch policy {
+	case cnf.WhitespaceTrim:
+		return strings.TrimSpace(v)
+	case cnf.WhitespaceCollapse:
+		return strings.Join(strings.Fields(v), " ")
+	}
+	return v
+}
+
+// normalizeStructAttrWhitespace applies the configured whitespace
+// policy to exported attributes of a structure and counts
+// the changed values
+func (tte *TTExtractor) normalizeStructAttrWhitespace(st *vertigo.Structure) {
+	if tte.whitespacePolicy == cnf.WhitespaceKeep {
+		return
+	}
+	for _, attr := range tte.structures[st.Name] {
+		v, ok := st.Attrs[attr]
+		if !ok {
+			continue
+		}
+		if nv := normalizeWhitespace(v, tte.whitespacePolicy); nv != v {
+			st.Attrs[attr] = nv
+			tte.normalizedValues++
+		}
+	}
+}
@@ -21,19 +21,8 @@ import (
 	"math"
 
 	"github.com/rs/zerolog/log"
-
-	"github.com/czcorpus/vert-tagextract/v3/cnf"
-	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
-
-	"github.com/tomachalek/vertigo/v6"
 )
 
-// Calculate ARF for processed n-grams. Please note that the way
-// this module closes unfinished n-grams is not 100% compatible with
-// the one TTExtractor does this. The difference may occur in case
-// a corpus contains nested atom structures (e.g. <p> within <p>,
-// <s> within <s> etc.).
-
 // For more information about ARF definition and possible calculation
 // please see e.g.:
 // https://www.sketchengine.eu/documentation/average-reduced-frequency/
@@ -69,74 +58,48 @@ func (ws WordARF) String() string {
 
 // ARFCalculator calculates ARF for all the
 // [ngram_uniq_id] => NgramCounter pairs we
-// obtain in the 1st pass.
+// obtain in the 1st pass. The calculator does not parse
+// the vertical itself - n-gram occurrences are expected
+// to be provided by the same code which counted them in
+// the 1st pass (see proc.TTExtractor) so both passes
+// produce exactly the same n-grams.
 type ARFCalculator struct {
-	ngramConf     *cnf.NgramConf
-	counts        map[string]*NgramCounter
-	currSentence  [][]int
-	numTokens     int
-	columnModders []*modders.StringTransformerChain
-	wordDict      *WordDict
-	atomStruct    string
+	counts    map[string]*NgramCounter
+	numTokens int
 }
 
 // NewARFCalculator is the recommended factory to create an instance of the type
-func NewARFCalculator(counts map[string]*NgramCounter, ngramConf *cnf.NgramConf, numTokens int,
-	columnModders []*modders.StringTransformerChain, wordDict *WordDict, atomStruct string) *ARFCalculator {
+func NewARFCalculator(counts map[string]*NgramCounter, numTokens int) *ARFCalculator {
 	return &ARFCalculator{
-		numTokens:     numTokens,
-		counts:        counts,
-		currSentence:  make([][]int, 0, 20),
-		ngramConf:     ngramConf,
-		columnModders: columnModders,
-		atomStruct:    atomStruct,
-		wordDict:      wordDict,
+		numTokens: numTokens,
+		counts:    counts,
 	}
 }
 
-// ProcToken is called by vertigo parser when a token is encountered
-func (arfc *ARFCalculator) ProcToken(tk *vertigo.Token, line int, err error) error {
-	attributes := make([]int, arfc.ngramConf.VertColumns.MaxColumn()+1)
-	for _, vertCol := range arfc.ngramConf.VertColumns {
-		v := tk.PosAttrByIndex(vertCol.Idx)
-		attributes[vertCol.Idx] = arfc.wordDict.Add(arfc.columnModders[vertCol.Idx].Transform(v))
+// AddOccurrence registers an occurrence of an n-gram (identified
+// by its NgramCounter.UniqueID()) ending at the position pos. Positions
+// are expected to be indices of tokens within the processed (possibly
+// filtered) token stream, i.e. in range [offset, offset+numTokens) where
+// offset is an arbitrary global position of the first token.
+func (arfc *ARFCalculator) AddOccurrence(key string, pos int) {
+	cnt, ok := arfc.counts[key]
+	if !ok {
+		log.Warn().Str("token", key).Msg("token not found in previously processed data")
+		return
 	}
-
-	arfc.currSentence = append(arfc.currSentence, attributes)
-	if len(arfc.currSentence) >= arfc.ngramConf.NgramSize {
-		ngram := NewNgramCounter(arfc.ngramConf.NgramSize)
-		startPos := len(arfc.currSentence) - arfc.ngramConf.NgramSize
-		for i := startPos; i < len(arfc.currSentence); i++ {
-			ngram.AddToken(arfc.currSentence[i])
-		}
-		key := ngram.UniqueID()
-		cnt, ok := arfc.counts[key]
-		if !ok {
-			log.Warn().Str("token", key).Msg("token not found in previously processed data")
-			return nil
-		}
-		if !cnt.HasARF() {
-			cnt.AddARF(tk)
-		}
-		if cnt.ARF().PrevTokIdx > -1 {
-			cnt.ARF().ARF += min(float64(arfc.numTokens)/float64(cnt.Count()), tk.Idx-cnt.ARF().PrevTokIdx)
-		}
-		cnt.ARF().PrevTokIdx = tk.Idx
+	if !cnt.HasARF() {
+		cnt.arf = &WordARF{PrevTokIdx: -1, FirstIdx: pos}
 	}
-	return nil
-}
-
-// ProcStruct is used by Vertigo parser but we don't need it here
-func (arfc *ARFCalculator) ProcStruct(strc *vertigo.Structure, line int, err error) error {
-	return err
+	if cnt.ARF().PrevTokIdx > -1 {
+		cnt.ARF().ARF += min(float64(arfc.numTokens)/float64(cnt.Count()), pos-cnt.ARF().PrevTokIdx)
+	}
+	cnt.ARF().PrevTokIdx = pos
 }
 
-// ProcStructClose is used by Vertigo parser but we don't need it here
-func (arfc *ARFCalculator) ProcStructClose(strc *vertigo.StructureClose, line int, err error) error {
-	if strc.Name == arfc.atomStruct {
-		arfc.currSentence = arfc.currSentence[:0]
-	}
-	return err
+// NumTokens returns the token total used to calculate
+// average distances
+func (arfc *ARFCalculator) NumTokens() int {
+	return arfc.numTokens
 }
 
 // Finalize performs some final calculations on obtained
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
 	"github.com/tomachalek/vertigo/v6"
 )
 
@@ -30,6 +31,34 @@ type Position struct {
 	Columns []int
 }
 
+// ApplyPositionalModders applies position dependent transformations
+// (see modders.StringTransformerChain) to encoded token attributes
+// placed at n-gram position pos. Attributes of positional columns are
+// expected to be stored untransformed (see PreTransform). In case there
+// are no positional columns, the original slice is returned.
+func ApplyPositionalModders(
+	attrs []int,
+	pos int,
+	columnModders []*modders.StringTransformerChain,
+	wd *WordDict,
+) []int {
+	var ans []int
+	for idx, m := range columnModders {
+		if !m.IsPositional() || idx >= len(attrs) {
+			continue
+		}
+		if ans == nil {
+			ans = make([]int, len(attrs))
+			copy(ans, attrs)
+		}
+		ans[idx] = wd.Add(m.TransformAt(pos, wd.Get(attrs[idx])))
+	}
+	if ans == nil {
+		return attrs
+	}
+	return ans
+}
+
 // NgramCounter stores an n-gram with multiple attributes
 // per position along absolute freq. information and optionally
 // with ARF information.
@@ -75,12 +104,20 @@ func (c *NgramCounter) ARF() *WordARF {
 	return c.arf
 }
 
+// ColumnNgram produces an n-gram out of values in column colIdx
+// using the default separator (see ColumnNgramSep)
 func (c *NgramCounter) ColumnNgram(colIdx int, wd *WordDict) string {
+	return c.ColumnNgramSep(colIdx, wd, DfltNgramSeparator)
+}
+
+// ColumnNgramSep produces an n-gram out of values in column colIdx
+// joined by sep (see JoinNgram)
+func (c *NgramCounter) ColumnNgramSep(colIdx int, wd *WordDict, sep string) string {
 	tmp := make([]string, len(c.tokens))
 	for i, v := range c.tokens {
 		tmp[i] = wd.Get(v.Columns[colIdx])
 	}
-	return strings.Join(tmp, " ")
+	return JoinNgram(tmp, sep)
 }
 
 // columnNgramNumeric produces an n-gram out of values in column
@@ -145,6 +182,12 @@ func (c *NgramCounter) IncCount() {
 	c.count++
 }
 
+// AddCount increases number of occurences by n
+// (e.g. when merging counts of multiple files)
+func (c *NgramCounter) AddCount(n int) {
+	c.count += n
+}
+
 // AddToken add additional (besides 0th) tokens to the n-gram
 func (c *NgramCounter) AddToken(pos []int) {
 	c.tokens = append(c.tokens, Position{Columns: pos})
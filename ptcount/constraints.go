@@ -0,0 +1,89 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+type columnConstraint struct {
+	idx       int
+	minLength int
+	match     *regexp.Regexp
+	notMatch  *regexp.Regexp
+}
+
+func (cc *columnConstraint) accept(v string) bool {
+	if cc.minLength > 0 && utf8.RuneCountInString(v) < cc.minLength {
+		return false
+	}
+	if cc.match != nil && !cc.match.MatchString(v) {
+		return false
+	}
+	if cc.notMatch != nil && cc.notMatch.MatchString(v) {
+		return false
+	}
+	return true
+}
+
+// ColumnConstraints tests tokens against constraints of counted
+// columns (db.VertColumn.MinLength etc.). Tokens not accepted
+// are expected to be excluded from n-gram counting.
+type ColumnConstraints []columnConstraint
+
+// NewColumnConstraints compiles constraints of provided columns.
+// Columns without constraints are ignored.
+func NewColumnConstraints(cols db.VertColumns) (ColumnConstraints, error) {
+	ans := make(ColumnConstraints, 0, len(cols))
+	for _, col := range cols {
+		if !col.HasConstraints() {
+			continue
+		}
+		cc := columnConstraint{idx: col.Idx, minLength: col.MinLength}
+		var err error
+		if col.Match != "" {
+			cc.match, err = regexp.Compile(col.Match)
+			if err != nil {
+				return nil, fmt.Errorf("invalid match expression of column %d: %w", col.Idx, err)
+			}
+		}
+		if col.NotMatch != "" {
+			cc.notMatch, err = regexp.Compile(col.NotMatch)
+			if err != nil {
+				return nil, fmt.Errorf("invalid notMatch expression of column %d: %w", col.Idx, err)
+			}
+		}
+		ans = append(ans, cc)
+	}
+	return ans, nil
+}
+
+// Accept returns true if all the constrained columns
+// of the token meet their constraints
+func (cc ColumnConstraints) Accept(tk *vertigo.Token) bool {
+	for i := range cc {
+		if !cc[i].accept(tk.PosAttrByIndex(cc[i].idx)) {
+			return false
+		}
+	}
+	return true
+}
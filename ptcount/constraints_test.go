@@ -0,0 +1,50 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+func TestColumnConstraints(t *testing.T) {
+	cc, err := NewColumnConstraints(db.VertColumns{
+		{Idx: 0, MinLength: 2, NotMatch: `^(https?://|\d+$)`},
+		{Idx: 1},
+		{Idx: 2, Match: `^[A-Z]`},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, cc, 2)
+	tk := func(word, tag string) *vertigo.Token {
+		return &vertigo.Token{Word: word, Attrs: []string{"x", tag}}
+	}
+	assert.True(t, cc.Accept(tk("čí", "NN")))
+	assert.False(t, cc.Accept(tk("č", "NN")))
+	assert.False(t, cc.Accept(tk("2024", "NN")))
+	assert.False(t, cc.Accept(tk("https://korpus.cz", "NN")))
+	assert.False(t, cc.Accept(tk("word", "nn")))
+	assert.False(t, cc.Accept(tk("word", "")))
+
+	_, err = NewColumnConstraints(db.VertColumns{{Idx: 0, Match: "(("}})
+	assert.Error(t, err)
+
+	var empty ColumnConstraints
+	assert.True(t, empty.Accept(tk("", "")))
+}
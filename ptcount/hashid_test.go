@@ -0,0 +1,43 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXXHash64(t *testing.T) {
+	assert.Equal(t, uint64(0xef46db3751d8e999), xxHash64([]byte("")))
+	assert.Equal(t, uint64(0xd24ec4f1a98c6e5b), xxHash64([]byte("a")))
+	assert.Equal(t, uint64(0x44bc2cf5ad770999), xxHash64([]byte("abc")))
+	assert.Equal(t, uint64(0xfbcea83c8a378bf1), xxHash64([]byte("Nobody inspects the spammish repetition")))
+}
+
+func TestNewHashIDFunc(t *testing.T) {
+	fn, err := NewHashIDFunc("")
+	assert.NoError(t, err)
+	assert.Equal(t, "a9993e364706816aba3e25717850c26c9cd0d89d", fn([]byte("abc")))
+
+	fn, err = NewHashIDFunc(HashIDXXHash64)
+	assert.NoError(t, err)
+	assert.Equal(t, "44bc2cf5ad770999", fn([]byte("abc")))
+
+	_, err = NewHashIDFunc("md5")
+	assert.Error(t, err)
+}
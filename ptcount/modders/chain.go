@@ -17,6 +17,7 @@
 package modders
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -30,6 +31,8 @@ const (
 	TransformerPosCSCNC2020  = "cs_cnc2020"
 	TransformerPosCSCNC2000  = "cs_cnc2000"
 	TransformerPosCNC2000Spk = "cs_cnc2000_spk"
+	TransformerUDFeats       = "udFeats"
+	TransformerNumBucket     = "numBucket"
 )
 
 // StringTransformer represents a type which is able
@@ -38,33 +41,140 @@ type StringTransformer interface {
 	Transform(s string) string
 }
 
+const (
+	// PositionalSegmentSeparator separates individual segments of
+	// a positional specification (e.g. "0=toLower|identity")
+	PositionalSegmentSeparator = "|"
+
+	// PositionalSelectorSeparator separates an n-gram position
+	// (zero based) from a chain specification (e.g. "0=toLower")
+	PositionalSelectorSeparator = "="
+)
+
+// StringTransformerChain applies a sequence of transformers to a string.
+// The specification is a ':'-separated list of transformer names
+// (e.g. "toLower:firstChar").
+//
+// Optionally, different chains can be applied based on a token
+// position within an n-gram. In such case, the specification contains
+// '|'-separated segments where each segment is either "N=chain" (N is
+// a zero-based n-gram position) or a plain "chain" used for all the
+// other positions. E.g. "0=toLower" lower-cases only the first token
+// of each n-gram.
 type StringTransformerChain struct {
 	fn []StringTransformer
+
+	// positional contains chains for specific n-gram positions.
+	// It is nil for non-positional chains.
+	positional map[int][]StringTransformer
 }
 
-func NewStringTransformerChain(specif string) *StringTransformerChain {
+func parseChain(specif string) []StringTransformer {
 	values := strings.Split(specif, ":")
-	if len(values) > 0 {
-		mod := make([]StringTransformer, 0, len(values))
-		for _, v := range values {
-			mod = append(mod, StringTransformerFactory(v))
+	mod := make([]StringTransformer, 0, len(values))
+	for _, v := range values {
+		if tr := StringTransformerFactory(v); tr != nil {
+			mod = append(mod, tr)
 		}
-		return &StringTransformerChain{mod}
 	}
-	return &StringTransformerChain{fn: []StringTransformer{}}
+	return mod
 }
 
-func (m *StringTransformerChain) Transform(s string) string {
-	if m == nil {
-		return s
+func NewStringTransformerChain(specif string) *StringTransformerChain {
+	if !strings.Contains(specif, PositionalSelectorSeparator) {
+		return &StringTransformerChain{fn: parseChain(specif)}
 	}
+	ans := &StringTransformerChain{
+		fn:         []StringTransformer{},
+		positional: make(map[int][]StringTransformer),
+	}
+	for _, segment := range strings.Split(specif, PositionalSegmentSeparator) {
+		tmp := strings.SplitN(segment, PositionalSelectorSeparator, 2)
+		if len(tmp) == 1 {
+			ans.fn = parseChain(tmp[0])
+			continue
+		}
+		pos, err := strconv.Atoi(strings.TrimSpace(tmp[0]))
+		if err != nil || pos < 0 {
+			log.Warn().Str("segment", segment).Msg("invalid n-gram position in modder specification")
+			continue
+		}
+		ans.positional[pos] = parseChain(tmp[1])
+	}
+	return ans
+}
+
+func applyTransformers(fn []StringTransformer, s string) string {
 	ans := s
-	for _, mod := range m.fn {
+	for _, mod := range fn {
 		ans = mod.Transform(ans)
 	}
 	return ans
 }
 
+// Transform applies the default (non-positional) chain
+func (m *StringTransformerChain) Transform(s string) string {
+	if m == nil {
+		return s
+	}
+	return applyTransformers(m.fn, s)
+}
+
+// Prepend adds a transformer applied before all the configured
+// ones (including positional chains)
+func (m *StringTransformerChain) Prepend(tr StringTransformer) {
+	m.fn = append([]StringTransformer{tr}, m.fn...)
+	for k, v := range m.positional {
+		m.positional[k] = append([]StringTransformer{tr}, v...)
+	}
+}
+
+// SetNumBucket replaces all the NumBucket transformers
+// of the chain by a provided (custom configured) one
+func (m *StringTransformerChain) SetNumBucket(nb *NumBucket) {
+	replace := func(fn []StringTransformer) {
+		for i, tr := range fn {
+			if _, ok := tr.(*NumBucket); ok {
+				fn[i] = nb
+			}
+		}
+	}
+	replace(m.fn)
+	for _, v := range m.positional {
+		replace(v)
+	}
+}
+
+// IsPositional tells whether the chain applies different
+// transformations based on an n-gram position
+func (m *StringTransformerChain) IsPositional() bool {
+	return m != nil && m.positional != nil
+}
+
+// PreTransform is applied to a token value before its position
+// within an n-gram is known. For positional chains, the value
+// is kept unchanged (see TransformAt), otherwise it is the same
+// as Transform.
+func (m *StringTransformerChain) PreTransform(s string) string {
+	if m.IsPositional() {
+		return s
+	}
+	return m.Transform(s)
+}
+
+// TransformAt applies a chain configured for a specified
+// n-gram position (zero based). In case there is no such
+// chain, the default one is applied.
+func (m *StringTransformerChain) TransformAt(pos int, s string) string {
+	if m == nil {
+		return s
+	}
+	if fn, ok := m.positional[pos]; ok {
+		return applyTransformers(fn, s)
+	}
+	return applyTransformers(m.fn, s)
+}
+
 func StringTransformerFactory(name string) StringTransformer {
 	switch name {
 	case TransformerToLower:
@@ -76,6 +186,10 @@ func StringTransformerFactory(name string) StringTransformer {
 		return FirstChar{}
 	case TransformerPosPenn:
 		return Penn2Pos{}
+	case TransformerUDFeats:
+		return UDFeats{}
+	case TransformerNumBucket:
+		return dfltNumBucket
 	case "", TransformerIdentity:
 		return Identity{}
 	}
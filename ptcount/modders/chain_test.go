@@ -0,0 +1,64 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainNonPositional(t *testing.T) {
+	ch := NewStringTransformerChain("toLower:firstChar")
+	assert.False(t, ch.IsPositional())
+	assert.Equal(t, "a", ch.Transform("Abc"))
+	assert.Equal(t, "a", ch.PreTransform("Abc"))
+	assert.Equal(t, "a", ch.TransformAt(1, "Abc"))
+}
+
+func TestChainPositional(t *testing.T) {
+	ch := NewStringTransformerChain("0=toLower|firstChar")
+	assert.True(t, ch.IsPositional())
+	assert.Equal(t, "Abc", ch.PreTransform("Abc"))
+	assert.Equal(t, "abc", ch.TransformAt(0, "Abc"))
+	assert.Equal(t, "A", ch.TransformAt(1, "Abc"))
+
+	ch = NewStringTransformerChain("1=toLower")
+	assert.Equal(t, "Abc", ch.TransformAt(0, "Abc"))
+	assert.Equal(t, "abc", ch.TransformAt(1, "Abc"))
+}
+
+func TestNumBucket(t *testing.T) {
+	ch := NewStringTransformerChain("numBucket")
+	assert.Equal(t, "<YEAR>", ch.Transform("1989"))
+	assert.Equal(t, "<ORDINAL>", ch.Transform("21st"))
+	assert.Equal(t, "<ORDINAL>", ch.Transform("3."))
+	assert.Equal(t, "<NUM>", ch.Transform("-12"))
+	assert.Equal(t, "<NUM>", ch.Transform("3,14"))
+	assert.Equal(t, "<NUM>", ch.Transform("3400"))
+	assert.Equal(t, "A4", ch.Transform("A4"))
+
+	nb, err := NewNumBucket([]NumericBucket{{Label: "<N>", Pattern: "^[0-9]+$"}})
+	assert.NoError(t, err)
+	ch = NewStringTransformerChain("0=numBucket|identity")
+	ch.SetNumBucket(nb)
+	assert.Equal(t, "<N>", ch.TransformAt(0, "1989"))
+	assert.Equal(t, "1989", ch.TransformAt(1, "1989"))
+
+	_, err = NewNumBucket([]NumericBucket{{Label: "<N>", Pattern: "^[0-9+$"}})
+	assert.Error(t, err)
+}
@@ -16,7 +16,11 @@
 
 package modders
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/ud"
+)
 
 var (
 	pennTags = map[string]string{
@@ -86,3 +90,16 @@ func (pp Penn2Pos) Transform(s string) string {
 	}
 	return v
 }
+
+// UDFeats normalizes Universal Dependencies FEATS values
+// so equivalent orderings of features produce the same value.
+// Unparseable values are kept unchanged.
+type UDFeats struct{}
+
+func (m UDFeats) Transform(s string) string {
+	feats, err := ud.ParseFeats(s)
+	if err != nil {
+		return s
+	}
+	return feats.Key()
+}
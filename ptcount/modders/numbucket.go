@@ -0,0 +1,92 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modders
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// NumericBucket specifies a class label replacing
+// values matching a regular expression
+type NumericBucket struct {
+	Label   string `json:"label"`
+	Pattern string `json:"pattern"`
+}
+
+// DefaultNumericBuckets are used by NumBucket in case
+// no custom buckets are configured
+var DefaultNumericBuckets = []NumericBucket{
+	{Label: "<YEAR>", Pattern: `^(1[0-9]|20)[0-9]{2}$`},
+	{Label: "<ORDINAL>", Pattern: `^[0-9]+(\.|st|nd|rd|th)$`},
+	{Label: "<NUM>", Pattern: `^[+-]?[0-9]+([.,\x{00A0}][0-9]+)*$`},
+}
+
+var dfltNumBucket = mustCompileNumBucket(DefaultNumericBuckets)
+
+type compiledNumericBucket struct {
+	label   string
+	pattern *regexp.Regexp
+}
+
+// NumBucket replaces numeric values with class labels (e.g. <NUM>, <YEAR>).
+// Buckets are tested in the configured order and the first matching one
+// is used. Values matching no bucket are kept unchanged.
+type NumBucket struct {
+	buckets []compiledNumericBucket
+}
+
+func (m *NumBucket) Transform(s string) string {
+	for _, b := range m.buckets {
+		if b.pattern.MatchString(s) {
+			return b.label
+		}
+	}
+	return s
+}
+
+// NewNumBucket compiles provided buckets. In case there are no buckets,
+// DefaultNumericBuckets are used.
+func NewNumBucket(buckets []NumericBucket) (*NumBucket, error) {
+	if len(buckets) == 0 {
+		return dfltNumBucket, nil
+	}
+	return compileNumBucket(buckets)
+}
+
+func compileNumBucket(buckets []NumericBucket) (*NumBucket, error) {
+	ans := &NumBucket{buckets: make([]compiledNumericBucket, len(buckets))}
+	for i, b := range buckets {
+		if b.Label == "" {
+			return nil, fmt.Errorf("numeric bucket %d has no label", i)
+		}
+		ptrn, err := regexp.Compile(b.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of numeric bucket %s: %w", b.Label, err)
+		}
+		ans.buckets[i] = compiledNumericBucket{label: b.Label, pattern: ptrn}
+	}
+	return ans, nil
+}
+
+func mustCompileNumBucket(buckets []NumericBucket) *NumBucket {
+	ans, err := compileNumBucket(buckets)
+	if err != nil {
+		panic(err)
+	}
+	return ans
+}
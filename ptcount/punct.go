@@ -0,0 +1,83 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/tomachalek/vertigo/v6"
+)
+
+// PunctuationHandler detects punctuation tokens and tells
+// how they should be handled when counting n-grams
+// (see cnf.PunctuationKeep etc.). A nil handler keeps
+// all the tokens.
+type PunctuationHandler struct {
+	policy  string
+	column  int
+	pattern *regexp.Regexp
+}
+
+// NewPunctuationHandler creates a handler based on n-gram configuration.
+// In case punctuation is kept (the default), nil is returned.
+func NewPunctuationHandler(conf *cnf.NgramConf) (*PunctuationHandler, error) {
+	policy, err := conf.PunctuationPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if policy == cnf.PunctuationKeep {
+		return nil, nil
+	}
+	if conf.PunctuationColumn < 0 {
+		return nil, fmt.Errorf("invalid punctuationColumn %d", conf.PunctuationColumn)
+	}
+	ans := &PunctuationHandler{policy: policy, column: conf.PunctuationColumn}
+	if conf.PunctuationPattern != "" {
+		ans.pattern, err = regexp.Compile(conf.PunctuationPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid punctuationPattern: %w", err)
+		}
+	}
+	return ans, nil
+}
+
+func (ph *PunctuationHandler) isPunctuation(v string) bool {
+	if ph.pattern != nil {
+		return ph.pattern.MatchString(v)
+	}
+	if v == "" {
+		return false
+	}
+	for _, r := range v {
+		if !unicode.IsPunct(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Action returns cnf.PunctuationKeep for non-punctuation tokens
+// and the configured policy for punctuation ones.
+func (ph *PunctuationHandler) Action(tk *vertigo.Token) string {
+	if ph == nil || !ph.isPunctuation(tk.PosAttrByIndex(ph.column)) {
+		return cnf.PunctuationKeep
+	}
+	return ph.policy
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"strings"
+)
+
+const (
+	// DfltNgramSeparator is a default string used to join
+	// individual tokens of an n-gram
+	DfltNgramSeparator = " "
+
+	// NgramEscapeChar escapes separators (and itself)
+	// within values of n-gram tokens
+	NgramEscapeChar = `\`
+)
+
+// escapeNgramValue escapes all the occurrences of the escape
+// character and of the separator in a value
+func escapeNgramValue(v, sep string) string {
+	if !strings.Contains(v, NgramEscapeChar) && !strings.Contains(v, sep) {
+		return v
+	}
+	ans := strings.ReplaceAll(v, NgramEscapeChar, NgramEscapeChar+NgramEscapeChar)
+	return strings.ReplaceAll(ans, sep, NgramEscapeChar+sep)
+}
+
+// JoinNgram joins n-gram tokens by sep. In case there are more
+// than one token and a custom separator is used, separators and
+// escape characters embedded in the values are escaped so the original
+// values can be obtained by SplitNgram. With DfltNgramSeparator, values
+// are joined as they are so stored n-grams (and their hashes) stay
+// the same as in databases created by older versions.
+// Single token values are kept unchanged.
+func JoinNgram(values []string, sep string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	if sep == DfltNgramSeparator {
+		return strings.Join(values, sep)
+	}
+	tmp := make([]string, len(values))
+	for i, v := range values {
+		tmp[i] = escapeNgramValue(v, sep)
+	}
+	return strings.Join(tmp, sep)
+}
+
+// SplitNgram splits an n-gram created by JoinNgram (with more than
+// one token) back to its tokens and unescapes them. For DfltNgramSeparator
+// (no escaping), tokens containing the separator cannot be restored.
+func SplitNgram(ngram, sep string) []string {
+	if sep == DfltNgramSeparator {
+		return strings.Split(ngram, sep)
+	}
+	ans := make([]string, 0, 3)
+	var curr strings.Builder
+	for i := 0; i < len(ngram); {
+		if strings.HasPrefix(ngram[i:], NgramEscapeChar) && i+len(NgramEscapeChar) < len(ngram) {
+			i += len(NgramEscapeChar)
+			if strings.HasPrefix(ngram[i:], sep) {
+				curr.WriteString(sep)
+				i += len(sep)
+
+			} else {
+				curr.WriteString(ngram[i : i+len(NgramEscapeChar)])
+				i += len(NgramEscapeChar)
+			}
+
+		} else if strings.HasPrefix(ngram[i:], sep) {
+			ans = append(ans, curr.String())
+			curr.Reset()
+			i += len(sep)
+
+		} else {
+			curr.WriteByte(ngram[i])
+			i++
+		}
+	}
+	return append(ans, curr.String())
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinSplitNgramRoundTrip(t *testing.T) {
+	for _, sep := range []string{"|", "<>", "  "} {
+		values := []string{"New York", `a\b`, "x|y", "p<>q", `\`, ""}
+		ngram := JoinNgram(values, sep)
+		assert.Equal(t, values, SplitNgram(ngram, sep), "separator: %s", sep)
+	}
+}
+
+func TestJoinNgram(t *testing.T) {
+	assert.Equal(t, `New York city`, JoinNgram([]string{"New York", "city"}, " "))
+	assert.Equal(t, `a\b c`, JoinNgram([]string{`a\b`, "c"}, " "))
+	assert.Equal(t, `New York|a\|b`, JoinNgram([]string{"New York", "a|b"}, "|"))
+	assert.Equal(t, "New York", JoinNgram([]string{"New York"}, " "))
+	assert.Equal(t, "a|b", JoinNgram([]string{"a", "b"}, "|"))
+}
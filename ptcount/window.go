@@ -0,0 +1,67 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
+)
+
+// NgramWindow collects encoded tokens (see WordDict) of the current
+// atom and produces n-grams as tokens go. The window size and mode
+// are read from the configuration on each call.
+type NgramWindow struct {
+	conf   *cnf.NgramConf
+	tokens [][]int
+}
+
+// Add appends a token to the window and returns an n-gram
+// ending at the token. In case there are not enough tokens
+// in the window yet, nil is returned.
+func (w *NgramWindow) Add(
+	attrs []int,
+	columnModders []*modders.StringTransformerChain,
+	wd *WordDict,
+) *NgramCounter {
+	w.tokens = append(w.tokens, attrs)
+	if len(w.tokens) < w.conf.NgramSize {
+		return nil
+	}
+	ngram := NewNgramCounter(w.conf.NgramSize)
+	startPos := len(w.tokens) - w.conf.NgramSize
+	for i := startPos; i < len(w.tokens); i++ {
+		ngram.AddToken(ApplyPositionalModders(w.tokens[i], i-startPos, columnModders, wd))
+	}
+	if w.conf.IsDisjoint() {
+		w.Reset()
+	}
+	return ngram
+}
+
+// Reset empties the window so no n-gram can span
+// over the current position
+func (w *NgramWindow) Reset() {
+	w.tokens = w.tokens[:0]
+}
+
+// NewNgramWindow creates a new window for a provided configuration
+func NewNgramWindow(conf *cnf.NgramConf) *NgramWindow {
+	return &NgramWindow{
+		conf:   conf,
+		tokens: make([][]int, 0, 20),
+	}
+}
@@ -1,5 +1,5 @@
-// Copyright 2020 Tomas Machalek <tomas.machalek@gmail.com>
-// Copyright 2020 Charles University, Faculty of Arts,
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
 //                Institute of the Czech National Corpus
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
@@ -16,42 +16,233 @@
 
 package ptcount
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// numConcurrentShards is a number of independently locked parts
+	// of a WordDict created by NewConcurrentWordDict (a power of two)
+	numConcurrentShards = 64
+
+	fnvOffset = 2166136261
+	fnvPrime  = 16777619
+
+	// wordDictFileVersion is a version of the format
+	// written by WordDict.Save
+	wordDictFileVersion = 1
+)
+
+// wordDictFile is a serialized form of WordDict. Words are stored
+// per shard in the order of their addition so all the numeric
+// representations are preserved once the file is loaded.
+type wordDictFile struct {
+	Version    int        `json:"version"`
+	Concurrent bool       `json:"concurrent"`
+	Shards     [][]string `json:"shards"`
+}
+
+// wordDictShard is a part of WordDict containing words
+// with the same hash modulo the number of shards
+type wordDictShard struct {
+	sync.RWMutex
+	data    map[string]int
+	dataRev []string
+}
+
+// add adds a new word to the shard (with index si)
+// and returns its numeric representation
+func (s *wordDictShard) add(word string, si, numShards int) int {
+	s.dataRev = append(s.dataRev, word)
+	v := (len(s.dataRev)-1)*numShards + si + 1
+	s.data[word] = v
+	return v
+}
+
 // WordDict is basically a bidirectional map for mapping
 // between words and ints and ints and words. It is used to
 // reduce memory usage when collecting n-grams.
+//
+// A WordDict created by NewConcurrentWordDict is safe for concurrent
+// use. Its words are distributed among shards locked independently
+// so concurrent Add calls rarely wait for each other. A numeric
+// representation of a word encodes both its shard and its position
+// within the shard so Get needs only a single shard's read lock.
+// A WordDict created by NewWordDict has a single shard and uses
+// no locks so the single-threaded processing is not slowed down.
 type WordDict struct {
-	counter int
-	data    map[string]int
-	dataRev map[int]string
+	shards []wordDictShard
+
+	// mask selects a shard out of a hash of a word
+	mask uint32
+
+	concurrent bool
+	size       atomic.Int64
+}
+
+// shardIdx calculates a shard of a word using
+// the (inlined) FNV-1a hash function
+func (w *WordDict) shardIdx(word string) int {
+	if w.mask == 0 {
+		return 0
+	}
+	h := uint32(fnvOffset)
+	for i := 0; i < len(word); i++ {
+		h ^= uint32(word[i])
+		h *= fnvPrime
+	}
+	return int(h & w.mask)
 }
 
 // Add adds a word to the dictionary and returns
-// its numeric representation.
+// its numeric representation (always > 0).
 func (w *WordDict) Add(word string) int {
-	v, ok := w.data[word]
-	if !ok {
-		w.counter++
-		w.data[word] = w.counter
-		w.dataRev[w.counter] = word
-		return w.counter
-
-	} else {
+	si := w.shardIdx(word)
+	shard := &w.shards[si]
+	if !w.concurrent {
+		if v, ok := shard.data[word]; ok {
+			return v
+		}
+		w.size.Add(1)
+		return shard.add(word, si, len(w.shards))
+	}
+	shard.RLock()
+	v, ok := shard.data[word]
+	shard.RUnlock()
+	if ok {
+		return v
+	}
+	shard.Lock()
+	defer shard.Unlock()
+	if v, ok := shard.data[word]; ok {
 		return v
 	}
+	w.size.Add(1)
+	return shard.add(word, si, len(w.shards))
 }
 
 // Get returns a word based on its integer representation.
 func (w *WordDict) Get(idx int) string {
-	return w.dataRev[idx]
+	if idx <= 0 {
+		return ""
+	}
+	shard := &w.shards[(idx-1)%len(w.shards)]
+	pos := (idx - 1) / len(w.shards)
+	if w.concurrent {
+		shard.RLock()
+		defer shard.RUnlock()
+	}
+	if pos >= len(shard.dataRev) {
+		return ""
+	}
+	return shard.dataRev[pos]
 }
 
 func (w *WordDict) Size() int {
-	return len(w.data)
+	return int(w.size.Load())
+}
+
+func newWordDict(numShards int, concurrent bool) *WordDict {
+	ans := &WordDict{
+		shards:     make([]wordDictShard, numShards),
+		mask:       uint32(numShards - 1),
+		concurrent: concurrent,
+	}
+	for i := range ans.shards {
+		ans.shards[i].data = make(map[string]int)
+	}
+	return ans
 }
 
+// NewWordDict creates a dictionary for single-threaded use
 func NewWordDict() *WordDict {
-	return &WordDict{
-		data:    make(map[string]int),
-		dataRev: make(map[int]string),
+	return newWordDict(1, false)
+}
+
+// NewConcurrentWordDict creates a dictionary safe for concurrent use
+func NewConcurrentWordDict() *WordDict {
+	return newWordDict(numConcurrentShards, true)
+}
+
+// Save writes the dictionary as JSON to a file so it can be reused
+// (with all the numeric representations preserved) by a later
+// computation. The file is replaced atomically.
+func (w *WordDict) Save(path string) error {
+	out := wordDictFile{
+		Version:    wordDictFileVersion,
+		Concurrent: w.concurrent,
+		Shards:     make([][]string, len(w.shards)),
+	}
+	for i := range w.shards {
+		if w.concurrent {
+			w.shards[i].RLock()
+		}
+		out.Shards[i] = w.shards[i].dataRev
+		if w.concurrent {
+			w.shards[i].RUnlock()
+		}
+	}
+	data, err := sonic.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("failed to save word dictionary: %w", err)
+	}
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to save word dictionary: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save word dictionary: %w", err)
+	}
+	return nil
+}
+
+// LoadWordDict loads a dictionary stored by WordDict.Save.
+// In case the file does not exist, an empty single-threaded
+// dictionary is returned.
+func LoadWordDict(path string) (*WordDict, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewWordDict(), nil
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load word dictionary: %w", err)
+	}
+	var stored wordDictFile
+	if err := sonic.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to load word dictionary: %w", err)
+	}
+	if stored.Version != wordDictFileVersion {
+		return nil, fmt.Errorf(
+			"failed to load word dictionary: unsupported version %d", stored.Version)
+	}
+	numShards := len(stored.Shards)
+	if numShards == 0 || numShards&(numShards-1) != 0 {
+		return nil, fmt.Errorf(
+			"failed to load word dictionary: invalid number of shards %d", numShards)
+	}
+	ans := newWordDict(numShards, stored.Concurrent)
+	for si, words := range stored.Shards {
+		shard := &ans.shards[si]
+		shard.dataRev = words
+		for pos, word := range words {
+			if ans.shardIdx(word) != si {
+				return nil, fmt.Errorf(
+					"failed to load word dictionary: word '%s' stored in a wrong shard", word)
+			}
+			if _, ok := shard.data[word]; ok {
+				return nil, fmt.Errorf(
+					"failed to load word dictionary: duplicate word '%s'", word)
+			}
+			shard.data[word] = pos*numShards + si + 1
+		}
+		ans.size.Add(int64(len(words)))
 	}
+	return ans, nil
 }
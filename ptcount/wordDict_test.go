@@ -0,0 +1,144 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ptcount
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordDict(t *testing.T) {
+	for _, wd := range []*WordDict{NewWordDict(), NewConcurrentWordDict()} {
+		a := wd.Add("a")
+		b := wd.Add("b")
+		assert.Equal(t, a, wd.Add("a"))
+		assert.NotEqual(t, a, b)
+		assert.Equal(t, "a", wd.Get(a))
+		assert.Equal(t, "b", wd.Get(b))
+		assert.Equal(t, "", wd.Get(0))
+		assert.Equal(t, "", wd.Get(b+1000))
+		assert.Equal(t, 2, wd.Size())
+	}
+	// single-threaded dictionaries keep sequential ids
+	wd := NewWordDict()
+	assert.Equal(t, []int{1, 2, 1}, []int{wd.Add("x"), wd.Add("y"), wd.Add("x")})
+}
+
+func TestWordDictSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.json")
+	empty, err := LoadWordDict(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, empty.Size())
+
+	for _, wd := range []*WordDict{NewWordDict(), NewConcurrentWordDict()} {
+		ids := make(map[string]int)
+		for i := 0; i < 100; i++ {
+			w := fmt.Sprintf("w%d", i)
+			ids[w] = wd.Add(w)
+		}
+		assert.NoError(t, wd.Save(path))
+		loaded, err := LoadWordDict(path)
+		assert.NoError(t, err)
+		assert.Equal(t, wd.Size(), loaded.Size())
+		for w, id := range ids {
+			assert.Equal(t, w, loaded.Get(id))
+			assert.Equal(t, id, loaded.Add(w))
+		}
+		assert.Equal(t, wd.Add("new"), loaded.Add("new"))
+	}
+}
+
+func TestConcurrentWordDict(t *testing.T) {
+	wd := NewConcurrentWordDict()
+	var wg sync.WaitGroup
+	ids := make([][]int, 8)
+	for g := range ids {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ids[g] = make([]int, 1000)
+			for i := range ids[g] {
+				ids[g][i] = wd.Add(fmt.Sprintf("w%d", i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, wd.Size())
+	for g := 1; g < len(ids); g++ {
+		assert.Equal(t, ids[0], ids[g])
+	}
+	for i, id := range ids[0] {
+		assert.Equal(t, fmt.Sprintf("w%d", i), wd.Get(id))
+	}
+}
+
+// benchmarkWords generates a Zipf-like mix of frequent and rare
+// words with approximately vocabSize distinct items
+func benchmarkWords(vocabSize int) []string {
+	words := make([]string, 1000000)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", (i*i)%(i*vocabSize/len(words)+1))
+	}
+	return words
+}
+
+func benchmarkWordDictAdd(b *testing.B, vocabSize int) {
+	words := benchmarkWords(vocabSize)
+	wd := NewWordDict()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wd.Add(words[i%len(words)])
+	}
+}
+
+func BenchmarkWordDictAddSmallVocab(b *testing.B) {
+	benchmarkWordDictAdd(b, 10000)
+}
+
+func BenchmarkWordDictAddLargeVocab(b *testing.B) {
+	benchmarkWordDictAdd(b, 1000000)
+}
+
+func BenchmarkWordDictGet(b *testing.B) {
+	words := benchmarkWords(10000)
+	wd := NewWordDict()
+	ids := make([]int, len(words))
+	for i, w := range words {
+		ids[i] = wd.Add(w)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		wd.Get(ids[i%len(ids)])
+	}
+}
+
+func BenchmarkConcurrentWordDictAddParallel(b *testing.B) {
+	words := benchmarkWords(1000000)
+	wd := NewConcurrentWordDict()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			wd.Add(words[i%len(words)])
+			i++
+		}
+	})
+}
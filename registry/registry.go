@@ -0,0 +1,182 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package registry generates draft Manatee registry files
+// from an extraction configuration and generated data
+package registry
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+const (
+	// DfltMaxSubcorpValues is a default max. number of distinct
+	// values of an attribute offered in SUBCORPATTRS
+	DfltMaxSubcorpValues = 100
+)
+
+// AttrStats contains observed statistics of a structural attribute
+type AttrStats struct {
+	Structure string
+	Attr      string
+
+	// NumValues is a number of distinct values
+	NumValues int
+
+	// NumAtoms is a number of atoms with the attribute set
+	NumAtoms int
+}
+
+// FullName returns the attribute name as used by Manatee (struct.attr)
+func (as AttrStats) FullName() string {
+	return as.Structure + "." + as.Attr
+}
+
+// orderedStructures returns names of configured structures starting
+// with the atom structure and its parent, others are sorted by name
+func orderedStructures(conf *cnf.VTEConf) []string {
+	ans := make([]string, 0, len(conf.Structures))
+	for _, st := range []string{conf.AtomStructure, conf.AtomParentStructure} {
+		if _, ok := conf.Structures[st]; ok && st != "" {
+			ans = append(ans, st)
+		}
+	}
+	others := make([]string, 0, len(conf.Structures))
+	for st := range conf.Structures {
+		if st != conf.AtomStructure && st != conf.AtomParentStructure {
+			others = append(others, st)
+		}
+	}
+	sort.Strings(others)
+	return append(ans, others...)
+}
+
+// CollectAttrStats obtains statistics of all the configured
+// structural attributes from the liveattrs table
+func CollectAttrStats(reader *db.Reader, conf *cnf.VTEConf) ([]AttrStats, error) {
+	ans := make([]AttrStats, 0, 20)
+	for _, st := range orderedStructures(conf) {
+		for _, attr := range conf.Structures[st] {
+			item := AttrStats{Structure: st, Attr: attr}
+			row := reader.DB.QueryRow(
+				fmt.Sprintf(
+					"SELECT COUNT(DISTINCT `%s_%s`), COUNT(`%s_%s`) FROM %s WHERE corpus_id = ?",
+					st, attr, st, attr, reader.Table(db.LiveAttrsTable)),
+				conf.CorpusID(),
+			)
+			if err := row.Scan(&item.NumValues, &item.NumAtoms); err != nil {
+				return nil, fmt.Errorf("failed to get statistics of %s: %w", item.FullName(), err)
+			}
+			ans = append(ans, item)
+		}
+	}
+	return ans, nil
+}
+
+// Options configures a generated registry
+type Options struct {
+
+	// MaxSubcorpValues is a max. number of distinct values
+	// of an attribute offered in SUBCORPATTRS
+	// (DfltMaxSubcorpValues if zero)
+	MaxSubcorpValues int
+}
+
+func (opts Options) maxSubcorpValues() int {
+	if opts.MaxSubcorpValues <= 0 {
+		return DfltMaxSubcorpValues
+	}
+	return opts.MaxSubcorpValues
+}
+
+// posAttrName returns a name of a positional attribute
+// based on the role of a counted column
+func posAttrName(vc db.VertColumn) string {
+	if vc.Role != "" {
+		return vc.Role
+	}
+	return fmt.Sprintf("col%d", vc.Idx)
+}
+
+// Write writes a draft registry of a corpus. Positional attributes
+// are derived from counted columns (which may not cover all the
+// columns of the vertical), structures and their attributes from
+// the configuration. Attributes with at least two and at most
+// opts.MaxSubcorpValues distinct values are offered in SUBCORPATTRS,
+// all the structural attributes with some values are listed in
+// FULLREF. Generated values are meant to be reviewed and completed
+// by hand (e.g. PATH).
+func Write(w io.Writer, conf *cnf.VTEConf, stats []AttrStats, opts Options) error {
+	var b strings.Builder
+	b.WriteString("# draft registry generated by vert-tagextract - review before use\n")
+	fmt.Fprintf(&b, "NAME \"%s\"\n", conf.Corpus)
+	fmt.Fprintf(&b, "PATH \"/path/to/data/%s\"\n", conf.Corpus)
+	if verticals := conf.GetDefinedVerticals(); len(verticals) == 1 {
+		fmt.Fprintf(&b, "VERTICAL \"%s\"\n", verticals[0])
+	}
+	encoding := conf.Encoding
+	if encoding == "" {
+		encoding = "utf-8"
+	}
+	fmt.Fprintf(&b, "ENCODING \"%s\"\n", encoding)
+
+	if len(conf.Ngrams.VertColumns) > 0 {
+		b.WriteString("\n# positional attributes known from countColumns (complete to match the vertical)\n")
+		cols := make(db.VertColumns, len(conf.Ngrams.VertColumns))
+		copy(cols, conf.Ngrams.VertColumns)
+		sort.SliceStable(cols, func(i, j int) bool { return cols[i].Idx < cols[j].Idx })
+		for _, vc := range cols {
+			fmt.Fprintf(&b, "ATTRIBUTE %s    # column %d\n", posAttrName(vc), vc.Idx)
+		}
+	}
+
+	statsByStruct := make(map[string][]AttrStats)
+	for _, st := range stats {
+		statsByStruct[st.Structure] = append(statsByStruct[st.Structure], st)
+	}
+	subcorpAttrs := make([]string, 0, len(stats))
+	fullRef := make([]string, 0, len(stats))
+	for _, st := range orderedStructures(conf) {
+		fmt.Fprintf(&b, "\nSTRUCTURE %s {\n", st)
+		for _, attrSt := range statsByStruct[st] {
+			fmt.Fprintf(
+				&b, "    ATTRIBUTE %s    # %d distinct values in %d atoms\n",
+				attrSt.Attr, attrSt.NumValues, attrSt.NumAtoms)
+			if attrSt.NumValues > 1 && attrSt.NumValues <= opts.maxSubcorpValues() {
+				subcorpAttrs = append(subcorpAttrs, attrSt.FullName())
+			}
+			if attrSt.NumValues > 0 {
+				fullRef = append(fullRef, attrSt.FullName())
+			}
+		}
+		b.WriteString("}\n")
+	}
+	b.WriteString("\n")
+	if len(subcorpAttrs) > 0 {
+		fmt.Fprintf(&b, "SUBCORPATTRS \"%s\"\n", strings.Join(subcorpAttrs, ","))
+	}
+	if len(fullRef) > 0 {
+		fmt.Fprintf(&b, "FULLREF \"%s\"\n", strings.Join(fullRef, ","))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	conf := &cnf.VTEConf{
+		Corpus:        "susanne",
+		AtomStructure: "p",
+		Structures: map[string][]string{
+			"p":   {"id"},
+			"doc": {"id", "genre", "note"},
+		},
+		VerticalFile: "/data/susanne.vert",
+	}
+	conf.Ngrams.VertColumns = db.VertColumns{{Idx: 2, Role: "lemma"}, {Idx: 0, Role: "word"}}
+	stats := []AttrStats{
+		{Structure: "p", Attr: "id", NumValues: 500, NumAtoms: 500},
+		{Structure: "doc", Attr: "id", NumValues: 20, NumAtoms: 500},
+		{Structure: "doc", Attr: "genre", NumValues: 4, NumAtoms: 500},
+		{Structure: "doc", Attr: "note", NumValues: 0, NumAtoms: 0},
+	}
+	var b strings.Builder
+	assert.NoError(t, Write(&b, conf, stats, Options{MaxSubcorpValues: 10}))
+	out := b.String()
+	assert.Contains(t, out, "NAME \"susanne\"\n")
+	assert.Contains(t, out, "VERTICAL \"/data/susanne.vert\"\n")
+	assert.Less(t, strings.Index(out, "ATTRIBUTE word"), strings.Index(out, "ATTRIBUTE lemma"))
+	assert.Less(t, strings.Index(out, "STRUCTURE p {"), strings.Index(out, "STRUCTURE doc {"))
+	assert.Contains(t, out, "    ATTRIBUTE genre    # 4 distinct values in 500 atoms\n")
+	assert.Contains(t, out, "SUBCORPATTRS \"doc.genre\"\n")
+	assert.Contains(t, out, "FULLREF \"p.id,doc.id,doc.genre\"\n")
+}
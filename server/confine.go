@@ -0,0 +1,131 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// isPipeInput tests whether a vertical file is specified
+// as a command whose output is read (e.g. "| zcat file.gz")
+func isPipeInput(path string) bool {
+	return strings.HasPrefix(strings.TrimSpace(path), "|")
+}
+
+// confinePath resolves a path submitted by a client so it points
+// inside the server data directory. Relative paths are taken
+// as relative to the directory. The item is used in error messages.
+func confinePath(dataDir, item, path string) (string, error) {
+	if dataDir == "" {
+		return "", fmt.Errorf(
+			"%s is not allowed in submitted jobs - the server has no data directory configured", item)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dataDir, path)
+	}
+	path = filepath.Clean(path)
+	rel, err := filepath.Rel(dataDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s must be located in the server data directory", item)
+	}
+	return path, nil
+}
+
+// confineDBConf confines file-based databases (i.e. all but MySQL)
+// of the database and its mirrors to the server data directory. Items
+// which would make the server read its own secrets (password variables
+// and files) and send them to a client-chosen host are rejected.
+func confineDBConf(dataDir, item string, conf *db.Conf) error {
+	if len(conf.SQLite.Extensions) > 0 {
+		return fmt.Errorf("%s.sqlite.extensions are not allowed in submitted jobs", item)
+	}
+	if conf.PasswordEnv != "" || conf.PasswordFile != "" {
+		return fmt.Errorf("%s.passwordEnv and %s.passwordFile are not allowed in submitted jobs", item, item)
+	}
+	if len(conf.DSNParams) > 0 {
+		return fmt.Errorf("%s.dsnParams are not allowed in submitted jobs", item)
+	}
+	if conf.Type != "mysql" && conf.Name != "" {
+		var err error
+		conf.Name, err = confinePath(dataDir, item+".name", conf.Name)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range conf.Mirrors {
+		if err := confineDBConf(dataDir, fmt.Sprintf("%s.mirrors[%d]", item, i), &conf.Mirrors[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSubmittedConf rejects configuration items which would make
+// the server run code specified by a client and confines all the files
+// the server would write to (databases, manifests etc.) to dataDir
+func checkSubmittedConf(conf *cnf.VTEConf, dataDir string) error {
+	if len(conf.PostCommitHooks) > 0 {
+		return fmt.Errorf("post-commit hooks are not allowed in submitted jobs")
+	}
+	if conf.Filter.Lib != "" || conf.Filter.Fn != "" {
+		return fmt.Errorf("filter plug-ins are not allowed in submitted jobs")
+	}
+	if conf.Ngrams.Filter != nil && (conf.Ngrams.Filter.Lib != "" || conf.Ngrams.Filter.Fn != "") {
+		return fmt.Errorf("n-gram filter plug-ins are not allowed in submitted jobs")
+	}
+	for _, step := range conf.Degradation.Steps {
+		if step.Filter != nil || step.NgramFilter != nil {
+			return fmt.Errorf("filter plug-ins of degradation steps are not allowed in submitted jobs")
+		}
+	}
+	if isPipeInput(conf.VerticalFile) {
+		return fmt.Errorf("piped verticalFile is not allowed in submitted jobs")
+	}
+	for _, path := range conf.VerticalFiles {
+		if isPipeInput(path) {
+			return fmt.Errorf("piped verticalFiles are not allowed in submitted jobs")
+		}
+	}
+	if err := confineDBConf(dataDir, "db", &conf.DB); err != nil {
+		return err
+	}
+	paths := []struct {
+		item  string
+		value *string
+	}{
+		{"kontext.path", &conf.KonText.Path},
+		{"manifest.path", &conf.Manifest.Path},
+		{"anonymize.mappingFile", &conf.Anonymize.MappingFile},
+		{"ngrams.wordDictFile", &conf.Ngrams.WordDictFile},
+	}
+	for _, p := range paths {
+		if *p.value == "" {
+			continue
+		}
+		var err error
+		*p.value, err = confinePath(dataDir, p.item, *p.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/library"
+	"github.com/czcorpus/vert-tagextract/v3/monitor"
+	"github.com/czcorpus/vert-tagextract/v3/proc"
+	"github.com/czcorpus/vert-tagextract/v3/status"
+)
+
+const (
+	JobStatePending   = "pending"
+	JobStateRunning   = "running"
+	JobStateFinished  = "finished"
+	JobStateFailed    = "failed"
+	JobStateCancelled = "cancelled"
+
+	// maxJobWarnings is a max. number of warnings
+	// kept by a job (the newest ones are dropped)
+	maxJobWarnings = 100
+)
+
+// StatusRecord is a JSON-serializable variant of proc.Status
+type StatusRecord = status.Record
+
+// JobInfo is a public description of a job
+type JobInfo struct {
+	ID       string           `json:"id"`
+	Corpus   string           `json:"corpus"`
+	Append   bool             `json:"append"`
+	Priority int              `json:"priority"`
+	State    string           `json:"state"`
+	Created  time.Time        `json:"created"`
+	Finished *time.Time       `json:"finished,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Progress monitor.Snapshot `json:"progress"`
+
+	// Warnings contains errors reported during the run which
+	// did not stop it (e.g. errors of skipped files). NumWarnings
+	// is their total number (only maxJobWarnings are kept).
+	Warnings    []string `json:"warnings,omitempty"`
+	NumWarnings int      `json:"numWarnings,omitempty"`
+}
+
+// Job represents a single extraction task submitted
+// to the server.
+type Job struct {
+	sync.Mutex
+	id          string
+	conf        *cnf.VTEConf
+	appendData  bool
+	priority    int
+	state       string
+	created     time.Time
+	finished    *time.Time
+	err         error
+	warnings    []string
+	numWarnings int
+	ctx         context.Context
+	cancel      context.CancelFunc
+	tracker     *monitor.Tracker
+	subscribers []chan StatusRecord
+}
+
+func (job *Job) ID() string {
+	return job.id
+}
+
+// State returns the current job state (JobStatePending, JobStateRunning,...)
+func (job *Job) State() string {
+	job.Lock()
+	defer job.Unlock()
+	return job.state
+}
+
+// IsDone returns true if the job is finished (no matter whether
+// successfully or not)
+func (job *Job) IsDone() bool {
+	job.Lock()
+	defer job.Unlock()
+	return job.finished != nil
+}
+
+// Info returns the current job state
+func (job *Job) Info() JobInfo {
+	job.Lock()
+	defer job.Unlock()
+	ans := JobInfo{
+		ID:       job.id,
+		Corpus:   job.conf.Corpus,
+		Append:   job.appendData,
+		Priority: job.priority,
+		State:    job.state,
+		Created:  job.created,
+		Finished: job.finished,
+		Progress: job.tracker.Snapshot(),
+	}
+	if job.numWarnings > 0 {
+		ans.Warnings = append([]string{}, job.warnings...)
+		ans.NumWarnings = job.numWarnings
+	}
+	if job.err != nil {
+		ans.Error = job.err.Error()
+	}
+	return ans
+}
+
+// Subscribe returns a channel providing all the status updates
+// of the job. The channel is closed once the job is finished.
+// In case the job is already finished, the returned channel
+// is closed right away.
+func (job *Job) Subscribe() <-chan StatusRecord {
+	job.Lock()
+	defer job.Unlock()
+	ch := make(chan StatusRecord, 100)
+	if job.finished != nil {
+		close(ch)
+
+	} else {
+		job.subscribers = append(job.subscribers, ch)
+	}
+	return ch
+}
+
+func (job *Job) publish(status proc.Status) {
+	rec := status.Record()
+	job.Lock()
+	defer job.Unlock()
+	for _, ch := range job.subscribers {
+		select {
+		case ch <- rec:
+		default:
+			log.Warn().Str("jobId", job.id).Msg("slow status subscriber, dropping status")
+		}
+	}
+}
+
+// addWarning stores a non-fatal error reported during the run
+func (job *Job) addWarning(err error) {
+	job.Lock()
+	defer job.Unlock()
+	job.numWarnings++
+	if len(job.warnings) < maxJobWarnings {
+		job.warnings = append(job.warnings, err.Error())
+	}
+}
+
+func (job *Job) setState(state string, err error) {
+	job.Lock()
+	defer job.Unlock()
+	job.state = state
+	if err != nil {
+		job.err = err
+	}
+	if state == JobStateFinished || state == JobStateFailed || state == JobStateCancelled {
+		t := time.Now()
+		job.finished = &t
+		for _, ch := range job.subscribers {
+			close(ch)
+		}
+		job.subscribers = nil
+	}
+}
+
+// Cancel stops the job. For a pending job, nothing is run.
+func (job *Job) Cancel() {
+	job.Lock()
+	state := job.state
+	job.Unlock()
+	switch state {
+	case JobStatePending:
+		job.cancel()
+		job.setState(JobStateCancelled, nil)
+	case JobStateRunning:
+		job.tracker.Cancel()
+	}
+}
+
+// Run performs the extraction and blocks until it is finished.
+// The job fails only in case the whole run fails. Other errors
+// (e.g. of files skipped due to cnf.VTEConf.OnFileError) are
+// reported as warnings.
+func (job *Job) Run() {
+	ctx := job.ctx
+	job.Lock()
+	if job.finished != nil {
+		job.Unlock()
+		return
+	}
+	job.state = JobStateRunning
+	job.Unlock()
+	log.Info().Str("jobId", job.id).Str("corpus", job.conf.Corpus).Msg("starting extraction job")
+	summary, err := library.Extract(
+		ctx,
+		job.conf,
+		job.appendData,
+		library.WithProgressFunc(func(status proc.Status) {
+			job.tracker.Update(status)
+			job.publish(status)
+			if status.Error != nil {
+				job.addWarning(status.Error)
+			}
+		}),
+	)
+	job.tracker.SetFinished()
+	if ctx.Err() != nil {
+		job.setState(JobStateCancelled, ctx.Err())
+
+	} else if err != nil {
+		job.setState(JobStateFailed, err)
+
+	} else if summary != nil && summary.Failed {
+		job.setState(JobStateFailed, errors.New(summary.LastError))
+
+	} else {
+		job.setState(JobStateFinished, nil)
+	}
+	log.Info().Str("jobId", job.id).Str("state", job.Info().State).Msg("extraction job finished")
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// NewJob creates a new pending job. The job must be started
+// via Run(). Jobs with higher priority are started first.
+func NewJob(conf *cnf.VTEConf, appendData bool, priority int) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Job{
+		id:         newJobID(),
+		conf:       conf,
+		appendData: appendData,
+		priority:   priority,
+		state:      JobStatePending,
+		created:    time.Now(),
+		ctx:        ctx,
+		cancel:     cancel,
+		tracker:    monitor.NewTracker(conf.Corpus, cancel),
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func createJobConf(t *testing.T, onFileError string) *cnf.VTEConf {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.txt")
+	valid := filepath.Join(dir, "valid.txt")
+	files := map[string]string{
+		broken: "<doc id=\"d1\">\n<p>\nfoo\tfoo\tN\nbar\n</p>\n</doc>\n",
+		valid:  "<doc id=\"d2\">\n<p>\nfoo\tfoo\tN\nbar\tbar\tN\n</p>\n</doc>\n",
+	}
+	for path, data := range files {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &cnf.VTEConf{
+		Corpus:        "test",
+		VerticalFiles: []string{broken, valid},
+		Encoding:      "utf-8",
+		AtomStructure: "p",
+		Structures:    map[string][]string{"doc": {"id"}},
+		Ngrams: cnf.NgramConf{
+			NgramSize:     1,
+			VertColumns:   db.VertColumns{{Idx: 0}, {Idx: 1}},
+			MissingColumn: cnf.MissingColumnError,
+		},
+		OnFileError: onFileError,
+		DB: db.Conf{
+			Type: "sqlite",
+			Name: filepath.Join(dir, "test.db"),
+		},
+	}
+}
+
+func TestJobSkippedFileIsWarning(t *testing.T) {
+	job := NewJob(createJobConf(t, cnf.FileErrorSkip), false, 0)
+	job.Run()
+	info := job.Info()
+	assert.Equal(t, JobStateFinished, info.State)
+	assert.Empty(t, info.Error)
+	assert.NotEmpty(t, info.Warnings)
+	assert.Equal(t, len(info.Warnings), info.NumWarnings)
+}
+
+func TestJobFailsOnFatalError(t *testing.T) {
+	job := NewJob(createJobConf(t, cnf.FileErrorAbort), false, 0)
+	job.Run()
+	info := job.Info()
+	assert.Equal(t, JobStateFailed, info.State)
+	assert.NotEmpty(t, info.Error)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bytedance/sonic"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/fs"
+)
+
+// queuedJob is a persistent representation of a job
+// which has not been finished yet
+type queuedJob struct {
+	ID       string       `json:"id"`
+	Conf     *cnf.VTEConf `json:"conf"`
+	Append   bool         `json:"append"`
+	Priority int          `json:"priority"`
+	Created  time.Time    `json:"created"`
+}
+
+// saveQueue writes all the unfinished jobs to a file. Please note
+// that the file contains complete job configurations including
+// possible database passwords so it is accessible only by its owner.
+func saveQueue(path string, jobs []*Job) error {
+	items := make([]queuedJob, 0, len(jobs))
+	for _, job := range jobs {
+		if job.IsDone() {
+			continue
+		}
+		items = append(items, queuedJob{
+			ID:       job.id,
+			Conf:     job.conf,
+			Append:   job.appendData,
+			Priority: job.priority,
+			Created:  job.created,
+		})
+	}
+	data, err := sonic.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+	return nil
+}
+
+// loadQueue loads previously stored unfinished jobs. All the jobs
+// are restored as pending (i.e. jobs interrupted while running
+// will start from the beginning). In case the file does not exist,
+// an empty list is returned.
+func loadQueue(path string) ([]*Job, error) {
+	if !fs.IsFile(path) {
+		return []*Job{}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job queue: %w", err)
+	}
+	var items []queuedJob
+	if err := sonic.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to load job queue: %w", err)
+	}
+	ans := make([]*Job, len(items))
+	for i, item := range items {
+		job := NewJob(item.Conf, item.Append, item.Priority)
+		job.id = item.ID
+		job.created = item.Created
+		ans[i] = job
+	}
+	return ans, nil
+}
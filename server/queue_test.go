@@ -0,0 +1,65 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueuePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	job1 := NewJob(&cnf.VTEConf{Corpus: "syn2015"}, false, 0)
+	job2 := NewJob(&cnf.VTEConf{Corpus: "intercorp_v13_en"}, true, 5)
+	job3 := NewJob(&cnf.VTEConf{Corpus: "foo"}, false, 0)
+	job3.setState(JobStateCancelled, nil)
+	err := saveQueue(path, []*Job{job1, job2, job3})
+	assert.NoError(t, err)
+
+	restored, err := loadQueue(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(restored))
+	assert.Equal(t, job1.ID(), restored[0].ID())
+	assert.Equal(t, "syn2015", restored[0].conf.Corpus)
+	assert.Equal(t, JobStatePending, restored[0].State())
+	assert.Equal(t, job2.ID(), restored[1].ID())
+	assert.True(t, restored[1].appendData)
+	assert.Equal(t, 5, restored[1].priority)
+}
+
+func TestLoadMissingQueue(t *testing.T) {
+	restored, err := loadQueue(filepath.Join(t.TempDir(), "nonexisting.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(restored))
+}
+
+func TestUnfinishedJobsOrder(t *testing.T) {
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1}
+	job1 := NewJob(&cnf.VTEConf{Corpus: "a"}, false, 0)
+	job2 := NewJob(&cnf.VTEConf{Corpus: "b"}, false, 10)
+	job3 := NewJob(&cnf.VTEConf{Corpus: "c"}, false, 0)
+	job1.created = time.Now().Add(-time.Minute)
+	for _, j := range []*Job{job1, job2, job3} {
+		srv.jobs[j.ID()] = j
+	}
+	ans := srv.unfinishedJobs()
+	assert.Equal(t, []string{"b", "a", "c"}, []string{ans[0].conf.Corpus, ans[1].conf.Corpus, ans[2].conf.Corpus})
+}
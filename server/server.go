@@ -0,0 +1,363 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	"github.com/rs/zerolog/log"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+)
+
+// SubmitArgs is a payload of the job submit request
+type SubmitArgs struct {
+	Conf     *cnf.VTEConf `json:"conf"`
+	Append   bool         `json:"append"`
+	Priority int          `json:"priority"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// Server provides a JSON API for submitting, watching and
+// cancelling extraction jobs:
+//
+//   - POST /jobs                - submit a job (see SubmitArgs)
+//   - GET /jobs                 - list all the jobs
+//   - GET /jobs/{id}            - get job information
+//   - GET /jobs/{id}/statuses   - stream job statuses as JSON lines
+//   - DELETE /jobs/{id}         - cancel the job
+//
+// Submitted jobs are queued and at most maxRunning of them run
+// concurrently. Pending jobs with higher priority go first, jobs
+// with the same priority are processed in the order of submission.
+// If queueFile is set, all the unfinished jobs are stored there so they
+// can be restored after the server restarts.
+//
+// Submitted jobs must not configure post-commit hooks, filter plug-ins
+// or piped vertical files as these would allow clients to run arbitrary
+// code on the server. Files written by submitted jobs (file-based
+// databases, manifests etc.) are confined to dataDir.
+// All the requests must provide authToken as a bearer token.
+type Server struct {
+	sync.Mutex
+	jobs         map[string]*Job
+	maxRunning   int
+	queueFile    string
+	authToken    string
+	dataDir      string
+	shuttingDown bool
+}
+
+func (s *Server) unfinishedJobs() []*Job {
+	ans := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		if !job.IsDone() {
+			ans = append(ans, job)
+		}
+	}
+	sort.SliceStable(ans, func(i, j int) bool {
+		if ans[i].priority != ans[j].priority {
+			return ans[i].priority > ans[j].priority
+		}
+		return ans[i].created.Before(ans[j].created)
+	})
+	return ans
+}
+
+// schedule starts pending jobs as long as there are free slots.
+// The method expects the server to be locked.
+func (s *Server) schedule() {
+	if s.shuttingDown {
+		return
+	}
+	unfinished := s.unfinishedJobs()
+	var numRunning int
+	for _, job := range unfinished {
+		if job.State() == JobStateRunning {
+			numRunning++
+		}
+	}
+	for _, job := range unfinished {
+		if numRunning >= s.maxRunning {
+			break
+		}
+		if job.State() == JobStatePending {
+			job.setState(JobStateRunning, nil)
+			numRunning++
+			go s.runJob(job)
+		}
+	}
+	s.persist(unfinished)
+}
+
+// persist stores the queue (if configured).
+// The method expects the server to be locked.
+func (s *Server) persist(unfinished []*Job) {
+	if s.queueFile == "" || s.shuttingDown {
+		return
+	}
+	if err := saveQueue(s.queueFile, unfinished); err != nil {
+		log.Error().Err(err).Msg("failed to persist job queue")
+	}
+}
+
+func (s *Server) runJob(job *Job) {
+	job.Run()
+	s.Lock()
+	defer s.Unlock()
+	s.schedule()
+}
+
+func (s *Server) getJob(id string) (*Job, bool) {
+	s.Lock()
+	defer s.Unlock()
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+func (s *Server) listJobs() []JobInfo {
+	s.Lock()
+	ans := make([]JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		ans = append(ans, job.Info())
+	}
+	s.Unlock()
+	sort.Slice(ans, func(i, j int) bool {
+		return ans[i].Created.Before(ans[j].Created)
+	})
+	return ans
+}
+
+// Submit adds a new job to the queue
+func (s *Server) Submit(conf *cnf.VTEConf, appendData bool, priority int) (*Job, error) {
+	if conf == nil {
+		return nil, fmt.Errorf("missing job configuration")
+	}
+	if conf.Corpus == "" {
+		return nil, fmt.Errorf("missing corpus name in job configuration")
+	}
+	if err := checkSubmittedConf(conf, s.dataDir); err != nil {
+		return nil, err
+	}
+	job := NewJob(conf, appendData, priority)
+	s.Lock()
+	defer s.Unlock()
+	s.jobs[job.ID()] = job
+	s.schedule()
+	return job, nil
+}
+
+// Cancel cancels a job and removes it from the queue
+func (s *Server) Cancel(job *Job) {
+	job.Cancel()
+	s.Lock()
+	defer s.Unlock()
+	s.schedule()
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var args SubmitArgs
+	if err := sonic.Unmarshal(data, &args); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse job: %w", err))
+		return
+	}
+	job, err := s.Submit(args.Conf, args.Append, args.Priority)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	log.Info().Str("jobId", job.ID()).Str("remoteAddr", r.RemoteAddr).Msg("accepted new job")
+	writeJSON(w, http.StatusCreated, job.Info())
+}
+
+func (s *Server) handleStatuses(w http.ResponseWriter, r *http.Request, job *Job) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	statuses := job.Subscribe()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case status, ok := <-statuses:
+			if !ok {
+				return
+			}
+			data, err := sonic.Marshal(status)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to serialize job status")
+				return
+			}
+			w.Write(data)
+			w.Write([]byte("\n"))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/jobs"), "/")
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.listJobs())
+		case http.MethodPost:
+			s.handleSubmit(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	items := strings.Split(path, "/")
+	job, ok := s.getJob(items[0])
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", items[0]))
+		return
+	}
+	if len(items) == 2 && items[1] == "statuses" && r.Method == http.MethodGet {
+		s.handleStatuses(w, r, job)
+		return
+	}
+	if len(items) > 1 {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, job.Info())
+	case http.MethodDelete:
+		s.Cancel(job)
+		log.Warn().Str("jobId", job.ID()).Str("remoteAddr", r.RemoteAddr).Msg("job cancelled")
+		writeJSON(w, http.StatusOK, job.Info())
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// isAuthorized tests the bearer token of a request. A server
+// without a token (which NewServer does not allow) rejects everything.
+func (s *Server) isAuthorized(r *http.Request) bool {
+	if s.authToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) == 1
+}
+
+// Handler returns an HTTP handler exposing the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", s.handleJobs)
+	mux.HandleFunc("/jobs/", s.handleJobs)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isAuthorized(r) {
+			log.Warn().Str("remoteAddr", r.RemoteAddr).Msg("unauthorized request")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("unauthorized"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Shutdown cancels all the pending and running jobs. In case
+// the queue is persistent, the unfinished jobs remain stored
+// and they will be restored once the server starts again.
+func (s *Server) Shutdown() {
+	s.Lock()
+	defer s.Unlock()
+	s.shuttingDown = true
+	for _, job := range s.jobs {
+		job.Cancel()
+	}
+}
+
+// NewServer creates a new job server. In case queueFile
+// is non-empty, the previously stored unfinished jobs are
+// restored and scheduled. A non-empty authToken is required
+// from all the API clients. In case dataDir is empty, submitted
+// jobs cannot write any files (i.e. only MySQL databases can be used).
+func NewServer(maxRunning int, queueFile, authToken, dataDir string) (*Server, error) {
+	if maxRunning < 1 {
+		return nil, fmt.Errorf("invalid max. number of concurrent jobs: %d", maxRunning)
+	}
+	if authToken == "" {
+		return nil, fmt.Errorf("missing auth. token")
+	}
+	if dataDir != "" {
+		var err error
+		dataDir, err = filepath.Abs(dataDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data directory: %w", err)
+		}
+	}
+	ans := &Server{
+		jobs:       make(map[string]*Job),
+		maxRunning: maxRunning,
+		queueFile:  queueFile,
+		authToken:  authToken,
+		dataDir:    dataDir,
+	}
+	if queueFile != "" {
+		restored, err := loadQueue(queueFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range restored {
+			ans.jobs[job.ID()] = job
+		}
+		if len(restored) > 0 {
+			log.Info().Int("numJobs", len(restored)).Msg("restored unfinished jobs")
+		}
+		ans.Lock()
+		ans.schedule()
+		ans.Unlock()
+	}
+	return ans, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitRejectsCodeExecution(t *testing.T) {
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1, shuttingDown: true}
+	_, err := srv.Submit(
+		&cnf.VTEConf{
+			Corpus:          "syn2015",
+			PostCommitHooks: []cnf.PostCommitHookConf{{Command: []string{"rm", "-rf", "/"}}},
+		},
+		false, 0,
+	)
+	assert.Error(t, err)
+	_, err = srv.Submit(
+		&cnf.VTEConf{Corpus: "syn2015", Filter: cnf.FilterConf{Lib: "evil.so", Fn: "Filter"}},
+		false, 0,
+	)
+	assert.Error(t, err)
+	_, err = srv.Submit(
+		&cnf.VTEConf{
+			Corpus: "syn2015",
+			Ngrams: cnf.NgramConf{Filter: &cnf.FilterConf{Lib: "evil.so", Fn: "Filter"}},
+		},
+		false, 0,
+	)
+	assert.Error(t, err)
+	_, err = srv.Submit(
+		&cnf.VTEConf{
+			Corpus: "syn2015",
+			Degradation: cnf.DegradationConf{
+				Steps: []cnf.DegradationStep{{Filter: &cnf.FilterConf{Lib: "evil.so", Fn: "Filter"}}},
+			},
+		},
+		false, 0,
+	)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(srv.jobs))
+}
+
+func TestSubmitRejectsPipedVerticals(t *testing.T) {
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1, shuttingDown: true}
+	_, err := srv.Submit(&cnf.VTEConf{Corpus: "syn2015", VerticalFile: "| rm -rf /"}, false, 0)
+	assert.Error(t, err)
+	_, err = srv.Submit(
+		&cnf.VTEConf{Corpus: "syn2015", VerticalFiles: []string{"/data/a.vert", " |curl evil"}}, false, 0)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(srv.jobs))
+}
+
+func TestSubmitConfinesPaths(t *testing.T) {
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1, shuttingDown: true}
+	_, err := srv.Submit(
+		&cnf.VTEConf{Corpus: "syn2015", DB: db.Conf{Type: "sqlite", Name: "/srv/vte/syn2015.db"}}, false, 0)
+	assert.Error(t, err)
+
+	srv.dataDir = "/srv/vte"
+	job, err := srv.Submit(
+		&cnf.VTEConf{
+			Corpus:  "syn2015",
+			DB:      db.Conf{Type: "sqlite", Name: "syn2015.db"},
+			KonText: cnf.KonTextConf{Path: "/srv/vte/kontext/syn2015.json"},
+		},
+		false, 0,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "/srv/vte/syn2015.db", job.conf.DB.Name)
+	assert.Equal(t, "/srv/vte/kontext/syn2015.json", job.conf.KonText.Path)
+
+	for _, conf := range []*cnf.VTEConf{
+		{Corpus: "syn2015", DB: db.Conf{Type: "sqlite", Name: "../syn2015.db"}},
+		{Corpus: "syn2015", DB: db.Conf{Type: "mysql", Mirrors: []db.Conf{{Type: "sqlite", Name: "/tmp/x.db"}}}},
+		{Corpus: "syn2015", DB: db.Conf{Type: "sqlite", SQLite: db.SQLiteConf{Extensions: []string{"evil.so"}}}},
+		{Corpus: "syn2015", Manifest: cnf.ManifestConf{Enabled: true, Path: "/etc/cron.d/vte"}},
+		{Corpus: "syn2015", Anonymize: cnf.AnonymizeConf{MappingFile: "/root/.ssh/authorized_keys"}},
+		{Corpus: "syn2015", Ngrams: cnf.NgramConf{WordDictFile: "/srv/vte-other/dict.txt"}},
+	} {
+		_, err := srv.Submit(conf, false, 0)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, len(srv.jobs))
+}
+
+func TestSubmitRejectsServerSecrets(t *testing.T) {
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1, shuttingDown: true}
+	t.Setenv("VTE_TEST_DB_PASSWORD", "secret")
+	for _, dbConf := range []db.Conf{
+		{PasswordEnv: "VTE_TEST_DB_PASSWORD"},
+		{PasswordFile: "/etc/vte/db-password"},
+		{Mirrors: []db.Conf{{PasswordEnv: "VTE_TEST_DB_PASSWORD"}}},
+		{DSNParams: map[string]string{"allowCleartextPasswords": "true"}},
+	} {
+		_, err := srv.Submit(&cnf.VTEConf{Corpus: "syn2015", DB: dbConf}, false, 0)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 0, len(srv.jobs))
+
+	job, err := srv.Submit(
+		&cnf.VTEConf{Corpus: "syn2015", DB: db.Conf{Type: "mysql", Password: "client-secret"}}, false, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "client-secret", job.conf.DB.Password)
+}
+
+func TestHandlerAuthToken(t *testing.T) {
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1, authToken: "secret"}
+	handler := srv.Handler()
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/jobs", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestNewServerRequiresAuthToken(t *testing.T) {
+	_, err := NewServer(1, "", "", "")
+	assert.Error(t, err)
+
+	srv := &Server{jobs: make(map[string]*Job), maxRunning: 1}
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
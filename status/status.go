@@ -0,0 +1,180 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package status defines data describing progress and results of
+// extraction runs for programs consuming them (e.g. services embedding
+// vert-tagextract or clients of `vte serve`). The package has no
+// dependencies except of the standard library so it can be imported
+// without pulling in the extraction itself.
+//
+// Compatibility: within a major version of vert-tagextract, existing
+// types, fields and their JSON names are neither removed nor renamed
+// and their meaning does not change. New fields may be added (with
+// `omitempty` JSON encoding) so consumers should ignore unknown fields.
+package status
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// FileOutcomeOK - the file has been processed successfully
+	FileOutcomeOK = "ok"
+
+	// FileOutcomeSkipped - the file processing failed and its data
+	// have been discarded (see cnf.FileErrorSkip)
+	FileOutcomeSkipped = "skipped"
+
+	// FileOutcomeFailed - the file processing failed and the whole
+	// run has been stopped
+	FileOutcomeFailed = "failed"
+)
+
+// ColumnCountViolation describes a token line with a number
+// of columns different from the first token line of the run
+type ColumnCountViolation struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	NumColumns int    `json:"numColumns"`
+	Expected   int    `json:"expected"`
+}
+
+func (v ColumnCountViolation) Error() string {
+	return fmt.Sprintf(
+		"line %d: token has %d columns, expected %d", v.Line, v.NumColumns, v.Expected)
+}
+
+// Record is a single status reported during a run
+// (see proc.Status for the meaning of the fields)
+type Record struct {
+	Datetime       time.Time `json:"datetime"`
+	File           string    `json:"file"`
+	ProcessedAtoms int       `json:"processedAtoms"`
+	ProcessedLines int       `json:"processedLines"`
+	Error          string    `json:"error,omitempty"`
+
+	// FileSummary is set once a file is processed
+	FileSummary *FileSummary `json:"fileSummary,omitempty"`
+
+	ErrorCategory        string                `json:"errorCategory,omitempty"`
+	MissingColumns       int                   `json:"missingColumns,omitempty"`
+	ProcessedTokens      int                   `json:"processedTokens,omitempty"`
+	AcceptedTokens       int                   `json:"acceptedTokens,omitempty"`
+	NormalizedValues     int                   `json:"normalizedValues,omitempty"`
+	ColumnCountViolation *ColumnCountViolation `json:"columnCountViolation,omitempty"`
+}
+
+// FileSummary contains information about a single processed
+// vertical file
+type FileSummary struct {
+	File            string    `json:"file"`
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+	ProcessedLines  int       `json:"processedLines"`
+	ProcessedAtoms  int       `json:"processedAtoms"`
+	ProcessedTokens int       `json:"processedTokens"`
+	AcceptedTokens  int       `json:"acceptedTokens"`
+	NumErrors       int       `json:"numErrors"`
+	MissingColumns  int       `json:"missingColumns"`
+	LastError       string    `json:"lastError,omitempty"`
+
+	// Outcome is one of FileOutcomeOK, FileOutcomeSkipped, FileOutcomeFailed
+	Outcome string `json:"outcome,omitempty"`
+
+	// Attempts is a number of processing attempts (more than one
+	// only with the cnf.FileErrorRetry policy)
+	Attempts int `json:"attempts,omitempty"`
+
+	// NormalizedValues is a number of structural attribute values
+	// changed by whitespace normalization (see cnf.VTEConf.Whitespace)
+	NormalizedValues int `json:"normalizedValues,omitempty"`
+}
+
+// Duration returns processing time of the file
+func (fs *FileSummary) Duration() time.Duration {
+	return fs.Finished.Sub(fs.Started)
+}
+
+// Summary contains overall information about a finished
+// extraction run
+type Summary struct {
+	Corpus         string    `json:"corpus"`
+	Started        time.Time `json:"started"`
+	Finished       time.Time `json:"finished"`
+	Failed         bool      `json:"failed"`
+	ProcessedFiles int       `json:"processedFiles"`
+	ProcessedAtoms int       `json:"processedAtoms"`
+	ProcessedLines int       `json:"processedLines"`
+	NumErrors      int       `json:"numErrors"`
+	MissingColumns int       `json:"missingColumns"`
+
+	// ProcessedTokens and AcceptedTokens (by the configured filter)
+	// are totals of all the processed files
+	ProcessedTokens int `json:"processedTokens"`
+	AcceptedTokens  int `json:"acceptedTokens"`
+
+	// NormalizedValues is a total of FileSummary.NormalizedValues
+	NormalizedValues int `json:"normalizedValues,omitempty"`
+
+	LastError string `json:"lastError,omitempty"`
+
+	// ErrorsByCategory contains numbers of errors
+	// of individual categories (see cnf.ErrorCategories)
+	ErrorsByCategory map[string]int `json:"errorsByCategory,omitempty"`
+
+	// ColumnCountViolations contains locations of token lines with
+	// an unexpected number of columns (up to a limit, see
+	// proc.MaxReportedColumnCountViolations)
+	ColumnCountViolations    []ColumnCountViolation `json:"columnCountViolations,omitempty"`
+	NumColumnCountViolations int                    `json:"numColumnCountViolations,omitempty"`
+
+	// DegradationStep is a 1-based index of a degradation step
+	// used for the run (zero means the original settings)
+	DegradationStep int `json:"degradationStep,omitempty"`
+
+	// Files contains results of individual files in the order
+	// of processing
+	Files []*FileSummary `json:"files,omitempty"`
+
+	// SkippedFiles is a number of failed files with their
+	// data discarded (see cnf.FileErrorSkip)
+	SkippedFiles int `json:"skippedFiles,omitempty"`
+
+	// Structures contains numbers of all the encountered structures
+	// (including the atom one) in successfully processed files
+	Structures map[string]int `json:"structures,omitempty"`
+
+	// HookErrors contains errors of failed post-commit hooks
+	// (the data are committed even if a hook fails)
+	HookErrors []string `json:"hookErrors,omitempty"`
+}
+
+// FailedFiles returns summaries of files with at least one error
+func (s *Summary) FailedFiles() []*FileSummary {
+	ans := make([]*FileSummary, 0, len(s.Files))
+	for _, fs := range s.Files {
+		if fs.NumErrors > 0 {
+			ans = append(ans, fs)
+		}
+	}
+	return ans
+}
+
+// Duration returns processing time
+func (s *Summary) Duration() time.Duration {
+	return s.Finished.Sub(s.Started)
+}
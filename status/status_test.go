@@ -0,0 +1,60 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryJSON(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := Summary{
+		Corpus:         "susanne",
+		Started:        started,
+		Finished:       started.Add(time.Minute),
+		ProcessedFiles: 1,
+		Files: []*FileSummary{
+			{File: "a.vrt", NumErrors: 1, Outcome: FileOutcomeOK},
+		},
+	}
+	data, err := json.Marshal(s)
+	assert.NoError(t, err)
+	var raw map[string]any
+	assert.NoError(t, json.Unmarshal(data, &raw))
+	for _, k := range []string{"corpus", "started", "finished", "failed", "processedFiles", "files"} {
+		assert.Contains(t, raw, k)
+	}
+	var decoded Summary
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, time.Minute, decoded.Duration())
+	assert.Len(t, decoded.FailedFiles(), 1)
+}
+
+func TestRecordJSON(t *testing.T) {
+	rec := Record{File: "a.vrt", ProcessedLines: 10, Error: "broken line"}
+	data, err := json.Marshal(rec)
+	assert.NoError(t, err)
+	var raw map[string]any
+	assert.NoError(t, json.Unmarshal(data, &raw))
+	assert.Equal(t, "broken line", raw["error"])
+	assert.NotContains(t, raw, "fileSummary")
+	assert.NotContains(t, raw, "errorCategory")
+}
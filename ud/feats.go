@@ -0,0 +1,93 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ud provides helpers for Universal Dependencies
+// morphological features (the FEATS column).
+package ud
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// EmptyFeats represents a token without any features
+	EmptyFeats = "_"
+
+	featSeparator   = "|"
+	valueSeparator  = "="
+	multiValueDelim = ","
+)
+
+// Feat is a single feature (e.g. Case=Nom)
+type Feat struct {
+	Name  string
+	Value string
+}
+
+func (f Feat) String() string {
+	return f.Name + valueSeparator + f.Value
+}
+
+// Feats is a list of features of a token
+type Feats []Feat
+
+// Key returns a normalized representation of the features
+// where features are sorted by their names (and values), so
+// equivalent feature strings with different ordering produce
+// the same key. Empty features produce EmptyFeats.
+func (ff Feats) Key() string {
+	if len(ff) == 0 {
+		return EmptyFeats
+	}
+	tmp := make([]string, len(ff))
+	for i, f := range ff {
+		tmp[i] = f.String()
+	}
+	return strings.Join(tmp, featSeparator)
+}
+
+// ParseFeats parses a FEATS value (e.g. "Number=Sing|Case=Nom").
+// The returned features are sorted and multi-values (e.g. "Case=Nom,Acc")
+// are normalized to be sorted too.
+func ParseFeats(s string) (Feats, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == EmptyFeats {
+		return Feats{}, nil
+	}
+	items := strings.Split(s, featSeparator)
+	ans := make(Feats, 0, len(items))
+	for _, item := range items {
+		if item == "" {
+			continue
+		}
+		tmp := strings.SplitN(item, valueSeparator, 2)
+		if len(tmp) != 2 || tmp[0] == "" {
+			return Feats{}, fmt.Errorf("unparseable feature '%s'", item)
+		}
+		values := strings.Split(tmp[1], multiValueDelim)
+		sort.Strings(values)
+		ans = append(ans, Feat{Name: tmp[0], Value: strings.Join(values, multiValueDelim)})
+	}
+	sort.Slice(ans, func(i, j int) bool {
+		if ans[i].Name != ans[j].Name {
+			return ans[i].Name < ans[j].Name
+		}
+		return ans[i].Value < ans[j].Value
+	})
+	return ans, nil
+}
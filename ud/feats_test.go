@@ -0,0 +1,43 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFeatsKeyOrdering(t *testing.T) {
+	f1, err := ParseFeats("Number=Sing|Case=Nom,Acc|Gender=Fem")
+	assert.NoError(t, err)
+	f2, err := ParseFeats("Gender=Fem|Case=Acc,Nom|Number=Sing")
+	assert.NoError(t, err)
+	assert.Equal(t, "Case=Acc,Nom|Gender=Fem|Number=Sing", f1.Key())
+	assert.Equal(t, f1.Key(), f2.Key())
+}
+
+func TestParseFeatsEmpty(t *testing.T) {
+	f, err := ParseFeats("_")
+	assert.NoError(t, err)
+	assert.Equal(t, EmptyFeats, f.Key())
+}
+
+func TestParseFeatsInvalid(t *testing.T) {
+	_, err := ParseFeats("Number")
+	assert.Error(t, err)
+}
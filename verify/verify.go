@@ -0,0 +1,239 @@
+// Copyright 2026 Tomas Machalek <tomas.machalek@gmail.com>
+// Copyright 2026 Charles University, Faculty of Arts,
+//                Institute of the Czech National Corpus
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verify compares data derived from a vertical file
+// with an existing database without modifying the database.
+package verify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+// maxExamples is a max. number of reported mismatching atoms
+const maxExamples = 10
+
+// Report describes differences between a vertical
+// and the data stored in a database
+type Report struct {
+	Corpus    string   `json:"corpus"`
+	Verticals []string `json:"verticals"`
+
+	// Sampled is true in case only a part of
+	// the vertical has been processed
+	Sampled bool `json:"sampled"`
+
+	// ParsedAtoms is a number of atoms found in the vertical
+	// (or its processed part in case of sampling)
+	ParsedAtoms int `json:"parsedAtoms"`
+
+	// StoredAtoms is a number of atoms stored in the database
+	StoredAtoms int `json:"storedAtoms"`
+
+	// MissingAtoms is a number of parsed atoms with no matching
+	// row (i.e. with the same attribute values) in the database
+	MissingAtoms int `json:"missingAtoms"`
+
+	// Examples contains descriptions of some mismatching atoms
+	Examples []string `json:"examples"`
+}
+
+// HasDrift tests whether the database does not match the vertical.
+// With sampling, atom totals are not compared.
+func (r *Report) HasDrift() bool {
+	return r.MissingAtoms > 0 || !r.Sampled && r.ParsedAtoms != r.StoredAtoms
+}
+
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "corpus: %s\n", r.Corpus)
+	for _, v := range r.Verticals {
+		fmt.Fprintf(w, "  vertical: %s\n", v)
+	}
+	if r.Sampled {
+		fmt.Fprintf(w, "sampled atoms: %d (totals not compared)\n", r.ParsedAtoms)
+
+	} else {
+		fmt.Fprintf(w, "atoms in vertical: %d\n", r.ParsedAtoms)
+	}
+	fmt.Fprintf(w, "atoms in database: %d\n", r.StoredAtoms)
+	fmt.Fprintf(w, "atoms not found in database: %d\n", r.MissingAtoms)
+	for _, ex := range r.Examples {
+		fmt.Fprintf(w, "    %s\n", ex)
+	}
+	if r.HasDrift() {
+		fmt.Fprintln(w, "result: the database does not match the vertical")
+
+	} else {
+		fmt.Fprintln(w, "result: no drift found")
+	}
+}
+
+// Checker is a db.Writer which, instead of writing, looks up
+// each atom (a row of db.LiveAttrsTable) in an existing database.
+// Other data (e.g. n-gram counts) are ignored. This allows reusing
+// the extraction logic so values are compared exactly the way
+// they would be written.
+type Checker struct {
+	reader   *db.Reader
+	report   *Report
+	maxAtoms int
+
+	// onLimit is called once maxAtoms atoms are checked
+	onLimit func()
+}
+
+// NewChecker creates a new Checker. With maxAtoms > 0, onLimit
+// is called once the number of checked atoms reaches the value
+// (typically to stop the parsing).
+func NewChecker(reader *db.Reader, report *Report, maxAtoms int, onLimit func()) *Checker {
+	return &Checker{reader: reader, report: report, maxAtoms: maxAtoms, onLimit: onLimit}
+}
+
+func (c *Checker) DatabaseExists() bool {
+	return true
+}
+
+func (c *Checker) Initialize(appendMode bool) error {
+	return nil
+}
+
+func (c *Checker) CreateBibView(cols []string, idAttr string) error {
+	return nil
+}
+
+func (c *Checker) PrepareInsert(table string, attrs []string) (db.InsertOperation, error) {
+	if table != db.LiveAttrsTable {
+		return nullInsert{}, nil
+	}
+	return &atomLookup{checker: c, attrs: attrs}, nil
+}
+
+func (c *Checker) SetRunMetadata(corpusID string, values map[string]string) error {
+	return nil
+}
+
+func (c *Checker) SetStats(corpusID string, values map[string]int) error {
+	return nil
+}
+
+func (c *Checker) SetColumnInfo(corpusID string, values []db.ColumnInfo) error {
+	return nil
+}
+
+func (c *Checker) AddAttrValues(corpusID string, values []db.AttrValueCount) error {
+	return nil
+}
+
+func (c *Checker) AddAttrPairs(corpusID string, values []db.AttrPairCount) error {
+	return nil
+}
+
+func (c *Checker) DeleteCorpus(corpusID string) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (c *Checker) Commit() error {
+	return nil
+}
+
+func (c *Checker) Savepoint(name string) error {
+	return nil
+}
+
+func (c *Checker) RollbackToSavepoint(name string) error {
+	return nil
+}
+
+func (c *Checker) Rollback() error {
+	return nil
+}
+
+func (c *Checker) Close() {}
+
+func (c *Checker) checkAtom(attrs []string, values []any) error {
+	if c.maxAtoms > 0 && c.report.ParsedAtoms >= c.maxAtoms {
+		return nil
+	}
+	c.report.ParsedAtoms++
+	where := make([]string, 0, len(attrs))
+	args := make([]any, 0, len(attrs))
+	descr := make([]string, 0, len(attrs))
+	for i, attr := range attrs {
+		v := fmt.Sprint(values[i])
+		if values[i] == nil || v == "" {
+			where = append(where, fmt.Sprintf("(%s IS NULL OR %s = '')", attr, attr))
+			continue
+		}
+		where = append(where, attr+" = ?")
+		args = append(args, values[i])
+		if attr != "corpus_id" {
+			descr = append(descr, attr+"="+v)
+		}
+	}
+	var cnt int
+	err := c.reader.DB.QueryRow(
+		fmt.Sprintf(
+			"SELECT COUNT(*) FROM %s WHERE %s",
+			c.reader.Table(db.LiveAttrsTable), strings.Join(where, " AND ")),
+		args...,
+	).Scan(&cnt)
+	if err != nil {
+		return fmt.Errorf("failed to look up atom: %w", err)
+	}
+	if cnt == 0 {
+		c.report.MissingAtoms++
+		if len(c.report.Examples) < maxExamples {
+			c.report.Examples = append(c.report.Examples, strings.Join(descr, ", "))
+		}
+	}
+	if c.maxAtoms > 0 && c.report.ParsedAtoms == c.maxAtoms && c.onLimit != nil {
+		c.onLimit()
+	}
+	return nil
+}
+
+type atomLookup struct {
+	checker *Checker
+	attrs   []string
+}
+
+func (al *atomLookup) Exec(values ...any) error {
+	return al.checker.checkAtom(al.attrs, values)
+}
+
+func (al *atomLookup) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return al.Exec(values...)
+}
+
+type nullInsert struct{}
+
+func (ni nullInsert) Exec(values ...any) error {
+	return nil
+}
+
+func (ni nullInsert) ExecContext(ctx context.Context, values ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ni.Exec(values...)
+}